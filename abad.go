@@ -1,94 +1,1082 @@
 package abad
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"github.com/NeowayLabs/abad/ast"
 	"github.com/NeowayLabs/abad/builtins"
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/scope"
+	"github.com/NeowayLabs/abad/sourcemap"
 	"github.com/NeowayLabs/abad/token"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
 	// Abad interpreter, a very bad one.
 	Abad struct {
 		global *types.DataObject
+
+		// env is the environment of the code being evaluated
+		env *environment
+
+		// scope analysis of the code being evaluated
+		scope *scope.Scope
+
+		onUncaughtException UncaughtExceptionHandler
+		onWarning           WarningHandler
+
+		// ops is the number of evaluation steps of the current
+		// evaluation, limited by maxOps when metered is true.
+		ops     uint
+		maxOps  uint
+		metered bool
+
+		// interrupted is set atomically by Interrupt and cleared
+		// by the step that stops the evaluation
+		interrupted int32
+
+		// coroutine being evaluated, if any
+		coroutine *Coroutine
+
+		// depth is the number of user function calls being
+		// evaluated, limited by maxDepth
+		depth    int
+		maxDepth int
+
+		// heap counts the objects reachable by the scripts
+		heap *types.Heap
+
+		// quietOps is the number of steps since the last console
+		// output, onLongEvaluation is called when it reaches
+		// longOps.
+		quietOps         uint
+		longOps          uint
+		onLongEvaluation LongEvaluationHandler
+
+		random func() float64
+		now    func() time.Time
+		loc    *time.Location
+		caps   Capabilities
+
+		// stdout is the output of the console
+		stdout io.Writer
+
+		// file being evaluated and its code. The source map of
+		// the code is loaded by sourceMap when an error needs it.
+		file      string
+		code      string
+		smap      *sourcemap.Map
+		smapReady bool
+		loadMap   SourceMapLoader
+
+		parserOpts []parser.Option
+		completion CompletionMode
+		trapNaN    bool
 	}
+
+	// Option configures the interpreter on its creation.
+	Option func(*Abad)
+
+	// CompletionMode tells what the evaluation of code returns.
+	CompletionMode int
+
+	// UncaughtExceptionHandler is called with the exception thrown
+	// by every statement and not caught by the script. It returns
+	// true if the evaluation must continue with the next statement
+	// or false if it must be aborted. It's never called with the
+	// errors that aren't exceptions, eg.: ErrBudgetExceeded, which
+	// always abort the evaluation.
+	UncaughtExceptionHandler func(err *JSError) bool
+
+	// WarningHandler is called with the warnings of the parser and
+	// of the evaluation, eg.: the assignment of an undeclared name.
+	WarningHandler func(w parser.Warning)
+
+	// LongEvaluationHandler is called when an evaluation runs many
+	// steps without output, with the number of steps run so far.
+	// It returns true if the evaluation must continue or false if
+	// it must be interrupted.
+	LongEvaluationHandler func(steps uint) bool
+
+	// SourceMapLoader loads the source map referenced by the
+	// sourceMappingURL comment of the code of file.
+	SourceMapLoader func(file, url string) (*sourcemap.Map, error)
+)
+
+const (
+	// CompletionLast returns the value of the last statement,
+	// as echoed by a REPL. It's the default.
+	CompletionLast CompletionMode = iota
+
+	// CompletionScript returns undefined, scripts are run for
+	// their effects and don't produce values.
+	CompletionScript
 )
 
 var (
 	consoleAttr = utf16.S("console")
+	mathAttr    = utf16.S("Math")
+	dateAttr    = utf16.S("Date")
+	arrayAttr   = utf16.S("Array")
+	objectAttr  = utf16.S("Object")
+	jsonAttr    = utf16.S("JSON")
+
+	parseIntAttr  = utf16.S("parseInt")
+	undefinedAttr = utf16.S("undefined")
 )
 
-// NewAbad creates a new ecma script evaluator.
-func NewAbad() (*Abad, error) {
-	a := &Abad{}
-	return a, a.setup()
-}
+// DefaultMaxCallDepth is the number of nested function calls allowed
+// by default, see MaxCallDepth.
+const DefaultMaxCallDepth = 10000
+
+// ErrBudgetExceeded is returned when an evaluation consumes all
+// the operations of its budget.
+var ErrBudgetExceeded = errors.New("evaluation budget exceeded")
+
+// ErrInterrupted is returned when an evaluation is stopped by Interrupt.
+var ErrInterrupted = errors.New("evaluation interrupted")
+
+// NewAbad creates a new ecma script evaluator.
+func NewAbad(opts ...Option) (*Abad, error) {
+	a := &Abad{
+		random: rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		now:    time.Now,
+		caps:   CapPure,
+		stdout: os.Stdout,
+		heap:   types.NewHeap(),
+
+		maxDepth: DefaultMaxCallDepth,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.loc == nil {
+		a.loc = time.Local
+	}
+
+	return a, a.setup()
+}
+
+// Deterministic makes every run of the same code produce the same
+// results, Math.random is seeded with seed, Date.now is frozen
+// on epoch and the local timezone is UTC, unless it's set by the
+// Timezone option.
+func Deterministic(seed int64, epoch time.Time) Option {
+	return func(a *Abad) {
+		a.random = rand.New(rand.NewSource(seed)).Float64
+		a.now = func() time.Time {
+			return epoch
+		}
+		if a.loc == nil {
+			a.loc = time.UTC
+		}
+	}
+}
+
+// Timezone sets the local timezone of dates, by default it's the
+// timezone of the host.
+func Timezone(loc *time.Location) Option {
+	return func(a *Abad) {
+		a.loc = loc
+	}
+}
+
+// Stdout sets the writer of the console output, by default it's
+// os.Stdout. A buffered writer must be flushed by the caller, eg.:
+// when the evaluation ends.
+func Stdout(w io.Writer) Option {
+	return func(a *Abad) {
+		a.stdout = w
+	}
+}
+
+// ParserOptions configures how the evaluated code is parsed.
+func ParserOptions(opts ...parser.Option) Option {
+	return func(a *Abad) {
+		a.parserOpts = append(a.parserOpts, opts...)
+	}
+}
+
+// Completion sets what Eval, EvalFile and EvalFiles return.
+func Completion(mode CompletionMode) Option {
+	return func(a *Abad) {
+		a.completion = mode
+	}
+}
+
+// TrapNaN makes arithmetic operations and calls to builtin functions
+// that produce NaN from operands that aren't NaN throw an error, eg.:
+// 0/0, "a"*2 or parseInt("px"). It's a diagnostic mode to find where
+// a NaN comes from, instead of seeing it propagate silently.
+func TrapNaN() Option {
+	return func(a *Abad) {
+		a.trapNaN = true
+	}
+}
+
+// MaxCallDepth sets the number of nested function calls allowed,
+// deeper calls throw a RangeError instead of exhausting the stack of
+// the host, eg.: on unbounded recursion.
+func MaxCallDepth(depth int) Option {
+	return func(a *Abad) {
+		a.maxDepth = depth
+	}
+}
+
+// SourceMaps makes the interpreter load the source maps that aren't
+// inline with load, eg.: from files, if the sandbox has CapFiles.
+// By default only inline data URLs are loaded.
+func SourceMaps(load SourceMapLoader) Option {
+	return func(a *Abad) {
+		a.loadMap = load
+	}
+}
+
+// LoadSourceMapFile is a SourceMapLoader resolving relative paths
+// from the directory of the file, as given to EvalFile.
+func LoadSourceMapFile(file, url string) (*sourcemap.Map, error) {
+	return sourcemap.Load(url, filepath.Dir(file))
+}
+
+// Eval the code when no filename is involved (interactive/repl mode).
+func (a *Abad) Eval(code string) (types.Value, error) {
+	return a.EvalFile("<interactive>", code)
+}
+
+// EvalFile the code that was obtained from filename.
+func (a *Abad) EvalFile(filename string, code string) (types.Value, error) {
+	a.begin()
+	program, err := parser.Parse(filename, code, a.parserOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("parser error: %s", err)
+	}
+
+	a.beginFile(filename, code)
+	a.warnUndeclared(a.analyze(program))
+
+	val, err := a.eval(program)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.complete(val), nil
+}
+
+// EvalFiles parses all files concurrently and then evaluates them
+// in the given order, returning the value of the last one.
+func (a *Abad) EvalFiles(files []parser.File) (types.Value, error) {
+	a.begin()
+	programs, err := parser.ParseFiles(files, a.parserOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("parser error: %s", err)
+	}
+
+	var result types.Value
+	for i, program := range programs {
+		a.beginFile(files[i].Name, files[i].Code)
+		a.warnUndeclared(a.analyze(program))
+		result, err = a.eval(program)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a.complete(result), nil
+}
+
+// beginFile sets the file being evaluated, forgetting the source map
+// of the previous one.
+func (a *Abad) beginFile(filename, code string) {
+	a.file = filename
+	a.code = code
+	a.smap = nil
+	a.smapReady = false
+}
+
+// sourceMap loads, once per file, the source map referenced by the
+// code being evaluated, so the positions of the errors are mapped to
+// the original sources. It's only called for errors, evaluations that
+// succeed never load it. Source maps that can't be loaded are
+// reported as warnings.
+func (a *Abad) sourceMap() *sourcemap.Map {
+	if a.smapReady {
+		return a.smap
+	}
+	a.smapReady = true
+
+	url, ok := sourcemap.URL(a.code)
+	if !ok {
+		return nil
+	}
+
+	var (
+		smap *sourcemap.Map
+		err  error
+	)
+
+	switch {
+	case sourcemap.IsInline(url):
+		smap, err = sourcemap.LoadInline(url)
+	case a.loadMap == nil:
+		err = errors.New("only inline source maps are loaded")
+	case !a.caps.Has(CapFiles):
+		err = errors.New("the sandbox doesn't allow reading files")
+	default:
+		smap, err = a.loadMap(a.file, url)
+	}
+
+	if err != nil {
+		a.warnf("ignoring source map %s: %s", url, err)
+		return nil
+	}
+
+	a.smap = smap
+	return smap
+}
+
+// analyze resolves the names of program before its evaluation. The
+// functions it creates get the slots of their environments from the
+// analysis. The globals are resolved only to report the undeclared
+// names, if there's a warning handler.
+func (a *Abad) analyze(program *ast.Program) *scope.Result {
+	var globals []string
+	if a.onWarning != nil {
+		for _, key := range a.global.OwnPropertyKeys(types.AllKeys) {
+			globals = append(globals, key.String())
+		}
+	}
+
+	result := scope.Analyze(program, globals)
+	a.scope = result.Global
+	return result
+}
+
+// warnUndeclared reports the names referenced by the analyzed program
+// that are neither declared by it nor properties of the global object,
+// eg.: typos. Names assigned by the program aren't reported, because
+// the assignments creating globals are reported when evaluated.
+func (a *Abad) warnUndeclared(result *scope.Result) {
+	if a.onWarning == nil {
+		return
+	}
+
+	skip := map[string]bool{}
+	for _, ref := range result.Refs {
+		if ref.Assigned && !ref.Resolved {
+			skip[ref.Ident.String()] = true
+		}
+	}
+
+	for _, ref := range result.Refs {
+		name := ref.Ident.String()
+		if ref.Resolved || skip[name] {
+			continue
+		}
+
+		skip[name] = true
+		a.warnf("%s is not declared", name)
+	}
+}
+
+// EvalWithBudget evaluates the code (like Eval) but aborting with
+// ErrBudgetExceeded if it needs more than maxOps evaluation steps.
+// It returns the number of steps consumed, which only depends on the
+// code being evaluated, never on the load of the host.
+func (a *Abad) EvalWithBudget(code string, maxOps uint) (types.Value, uint, error) {
+	a.metered = true
+	a.maxOps = maxOps
+
+	defer func() {
+		a.metered = false
+	}()
+
+	val, err := a.Eval(code)
+	return val, a.ops, err
+}
+
+// EvalExprWithScope evaluates a single expression, eg.: a.b + 1,
+// with the names of scope declared in a temporary environment over
+// the global one, so they shadow the globals only for this expression.
+// It's meant to use the interpreter as an expression engine, of rules
+// or templates, with the data given by the host.
+//
+// The scope values can be types.Value, nil (null), bool, string,
+// Go numbers, and []interface{} and map[string]interface{} of them,
+// which are copied as arrays and objects.
+func (a *Abad) EvalExprWithScope(expr string, scope map[string]interface{}) (types.Value, error) {
+	a.begin()
+	node, err := parser.ParseExpr("<expr>", expr, a.parserOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("parser error: %s", err)
+	}
+
+	bindings := types.NewDataObject(types.Null)
+	for name, goval := range scope {
+		val, err := toValue(goval)
+		if err != nil {
+			return nil, fmt.Errorf("scope %s: %s", name, err)
+		}
+
+		_, err = bindings.DefineOwnPropertyP(utf16.S(name),
+			types.NewDataPropDesc(val, true, true, false), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a.file = "<expr>"
+	a.analyze(&ast.Program{Nodes: []ast.Node{node}})
+
+	outer := a.env
+	a.env = a.newEnvironment(bindings, outer)
+	defer func() {
+		a.env = outer
+	}()
+
+	val, err := a.eval(node)
+	if err != nil {
+		return nil, uncaught(err, a.file, a.sourceMap)
+	}
+
+	return val, nil
+}
+
+// toValue converts a Go value of the host to a value of the script.
+func toValue(goval interface{}) (types.Value, error) {
+	switch v := goval.(type) {
+	case nil:
+		return types.Null, nil
+	case types.Value:
+		return v, nil
+	case bool:
+		return types.NewBool(v), nil
+	case string:
+		return types.NewString(v), nil
+	case float64:
+		return types.NewNumber(v), nil
+	case float32:
+		return types.NewNumber(float64(v)), nil
+	case int:
+		return types.NewNumber(float64(v)), nil
+	case int32:
+		return types.NewNumber(float64(v)), nil
+	case int64:
+		return types.NewNumber(float64(v)), nil
+	case uint:
+		return types.NewNumber(float64(v)), nil
+	case []interface{}:
+		values := make([]types.Value, len(v))
+		for i, elem := range v {
+			val, err := toValue(elem)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", i, err)
+			}
+			values[i] = val
+		}
+		return types.NewSlice(values, types.SliceShared), nil
+	case map[string]interface{}:
+		obj := types.NewBaseDataObject()
+		for name, elem := range v {
+			val, err := toValue(elem)
+			if err != nil {
+				return nil, fmt.Errorf(".%s: %s", name, err)
+			}
+
+			err = obj.Put(utf16.S(name), val, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("unsupported Go type %T", goval)
+}
+
+// Interrupt stops the evaluation running on another goroutine, which
+// returns ErrInterrupted. It is safe to call it concurrently. If no
+// evaluation is running, the next one is stopped as soon as it
+// starts, unless the interrupt is discarded by ClearInterrupt.
+func (a *Abad) Interrupt() {
+	atomic.StoreInt32(&a.interrupted, 1)
+}
+
+// ClearInterrupt discards an Interrupt that didn't stop an evaluation
+// yet, eg.: one received after the evaluation it was meant for ended.
+// It is safe to call it concurrently.
+func (a *Abad) ClearInterrupt() {
+	atomic.StoreInt32(&a.interrupted, 0)
+}
+
+// Stats returns the counts of the objects, properties, string bytes
+// and environments reachable by the scripts, so hosts can stop a
+// script before its memory grows out of bounds. The counts are kept
+// up to date by the evaluation, the objects that became unreachable
+// leave them when the Go GC collects them. It is safe to call it
+// concurrently.
+func (a *Abad) Stats() types.HeapStats {
+	return a.heap.Stats()
+}
+
+// OnUncaughtException registers fn to be called when a statement
+// fails with an uncaught exception. Without a handler (the default)
+// the evaluation is aborted and the error is returned by Eval, as
+// are the errors that aren't exceptions, eg.: ErrInterrupted.
+func (a *Abad) OnUncaughtException(fn UncaughtExceptionHandler) {
+	a.onUncaughtException = fn
+}
+
+// OnWarning registers fn to be called with the warnings about valid
+// but suspicious code, found while parsing, eg.: a legacy octal
+// literal, by the scope analysis, eg.: a reference to an undeclared
+// name, or while evaluating, eg.: the implicit creation of a global
+// by the assignment of an undeclared name. A nil fn removes the
+// handler.
+func (a *Abad) OnWarning(fn WarningHandler) {
+	a.onWarning = fn
+}
+
+// parserOptions are the options of the parser with the warnings
+// handler, if any.
+func (a *Abad) parserOptions() []parser.Option {
+	if a.onWarning == nil {
+		return a.parserOpts
+	}
+
+	opts := append([]parser.Option{}, a.parserOpts...)
+	return append(opts, parser.OnWarning(a.onWarning))
+}
+
+// warnf reports a warning of the evaluation of the current file.
+func (a *Abad) warnf(format string, args ...interface{}) {
+	if a.onWarning != nil {
+		a.onWarning(parser.Warning{
+			File: a.file,
+			Msg:  fmt.Sprintf(format, args...),
+		})
+	}
+}
+
+// OnLongEvaluation registers fn to be called every time an
+// evaluation runs the given number of steps without writing to the
+// console, eg.: stuck on an infinite loop. If fn returns false the
+// evaluation is interrupted, returning ErrInterrupted. Zero steps
+// removes the handler.
+func (a *Abad) OnLongEvaluation(steps uint, fn LongEvaluationHandler) {
+	a.longOps = steps
+	a.onLongEvaluation = fn
+	if steps == 0 {
+		a.onLongEvaluation = nil
+	}
+}
+
+// DefineAccessor defines an accessor property on the global object,
+// whose value is computed by get every time name is read. Assigning
+// name calls set, or is silently ignored when set is nil.
+// Accessors are not enumerable, as builtins.
+// https://es5.github.io/#x8.6.1
+func (a *Abad) DefineAccessor(name string, get, set types.Execfn) error {
+	getter, setter := types.Value(types.Undefined), types.Value(types.Undefined)
+	if get != nil {
+		getter = types.NewBuiltinfn(get)
+	}
+	if set != nil {
+		setter = types.NewBuiltinfn(set)
+	}
+
+	desc := types.NewAcessorPropDesc(getter, setter, false, true)
+	_, err := a.global.DefineOwnPropertyP(utf16.S(name), desc, true)
+	return err
+}
+
+func (a *Abad) eval(n ast.Node) (types.Value, error) {
+	if ast.IsExpr(n) {
+		return a.evalExpr(n)
+	}
+
+	err := a.step()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret types.Value
+
+	switch n.Type() {
+	case ast.NodeProgram:
+		ret, err = a.evalProgram(n.(*ast.Program))
+	case ast.NodeFunDecl:
+		// declared when hoisting the enclosing code
+		ret = types.Undefined
+	case ast.NodeVarDecls:
+		ret, err = a.evalVarDecls(n.(ast.VarDecls))
+	default:
+		panic(fmt.Sprintf("AST(%s) not implemented", n))
+	}
+
+	return ret, err
+}
+
+// complete returns the completion value of the evaluation
+// that produced val, according to the completion mode.
+func (a *Abad) complete(val types.Value) types.Value {
+	if a.completion == CompletionScript {
+		return types.Undefined
+	}
+
+	return val
+}
+
+// begin resets the state of a new evaluation.
+func (a *Abad) begin() {
+	a.ops = 0
+	a.quietOps = 0
+}
+
+// step accounts one evaluation step, failing if the
+// evaluation was interrupted, which clears the interrupt,
+// or its budget is over.
+func (a *Abad) step() error {
+	if atomic.CompareAndSwapInt32(&a.interrupted, 1, 0) {
+		return ErrInterrupted
+	}
+
+	if a.metered && a.ops >= a.maxOps {
+		return ErrBudgetExceeded
+	}
+
+	a.ops++
+
+	if a.onLongEvaluation != nil {
+		a.quietOps++
+		if a.quietOps >= a.longOps {
+			a.quietOps = 0
+			if !a.onLongEvaluation(a.ops) {
+				return ErrInterrupted
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *Abad) setup() error {
+	global := types.NewBaseDataObject()
+
+	// undefined is read only but can be shadowed by local variables
+	// https://es5.github.io/#x15.1.1.3
+	_, err := global.DefineOwnPropertyP(undefinedAttr,
+		types.NewDataPropDesc(types.Undefined, false, false, false), true)
+	if err != nil {
+		return err
+	}
+
+	math, err := builtins.NewMath(a.random)
+	if err != nil {
+		return err
+	}
+
+	err = global.DefineBuiltin(mathAttr, math)
+	if err != nil {
+		return err
+	}
+
+	object, err := builtins.NewObject(a.step)
+	if err != nil {
+		return err
+	}
+
+	err = global.DefineBuiltin(objectAttr, object)
+	if err != nil {
+		return err
+	}
+
+	array, err := builtins.NewArray(a.step)
+	if err != nil {
+		return err
+	}
+
+	err = global.DefineBuiltin(arrayAttr, array)
+	if err != nil {
+		return err
+	}
+
+	json, err := builtins.NewJSON(a.step)
+	if err != nil {
+		return err
+	}
+
+	err = global.DefineBuiltin(jsonAttr, json)
+	if err != nil {
+		return err
+	}
+
+	err = global.DefineBuiltin(parseIntAttr, builtins.NewParseInt())
+	if err != nil {
+		return err
+	}
+
+	if a.caps.Has(CapConsole) {
+		// timers read the clock
+		var now func() time.Time
+		if a.caps.Has(CapClock) {
+			now = a.now
+		}
+
+		console, err := builtins.NewConsole(a.stdout, func(v types.Value) string {
+			// output of a long evaluation shows it's not stuck
+			a.quietOps = 0
+			return FormatValue(v, ConsoleFormat)
+		}, now)
+		if err != nil {
+			return err
+		}
+
+		err = global.DefineBuiltin(consoleAttr, console)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.caps.Has(CapClock) {
+		date, err := builtins.NewDate(a.now, a.loc)
+		if err != nil {
+			return err
+		}
+
+		err = global.DefineBuiltin(dateAttr, date)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.global = global
+	a.heap.Track(global)
+	a.env = a.newEnvironment(global, nil)
+	a.env.this = global
+	return nil
+}
+
+func (a *Abad) evalProgram(stmts *ast.Program) (types.Value, error) {
+	var (
+		result types.Value
+		err    error
+	)
+
+	err = a.hoist(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range stmts.Nodes {
+		err = a.checkpoint()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = a.eval(node)
+		if err != nil {
+			err = uncaught(err, a.file, a.sourceMap)
+			if jserr, ok := err.(*JSError); ok &&
+				a.onUncaughtException != nil &&
+				a.onUncaughtException(jserr) {
+				result = types.Undefined
+				continue
+			}
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// evalBody evaluates the body of a function, already hoisted. Unlike
+// evalProgram the errors are returned to the caller, never handled.
+func (a *Abad) evalBody(body *ast.Program) (types.Value, error) {
+	var result types.Value = types.Undefined
+	for _, node := range body.Nodes {
+		err := a.checkpoint()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = a.eval(node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// hoist declares the functions of body in the current environment
+// before the body is evaluated, so they can be called before their
+// declaration. The parser flattens blocks into the enclosing body,
+// then functions declared inside blocks are hoisted to the enclosing
+// function, as in the web legacy semantics (ES2015 Annex B.3.3).
+// The variables are declared undefined, unless the name is taken by
+// a function or a parameter.
+// https://es5.github.io/#x10.5
+func (a *Abad) hoist(body *ast.Program) error {
+	for _, node := range body.Nodes {
+		if node.Type() != ast.NodeFunDecl {
+			continue
+		}
+
+		decl := node.(*ast.FunDecl)
+		fn := a.newUserFunction(decl, decl.Name, decl.Args, decl.Body, nil)
+		err := a.env.declare(utf16.Str(decl.Name), fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, node := range body.Nodes {
+		decls, ok := node.(ast.VarDecls)
+		if !ok {
+			continue
+		}
+
+		for _, decl := range decls {
+			err := a.env.declareVar(utf16.Str(decl.Name))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// evalVarDecls assigns the initializers of the variables, which are
+// declared when hoisting the enclosing code. Variables without an
+// initializer keep their value, eg.: var a = 1; var a; a is 1.
+// Anonymous functions are named after the variable.
+// https://es5.github.io/#x12.2
+func (a *Abad) evalVarDecls(decls ast.VarDecls) (types.Value, error) {
+	for _, decl := range decls {
+		if decl.Value.Type() == ast.NodeUndefined {
+			continue
+		}
+
+		val, err := a.evalNamedExpr(decl.Value, decl.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = a.env.assign(utf16.Str(decl.Name), val)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return types.Undefined, nil
+}
+
+// newUserFunction creates the function of node, a FunDecl or a FunExpr,
+// closing over the current environment. The self name, of a named
+// function expression, is bound to the function in its body.
+func (a *Abad) newUserFunction(
+	node ast.Node, name ast.Ident, args []ast.Ident, body *ast.Program, self ast.Ident,
+) *types.UserFunction {
+	var params []utf16.Str
+	for _, arg := range args {
+		params = append(params, utf16.Str(arg))
+	}
+
+	call := func(fn *types.UserFunction, this types.Object, args []types.Value) (types.Value, error) {
+		return a.callUserFunction(fn, this, args)
+	}
+
+	scope := &closure{
+		env:   a.env,
+		scope: a.functionScope(node),
+		name:  utf16.Str(self),
+	}
+
+	fn := types.NewUserFunction(params, body, scope, false, call)
+	if len(name) > 0 {
+		fn.SetName(utf16.Str(name))
+	}
+
+	return fn
+}
+
+// evalFunExpr creates the function. A named function expression
+// can call itself by its name, which is bound only in the scope
+// of the function.
+// https://es5.github.io/#x13
+func (a *Abad) evalFunExpr(expr *ast.FunExpr) (types.Value, error) {
+	return a.newUserFunction(expr, expr.Name, expr.Args, expr.Body, expr.Name), nil
+}
+
+// evalNamedExpr evaluates expr, naming it after name when it is an
+// anonymous function, eg.: f = function () {}
+// The name of functions assigned to properties isn't inferred, as in
+// the spec.
+// https://www.ecma-international.org/ecma-262/6.0/#sec-assignment-operators-runtime-semantics-evaluation
+func (a *Abad) evalNamedExpr(expr ast.Node, name ast.Ident) (types.Value, error) {
+	val, err := a.evalExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if funexpr, ok := expr.(*ast.FunExpr); ok && len(funexpr.Name) == 0 {
+		val.(*types.UserFunction).SetName(utf16.Str(name))
+	}
+
+	return val, nil
+}
+
+// evalAssignExpr assigns the value to an identifier or to a property,
+// returning the value. Assignments that are not allowed (eg.: of
+// read only properties) fail silently, as in non strict code.
+// https://es5.github.io/#x11.13.1
+func (a *Abad) evalAssignExpr(assign *ast.AssignExpr) (types.Value, error) {
+	switch target := assign.Target.(type) {
+	case ast.Ident:
+		var (
+			val types.Value
+			err error
+		)
+
+		if assign.Operator == token.Assign {
+			val, err = a.evalNamedExpr(assign.Value, target)
+		} else {
+			val, err = a.evalAssignedValue(assign, func() (types.Value, error) {
+				return a.evalIdentExpr(target)
+			})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		name := utf16.Str(target)
+		if a.onWarning != nil && !a.env.declared(name) {
+			a.warnf("assignment to undeclared %s creates a global", name)
+		}
+
+		return val, a.env.assign(name, val)
+	case *ast.MemberExpr:
+		objval, err := a.evalExpr(target.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		name := utf16.Str(target.Property)
+		val, err := a.evalAssignedValue(assign, func() (types.Value, error) {
+			return getValue(objval, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return val, putValue(objval, name, val)
+	case *ast.IndexExpr:
+		objval, err := a.evalExpr(target.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := a.evalExpr(target.Index)
+		if err != nil {
+			return nil, err
+		}
+
+		name := utf16.Str(index.ToString())
+		val, err := a.evalAssignedValue(assign, func() (types.Value, error) {
+			return getValue(objval, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return val, putValue(objval, name, val)
+	}
 
-// Eval the code when no filename is involved (interactive/repl mode).
-func (a *Abad) Eval(code string) (types.Value, error) {
-	return a.EvalFile("<interactive>", code)
+	return nil, newReferenceError("invalid assignment target: %s", assign.Target)
 }
 
-// EvalFile the code that was obtained from filename.
-func (a *Abad) EvalFile(filename string, code string) (types.Value, error) {
-	program, err := parser.Parse(filename, code)
+// evalAssignedValue evaluates the value of the assignment. Compound
+// assignments apply their operator to the current value of the
+// target, read with get before the value is evaluated, eg.: a += b
+// assigns a + b.
+// https://es5.github.io/#x11.13.2
+func (a *Abad) evalAssignedValue(assign *ast.AssignExpr, get func() (types.Value, error)) (types.Value, error) {
+	if assign.Operator == token.Assign {
+		return a.evalExpr(assign.Value)
+	}
+
+	left, err := get()
 	if err != nil {
-		return nil, fmt.Errorf("parser error: %s", err)
+		return nil, err
 	}
-	return a.eval(program)
-}
 
-func (a *Abad) eval(n ast.Node) (types.Value, error) {
-	if ast.IsExpr(n) {
-		return a.evalExpr(n)
+	right, err := a.evalExpr(assign.Value)
+	if err != nil {
+		return nil, err
 	}
 
-	var ret types.Value
-	var err error
+	val, err := applyBinaryOperator(token.CompoundOperator(assign.Operator), left, right)
+	if err != nil {
+		return nil, err
+	}
 
-	switch n.Type() {
-	case ast.NodeProgram:
-		ret, err = a.evalProgram(n.(*ast.Program))
-	default:
-		panic(fmt.Sprintf("AST(%s) not implemented", n))
+	if a.trapsNaN(val, left, right) {
+		return nil, newError("Error", "%s produced NaN", assign)
 	}
 
-	return ret, err
+	return val, nil
 }
 
-func (a *Abad) setup() error {
-	console, err := builtins.NewConsole()
-	if err != nil {
-		return err
+// getValue reads the property name of objval, wrapping
+// primitive values into objects. There are no Number and Boolean
+// objects yet, so the properties of numbers and booleans are
+// undefined, eg.: (1).toString
+// https://es5.github.io/#x8.7.1
+func getValue(objval types.Value, name utf16.Str) (types.Value, error) {
+	switch objval.Kind() {
+	case types.KindUndefined, types.KindNull:
+		return nil, newTypeError("cannot read property %s of %s",
+			name, objval.ToString())
+	case types.KindNumber, types.KindBool:
+		return types.Undefined, nil
 	}
 
-	global := types.NewBaseDataObject()
-	err = global.Put(consoleAttr, console, true)
+	obj, err := objval.ToObject()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	a.global = global
-	return nil
+	return obj.Get(name)
 }
 
-func (a *Abad) evalProgram(stmts *ast.Program) (types.Value, error) {
-	var (
-		result types.Value
-		err    error
-	)
-	for _, node := range stmts.Nodes {
-		result, err = a.eval(node)
-		if err != nil {
-			return nil, err
-		}
+// putValue sets the property name of objval. Assigning properties
+// of primitive values has no effect, because they would be set on
+// a temporary wrapper object.
+// https://es5.github.io/#x8.7.2
+func putValue(objval types.Value, name utf16.Str, val types.Value) error {
+	switch objval.Kind() {
+	case types.KindObject:
+		return objval.(types.Object).Put(name, val, false)
+	case types.KindUndefined, types.KindNull:
+		return newTypeError("cannot set property %s of %s",
+			name, objval.ToString())
 	}
 
-	return result, nil
+	return nil
 }
 
 func (a *Abad) evalUnaryExpr(expr *ast.UnaryExpr) (types.Value, error) {
@@ -98,19 +1086,18 @@ func (a *Abad) evalUnaryExpr(expr *ast.UnaryExpr) (types.Value, error) {
 		return nil, err
 	}
 
-	// TODO(i4k): UnaryExpr could work in any expression in js
-	// examples below are valid:
-	//   -[]
-	//	 +[]
-	//   -{}
-	//   +{}
-	//   -eval("0")
-	//   -new Object()
-	num, ok := obj.(types.Number)
-	if !ok {
-		return nil, fmt.Errorf("not a number: %s", obj)
+	// https://es5.github.io/#x11.4.9
+	if op == token.LNot {
+		return types.NewBool(obj.IsFalse()), nil
+	}
+
+	// https://es5.github.io/#x11.4.6
+	prim, err := obj.ToPrimitive(types.KindNumber)
+	if err != nil {
+		return nil, err
 	}
 
+	num := prim.ToNumber()
 	switch op {
 	case token.Minus:
 		num = -num
@@ -120,19 +1107,262 @@ func (a *Abad) evalUnaryExpr(expr *ast.UnaryExpr) (types.Value, error) {
 		return nil, fmt.Errorf("unsupported unary operator: %s", op)
 	}
 
+	if a.trapsNaN(num, obj) {
+		return nil, newError("Error", "%s produced NaN", expr)
+	}
+
 	return num, nil
 }
 
+func (a *Abad) evalBinaryExpr(expr *ast.BinaryExpr) (types.Value, error) {
+	left, err := a.evalExpr(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	// the logical operators return an operand, the right one is
+	// evaluated only if the left doesn't decide the result.
+	// https://es5.github.io/#x11.11
+	switch expr.Operator {
+	case token.LAnd:
+		if left.IsFalse() {
+			return left, nil
+		}
+		return a.evalExpr(expr.Right)
+	case token.LOr:
+		if left.IsTrue() {
+			return left, nil
+		}
+		return a.evalExpr(expr.Right)
+	}
+
+	right, err := a.evalExpr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := applyBinaryOperator(expr.Operator, left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.trapsNaN(val, left, right) {
+		return nil, newError("Error", "%s produced NaN", expr)
+	}
+
+	return val, nil
+}
+
+// applyBinaryOperator applies the operator to the values of the
+// operands, for the binary expressions and compound assignments.
+func applyBinaryOperator(op token.Type, left, right types.Value) (types.Value, error) {
+	switch op {
+	case token.In:
+		return evalIn(left, right)
+	case token.Plus, token.Minus, token.Mul, token.Quo, token.Rem:
+		return evalArithmetic(op, left, right)
+	case token.LShift, token.RShift, token.RShiftZero,
+		token.And, token.Or, token.Xor:
+		return evalBitwise(op, left, right)
+	case token.Less, token.Greater, token.LessEq, token.GreaterEq:
+		return evalRelational(op, left, right)
+	case token.Equal, token.NotEqual:
+		equal, err := types.Equal(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewBool(equal == (op == token.Equal)), nil
+	case token.TEqual, token.NotTEqual:
+		equal := types.StrictEqual(left, right)
+		return types.NewBool(equal == (op == token.TEqual)), nil
+	}
+
+	return nil, fmt.Errorf("unsupported binary operator: %s", op)
+}
+
+// evalArithmetic applies the additive and multiplicative operators.
+// The addition concatenates the operands if any of them converts to
+// a string primitive, the other operators work on numbers.
+// https://es5.github.io/#x11.5
+// https://es5.github.io/#x11.6
+func evalArithmetic(op token.Type, left, right types.Value) (types.Value, error) {
+	// only the addition converts without a hint, so dates can be
+	// concatenated
+	hint := types.KindNumber
+	if op == token.Plus {
+		hint = types.NoHint
+	}
+
+	lprim, err := left.ToPrimitive(hint)
+	if err != nil {
+		return nil, err
+	}
+
+	rprim, err := right.ToPrimitive(hint)
+	if err != nil {
+		return nil, err
+	}
+
+	if op == token.Plus &&
+		(lprim.Kind() == types.KindString || rprim.Kind() == types.KindString) {
+		lstr, rstr := lprim.ToString(), rprim.ToString()
+		str := make(utf16.Str, 0, len(lstr)+len(rstr))
+		str = append(append(str, lstr...), rstr...)
+		return types.String(str), nil
+	}
+
+	lnum, rnum := lprim.ToNumber().Value(), rprim.ToNumber().Value()
+	switch op {
+	case token.Plus:
+		return types.NewNumber(lnum + rnum), nil
+	case token.Minus:
+		return types.NewNumber(lnum - rnum), nil
+	case token.Mul:
+		return types.NewNumber(lnum * rnum), nil
+	case token.Quo:
+		return types.NewNumber(lnum / rnum), nil
+	}
+
+	// the remainder of the truncating division, as math.Mod
+	// https://es5.github.io/#x11.5.3
+	return types.NewNumber(math.Mod(lnum, rnum)), nil
+}
+
+// evalBitwise applies the shift and bitwise operators, which work on
+// the operands converted to 32 bit integers. The shift count is
+// taken modulo 32.
+// https://es5.github.io/#x11.7
+// https://es5.github.io/#x11.10
+func evalBitwise(op token.Type, left, right types.Value) (types.Value, error) {
+	lprim, err := left.ToPrimitive(types.KindNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	rprim, err := right.ToPrimitive(types.KindNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	lnum, rnum := lprim.ToNumber(), rprim.ToNumber()
+	lint, rint := lnum.ToInt32(), rnum.ToInt32()
+	shift := rnum.ToUint32() & 0x1f
+
+	var res float64
+	switch op {
+	case token.LShift:
+		res = float64(lint << shift)
+	case token.RShift:
+		res = float64(lint >> shift)
+	case token.RShiftZero:
+		res = float64(lnum.ToUint32() >> shift)
+	case token.And:
+		res = float64(lint & rint)
+	case token.Or:
+		res = float64(lint | rint)
+	case token.Xor:
+		res = float64(lint ^ rint)
+	}
+
+	return types.NewNumber(res), nil
+}
+
+// evalRelational applies the relational operators, which are false
+// when any operand converts to NaN.
+// https://es5.github.io/#x11.8
+func evalRelational(op token.Type, left, right types.Value) (types.Value, error) {
+	var (
+		less, ok bool
+		err      error
+	)
+
+	// the operands are converted from left to right
+	switch op {
+	case token.Less, token.GreaterEq:
+		less, ok, err = lessThan(left, right, true)
+	default:
+		less, ok, err = lessThan(right, left, false)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case token.Less, token.Greater:
+		return types.NewBool(ok && less), nil
+	}
+
+	return types.NewBool(ok && !less), nil
+}
+
+// lessThan is the abstract relational comparison x < y, ok is false
+// if the result is undefined, because of a NaN. The operands are
+// converted to primitives in the order of the code, leftFirst tells
+// if it's x and then y.
+// https://es5.github.io/#x11.8.5
+func lessThan(x, y types.Value, leftFirst bool) (less, ok bool, err error) {
+	var px, py types.Value
+	if leftFirst {
+		px, err = x.ToPrimitive(types.KindNumber)
+		if err == nil {
+			py, err = y.ToPrimitive(types.KindNumber)
+		}
+	} else {
+		py, err = y.ToPrimitive(types.KindNumber)
+		if err == nil {
+			px, err = x.ToPrimitive(types.KindNumber)
+		}
+	}
+
+	if err != nil {
+		return false, false, err
+	}
+
+	if px.Kind() == types.KindString && py.Kind() == types.KindString {
+		cmp := utf16.Str(px.(types.String)).Compare(utf16.Str(py.(types.String)))
+		return cmp < 0, true, nil
+	}
+
+	nx, ny := px.ToNumber().Value(), py.ToNumber().Value()
+	if math.IsNaN(nx) || math.IsNaN(ny) {
+		return false, false, nil
+	}
+
+	return nx < ny, true, nil
+}
+
+// evalIn tells if the property named left exists in the
+// object right or in its prototype chain.
+// https://es5.github.io/#x11.8.7
+func evalIn(left, right types.Value) (types.Value, error) {
+	if right.Kind() != types.KindObject {
+		return nil, newTypeError("cannot use 'in' operator to search for '%s' in %s",
+			left.ToString(), right.ToString())
+	}
+
+	obj := right.(types.Object)
+	name := utf16.Str(left.ToString())
+	return types.NewBool(obj.HasProperty(name)), nil
+}
+
 func (a *Abad) evalExpr(n ast.Node) (types.Value, error) {
 	if !ast.IsExpr(n) {
 		return nil, fmt.Errorf("internal error: node[%s] is not an expression", n)
 	}
 
+	err := a.step()
+	if err != nil {
+		return nil, err
+	}
+
 	switch n.Type() {
 	case ast.NodeUndefined:
 		return types.Undefined, nil
 	case ast.NodeNull:
 		return types.Null, nil
+	case ast.NodeThis:
+		return a.env.thisValue(), nil
 	case ast.NodeBool:
 		val := n.(ast.Bool)
 		return types.Bool(val.Value()), nil
@@ -142,31 +1372,69 @@ func (a *Abad) evalExpr(n ast.Node) (types.Value, error) {
 	case ast.NodeString:
 		val := n.(ast.String)
 		return types.String(val), nil
+	case ast.NodeBigInt:
+		return nil, newError("SyntaxError", "BigInt not supported: %s", n)
 	case ast.NodeIdent:
 		val := n.(ast.Ident)
 		return a.evalIdentExpr(val)
 	case ast.NodeMemberExpr:
 		val := n.(*ast.MemberExpr)
 		return a.evalMemberExpr(val)
+	case ast.NodeIndexExpr:
+		val := n.(*ast.IndexExpr)
+		return a.evalIndexExpr(val)
 	case ast.NodeCallExpr:
 		val := n.(*ast.CallExpr)
 		return a.evalCallExpr(val)
+	case ast.NodeNewExpr:
+		val := n.(*ast.NewExpr)
+		return a.evalNewExpr(val)
 	case ast.NodeUnaryExpr:
 		expr := n.(*ast.UnaryExpr)
 		return a.evalUnaryExpr(expr)
+	case ast.NodeBinaryExpr:
+		expr := n.(*ast.BinaryExpr)
+		return a.evalBinaryExpr(expr)
+	case ast.NodeSequenceExpr:
+		expr := n.(*ast.SequenceExpr)
+		return a.evalSequenceExpr(expr)
+	case ast.NodeAssignExpr:
+		expr := n.(*ast.AssignExpr)
+		return a.evalAssignExpr(expr)
+	case ast.NodeFunExpr:
+		expr := n.(*ast.FunExpr)
+		return a.evalFunExpr(expr)
 	default:
 		return nil, fmt.Errorf("unknown node type: %v", n)
 	}
 }
 
+// evalSequenceExpr evaluates all expressions, returning the value
+// of the last one.
+func (a *Abad) evalSequenceExpr(seq *ast.SequenceExpr) (types.Value, error) {
+	var (
+		val types.Value
+		err error
+	)
+
+	for _, expr := range seq.Exprs {
+		val, err = a.evalExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return val, nil
+}
+
 func (a *Abad) evalIdentExpr(ident ast.Ident) (types.Value, error) {
-	val, err := a.global.Get(utf16.Str(ident))
+	val, ok, err := a.env.lookup(utf16.Str(ident))
 	if err != nil {
 		return nil, err
 	}
 
-	if types.StrictEqual(val, types.Undefined) {
-		return nil, fmt.Errorf("[%s] is not defined",
+	if !ok {
+		return nil, newReferenceError("[%s] is not defined",
 			ident.String())
 	}
 
@@ -179,41 +1447,217 @@ func (a *Abad) evalMemberExpr(member *ast.MemberExpr) (types.Value, error) {
 		return nil, err
 	}
 
-	if objval.Kind() != types.KindObject {
-		panic("wrapping primitive values not implemented yet")
+	return getValue(objval, utf16.Str(member.Property))
+}
+
+func (a *Abad) evalIndexExpr(expr *ast.IndexExpr) (types.Value, error) {
+	objval, err := a.evalExpr(expr.Object)
+	if err != nil {
+		return nil, err
 	}
 
-	obj, err := objval.ToObject()
+	index, err := a.evalExpr(expr.Index)
 	if err != nil {
 		return nil, err
 	}
 
-	return obj.Get(utf16.Str(member.Property))
+	return getValue(objval, utf16.Str(index.ToString()))
 }
 
 func (a *Abad) evalCallExpr(call *ast.CallExpr) (types.Value, error) {
 	// TODO(i4k): safe to assume the AST is ok?
-	objval, err := a.evalExpr(call.Callee)
+	callee, this, err := a.evalCallee(call.Callee)
 	if err != nil {
 		return nil, err
 	}
 
-	obj, err := objval.ToObject() // wraps primitives (if needed)
+	args, err := a.evalArgs(call.Args)
 	if err != nil {
 		return nil, err
 	}
 
-	fun, ok := obj.(types.Function)
+	if userfn, ok := callee.(*types.UserFunction); ok {
+		return a.callUserFunction(userfn, this, args)
+	}
+
+	fun, ok := callee.(types.Function)
 	if !ok {
-		return nil, fmt.Errorf("%s is not a function", objval.Kind())
+		err := newTypeError("%s is not a function", call.Callee)
+		return nil, err.at(a.file, call.Line, call.Column)
 	}
 
-	args, err := a.evalArgs(call.Args)
+	val, err := fun.Call(this, args)
+	if err == nil && a.trapsNaN(val, args...) {
+		err := newError("Error", "%s produced NaN", call)
+		return nil, err.at(a.file, call.Line, call.Column)
+	}
+
+	return val, err
+}
+
+// trapsNaN tells if NaN is being trapped and val is a NaN produced
+// from operands that aren't NaN.
+func (a *Abad) trapsNaN(val types.Value, operands ...types.Value) bool {
+	return a.trapNaN && producesNaN(val, operands)
+}
+
+// producesNaN tells if val is NaN while no argument is.
+func producesNaN(val types.Value, args []types.Value) bool {
+	if !isNaN(val) {
+		return false
+	}
+
+	for _, arg := range args {
+		if isNaN(arg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNaN(val types.Value) bool {
+	num, ok := val.(types.Number)
+	return ok && math.IsNaN(num.Value())
+}
+
+// evalNewExpr constructs an object with a builtin constructor.
+// User functions can't be constructors until this is supported.
+// https://es5.github.io/#x11.2.2
+func (a *Abad) evalNewExpr(expr *ast.NewExpr) (types.Value, error) {
+	callee, err := a.evalExpr(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := a.evalArgs(expr.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	constructor, ok := callee.(types.Constructor)
+	if !ok {
+		return nil, newTypeError("%s is not a constructor", expr.Callee)
+	}
+
+	return constructor.Construct(args)
+}
+
+// evalCallee evaluates the function being called and the this value
+// of the call, which is the object of member and index expressions,
+// eg.: o.f() and o["f"](), and nil otherwise.
+// https://es5.github.io/#x11.2.3
+func (a *Abad) evalCallee(callee ast.Node) (types.Value, types.Object, error) {
+	var (
+		base types.Value
+		name utf16.Str
+		err  error
+	)
+
+	switch expr := callee.(type) {
+	case *ast.MemberExpr:
+		base, err = a.evalExpr(expr.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name = utf16.Str(expr.Property)
+	case *ast.IndexExpr:
+		base, err = a.evalExpr(expr.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		index, err := a.evalExpr(expr.Index)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name = utf16.Str(index.ToString())
+	default:
+		fn, err := a.evalExpr(callee)
+		return fn, nil, err
+	}
+
+	fn, err := getValue(base, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch base.Kind() {
+	case types.KindNumber, types.KindBool:
+		// fn is undefined, the call fails as not a function
+		return fn, nil, nil
+	}
+
+	this, err := base.ToObject()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fn, this, nil
+}
+
+// callUserFunction evaluates the body of fn in a new environment,
+// child of the environment where fn was declared, with the slots of
+// its scope analysis. A nil this is the global object, as in non
+// strict code. The name of a named function expression is bound to
+// the function unless its params or declarations shadow it.
+// https://es5.github.io/#x10.4.3
+func (a *Abad) callUserFunction(fn *types.UserFunction, this types.Object, args []types.Value) (types.Value, error) {
+	if a.depth >= a.maxDepth {
+		return nil, newRangeError("Maximum call stack size exceeded")
+	}
+
+	a.depth++
+	defer func() {
+		a.depth--
+	}()
+
+	scope := fn.Scope().(*closure)
+	env := a.newCallEnvironment(scope.scope, scope.env)
+	env.this = a.global
+	if this != nil {
+		env.this = this.(types.Value)
+	}
+
+	for i, param := range fn.Params() {
+		var arg types.Value = types.Undefined
+		if i < len(args) {
+			arg = args[i]
+		}
+
+		err := env.declare(param, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	caller := a.env
+	a.env = env
+	defer func() {
+		a.env = caller
+	}()
+
+	err := a.hoist(fn.Body())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scope.name) > 0 {
+		err = env.declareUnset(scope.name, fn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = a.evalBody(fn.Body())
 	if err != nil {
 		return nil, err
 	}
 
-	return fun.Call(obj, args), nil
+	// TODO(i4k): return statement
+	return types.Undefined, nil
 }
 
 func (a *Abad) evalArgs(args []ast.Node) ([]types.Value, error) {
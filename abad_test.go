@@ -1,11 +1,18 @@
 package abad_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/NeowayLabs/abad"
+	"github.com/NeowayLabs/abad/parser"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 	"github.com/madlambda/spells/assert"
 )
 
@@ -49,9 +56,17 @@ func TestNumberEval(t *testing.T) {
 			code: "-+-+0",
 			obj:  types.Number(0.0),
 		},
+		{
+			code: "-(-1)",
+			obj:  types.Number(1.0),
+		},
+		{
+			code: "(1, -(2, 3))",
+			obj:  types.Number(-3.0),
+		},
 		{
 			code: "0.1.",
-			err:  E("parser error: <anonymous>:1:0: invalid token: 0.1."),
+			err:  E("parser error: <anonymous>:1:4: unexpected character U+002E after numeric literal"),
 		},
 	} {
 		js, err := abad.NewAbad()
@@ -86,10 +101,10 @@ func TestIdentExprEval(t *testing.T) {
 		},
 		{
 			code: "angular",
-			err:  E("[angular] is not defined"),
+			err:  E("ReferenceError: [angular] is not defined"),
 		},
 	} {
-		js, err := abad.NewAbad()
+		js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
 		assert.NoError(t, err, "failed to start interpreter")
 		val, err := js.Eval(tc.code)
 		assert.EqualErrs(t, tc.err, err, "errors differ")
@@ -137,3 +152,2426 @@ func TestStringEval(t *testing.T) {
 		})
 	}
 }
+
+func TestUncaughtExceptionHandler(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		code     string
+		proceed  bool
+		want     types.Value
+		wantErrs []error
+		err      error
+	}{
+		{
+			name: "NoErrors",
+			code: "1",
+			want: types.Number(1),
+		},
+		{
+			name:     "Continue",
+			code:     "angular; 2",
+			proceed:  true,
+			want:     types.Number(2),
+			wantErrs: []error{E("ReferenceError: [angular] is not defined")},
+		},
+		{
+			name:     "ContinueOnLastStatement",
+			code:     "1; angular",
+			proceed:  true,
+			want:     types.Undefined,
+			wantErrs: []error{E("ReferenceError: [angular] is not defined")},
+		},
+		{
+			name:     "Abort",
+			code:     "angular; 2",
+			wantErrs: []error{E("ReferenceError: [angular] is not defined")},
+			err:      E("ReferenceError: [angular] is not defined"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			var gotErrs []error
+			js.OnUncaughtException(func(err *abad.JSError) bool {
+				gotErrs = append(gotErrs, err)
+				return tc.proceed
+			})
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+			assert.EqualInts(t, len(tc.wantErrs), len(gotErrs),
+				"uncaught exceptions count")
+
+			for i, want := range tc.wantErrs {
+				assert.EqualErrs(t, want, gotErrs[i], "uncaught exception")
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestUncaughtHostError(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	hosterr := errors.New("host failure")
+	err = js.DefineAccessor("host", func(types.Object, []types.Value) (types.Value, error) {
+		return nil, hosterr
+	}, nil)
+	assert.NoError(t, err, "defining accessor")
+
+	handled := 0
+	js.OnUncaughtException(func(*abad.JSError) bool {
+		handled++
+		return true
+	})
+
+	_, err = js.Eval("angular; host; 1")
+	assert.EqualErrs(t, hosterr, err, "host errors abort the evaluation")
+	assert.EqualInts(t, 1, handled, "uncaught exceptions handled")
+}
+
+func TestEvalWithBudget(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		code   string
+		maxOps uint
+		ops    uint
+		err    error
+	}{
+		{
+			name:   "Literal",
+			code:   "1",
+			maxOps: 10,
+			ops:    2,
+		},
+		{
+			name:   "Unary",
+			code:   "-+1",
+			maxOps: 10,
+			ops:    4,
+		},
+		{
+			name:   "ExactBudget",
+			code:   "-+1",
+			maxOps: 4,
+			ops:    4,
+		},
+		{
+			name:   "Exceeded",
+			code:   "-+1",
+			maxOps: 3,
+			ops:    3,
+			err:    abad.ErrBudgetExceeded,
+		},
+		{
+			name:   "Statements",
+			code:   "1; 2; 3",
+			maxOps: 10,
+			ops:    4,
+		},
+		{
+			name:   "Builtin",
+			code:   `JSON.stringify(Array.of(1, 2, 3))`,
+			maxOps: 100,
+			ops:    12,
+		},
+		{
+			name:   "BuiltinExceeded",
+			code:   `Object.keys("abcdefghijklmnopqrstuvwxyz")`,
+			maxOps: 20,
+			ops:    20,
+			err:    abad.ErrBudgetExceeded,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			// budget errors must never be handled by the script
+			js.OnUncaughtException(func(*abad.JSError) bool { return true })
+
+			_, ops, err := js.EvalWithBudget(tc.code, tc.maxOps)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+			assert.EqualInts(t, int(tc.ops), int(ops), "consumed ops")
+
+			// same code always consumes the same budget
+			_, ops, _ = js.EvalWithBudget(tc.code, tc.maxOps)
+			assert.EqualInts(t, int(tc.ops), int(ops), "consumed ops on reevaluation")
+
+			// budget is not kept after metered evaluations
+			_, err = js.Eval(tc.code)
+			assert.NoError(t, err, "unmetered evaluation")
+		})
+	}
+}
+
+func TestEvalWithBudgetParseError(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, ops, err := js.EvalWithBudget("1; 2; 3", 10)
+	assert.NoError(t, err, "evaluating with budget")
+	assert.EqualInts(t, 4, int(ops), "consumed ops")
+
+	_, ops, err = js.EvalWithBudget("1 +", 10)
+	assert.Error(t, err, "parsing")
+	assert.EqualInts(t, 0, int(ops), "consumed ops of a parser error")
+}
+
+func TestInterrupt(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	js.OnUncaughtException(func(*abad.JSError) bool {
+		js.Interrupt()
+		return true
+	})
+
+	_, err = js.Eval("angular; 1")
+	assert.EqualErrs(t, abad.ErrInterrupted, err, "interrupted evaluation")
+
+	val, err := js.Eval("1")
+	assert.NoError(t, err, "new evaluations are not interrupted")
+	if !types.StrictEqual(types.Number(1), val) {
+		t.Fatalf("got %v but want 1", val)
+	}
+}
+
+func TestInterruptBeforeEvaluation(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	js.Interrupt()
+	_, err = js.Eval("1")
+	assert.EqualErrs(t, abad.ErrInterrupted, err, "evaluation started after the interrupt")
+
+	_, err = js.Eval("1")
+	assert.NoError(t, err, "the interrupt stops a single evaluation")
+
+	js.Interrupt()
+	js.ClearInterrupt()
+	_, err = js.Eval("1")
+	assert.NoError(t, err, "cleared interrupt")
+}
+
+func TestBuiltinLoops(t *testing.T) {
+	// Array.from reads every index up to the length of an array-like
+	const code = "Math.length = 300000000; Array.from(Math)"
+
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, ops, err := js.EvalWithBudget(code, 100)
+	assert.EqualErrs(t, abad.ErrBudgetExceeded, err, "budget of the builtin")
+	assert.EqualInts(t, 100, int(ops), "consumed ops")
+
+	js.OnLongEvaluation(1000, func(uint) bool {
+		go js.Interrupt()
+		return true
+	})
+
+	_, err = js.Eval(code)
+	assert.EqualErrs(t, abad.ErrInterrupted, err, "interrupted builtin")
+}
+
+func TestOnLongEvaluation(t *testing.T) {
+	const code = "1; 2; 3; 4; 5; 6; 7; 8; 9; 10"
+
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, total, err := js.EvalWithBudget(code, 1000)
+	assert.NoError(t, err, "evaluating with budget")
+
+	var calls []uint
+	js.OnLongEvaluation(3, func(steps uint) bool {
+		calls = append(calls, steps)
+		return true
+	})
+
+	val, err := js.Eval(code)
+	assert.NoError(t, err, "evaluation continued")
+	if !types.StrictEqual(types.Number(10), val) {
+		t.Fatalf("got %v but want 10", val)
+	}
+
+	assert.EqualInts(t, int(total/3), len(calls), "handler calls")
+	for i, steps := range calls {
+		assert.EqualInts(t, 3*(i+1), int(steps), "steps of call %d", i)
+	}
+
+	js.OnLongEvaluation(3, func(uint) bool { return false })
+	_, err = js.Eval(code)
+	assert.EqualErrs(t, abad.ErrInterrupted, err, "aborted evaluation")
+
+	js.OnLongEvaluation(0, func(uint) bool { return false })
+	_, err = js.Eval(code)
+	assert.NoError(t, err, "handler removed")
+}
+
+func TestDeterministic(t *testing.T) {
+	epoch := time.Unix(1000, 0)
+	eval := func(code string) types.Value {
+		js, err := abad.NewAbad(abad.Sandbox(abad.CapAll), abad.Deterministic(42, epoch))
+		assert.NoError(t, err, "failed to start interpreter")
+
+		val, err := js.Eval(code)
+		assert.NoError(t, err, "evaluating %s", code)
+		return val
+	}
+
+	first := eval("Math.random()")
+	second := eval("Math.random()")
+	if !types.StrictEqual(first, second) {
+		t.Fatalf("Math.random differs: %v != %v", first, second)
+	}
+
+	now := eval("Date.now()")
+	if !types.StrictEqual(types.Number(1000000), now) {
+		t.Fatalf("Date.now is %v but want 1000000", now)
+	}
+}
+
+func TestJSError(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		code    string
+		errname string
+		message string
+	}{
+		{
+			name:    "ReferenceError",
+			code:    "angular",
+			errname: "ReferenceError",
+			message: "[angular] is not defined",
+		},
+		{
+			name:    "TypeError",
+			code:    "console.log.name()",
+			errname: "TypeError",
+			message: "console.log.name is not a function",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			_, err = js.EvalFile("test.js", tc.code)
+			jserr, ok := err.(*abad.JSError)
+			if !ok {
+				t.Fatalf("got %#v but want a JSError", err)
+			}
+
+			assert.EqualStrings(t, tc.errname, jserr.Name(), "error name")
+			assert.EqualStrings(t, tc.message, jserr.Message(), "error message")
+
+			frames := jserr.StackFrames()
+			assert.EqualInts(t, 1, len(frames), "stack frames")
+			assert.EqualStrings(t, "test.js", frames[0].File, "frame file")
+
+			obj, ok := jserr.Value().(types.Object)
+			if !ok {
+				t.Fatalf("error value is not an object: %v", jserr.Value())
+			}
+
+			name, err := obj.Get(utf16.S("name"))
+			assert.NoError(t, err, "getting name")
+			assert.EqualStrings(t, tc.errname, name.ToString().String(), "value name")
+		})
+	}
+}
+
+func TestParseErrorIsNotJSError(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("0.1.")
+	if _, ok := err.(*abad.JSError); ok {
+		t.Fatalf("parser error must not be a JSError: %s", err)
+	}
+}
+
+func TestParserOptions(t *testing.T) {
+	code := "function f(a, b,) {} x = f(1, 2,); 2"
+
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval(code)
+	assert.Error(t, err, "trailing comma must be rejected by default")
+
+	js, err = abad.NewAbad(abad.ParserOptions(parser.TrailingCommas()))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	val, err := js.Eval(code)
+	assert.NoError(t, err, "evaluating %s", code)
+
+	if !types.StrictEqual(types.Number(2), val) {
+		t.Fatalf("got %v but want 2", val)
+	}
+}
+
+func TestES6ParserOption(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("0b11")
+	assert.Error(t, err, "binary literal must be rejected by default")
+
+	js, err = abad.NewAbad(abad.ParserOptions(parser.ES6()))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	val, err := js.Eval("0b11")
+	assert.NoError(t, err, "evaluating binary literal")
+
+	if !types.StrictEqual(types.Number(3), val) {
+		t.Fatalf("got %v but want 3", val)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	code := "a = 1; a"
+
+	for _, tc := range []struct {
+		name string
+		opts []abad.Option
+		want types.Value
+	}{
+		{
+			name: "Default",
+			want: types.Number(1),
+		},
+		{
+			name: "Last",
+			opts: []abad.Option{abad.Completion(abad.CompletionLast)},
+			want: types.Number(1),
+		},
+		{
+			name: "Script",
+			opts: []abad.Option{abad.Completion(abad.CompletionScript)},
+			want: types.Undefined,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(tc.opts...)
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.EvalFile("test.js", code)
+			assert.NoError(t, err, "evaluating file")
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("EvalFile: got %v but want %v", val, tc.want)
+			}
+
+			val, err = js.EvalFiles([]parser.File{{Name: "test.js", Code: code}})
+			assert.NoError(t, err, "evaluating files")
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("EvalFiles: got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefineAccessor(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	var count float64
+	err = js.DefineAccessor("counter", func(types.Object, []types.Value) (types.Value, error) {
+		count++
+		return types.Number(count), nil
+	}, nil)
+	assert.NoError(t, err, "defining counter")
+
+	var stored types.Value = types.Undefined
+	err = js.DefineAccessor("stored", func(types.Object, []types.Value) (types.Value, error) {
+		return stored, nil
+	}, func(_ types.Object, args []types.Value) (types.Value, error) {
+		stored = args[0]
+		return types.Undefined, nil
+	})
+	assert.NoError(t, err, "defining stored")
+
+	for _, tc := range []struct {
+		code string
+		want types.Value
+	}{
+		{code: "counter", want: types.Number(1)},
+		{code: "counter; counter", want: types.Number(3)},
+		{code: "counter = 10; counter", want: types.Number(4)},
+		{code: "stored = 5", want: types.Number(5)},
+		{code: "stored", want: types.Number(5)},
+	} {
+		val, err := js.Eval(tc.code)
+		assert.NoError(t, err, "evaluating %s", tc.code)
+
+		if !types.StrictEqual(tc.want, val) {
+			t.Fatalf("%s: got %v but want %v", tc.code, val, tc.want)
+		}
+	}
+
+	if !types.StrictEqual(types.Number(5), stored) {
+		t.Fatalf("setter got %v but want 5", stored)
+	}
+}
+
+func TestBuiltinError(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	fail := func(types.Object, []types.Value) (types.Value, error) {
+		return nil, types.NewTypeError("builtin failed")
+	}
+
+	err = js.DefineAccessor("getfail", fail, nil)
+	assert.NoError(t, err, "defining getfail")
+
+	err = js.DefineAccessor("callfail", func(types.Object, []types.Value) (types.Value, error) {
+		return types.NewBuiltinfn(fail), nil
+	}, nil)
+	assert.NoError(t, err, "defining callfail")
+
+	for _, code := range []string{"getfail", "callfail()", "a = 1; callfail(); a = 2"} {
+		_, err := js.Eval(code)
+		assert.EqualErrs(t, E("TypeError: builtin failed"), err, "evaluating %s", code)
+	}
+
+	val, err := js.Eval("a")
+	assert.NoError(t, err, "evaluating a")
+	if !types.StrictEqual(types.Number(1), val) {
+		t.Fatalf("evaluation must stop on the builtin error, got a = %v", val)
+	}
+}
+
+func TestCyclicObjects(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Property",
+			code: "Math.self = Math; Math.self.self.self.toString()",
+			want: types.NewString("[object Math]"),
+		},
+		{
+			name: "StringConversion",
+			code: `Math.self = Math; Math[Math.self] = 1; Math["[object Math]"]`,
+			want: types.Number(1),
+		},
+		{
+			name: "Function",
+			code: `f = function () {}; f.f = f; f.f.f.name`,
+			want: types.NewString("f"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestFunctionDeclaration(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		err  error
+	}{
+		{
+			name: "CallBeforeDeclaration",
+			code: "f(); function f() {}",
+		},
+		{
+			name: "InnerFunctionHoisting",
+			code: "function f() { g(); function g() {} } f()",
+		},
+		{
+			name: "InnerFunctionInBlock",
+			code: "function f() { g(); { function g() {} } } f()",
+		},
+		{
+			name: "Params",
+			code: "function f(a, b) { g(b); function g(c) { c.log } } f(1, console)",
+		},
+		{
+			name: "MissingParamIsUndefined",
+			code: "function f(a) { a() } f()",
+			err:  E("TypeError: a is not a function (<interactive>:1:18)"),
+		},
+		{
+			name: "InnerFunctionDoesNotLeak",
+			code: "function f() { function g() {} } f(); g",
+			err:  E("ReferenceError: [g] is not defined"),
+		},
+		{
+			name: "ErrorInsideFunction",
+			code: "function f() { angular } f()",
+			err:  E("ReferenceError: [angular] is not defined"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(types.Undefined, val) {
+				t.Fatalf("got %v but want undefined", val)
+			}
+		})
+	}
+}
+
+func TestMaxCallDepth(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("function f() { f() } f()")
+	assert.EqualErrs(t, E("RangeError: Maximum call stack size exceeded"), err,
+		"unbounded recursion")
+
+	js, err = abad.NewAbad(abad.MaxCallDepth(3))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("var calls = 0; function g() { calls = calls + 1; g() } g()")
+	assert.EqualErrs(t, E("RangeError: Maximum call stack size exceeded"), err,
+		"recursion deeper than the limit")
+
+	val, err := js.Eval("calls")
+	assert.NoError(t, err, "reading calls")
+	if !types.StrictEqual(types.Number(3), val) {
+		t.Fatalf("got %v calls but want 3", val)
+	}
+
+	// the depth is restored when the calls fail
+	_, err = js.Eval("function h() { function i() { function j() {} j() } i() } h()")
+	assert.NoError(t, err, "calls up to the limit")
+}
+
+func TestFunctionName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Declaration",
+			code: "function f() {} f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "NamedExpression",
+			code: "f = function g() {}; f.name",
+			want: types.NewString("g"),
+		},
+		{
+			name: "InferredFromIdentifier",
+			code: "f = function () {}; f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "InferredFromVar",
+			code: "var f = function () {}; f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "InferredFromVarList",
+			code: "var a = 1, f = function () {}; f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "InferredFromVarInFunction",
+			code: "function g() { var f = function () {}; h = f } g(); h.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "InferredFromChainedAssignment",
+			code: "f = g = function () {}; f.name",
+			want: types.NewString("g"),
+		},
+		{
+			name: "NotInferredFromProperty",
+			code: "Math.f = function () {}; Math.f.name",
+			want: types.Undefined,
+		},
+		{
+			name: "InferredThroughGroup",
+			code: "f = (function () {}); f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "ReadOnly",
+			code: `function f() {} f.name = "g"; f.name`,
+			want: types.NewString("f"),
+		},
+		{
+			name: "HasProperty",
+			code: `function f() {} "name" in f`,
+			want: types.True,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestFunctionEnvironment(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Closure",
+			code: "var get; function f() { var n = 1; get = function () { n = n + 1; r = n } } f(); get(); get(); r",
+			want: types.Number(3),
+		},
+		{
+			name: "ClosuresOfEachCall",
+			code: "var fs = Array.of(); function f(n) { fs[n] = function () { r = n } } f(0); f(1); fs[0](); r",
+			want: types.Number(0),
+		},
+		{
+			name: "NamedExpressionSelf",
+			code: "var f = function g() { r = g }; f(); r === f",
+			want: types.True,
+		},
+		{
+			name: "NamedExpressionShadowedByParam",
+			code: "var f = function g(g) { r = g }; f(1); r",
+			want: types.Number(1),
+		},
+		{
+			name: "NamedExpressionShadowedByVar",
+			code: "var f = function g() { var g; r = g }; f(); r",
+			want: types.Undefined,
+		},
+		{
+			name: "NamedExpressionDoesNotLeak",
+			code: "var f = function g() {}; g",
+			err:  E("ReferenceError: [g] is not defined"),
+		},
+		{
+			name: "GlobalFromFunction",
+			code: "var a = 1; function f() { a = a + 1; b = a } f(); a + b",
+			want: types.Number(4),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "ImplicitGlobal",
+			code: "a = 1; a",
+			want: types.Number(1),
+		},
+		{
+			name: "ImplicitGlobalFromFunction",
+			code: "function f() { a = 2 } f(); a",
+			want: types.Number(2),
+		},
+		{
+			name: "Parameter",
+			code: "function f(a) { a = 3 } f(1); a",
+			err:  E("ReferenceError: [a] is not defined"),
+		},
+		{
+			name: "Chained",
+			code: "a = b = 4; a",
+			want: types.Number(4),
+		},
+		{
+			name: "BuiltinObject",
+			code: "Math.rand = Math.random; Math.rand(); Math.x = 6",
+			want: types.Number(6),
+		},
+		{
+			name: "UserFunction",
+			code: "Math.f = function (a) { Math.y = a }; Math.f(7); Math.y",
+			want: types.Number(7),
+		},
+		{
+			name: "Primitive",
+			code: "a = 1; a.b = 8",
+			want: types.Number(8),
+		},
+		{
+			name: "Undefined",
+			code: "console.nothing.b = 1",
+			err:  E("TypeError: cannot set property b of undefined"),
+		},
+		{
+			name: "AddAssign",
+			code: "a = 1; a += 2; a",
+			want: types.Number(3),
+		},
+		{
+			name: "AddAssignConcatenates",
+			code: `a = "a"; a += 1`,
+			want: types.NewString("a1"),
+		},
+		{
+			name: "Arithmetic",
+			code: "a = 10; a -= 1; a *= 2; a /= 3; a %= 4",
+			want: types.Number(2),
+		},
+		{
+			name: "Shifts",
+			code: "a = -16; b = a; c = 1; a >>= 2; b >>>= 28; c <<= 33; a + b + c",
+			want: types.Number(13),
+		},
+		{
+			name: "Bitwise",
+			code: "a = 12; a &= 10; b = a; b |= 5; c = b; c ^= 3; a + b + c",
+			want: types.Number(35),
+		},
+		{
+			name: "RightAssociative",
+			code: "a = 1; b = 2; a += b *= 3; a + b",
+			want: types.Number(13),
+		},
+		{
+			name: "Member",
+			code: "Math.z = 1; Math.z += 2; Math.z",
+			want: types.Number(3),
+		},
+		{
+			name: "Index",
+			code: `Math["w"] = 5; Math["w"] <<= 1; Math.w`,
+			want: types.Number(10),
+		},
+		{
+			name: "CompoundUndeclared",
+			code: "nothing += 1",
+			err:  E("ReferenceError: [nothing] is not defined"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestVarStatement(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Initializer",
+			code: "var a = 1; a",
+			want: types.Number(1),
+		},
+		{
+			name: "Expression",
+			code: "var a = 1 + 2 * 3, b = a - 1; b",
+			want: types.Number(6),
+		},
+		{
+			name: "NoInitializer",
+			code: "var a; a",
+			want: types.Undefined,
+		},
+		{
+			name: "Hoisted",
+			code: "b = a; var a = 1; b",
+			want: types.Undefined,
+		},
+		{
+			name: "Redeclared",
+			code: "var a = 1; var a; a",
+			want: types.Number(1),
+		},
+		{
+			name: "GlobalProperty",
+			code: "var a = 2; this.a",
+			want: types.Number(2),
+		},
+		{
+			name: "KeepsGlobal",
+			code: "var Math; Math.random() < 1",
+			want: types.True,
+		},
+		{
+			name: "Local",
+			code: "function f() { var a = 3 } f(); a",
+			err:  E("ReferenceError: [a] is not defined"),
+		},
+		{
+			name: "ShadowsGlobal",
+			code: "a = 1; function f() { var a = 2 } f(); a",
+			want: types.Number(1),
+		},
+		{
+			name: "HoistedInFunction",
+			code: "a = 1; function f() { b = a; var a = 2 } f(); b",
+			want: types.Undefined,
+		},
+		{
+			name: "Parameter",
+			code: "function f(a) { var a; b = a } f(4); b",
+			want: types.Number(4),
+		},
+		{
+			name: "FunctionDeclaration",
+			code: "var f; function f() {} f.name",
+			want: types.NewString("f"),
+		},
+		{
+			name: "WithoutSemicolon",
+			code: "var a = 5\na",
+			want: types.Number(5),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestThis(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "GlobalObject",
+			code: "this.x = 1; x",
+			want: types.Number(1),
+		},
+		{
+			name: "Global",
+			code: "x = 2; this.x",
+			want: types.Number(2),
+		},
+		{
+			name: "FunctionCall",
+			code: "function f() { this.y = 3 } f(); y",
+			want: types.Number(3),
+		},
+		{
+			name: "MethodCall",
+			code: "Math.f = function () { this.z = 4 }; Math.f(); Math.z",
+			want: types.Number(4),
+		},
+		{
+			name: "NestedFunction",
+			code: "Math.f = function () { function g() { this.w = 5 } g() }; Math.f(); w",
+			want: types.Number(5),
+		},
+		{
+			name: "MemberOfNull",
+			code: "null.x",
+			err:  E("TypeError: cannot read property x of null"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "OwnProperty",
+			code: `"log" in console`,
+			want: types.True,
+		},
+		{
+			name: "MissingProperty",
+			code: `"nothing" in console`,
+			want: types.False,
+		},
+		{
+			name: "AssignedProperty",
+			code: `Math.x = 1; "x" in Math`,
+			want: types.True,
+		},
+		{
+			name: "NumberKey",
+			code: `Math.x = 1; 1 in Math`,
+			want: types.False,
+		},
+		{
+			name: "PrimitiveRightOperand",
+			code: `"a" in "abc"`,
+			err:  E("TypeError: cannot use 'in' operator to search for 'a' in abc"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestArithmeticOperators(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Precedence",
+			code: "1 + 2 * 3",
+			want: types.Number(7),
+		},
+		{
+			name: "LeftAssociative",
+			code: "10 - 4 - 3",
+			want: types.Number(3),
+		},
+		{
+			name: "Division",
+			code: "6 / 3 * 2",
+			want: types.Number(4),
+		},
+		{
+			name: "DivisionByZero",
+			code: "-1 / 0",
+			want: types.Number(math.Inf(-1)),
+		},
+		{
+			name: "Remainder",
+			code: "-7 % 3",
+			want: types.Number(-1),
+		},
+		{
+			name: "Concatenation",
+			code: `"a" + 1 + 2`,
+			want: types.NewString("a12"),
+		},
+		{
+			name: "SumThenConcatenation",
+			code: `1 + 2 + "a"`,
+			want: types.NewString("3a"),
+		},
+		{
+			name: "StringToNumber",
+			code: `"6" * "7"`,
+			want: types.Number(42),
+		},
+		{
+			name: "Null",
+			code: "null + 1",
+			want: types.Number(1),
+		},
+		{
+			name: "Variables",
+			code: "a = 2; a * a + 1",
+			want: types.Number(5),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestComparisonOperators(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		want bool
+	}{
+		{code: "1 < 2", want: true},
+		{code: "2 < 1", want: false},
+		{code: "2 > 1", want: true},
+		{code: "1 <= 1", want: true},
+		{code: "2 >= 3", want: false},
+		{code: `"a" < "b"`, want: true},
+		{code: `"10" < "9"`, want: true},
+		{code: `10 < "9"`, want: false},
+		{code: "0 / 0 < 1", want: false},
+		{code: "0 / 0 >= 1", want: false},
+		{code: "undefined <= 0", want: false},
+		{code: "null <= 0", want: true},
+		{code: "1 + 1 == 2", want: true},
+		{code: `1 == "1"`, want: true},
+		{code: `1 === "1"`, want: false},
+		{code: "0 == false", want: true},
+		{code: `"" != 0`, want: false},
+		{code: "null == undefined", want: true},
+		{code: "null === undefined", want: false},
+		{code: "null == 0", want: false},
+		{code: "0 / 0 == 0 / 0", want: false},
+		{code: "Math == Math", want: true},
+		{code: "Math === console", want: false},
+		{code: "1 !== 1", want: false},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(types.NewBool(tc.want), val) {
+				t.Fatalf("got %v but want %t", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		want types.Value
+	}{
+		{code: "1 && 2", want: types.Number(2)},
+		{code: `0 && "a"`, want: types.Number(0)},
+		{code: `"" || "a"`, want: types.NewString("a")},
+		{code: "1 || 2", want: types.Number(1)},
+		{code: `"" || null || 0`, want: types.Number(0)},
+		{code: "1 < 2 && 2 < 3 || !1", want: types.True},
+		{code: "!0", want: types.True},
+		{code: `!"a"`, want: types.False},
+		{code: "!!Math", want: types.True},
+		{code: "!(0 / 0)", want: types.True},
+		{code: "!undefined", want: types.True},
+		// the right operand isn't evaluated, f is not defined
+		{code: "0 && f()", want: types.Number(0)},
+		{code: `"a" || f()`, want: types.NewString("a")},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringIndexing(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Index",
+			code: `s = "abc"; s[1]`,
+			want: types.NewString("b"),
+		},
+		{
+			name: "IndexOutOfRange",
+			code: `s = "abc"; s[3]`,
+			want: types.Undefined,
+		},
+		{
+			name: "Length",
+			code: `s = "abc"; s.length`,
+			want: types.Number(3),
+		},
+		{
+			name: "EscapedLength",
+			code: `s = "a\tb\u00e7"; s.length`,
+			want: types.Number(4),
+		},
+		{
+			name: "LengthByName",
+			code: `s = "abc"; s["length"]`,
+			want: types.Number(3),
+		},
+		{
+			name: "LiteralLength",
+			code: `"abc".length+"de".length`,
+			want: types.Number(5),
+		},
+		{
+			name: "LiteralIndex",
+			code: `"abc"[2]`,
+			want: types.NewString("c"),
+		},
+		{
+			name: "ReadOnly",
+			code: `s = "abc"; s[0] = "x"; s[0]`,
+			want: types.NewString("a"),
+		},
+		{
+			name: "ObjectIndex",
+			code: `Math["x"] = 1; Math.x`,
+			want: types.Number(1),
+		},
+		{
+			name: "UndefinedIndex",
+			code: `console.nothing[0]`,
+			err:  E("TypeError: cannot read property 0 of undefined"),
+		},
+		{
+			name: "NumberProperty",
+			code: `x = 1; x.y`,
+			want: types.Undefined,
+		},
+		{
+			name: "NumberIndex",
+			code: `x = 1; x[0]`,
+			want: types.Undefined,
+		},
+		{
+			name: "NumberMethod",
+			code: `(1).toString`,
+			want: types.Undefined,
+		},
+		{
+			name: "BoolProperty",
+			code: `true.x`,
+			want: types.Undefined,
+		},
+		{
+			name: "NumberCompoundAssign",
+			code: `x = 1; x.y += 1; x.y`,
+			want: types.Undefined,
+		},
+		{
+			name: "NumberMethodCall",
+			code: `(1).toString()`,
+			err:  E("TypeError: 1.toString is not a function (<interactive>:1:13)"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestArray(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Of",
+			code: `a = Array.of("a", "b"); a[1]`,
+			want: types.NewString("b"),
+		},
+		{
+			name: "OfLength",
+			code: `Array.of(1, 2, 3).length`,
+			want: types.Number(3),
+		},
+		{
+			name: "FromString",
+			code: `Array.from("abc")[2]`,
+			want: types.NewString("c"),
+		},
+		{
+			name: "FromUserMapFunction",
+			code: `function f(v, i) { last = i }; Array.from("abc", f); last`,
+			want: types.Number(2),
+		},
+		{
+			name: "Sort",
+			code: `a = Array.of("b", "c", "a"); a.sort(); a[0]`,
+			want: types.NewString("a"),
+		},
+		{
+			name: "SortChained",
+			code: `Array.of(10, 9, 1).sort()[1]`,
+			want: types.Number(10),
+		},
+		{
+			name: "SortNotFunction",
+			code: `Array.of(2, 1).sort(1)`,
+			err:  E("TypeError: the comparison function must be a function or undefined"),
+		},
+		{
+			name: "FromUndefined",
+			code: `Array.from(undefined)`,
+			err:  E("TypeError: undefined cannot be converted to Object"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringReplace(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "String",
+			code: `s = "a-b-c"; s.replace("-", "+")`,
+			want: types.NewString("a+b-c"),
+		},
+		{
+			name: "Patterns",
+			code: `s = "abc"; s.replace("b", "[$&$$]")`,
+			want: types.NewString("a[b$]c"),
+		},
+		{
+			name: "IndexCallee",
+			code: `s = "abc"; s["replace"]("c", "d")`,
+			want: types.NewString("abd"),
+		},
+		{
+			name: "UserFunction",
+			code: `function f(m, pos, str) { got = str }; s = "abc"; s.replace("b", f); got`,
+			want: types.NewString("abc"),
+		},
+		{
+			name: "WithoutThis",
+			code: `s = "abc"; r = s.replace; r("b", "c")`,
+			err:  E("TypeError: String.prototype.replace called on null or undefined"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestDate(t *testing.T) {
+	brt := time.FixedZone("BRT", -3*60*60)
+	epoch := time.Unix(1500000000, 0)
+
+	for _, tc := range []struct {
+		name string
+		code string
+		opts []abad.Option
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Now",
+			code: `d = new Date; d.getTime()`,
+			opts: []abad.Option{abad.Deterministic(0, epoch)},
+			want: types.Number(1500000000000),
+		},
+		{
+			name: "DeterministicIsUTC",
+			code: `d = new Date(0); d.getHours()`,
+			opts: []abad.Option{abad.Deterministic(0, epoch)},
+			want: types.Number(0),
+		},
+		{
+			name: "Timezone",
+			code: `d = new Date(0); d.getHours()`,
+			opts: []abad.Option{abad.Timezone(brt), abad.Deterministic(0, epoch)},
+			want: types.Number(21),
+		},
+		{
+			name: "UTC",
+			code: `d = new Date(0); d.getUTCHours()`,
+			opts: []abad.Option{abad.Timezone(brt)},
+			want: types.Number(0),
+		},
+		{
+			name: "Components",
+			code: `d = new Date(2000, 0, 1); d.getUTCHours()`,
+			opts: []abad.Option{abad.Timezone(brt)},
+			want: types.Number(3),
+		},
+		{
+			name: "SetTime",
+			code: `d = new Date(0); d.setTime(86400000); d.getUTCDate()`,
+			want: types.Number(2),
+		},
+		{
+			name: "NewMemberCall",
+			code: `new Date(0).getTime()`,
+			want: types.Number(0),
+		},
+		{
+			name: "NotConstructor",
+			code: `new Math`,
+			err:  E("TypeError: Math is not a constructor"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(append(tc.opts, abad.Sandbox(abad.CapAll))...)
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Array",
+			code: `JSON.stringify(Array.of(1, "a", undefined, null))`,
+			want: types.NewString(`[1,"a",null,null]`),
+		},
+		{
+			name: "Object",
+			code: `Math.a = 1; Math.f = function() {}; JSON.stringify(Math)`,
+			want: types.NewString(`{"a":1}`),
+		},
+		{
+			name: "Space",
+			code: `JSON.stringify(Array.of(1), undefined, 2)`,
+			want: types.NewString("[\n  1\n]"),
+		},
+		{
+			name: "ReplacerArray",
+			code: `Math.a = 1; Math.b = 2; JSON.stringify(Math, Array.of("b"))`,
+			want: types.NewString(`{"b":2}`),
+		},
+		{
+			name: "Undefined",
+			code: `JSON.stringify(undefined)`,
+			want: types.Undefined,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating")
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	for code, want := range map[string]float64{
+		`parseInt("42px")`:          42,
+		`parseInt("  -0x1F")`:       -31,
+		`parseInt("ff", 16)`:        255,
+		`parseInt("0x1F", 10)`:      0,
+		`parseInt("11", 2)`:         3,
+		`parseInt("zz", 36)`:        1295,
+		`parseInt("12", "8")`:       10,
+		`parseInt("12", undefined)`: 12,
+		`parseInt(123.9)`:           123,
+		`parseInt("12", 37)`:        math.NaN(),
+		`parseInt("x")`:             math.NaN(),
+	} {
+		t.Run(code, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(code)
+			assert.NoError(t, err, "evaluating")
+
+			got := val.ToNumber().Value()
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Fatalf("got %v but want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNotFunction(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "Ident",
+			code: `x = 1; x()`,
+			want: "TypeError: x is not a function (test.js:1:9)",
+		},
+		{
+			name: "Member",
+			code: "a = 1;\nMath.doStuff(a)",
+			want: "TypeError: Math.doStuff is not a function (test.js:2:13)",
+		},
+		{
+			name: "Index",
+			code: `Math["do stuff"]()`,
+			want: `TypeError: Math["do stuff"] is not a function (test.js:1:17)`,
+		},
+		{
+			name: "CallResult",
+			code: `Math.random()()`,
+			want: "TypeError: Math.random(<args>) is not a function (test.js:1:14)",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			_, err = js.EvalFile("test.js", tc.code)
+			assert.EqualErrs(t, E(tc.want), err, "errors differ")
+
+			jserr, ok := err.(*abad.JSError)
+			if !ok {
+				t.Fatalf("got %T, want a *abad.JSError", err)
+			}
+
+			assert.EqualStrings(t, "TypeError", jserr.Name(), "error name")
+		})
+	}
+}
+
+func TestObjectKeys(t *testing.T) {
+	const setup = `Math.b = 1; Math[10] = 2; Math.a = 3; Math[2] = 4; `
+
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Order",
+			code: `k = Object.keys(Math); k[0] + k[1] + k[2] + k[3]`,
+			want: types.NewString("210ba"),
+		},
+		{
+			name: "SkipsBuiltins",
+			code: `Object.keys(Math).length`,
+			want: types.Number(4),
+		},
+		{
+			name: "Values",
+			code: `v = Object.values(Math); v[0]*1000 + v[1]*100 + v[2]*10 + v[3]`,
+			want: types.Number(4213),
+		},
+		{
+			name: "Entries",
+			code: `e = Object.entries(Math); e[3][0] + e[3][1] + e.length`,
+			want: types.NewString("a34"),
+		},
+		{
+			name: "Number",
+			code: `Object.keys(1).length + Object.values(1.5).length + Object.entries(0/0).length`,
+			want: types.Number(0),
+		},
+		{
+			name: "Bool",
+			code: `Object.keys(true).length + Object.values(false).length + Object.entries(true).length`,
+			want: types.Number(0),
+		},
+		{
+			name: "PrimitiveGrowable",
+			code: `k = Object.keys(1); k[0] = "a"; k.length`,
+			want: types.Number(1),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(setup + tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestGrowableArrays(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "WritePastTheEnd",
+			code: `a = Array.of(1); a[1] = 2; a.length`,
+			want: types.Number(2),
+		},
+		{
+			name: "GapIsUndefined",
+			code: `a = Array.from("a"); a[2] = "c"; a[1]`,
+			want: types.Undefined,
+		},
+		{
+			name: "Truncate",
+			code: `a = Array.of(1, 2, 3); a.length = 1; a[1]`,
+			want: types.Undefined,
+		},
+		{
+			name: "InvalidLength",
+			code: `a = Array.of(1); a.length = 1.5`,
+			err:  E("TypeError: invalid array length 1.5"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestObjectAssign(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+		err  error
+	}{
+		{
+			name: "Copies",
+			code: `Math.x = 1; o = Object.assign(console, Math); o.x`,
+			want: types.Number(1),
+		},
+		{
+			name: "ReturnsTarget",
+			code: `o = Object.assign(Math); o.x = 2; Math.x`,
+			want: types.Number(2),
+		},
+		{
+			name: "SkipsBuiltinMethods",
+			code: `o = Object.assign(Array.of(), Math, Object, console); o.random || o.assign || o.log || o.toString`,
+			want: types.Undefined,
+		},
+		{
+			name: "SkipsBuiltinGlobals",
+			code: `o = Object.assign(Array.of(), this); o.Object || o.Math || o.console || o.parseInt`,
+			want: types.Undefined,
+		},
+		{
+			name: "ArrayPastTheEnd",
+			code: `a = Object.assign(Array.of(1), Array.of(5, 6)); a[0] + a[1] + a.length`,
+			want: types.Number(13),
+		},
+		{
+			name: "PrimitiveSources",
+			code: `Object.assign(Math, 1, true, null) === Math`,
+			want: types.True,
+		},
+		{
+			name: "UndefinedTarget",
+			code: `Object.assign(undefined, Math)`,
+			err:  E("TypeError: undefined cannot be converted to Object"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if err != nil {
+				return
+			}
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestUndefinedGlobal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "Value",
+			code: `undefined`,
+			want: types.Undefined,
+		},
+		{
+			name: "NotWritable",
+			code: `undefined = 1; undefined`,
+			want: types.Undefined,
+		},
+		{
+			name: "ShadowedByParam",
+			code: `function f(undefined) { x = undefined } f(1); x`,
+			want: types.Number(1),
+		},
+		{
+			name: "ShadowedByParamIsUndefined",
+			code: `function f(undefined) { x = undefined } f(); x`,
+			want: types.Undefined,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating")
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestNullIsLiteral(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval(`null = 1`)
+	if err == nil {
+		t.Fatal("assigning to null must fail")
+	}
+
+	if _, ok := err.(*abad.JSError); ok {
+		t.Fatalf("got JSError %v, want a parse error", err)
+	}
+}
+
+func TestTrapNaN(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		opts []abad.Option
+		err  error
+	}{
+		{
+			name: "Disabled",
+			code: `parseInt("px")`,
+		},
+		{
+			name: "Produced",
+			code: `n = parseInt("42");` + "\n" + `parseInt("px")`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: parseInt(<args>) produced NaN (test.js:2:9)"),
+		},
+		{
+			name: "Propagated",
+			code: `parseInt(nan)`,
+			opts: []abad.Option{abad.TrapNaN()},
+		},
+		{
+			name: "Division",
+			code: `0/0`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: (0 / 0) produced NaN"),
+		},
+		{
+			name: "StringOperand",
+			code: `"a"*2`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: (a * 2) produced NaN"),
+		},
+		{
+			name: "UndefinedOperand",
+			code: `undefined+1`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: (undefined + 1) produced NaN"),
+		},
+		{
+			name: "CompoundAssignment",
+			code: `x = "a";` + "\n" + `x -= 1`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: x -= 1 produced NaN"),
+		},
+		{
+			name: "UnaryOperator",
+			code: `-"a"`,
+			opts: []abad.Option{abad.TrapNaN()},
+			err:  E("Error: -a produced NaN"),
+		},
+		{
+			name: "ArithmeticDisabled",
+			code: `0/0 + "a"*2`,
+		},
+		{
+			name: "ArithmeticPropagated",
+			code: `nan * 2 + 1`,
+			opts: []abad.Option{abad.TrapNaN()},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(tc.opts...)
+			assert.NoError(t, err, "failed to start interpreter")
+
+			err = js.DefineAccessor("nan", func(types.Object, []types.Value) (types.Value, error) {
+				return types.NewNumber(math.NaN()), nil
+			}, nil)
+			assert.NoError(t, err, "defining nan")
+
+			_, err = js.EvalFile("test.js", tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+		})
+	}
+}
+
+func TestEvalExprWithScope(t *testing.T) {
+	scope := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":  "ana",
+			"roles": []interface{}{"admin", "dev"},
+			"age":   33,
+		},
+		"parseInt": "shadowed",
+		"none":     nil,
+		"active":   true,
+		"total":    types.Number(7),
+	}
+
+	for _, tc := range []struct {
+		code string
+		want types.Value
+		err  error
+	}{
+		{code: "user.name", want: types.NewString("ana")},
+		{code: "user.roles[1]", want: types.NewString("dev")},
+		{code: "user.roles.length", want: types.Number(2)},
+		{code: "user.age", want: types.Number(33)},
+		{code: `"age" in user`, want: types.True},
+		{code: "parseInt", want: types.NewString("shadowed")},
+		{code: "none", want: types.Null},
+		{code: "active", want: types.True},
+		{code: "total;", want: types.Number(7)},
+		{code: "-total", want: types.Number(-7)},
+		{code: "active = false", want: types.False},
+		{code: "user.nope", want: types.Undefined},
+		{code: "nope", err: E("ReferenceError: [nope] is not defined")},
+		{
+			code: "user; total",
+			err:  E(`parser error: <expr>: "user; total" is not a single expression`),
+		},
+	} {
+		js, err := abad.NewAbad()
+		assert.NoError(t, err, "failed to start interpreter")
+
+		val, err := js.EvalExprWithScope(tc.code, scope)
+		assert.EqualErrs(t, tc.err, err, "evaluating %s", tc.code)
+
+		if tc.err == nil && !types.StrictEqual(tc.want, val) {
+			t.Fatalf("%s: got %v but want %v", tc.code, val, tc.want)
+		}
+
+		// the scope never leaks to the global environment
+		_, err = js.Eval(`parseInt("1"); active`)
+		assert.EqualErrs(t, E("ReferenceError: [active] is not defined"), err,
+			"evaluating %s", tc.code)
+	}
+}
+
+func TestEvalExprWithScopeUnsupported(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.EvalExprWithScope("a", map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{1, struct{}{}},
+		},
+	})
+	assert.EqualErrs(t, E("scope a: .b: [1]: unsupported Go type struct {}"), err,
+		"converting scope")
+}
+
+func TestStdout(t *testing.T) {
+	var out bytes.Buffer
+	js, err := abad.NewAbad(abad.Sandbox(abad.CapAll), abad.Stdout(&out))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval(`console.log("a", 1); console.group("b"); console.log(true)`)
+	assert.NoError(t, err, "logging")
+	assert.EqualStrings(t, "a 1\nb\n  true\n", out.String(), "console output")
+}
+
+func TestHostObject(t *testing.T) {
+	tables := map[string]int{"users": 2, "orders": 3}
+	get := func(name utf16.Str) (types.Value, bool, error) {
+		rows, ok := tables[name.String()]
+		if !ok {
+			return nil, false, nil
+		}
+		return types.NewNumber(float64(rows)), true, nil
+	}
+
+	db := types.NewHostObject(types.HostTraps{
+		Get: get,
+		Has: func(name utf16.Str) bool {
+			_, ok, _ := get(name)
+			return ok
+		},
+		OwnKeys: func() []utf16.Str {
+			return []utf16.Str{utf16.S("orders"), utf16.S("users")}
+		},
+	})
+	count := types.NewHostObject(types.HostTraps{
+		Call: func(this types.Object, args []types.Value) (types.Value, error) {
+			rows, _, err := get(utf16.Str(args[0].ToString()))
+			return rows, err
+		},
+	})
+
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	for _, tc := range []struct {
+		code string
+		want types.Value
+	}{
+		{code: "db.users", want: types.NewNumber(2)},
+		{code: `db["orders"]`, want: types.NewNumber(3)},
+		{code: "db.nope", want: types.Undefined},
+		{code: `"users" in db`, want: types.True},
+		{code: `"nope" in db`, want: types.False},
+		{code: `count("orders")`, want: types.NewNumber(3)},
+		{code: "JSON.stringify(db)", want: types.NewString(`{"orders":3,"users":2}`)},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			got, err := js.EvalExprWithScope(tc.code, map[string]interface{}{
+				"db":    db,
+				"count": count,
+			})
+			assert.NoError(t, err, "evaluating %s", tc.code)
+			if !types.StrictEqual(tc.want, got) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBigIntNotSupported(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("a = 1; b = 10n; a = 2")
+	assert.EqualErrs(t, E("SyntaxError: BigInt not supported: 10n"), err, "evaluating BigInt")
+
+	val, err := js.Eval("a")
+	assert.NoError(t, err, "evaluating a")
+	if !types.StrictEqual(types.Number(1), val) {
+		t.Fatalf("evaluation must stop on the BigInt, got a = %v", val)
+	}
+}
+
+func TestNumericPropertyKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want types.Value
+	}{
+		{
+			name: "NumberAndString",
+			code: `Math[1] = "a"; Math["1"]`,
+			want: types.NewString("a"),
+		},
+		{
+			name: "StringAndFloat",
+			code: `Math["1"] = "a"; Math[1.0]`,
+			want: types.NewString("a"),
+		},
+		{
+			name: "NegativeZero",
+			code: `Math[-0] = "z"; Math["0"]`,
+			want: types.NewString("z"),
+		},
+		{
+			name: "Exponent",
+			code: `Math[1e21] = "big"; Math["1e+21"]`,
+			want: types.NewString("big"),
+		},
+		{
+			name: "NegativeExponent",
+			code: `Math[0.0000001] = "small"; Math["1e-7"]`,
+			want: types.NewString("small"),
+		},
+		{
+			name: "NaN",
+			code: `Math[parseInt("x")] = "nan"; Math["NaN"]`,
+			want: types.NewString("nan"),
+		},
+		{
+			name: "NotCanonical",
+			code: `Math[1] = "a"; Math["01"]`,
+			want: types.Undefined,
+		},
+		{
+			name: "ArrayNegativeZero",
+			code: `a = Array.of("a", "b"); a[-0]`,
+			want: types.NewString("a"),
+		},
+		{
+			name: "ArrayFloatWrite",
+			code: `a = Array.of("a", "b"); a[1.0] = "c"; a["1"]`,
+			want: types.NewString("c"),
+		},
+		{
+			name: "ArrayNotCanonical",
+			code: `a = Array.of("a", "b"); a["1.0"]`,
+			want: types.Undefined,
+		},
+		{
+			name: "String",
+			code: `s = "abc"; s[2.0]`,
+			want: types.NewString("c"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
+
+func TestObjectChangeHook(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	state := types.NewBaseDataObject()
+
+	synced := map[string]types.Value{}
+	state.OnChange(func(name utf16.Str, change types.Change) {
+		val, err := state.Get(name)
+		assert.NoError(t, err, "getting %s", name)
+		synced[name.String()] = val
+	})
+
+	_, err = js.EvalExprWithScope(`state.count = (state.name = "abad", 2)`,
+		map[string]interface{}{"state": state})
+	assert.NoError(t, err, "evaluating")
+
+	if !types.StrictEqual(types.NewString("abad"), synced["name"]) ||
+		!types.StrictEqual(types.Number(2), synced["count"]) {
+		t.Fatalf("got synced state %v", synced)
+	}
+}
+
+func TestStats(t *testing.T) {
+	js, err := abad.NewAbad(abad.Sandbox(abad.CapAll))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	eval := func(code string) types.HeapStats {
+		_, err := js.Eval(code)
+		assert.NoError(t, err, "evaluating %s", code)
+		return js.Stats()
+	}
+
+	before := js.Stats()
+	if before.Environments != 1 || before.Objects["Function"] == 0 {
+		t.Fatalf("got initial stats %+v", before)
+	}
+
+	got := eval(`s = "abcd"`)
+	if got.Properties != before.Properties+1 ||
+		got.StringBytes != before.StringBytes+8 {
+		t.Fatalf("got %+v after a string, before %+v", got, before)
+	}
+
+	before = got
+	got = eval(`d = new Date(0)`)
+	if got.Objects["object"] <= before.Objects["object"] {
+		t.Fatalf("got %+v after a date, before %+v", got, before)
+	}
+
+	got = eval(`function f(a) { g = function () {} }; f(1)`)
+	if got.Environments != 2 {
+		t.Fatalf("got %+v, want the environment of the closure", got)
+	}
+
+	eval(`g = null`)
+	deadline := time.Now().Add(10 * time.Second)
+	for js.Stats().Environments != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, the closure was not collected", js.Stats())
+		}
+
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOnWarning(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	var got []string
+	js.OnWarning(func(w parser.Warning) {
+		got = append(got, w.String())
+	})
+
+	_, err = js.EvalFile("test.js", "function f(a) { a = 2; b = a }\nf(1); c = 010")
+	assert.NoError(t, err, "evaluating")
+
+	want := []string{
+		"test.js:2:11: legacy octal literal 010 is deprecated, use 0o10",
+		"test.js: assignment to undeclared b creates a global",
+		"test.js: assignment to undeclared c creates a global",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got warnings %q, want %q", got, want)
+	}
+
+	got = nil
+	_, err = js.EvalFile("test.js", "var d = 1; d = 2\nfunction g() { var e; e = 3 }\ng()\nvar k = function l() { l }")
+	assert.NoError(t, err, "evaluating declared variables")
+	if len(got) != 0 {
+		t.Fatalf("got warnings %q assigning declared variables", got)
+	}
+
+	got = nil
+	_, err = js.EvalFile("test.js", "function h() { typo; typo; j; d; Math }\nj = 1")
+	assert.NoError(t, err, "evaluating undeclared names")
+
+	want = []string{
+		"test.js: typo is not declared",
+		"test.js: assignment to undeclared j creates a global",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got warnings %q, want %q", got, want)
+	}
+
+	got = nil
+	js.OnWarning(nil)
+	_, err = js.Eval("d = 010")
+	assert.NoError(t, err, "evaluating without handler")
+	if len(got) != 0 {
+		t.Fatalf("got warnings %q without handler", got)
+	}
+}
+
+func TestOperatorsToPrimitive(t *testing.T) {
+	var calls []string
+
+	method := func(name string, result types.Value) types.Value {
+		return types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+			calls = append(calls, name)
+			if result == nil {
+				return nil, types.NewTypeError("%s failed", name)
+			}
+			return result, nil
+		})
+	}
+
+	object := func(methods ...types.Value) types.Object {
+		obj := types.NewBaseDataObject()
+		for i, name := range []string{"valueOf", "toString"} {
+			if methods[i] != nil {
+				err := obj.Put(utf16.S(name), methods[i], true)
+				assert.NoError(t, err, "putting %s", name)
+			}
+		}
+		return obj
+	}
+
+	str := func(s string) types.Value { return types.NewString(s) }
+	num := func(n float64) types.Value { return types.Number(n) }
+	// a missing method
+	var undef types.Value
+
+	for _, tc := range []struct {
+		name  string
+		code  string
+		obj   types.Object
+		want  types.Value
+		err   error
+		calls []string
+	}{
+		{
+			name:  "ValueOfOnly",
+			code:  "o + 1",
+			obj:   object(method("valueOf", num(41)), undef),
+			want:  num(42),
+			calls: []string{"valueOf"},
+		},
+		{
+			name:  "ToStringOnly",
+			code:  "o * 2",
+			obj:   object(undef, method("toString", str("21"))),
+			want:  num(42),
+			calls: []string{"toString"},
+		},
+		{
+			name:  "ToStringOnlyConcatenates",
+			code:  "o + 1",
+			obj:   object(undef, method("toString", str("4"))),
+			want:  str("41"),
+			calls: []string{"toString"},
+		},
+		{
+			name:  "BothValueOfFirst",
+			code:  `o + ""`,
+			obj:   object(method("valueOf", num(1)), method("toString", str("a"))),
+			want:  str("1"),
+			calls: []string{"valueOf"},
+		},
+		{
+			name:  "ValueOfNotPrimitive",
+			code:  "o < 2",
+			obj:   object(method("valueOf", types.NewBaseDataObject()), method("toString", str("1"))),
+			want:  types.True,
+			calls: []string{"valueOf", "toString"},
+		},
+		{
+			name:  "Equality",
+			code:  `o == "42"`,
+			obj:   object(method("valueOf", num(42)), method("toString", str("a"))),
+			want:  types.True,
+			calls: []string{"valueOf"},
+		},
+		{
+			name:  "StrictEqualityDoesNotConvert",
+			code:  "o === 42",
+			obj:   object(method("valueOf", num(42)), undef),
+			want:  types.False,
+			calls: nil,
+		},
+		{
+			name:  "Unary",
+			code:  "-o",
+			obj:   object(method("valueOf", num(42)), undef),
+			want:  num(-42),
+			calls: []string{"valueOf"},
+		},
+		{
+			name:  "ValueOfThrows",
+			code:  "o - 1",
+			obj:   object(method("valueOf", nil), method("toString", str("1"))),
+			err:   E("TypeError: valueOf failed"),
+			calls: []string{"valueOf"},
+		},
+		{
+			name:  "ToStringThrows",
+			code:  "o > 1",
+			obj:   object(undef, method("toString", nil)),
+			err:   E("TypeError: toString failed"),
+			calls: []string{"toString"},
+		},
+		{
+			name: "NoMethods",
+			code: "o + 1",
+			obj:  object(undef, undef),
+			err:  E("TypeError: cannot convert object to primitive value"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			calls = nil
+			val, err := js.EvalExprWithScope(tc.code, map[string]interface{}{"o": tc.obj})
+			assert.EqualErrs(t, tc.err, err, "evaluating %s", tc.code)
+
+			if err == nil && !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+
+			if fmt.Sprint(calls) != fmt.Sprint(tc.calls) {
+				t.Fatalf("got calls %v, want %v", calls, tc.calls)
+			}
+		})
+	}
+}
+
+func TestDateToPrimitive(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		want types.Value
+	}{
+		{code: "new Date(0) - 0", want: types.Number(0)},
+		{code: "new Date(1000) > new Date(0)", want: types.True},
+		{code: `new Date(0) + 1`, want: types.NewString("Thu Jan 01 1970 00:00:00 GMT+0000 (UTC)1")},
+		{code: `new Date(0) == "Thu Jan 01 1970 00:00:00 GMT+0000 (UTC)"`, want: types.True},
+		{code: `new Date(0) == 0`, want: types.False},
+		{code: `(d = new Date(0), d.toString = Date.now, d + 1)`, want: types.Number(1)},
+		{code: `(d = new Date(0), d.valueOf = Date.now, d * 1)`, want: types.Number(0)},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			js, err := abad.NewAbad(abad.Sandbox(abad.CapAll), abad.Deterministic(0, time.Unix(0, 0)))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			if !types.StrictEqual(tc.want, val) {
+				t.Fatalf("got %v but want %v", val, tc.want)
+			}
+		})
+	}
+}
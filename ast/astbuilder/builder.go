@@ -0,0 +1,156 @@
+// Package astbuilder builds ASTs programmatically, eg.: in codemods
+// and tests, with the same nodes produced by the parser.
+package astbuilder
+
+import (
+	"strings"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+// Builder creates the nodes of an AST. The calls it creates are
+// synthetic, see ast.CallExpr, and are positioned at the position of
+// the builder, so the errors they raise point to the code they were
+// built from. The zero Builder has no position, which is kept unknown
+// or, if the calls replace others in ast.Rewrite, is of the calls
+// replaced.
+type Builder struct {
+	Line   uint
+	Column uint
+}
+
+// At creates a builder of nodes positioned at line and column.
+func At(line, column uint) Builder {
+	return Builder{Line: line, Column: column}
+}
+
+// Ident creates an identifier.
+func (b Builder) Ident(name string) ast.Ident {
+	return ast.NewIdent(utf16.S(name))
+}
+
+// Path creates the identifiers and member expressions of a dotted
+// path, eg.: console.log
+func (b Builder) Path(path string) ast.Node {
+	names := strings.Split(path, ".")
+
+	var node ast.Node = b.Ident(names[0])
+	for _, name := range names[1:] {
+		node = b.Member(node, name)
+	}
+
+	return node
+}
+
+// String creates a string literal.
+func (b Builder) String(str string) ast.String {
+	return ast.NewString(utf16.S(str))
+}
+
+// Number creates a numeric literal.
+func (b Builder) Number(n float64) ast.Number {
+	return ast.NewNumber(n)
+}
+
+// Bool creates a boolean literal.
+func (b Builder) Bool(v bool) ast.Bool {
+	return ast.NewBool(v)
+}
+
+// Null creates the null literal.
+func (b Builder) Null() ast.Null {
+	return ast.NewNull()
+}
+
+// Unary creates a unary expression, eg.: -a
+func (b Builder) Unary(operator token.Type, operand ast.Node) *ast.UnaryExpr {
+	return ast.NewUnaryExpr(operator, operand)
+}
+
+// Binary creates a binary expression, eg.: a in b
+func (b Builder) Binary(operator token.Type, left, right ast.Node) *ast.BinaryExpr {
+	return ast.NewBinaryExpr(operator, left, right)
+}
+
+// Member creates the member expression object.property
+func (b Builder) Member(object ast.Node, property string) *ast.MemberExpr {
+	return ast.NewMemberExpr(object, b.Ident(property))
+}
+
+// Index creates the index expression object[index]
+func (b Builder) Index(object, index ast.Node) *ast.IndexExpr {
+	return ast.NewIndexExpr(object, index)
+}
+
+// Call creates a synthetic call positioned at the builder position.
+func (b Builder) Call(callee ast.Node, args ...ast.Node) *ast.CallExpr {
+	call := ast.NewCallExpr(callee, nodes(args))
+	call.Line, call.Column = b.Line, b.Column
+	call.Synthetic = true
+	return call
+}
+
+// New creates the new expression new callee(args)
+func (b Builder) New(callee ast.Node, args ...ast.Node) *ast.NewExpr {
+	return ast.NewNewExpr(callee, nodes(args))
+}
+
+// Assign creates the assignment target = value
+func (b Builder) Assign(target, value ast.Node) *ast.AssignExpr {
+	return ast.NewAssignExpr(target, value)
+}
+
+// Sequence creates a sequence expression, eg.: (a, b)
+func (b Builder) Sequence(exprs ...ast.Node) *ast.SequenceExpr {
+	return ast.NewSequenceExpr(exprs...)
+}
+
+// Fun creates a function expression, name is empty for anonymous
+// functions.
+func (b Builder) Fun(name string, params []string, body ...ast.Node) *ast.FunExpr {
+	return ast.NewFunExpr(b.Ident(name), b.idents(params), b.Program(body...))
+}
+
+// FunDecl creates a function declaration.
+func (b Builder) FunDecl(name string, params []string, body ...ast.Node) *ast.FunDecl {
+	return ast.NewFunDecl(b.Ident(name), b.idents(params), b.Program(body...))
+}
+
+// Var creates the declaration of a variable, a nil value declares
+// it undefined.
+func (b Builder) Var(name string, value ast.Node) ast.VarDecls {
+	if value == nil {
+		value = ast.NewUndefined()
+	}
+
+	return ast.NewVarDecls(ast.NewVarDecl(b.Ident(name), value))
+}
+
+// Program creates a program, or a function body, of the statements.
+func (b Builder) Program(stmts ...ast.Node) *ast.Program {
+	return &ast.Program{Nodes: nodes(stmts)}
+}
+
+func (b Builder) idents(names []string) []ast.Ident {
+	if len(names) == 0 {
+		return nil
+	}
+
+	res := make([]ast.Ident, len(names))
+	for i, name := range names {
+		res[i] = b.Ident(name)
+	}
+
+	return res
+}
+
+// nodes returns nil for no nodes, as the parser does.
+func nodes(list []ast.Node) []ast.Node {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list
+}
@@ -0,0 +1,107 @@
+package astbuilder_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/ast/astbuilder"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/token"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestBuilderMatchesParser(t *testing.T) {
+	var b astbuilder.Builder
+
+	for _, tc := range []struct {
+		name string
+		code string
+		node ast.Node
+	}{
+		{
+			name: "Call",
+			code: `console.log("a", 1, true, null)`,
+			node: b.Call(b.Path("console.log"),
+				b.String("a"), b.Number(1), b.Bool(true), b.Null()),
+		},
+		{
+			name: "NoArgs",
+			code: `f()`,
+			node: b.Call(b.Ident("f")),
+		},
+		{
+			name: "New",
+			code: `d = new Date(0)`,
+			node: b.Assign(b.Ident("d"), b.New(b.Ident("Date"), b.Number(0))),
+		},
+		{
+			name: "Operators",
+			code: `-a[0] in b`,
+			node: b.Binary(token.In,
+				b.Unary(token.Minus, b.Index(b.Ident("a"), b.Number(0))),
+				b.Ident("b")),
+		},
+		{
+			name: "Sequence",
+			code: `(a, b)`,
+			node: b.Sequence(b.Ident("a"), b.Ident("b")),
+		},
+		{
+			name: "Function",
+			code: `function f(a, b) { g = function () {} }`,
+			node: b.FunDecl("f", []string{"a", "b"},
+				b.Assign(b.Ident("g"), b.Fun("", nil))),
+		},
+		{
+			name: "Var",
+			code: `var a = 1`,
+			node: b.Var("a", b.Number(1)),
+		},
+		{
+			name: "VarUndefined",
+			code: `var a;`,
+			node: b.Var("a", nil),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := parser.Parse("test.js", tc.code)
+			assert.NoError(t, err, "parsing %s", tc.code)
+
+			want := b.Program(tc.node)
+			if !program.Equal(want) {
+				t.Fatalf("built %s, parsed %s", want, program)
+			}
+		})
+	}
+}
+
+func TestBuilderPosition(t *testing.T) {
+	call := astbuilder.At(3, 7).Call(astbuilder.At(1, 1).Ident("f"))
+
+	if call.Line != 3 || call.Column != 7 || !call.Synthetic {
+		t.Fatalf("got call at %d:%d synthetic %t, want synthetic at 3:7",
+			call.Line, call.Column, call.Synthetic)
+	}
+
+	program, err := parser.Parse("test.js", "a = 1;\nf(a)")
+	assert.NoError(t, err, "parsing")
+
+	parsed := program.Nodes[1].(*ast.CallExpr)
+	if parsed.Synthetic {
+		t.Fatal("parsed call must not be synthetic")
+	}
+
+	var b astbuilder.Builder
+	got := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if call, ok := node.(*ast.CallExpr); ok {
+			return b.Call(b.Ident("g"), call.Args...)
+		}
+		return node
+	}).(*ast.Program)
+
+	call = got.Nodes[1].(*ast.CallExpr)
+	if call.Line != 2 || call.Column != 2 || !call.Synthetic {
+		t.Fatalf("got call at %d:%d synthetic %t, want synthetic at 2:2",
+			call.Line, call.Column, call.Synthetic)
+	}
+}
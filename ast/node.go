@@ -3,11 +3,12 @@ package ast
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -28,20 +29,39 @@ type (
 
 	Number float64
 
+	// BigInt is an arbitrary precision integer literal of ES2020,
+	// eg.: 10n
+	BigInt struct {
+		value *big.Int
+	}
+
 	String utf16.Str
 
 	Bool bool
 
+	// Undefined is the value of variables declared without an
+	// initializer. The undefined identifier is not a literal, it's
+	// a property of the global object.
 	Undefined struct{}
 
 	Null struct{}
 
+	// This is the this value of the code being evaluated.
+	This struct{}
+
 	// UnaryExpr is a unary expression (-a, +a, ~a, and so on)
 	UnaryExpr struct {
 		Operator token.Type
 		Operand  Node
 	}
 
+	// BinaryExpr is a binary expression (a in b, and so on)
+	BinaryExpr struct {
+		Operator token.Type
+		Left     Node
+		Right    Node
+	}
+
 	// MemberExpr handles get of object's properties
 	// eg.: <object>.<property>
 	MemberExpr struct {
@@ -49,9 +69,61 @@ type (
 		Property Ident
 	}
 
+	// IndexExpr handles get of object's properties by a
+	// computed name.
+	// eg.: <object>[<index>]
+	IndexExpr struct {
+		Object Node
+		Index  Node
+	}
+
 	CallExpr struct {
 		Callee Node
 		Args   []Node
+
+		// Line and Column of the call, ie. of the left paren of
+		// the arguments. They are zero if unknown and are not
+		// compared by Equal.
+		Line   uint
+		Column uint
+
+		// Synthetic tells the call was built, eg.: by a codemod,
+		// instead of parsed, its position is of the code it was
+		// built from. It's not compared by Equal.
+		Synthetic bool
+	}
+
+	// NewExpr constructs an object calling Callee as a
+	// constructor, the arguments are optional.
+	// eg.: new <callee>(<args>)
+	NewExpr struct {
+		Callee Node
+		Args   []Node
+	}
+
+	// AssignExpr assigns Value to Target, which is an identifier
+	// or a member expression. The Operator is token.Assign or a
+	// compound assignment, eg.: token.AddAssign
+	// eg.: <target> = <value>
+	// eg.: <target> += <value>
+	AssignExpr struct {
+		Operator token.Type
+		Target   Node
+		Value    Node
+	}
+
+	// FunExpr is a function expression, its name is optional.
+	// eg.: function (a, b) { ... }
+	FunExpr struct {
+		Name Ident
+		Args []Ident
+		Body *Program
+	}
+
+	// SequenceExpr is a comma separated list of expressions
+	// eg.: (a, b)
+	SequenceExpr struct {
+		Exprs []Node
 	}
 
 	// FunDecl is the syntatic function declaration
@@ -80,13 +152,21 @@ const (
 	exprBegin
 
 	NodeNumber
+	NodeBigInt
 	NodeString
 	NodeNull
+	NodeThis
 	NodeUndefined
 	NodeBool
 	NodeUnaryExpr
+	NodeBinaryExpr
 	NodeMemberExpr
+	NodeIndexExpr
 	NodeCallExpr
+	NodeNewExpr
+	NodeSequenceExpr
+	NodeAssignExpr
+	NodeFunExpr
 	NodeIdent
 
 	exprEnd
@@ -95,20 +175,28 @@ const (
 )
 
 var nodeTypesNames = [...]string{
-	NodeProgram:    "PROGRAM",
-	NodeFunDecl:    "FUNDECL",
-	NodeVarDecl:    "VARDECL",
-	NodeVarDecls:   "VARDECLS",
-	NodeNumber:     "NUMBER",
-	NodeString:     "STRING",
-	NodeBool:       "BOOLEAN",
-	NodeUndefined:  "UNDEFINED",
-	NodeNull:       "NULL",
-	NodeUnaryExpr:  "UNARYEXPR",
-	NodeMemberExpr: "MEMBEREXPR",
-	NodeCallExpr:   "CALLEXPR",
-	NodeIdent:      "IDENT",
-	exprEnd:        "",
+	NodeProgram:      "PROGRAM",
+	NodeFunDecl:      "FUNDECL",
+	NodeVarDecl:      "VARDECL",
+	NodeVarDecls:     "VARDECLS",
+	NodeNumber:       "NUMBER",
+	NodeBigInt:       "BIGINT",
+	NodeString:       "STRING",
+	NodeBool:         "BOOLEAN",
+	NodeUndefined:    "UNDEFINED",
+	NodeNull:         "NULL",
+	NodeThis:         "THIS",
+	NodeUnaryExpr:    "UNARYEXPR",
+	NodeBinaryExpr:   "BINARYEXPR",
+	NodeMemberExpr:   "MEMBEREXPR",
+	NodeIndexExpr:    "INDEXEXPR",
+	NodeCallExpr:     "CALLEXPR",
+	NodeNewExpr:      "NEWEXPR",
+	NodeSequenceExpr: "SEQUENCEEXPR",
+	NodeAssignExpr:   "ASSIGNEXPR",
+	NodeFunExpr:      "FUNEXPR",
+	NodeIdent:        "IDENT",
+	exprEnd:          "",
 }
 
 // console.log(Number.EPSILON);
@@ -236,6 +324,23 @@ func (Null) String() string {
 	return "null"
 }
 
+func NewThis() This {
+	return This{}
+}
+
+func (This) Equal(other Node) bool {
+	_, ok := other.(This)
+	return ok
+}
+
+func (This) Type() NodeType {
+	return NodeThis
+}
+
+func (This) String() string {
+	return "this"
+}
+
 func NewNumber(a float64) Number {
 	return Number(a)
 }
@@ -265,6 +370,31 @@ func (a Number) Equal(other Node) bool {
 	return floatEquals(float64(a), float64(o))
 }
 
+// NewBigInt creates a BigInt literal of a copy of v.
+func NewBigInt(v *big.Int) BigInt {
+	return BigInt{value: new(big.Int).Set(v)}
+}
+
+// Value returns a copy of the integer.
+func (a BigInt) Value() *big.Int { return new(big.Int).Set(a.value) }
+
+func (BigInt) Type() NodeType {
+	return NodeBigInt
+}
+
+func (a BigInt) String() string {
+	return a.value.String() + "n"
+}
+
+func (a BigInt) Equal(other Node) bool {
+	o, ok := other.(BigInt)
+	if !ok {
+		return false
+	}
+
+	return a.value.Cmp(o.value) == 0
+}
+
 func NewUnaryExpr(operator token.Type, operand Node) *UnaryExpr {
 	return &UnaryExpr{
 		Operator: operator,
@@ -293,6 +423,35 @@ func (a *UnaryExpr) Equal(other Node) bool {
 	return a.Operand.Equal(o.Operand)
 }
 
+func NewBinaryExpr(operator token.Type, left, right Node) *BinaryExpr {
+	return &BinaryExpr{
+		Operator: operator,
+		Left:     left,
+		Right:    right,
+	}
+}
+
+func (_ *BinaryExpr) Type() NodeType {
+	return NodeBinaryExpr
+}
+
+func (b *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Left, b.Operator, b.Right)
+}
+
+func (b *BinaryExpr) Equal(other Node) bool {
+	if other.Type() != b.Type() {
+		return false
+	}
+
+	o := other.(*BinaryExpr)
+	if b.Operator != o.Operator {
+		return false
+	}
+
+	return b.Left.Equal(o.Left) && b.Right.Equal(o.Right)
+}
+
 func NewIdent(ident utf16.Str) Ident {
 	return Ident(ident)
 }
@@ -348,6 +507,31 @@ func (m *MemberExpr) Equal(other Node) bool {
 		m.Property.Equal(o.Property)
 }
 
+func NewIndexExpr(object Node, index Node) *IndexExpr {
+	return &IndexExpr{
+		Object: object,
+		Index:  index,
+	}
+}
+
+func (i *IndexExpr) Type() NodeType { return NodeIndexExpr }
+func (i *IndexExpr) String() string {
+	if str, ok := i.Index.(String); ok {
+		return fmt.Sprintf("%s[%q]", i.Object, str)
+	}
+	return fmt.Sprintf("%s[%s]", i.Object, i.Index)
+}
+
+func (i *IndexExpr) Equal(other Node) bool {
+	if i.Type() != other.Type() {
+		return false
+	}
+
+	o := other.(*IndexExpr)
+	return i.Object.Equal(o.Object) &&
+		i.Index.Equal(o.Index)
+}
+
 func NewVarDecl(name Ident, val Node) VarDecl {
 	return VarDecl{
 		Name:  name,
@@ -436,6 +620,152 @@ func (c *CallExpr) Equal(other Node) bool {
 	return c.Callee.Equal(o.Callee)
 }
 
+// NewNewExpr creates a new expression.
+func NewNewExpr(callee Node, args []Node) *NewExpr {
+	return &NewExpr{
+		Callee: callee,
+		Args:   args,
+	}
+}
+
+func (n *NewExpr) Type() NodeType { return NodeNewExpr }
+func (n *NewExpr) String() string {
+	return fmt.Sprintf("new %s(<args>)", n.Callee)
+}
+
+func (n *NewExpr) Equal(other Node) bool {
+	if other.Type() != n.Type() {
+		return false
+	}
+
+	o := other.(*NewExpr)
+
+	if len(n.Args) != len(o.Args) {
+		return false
+	}
+
+	for i := 0; i < len(n.Args); i++ {
+		if !n.Args[i].Equal(o.Args[i]) {
+			return false
+		}
+	}
+
+	return n.Callee.Equal(o.Callee)
+}
+
+// NewSequenceExpr creates a new sequence of expressions.
+func NewSequenceExpr(exprs ...Node) *SequenceExpr {
+	return &SequenceExpr{
+		Exprs: exprs,
+	}
+}
+
+func (s *SequenceExpr) Type() NodeType { return NodeSequenceExpr }
+func (s *SequenceExpr) String() string {
+	var exprs []string
+	for _, expr := range s.Exprs {
+		exprs = append(exprs, expr.String())
+	}
+	return fmt.Sprintf("(%s)", strings.Join(exprs, ", "))
+}
+
+func (s *SequenceExpr) Equal(other Node) bool {
+	if other.Type() != s.Type() {
+		return false
+	}
+
+	o := other.(*SequenceExpr)
+
+	if len(s.Exprs) != len(o.Exprs) {
+		return false
+	}
+
+	for i := 0; i < len(s.Exprs); i++ {
+		if !s.Exprs[i].Equal(o.Exprs[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewAssignExpr creates a new assignment expression.
+func NewAssignExpr(target Node, value Node) *AssignExpr {
+	return NewCompoundAssignExpr(token.Assign, target, value)
+}
+
+// NewCompoundAssignExpr creates an assignment expression with the
+// operator, eg.: token.AddAssign for <target> += <value>
+func NewCompoundAssignExpr(operator token.Type, target Node, value Node) *AssignExpr {
+	return &AssignExpr{
+		Operator: operator,
+		Target:   target,
+		Value:    value,
+	}
+}
+
+func (a *AssignExpr) Type() NodeType { return NodeAssignExpr }
+func (a *AssignExpr) String() string {
+	return fmt.Sprintf("%s %s %s", a.Target, a.Operator, a.Value)
+}
+
+func (a *AssignExpr) Equal(other Node) bool {
+	if other.Type() != a.Type() {
+		return false
+	}
+
+	o := other.(*AssignExpr)
+	if a.Operator != o.Operator {
+		return false
+	}
+
+	return a.Target.Equal(o.Target) && a.Value.Equal(o.Value)
+}
+
+// NewFunExpr creates a new function expression node.
+func NewFunExpr(name Ident, args []Ident, body *Program) *FunExpr {
+	return &FunExpr{
+		Name: name,
+		Args: args,
+		Body: body,
+	}
+}
+
+func (f *FunExpr) Type() NodeType { return NodeFunExpr }
+func (f *FunExpr) String() string {
+	var args []string
+
+	for _, arg := range f.Args {
+		args = append(args, arg.String())
+	}
+
+	return fmt.Sprintf("function %s(%s) {\n%s\n}",
+		f.Name,
+		strings.Join(args, ", "),
+		f.Body.String(),
+	)
+}
+
+func (f *FunExpr) Equal(other Node) bool {
+	if other.Type() != NodeFunExpr {
+		return false
+	}
+
+	o := other.(*FunExpr)
+
+	if len(f.Args) != len(o.Args) {
+		return false
+	}
+
+	for i := 0; i < len(f.Args); i++ {
+		if !f.Args[i].Equal(o.Args[i]) {
+			return false
+		}
+	}
+
+	return f.Name.Equal(o.Name) && f.Body.Equal(o.Body)
+}
+
 // NewFunDecl creates a new function declaration node.
 func NewFunDecl(name Ident, args []Ident, body *Program) *FunDecl {
 	return &FunDecl{
@@ -485,5 +815,6 @@ func (a *FunDecl) Equal(other Node) bool {
 }
 
 func floatEquals(a, b float64) bool {
-	return math.Abs(a-b) < ε && math.Abs(b-a) < ε
+	// WHY: infinities are equal, but their difference is NaN
+	return a == b || math.Abs(a-b) < ε && math.Abs(b-a) < ε
 }
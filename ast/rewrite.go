@@ -0,0 +1,103 @@
+package ast
+
+// Rewrite transforms the tree of node bottom-up: the children of each
+// node are rewritten first, then fn is called with the node and its
+// result replaces the node. Returning the node given keeps it.
+//
+// The tree of node is not modified, the rewritten nodes are copies,
+// so it can be shared with other rewrites. The position of a call
+// replaced by a call without position, eg.: created by NewCallExpr,
+// is kept.
+//
+// Only expressions and statements are rewritten: fn is not called
+// with the names of functions, arguments, properties and variables,
+// nor with function bodies, whose statements are rewritten.
+func Rewrite(node Node, fn func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+
+	return keepPosition(fn(rewriteChildren(node, fn)), node)
+}
+
+// rewriteChildren copies node with its children rewritten.
+func rewriteChildren(node Node, fn func(Node) Node) Node {
+	switch n := node.(type) {
+	case *Program:
+		return &Program{Nodes: rewriteNodes(n.Nodes, fn)}
+	case *UnaryExpr:
+		return NewUnaryExpr(n.Operator, Rewrite(n.Operand, fn))
+	case *BinaryExpr:
+		return NewBinaryExpr(n.Operator,
+			Rewrite(n.Left, fn), Rewrite(n.Right, fn))
+	case *MemberExpr:
+		return NewMemberExpr(Rewrite(n.Object, fn), n.Property)
+	case *IndexExpr:
+		return NewIndexExpr(Rewrite(n.Object, fn), Rewrite(n.Index, fn))
+	case *CallExpr:
+		call := NewCallExpr(Rewrite(n.Callee, fn), rewriteNodes(n.Args, fn))
+		call.Line, call.Column = n.Line, n.Column
+		call.Synthetic = n.Synthetic
+		return call
+	case *NewExpr:
+		return NewNewExpr(Rewrite(n.Callee, fn), rewriteNodes(n.Args, fn))
+	case *SequenceExpr:
+		return NewSequenceExpr(rewriteNodes(n.Exprs, fn)...)
+	case *AssignExpr:
+		return NewCompoundAssignExpr(n.Operator, Rewrite(n.Target, fn), Rewrite(n.Value, fn))
+	case *FunExpr:
+		return NewFunExpr(n.Name, n.Args, rewriteBody(n.Body, fn))
+	case *FunDecl:
+		return NewFunDecl(n.Name, n.Args, rewriteBody(n.Body, fn))
+	case VarDecl:
+		return NewVarDecl(n.Name, Rewrite(n.Value, fn))
+	case VarDecls:
+		decls := make(VarDecls, len(n))
+		for i, decl := range n {
+			decls[i] = NewVarDecl(decl.Name, Rewrite(decl.Value, fn))
+		}
+		return decls
+	}
+
+	// literals and identifiers have no children
+	return node
+}
+
+func rewriteNodes(nodes []Node, fn func(Node) Node) []Node {
+	if nodes == nil {
+		return nil
+	}
+
+	res := make([]Node, len(nodes))
+	for i, node := range nodes {
+		res[i] = Rewrite(node, fn)
+	}
+
+	return res
+}
+
+func rewriteBody(body *Program, fn func(Node) Node) *Program {
+	if body == nil {
+		return nil
+	}
+
+	return &Program{Nodes: rewriteNodes(body.Nodes, fn)}
+}
+
+// keepPosition gives the position of the old call to the new call
+// replacing it, if it has no position.
+func keepPosition(newnode, old Node) Node {
+	oldcall, ok := old.(*CallExpr)
+	if !ok || oldcall.Line == 0 {
+		return newnode
+	}
+
+	newcall, ok := newnode.(*CallExpr)
+	if !ok || newcall.Line != 0 {
+		return newnode
+	}
+
+	call := *newcall
+	call.Line, call.Column = oldcall.Line, oldcall.Column
+	return &call
+}
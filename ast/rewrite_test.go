@@ -0,0 +1,99 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+// rename renames the identifier a to b.
+func rename(node ast.Node) ast.Node {
+	if ident, ok := node.(ast.Ident); ok && ident.String() == "a" {
+		return ast.NewIdent(utf16.S("b"))
+	}
+
+	return node
+}
+
+func TestRewrite(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "Ident",
+			code: "a",
+			want: "b",
+		},
+		{
+			name: "Unary",
+			code: "-a",
+			want: "-b",
+		},
+		{
+			name: "Member",
+			code: "a.a = a[a]",
+			want: "b.a = b[b]",
+		},
+		{
+			name: "Call",
+			code: "a(a, c, new a(a))",
+			want: "b(b, c, new b(b))",
+		},
+		{
+			name: "Function",
+			code: "function a(a) { a = \"a\" in a }",
+			want: "function a(a) { b = \"a\" in b }",
+		},
+		{
+			name: "FunctionExpr",
+			code: "f = function (a) { c = a }",
+			want: "f = function (a) { c = b }",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			program := parse(t, tc.code)
+			original := parse(t, tc.code)
+			want := parse(t, tc.want)
+
+			got := ast.Rewrite(program, rename)
+			if !got.Equal(want) {
+				t.Fatalf("got %s, want %s", got, want)
+			}
+
+			if !program.Equal(original) {
+				t.Fatalf("rewrite modified the tree: %s", program)
+			}
+		})
+	}
+}
+
+func TestRewriteKeepsPosition(t *testing.T) {
+	program := parse(t, "a = 1;\nf(a)")
+
+	got := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if call, ok := node.(*ast.CallExpr); ok {
+			return ast.NewCallExpr(ast.NewIdent(utf16.S("g")), call.Args)
+		}
+		return node
+	}).(*ast.Program)
+
+	call := got.Nodes[1].(*ast.CallExpr)
+	assert.EqualStrings(t, "g(<args>)", call.String(), "call")
+
+	if call.Line != 2 || call.Column != 2 {
+		t.Fatalf("got call at %d:%d, want 2:2", call.Line, call.Column)
+	}
+}
+
+func parse(t *testing.T, code string) *ast.Program {
+	t.Helper()
+
+	program, err := parser.Parse("test.js", code)
+	assert.NoError(t, err, "parsing %s", code)
+	return program
+}
@@ -0,0 +1,163 @@
+package builtins
+
+import (
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// Array is the Array builtin object. Arrays are not implemented
+	// yet, so its functions return growable slices.
+	// https://es5.github.io/#x15.4
+	Array struct {
+		*types.DataObject
+
+		step Step
+	}
+)
+
+var (
+	fromAttr   = utf16.S("from")
+	ofAttr     = utf16.S("of")
+	lengthAttr = utf16.S("length")
+)
+
+// NewArray creates the Array object, its functions call step on every
+// element.
+func NewArray(step Step) (*Array, error) {
+	array := &Array{
+		DataObject: types.NewBaseDataObject(),
+		step:       step,
+	}
+
+	err := array.DefineBuiltin(fromAttr, types.NewBuiltinfn(array.from))
+	if err != nil {
+		return nil, err
+	}
+
+	err = array.DefineBuiltin(ofAttr, types.NewBuiltinfn(arrayOf))
+	if err != nil {
+		return nil, err
+	}
+
+	toStrfn := types.NewBuiltinfn(
+		toStringer("function Array() { [native code] }"),
+	)
+	err = array.DefineBuiltin(toStringAttr, toStrfn)
+	return array, err
+}
+
+// from creates a slice with the values of an iterable or with
+// the elements of an array-like object, mapped by the optional mapFn.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-array.from
+func (array *Array) from(_ types.Object, args []types.Value) (types.Value, error) {
+	items, mapfn := argAt(args, 0), argAt(args, 1)
+
+	var mapper types.Function
+	if mapfn.Kind() != types.KindUndefined {
+		fn, ok := mapfn.(types.Function)
+		if !ok {
+			return nil, types.NewTypeError("Array.from: %s is not a function", mapfn.ToString())
+		}
+		mapper = fn
+	}
+
+	var values []types.Value
+	add := func(v types.Value) error {
+		if mapper != nil {
+			index := types.NewNumber(float64(len(values)))
+			mapped, err := mapper.Call(nil, []types.Value{v, index})
+			if err != nil {
+				return err
+			}
+			v = mapped
+		}
+
+		values = append(values, v)
+		return nil
+	}
+
+	obj, err := items.ToObject()
+	if err != nil {
+		return nil, err
+	}
+
+	iterable, err := isIterable(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if iterable {
+		err = types.Iterate(items, func(v types.Value) error {
+			if err := array.step(); err != nil {
+				return err
+			}
+			return add(v)
+		})
+	} else {
+		err = forEachIndex(obj, array.step, add)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewSlice(values, types.SliceGrowable), nil
+}
+
+// arrayOf creates a slice with its arguments.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-array.of
+func arrayOf(_ types.Object, args []types.Value) (types.Value, error) {
+	values := make([]types.Value, len(args))
+	copy(values, args)
+	return types.NewSlice(values, types.SliceGrowable), nil
+}
+
+func isIterable(obj types.Object) (bool, error) {
+	if _, ok := obj.(*types.Slice); ok {
+		return true, nil
+	}
+
+	method, err := obj.Get(types.IteratorAttr)
+	if err != nil {
+		return false, err
+	}
+
+	return method.Kind() != types.KindUndefined, nil
+}
+
+// forEachIndex calls fn with the elements of the array-like obj,
+// from 0 to its length. The length is given by the script, up to
+// 2^32-1, so step is called before reading every element.
+func forEachIndex(obj types.Object, step Step, fn func(types.Value) error) error {
+	lenval, err := obj.Get(lengthAttr)
+	if err != nil {
+		return err
+	}
+
+	length := lenval.ToNumber().ToUint32()
+	for i := uint32(0); i < length; i++ {
+		if err := step(); err != nil {
+			return err
+		}
+
+		val, err := obj.Get(utf16.Str(types.NewNumber(float64(i)).ToString()))
+		if err != nil {
+			return err
+		}
+
+		if err := fn(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func argAt(args []types.Value, i int) types.Value {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return types.Undefined
+}
@@ -0,0 +1,177 @@
+package builtins_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NeowayLabs/abad/builtins"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestArrayOf(t *testing.T) {
+	array := newArray(t)
+
+	assertSlice(t, callMethod(t, array, "of"))
+	assertSlice(t, callMethod(t, array, "of", types.NewNumber(1), str("a")),
+		types.NewNumber(1), str("a"))
+}
+
+func TestArrayOfGrows(t *testing.T) {
+	array := newArray(t)
+
+	got := callMethod(t, array, "of", types.NewNumber(1))
+	put(t, got.(types.Object), "1", types.NewNumber(2))
+	assertSlice(t, got, types.NewNumber(1), types.NewNumber(2))
+}
+
+func TestArrayFrom(t *testing.T) {
+	array := newArray(t)
+
+	slice := types.NewSlice([]types.Value{str("a"), str("b")}, types.SliceShared)
+	assertSlice(t, callMethod(t, array, "from", slice), str("a"), str("b"))
+
+	assertSlice(t, callMethod(t, array, "from", str("ab")), str("a"), str("b"))
+
+	arrayLike := types.NewBaseDataObject()
+	put(t, arrayLike, "length", types.NewNumber(3))
+	put(t, arrayLike, "0", str("a"))
+	put(t, arrayLike, "2", str("c"))
+	assertSlice(t, callMethod(t, array, "from", arrayLike),
+		str("a"), types.Undefined, str("c"))
+
+	iterator := types.NewBaseDataObject()
+	put(t, iterator, "next", newCounterNext(t, 2))
+
+	iterable := types.NewBaseDataObject()
+	put(t, iterable, "@@iterator", types.NewBuiltinfn(
+		func(types.Object, []types.Value) (types.Value, error) {
+			return iterator, nil
+		}))
+	assertSlice(t, callMethod(t, array, "from", iterable),
+		types.NewNumber(0), types.NewNumber(1))
+}
+
+func TestArrayFromMap(t *testing.T) {
+	array := newArray(t)
+
+	var indexes []types.Value
+	mapfn := types.NewBuiltinfn(func(_ types.Object, args []types.Value) (types.Value, error) {
+		indexes = append(indexes, args[1])
+		return str(args[0].ToString().String() + "!"), nil
+	})
+
+	got := callMethod(t, array, "from", str("ab"), mapfn)
+	assertSlice(t, got, str("a!"), str("b!"))
+	assertValues(t, indexes, types.NewNumber(0), types.NewNumber(1))
+}
+
+func TestArrayFromErrors(t *testing.T) {
+	array := newArray(t)
+
+	method, err := array.Get(utf16.S("from"))
+	assert.NoError(t, err, "getting from")
+	from := method.(types.Function)
+
+	for name, args := range map[string][]types.Value{
+		"NoArgs":     nil,
+		"Undefined":  {types.Undefined},
+		"Null":       {types.Null},
+		"NoNext":     {iterableWithoutNext(t)},
+		"MapNotFunc": {str("ab"), str("f")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := from.Call(array, args)
+			if _, ok := err.(types.TypeError); !ok {
+				t.Fatalf("got error %v, want a TypeError", err)
+			}
+		})
+	}
+}
+
+func TestArrayFromStep(t *testing.T) {
+	stop := errors.New("stop")
+	steps := 0
+	array, err := builtins.NewArray(func() error {
+		steps++
+		if steps == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.NoError(t, err, "array creation")
+
+	method, err := array.Get(utf16.S("from"))
+	assert.NoError(t, err, "getting from")
+
+	_, err = method.(types.Function).Call(array, []types.Value{str("abc")})
+	assert.EqualErrs(t, stop, err, "error of the step")
+	assert.EqualInts(t, 2, steps, "steps")
+}
+
+// iterableWithoutNext returns an iterable whose iterator has no next.
+func iterableWithoutNext(t *testing.T) *types.DataObject {
+	iterable := types.NewBaseDataObject()
+	put(t, iterable, "@@iterator", types.NewBuiltinfn(
+		func(types.Object, []types.Value) (types.Value, error) {
+			return types.NewBaseDataObject(), nil
+		}))
+	return iterable
+}
+
+// newCounterNext returns a next method iterating from 0 to n-1.
+func newCounterNext(t *testing.T, n int) types.Value {
+	i := 0
+	return types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		res := types.NewBaseDataObject()
+		put(t, res, "done", types.NewBool(i >= n))
+		put(t, res, "value", types.NewNumber(float64(i)))
+		i++
+		return res, nil
+	})
+}
+
+func newArray(t *testing.T) *builtins.Array {
+	array, err := builtins.NewArray(noStep)
+	assert.NoError(t, err, "array creation")
+	assert.EqualStrings(t, "function Array() { [native code] }", array.String(), "array toString")
+	return array
+}
+
+// noStep is the step of builtins that are never stopped.
+func noStep() error { return nil }
+
+func put(t *testing.T, obj types.Object, name string, val types.Value) {
+	t.Helper()
+
+	err := obj.Put(utf16.S(name), val, true)
+	assert.NoError(t, err, "putting %s", name)
+}
+
+func assertSlice(t *testing.T, got types.Value, want ...types.Value) {
+	t.Helper()
+
+	slice, ok := got.(*types.Slice)
+	if !ok {
+		t.Fatalf("got %v, want a slice", got)
+	}
+
+	assertValues(t, slice.Values(), want...)
+}
+
+func assertValues(t *testing.T, got []types.Value, want ...types.Value) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if !types.StrictEqual(got[i], want[i]) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+var str = types.NewString
@@ -2,67 +2,122 @@ package builtins
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
+	// Console is the console object, it writes on its output,
+	// usually the standard output. Groups indent the output that
+	// follows them.
 	Console struct {
 		*types.DataObject
+
+		out    io.Writer
+		format func(types.Value) string
+		now    func() time.Time
+
+		// depth of the current group
+		depth  int
+		counts map[string]int
+		timers map[string]time.Time
 	}
 )
 
 var (
-	logAttr      = utf16.S("log")
-	toStringAttr = utf16.S("toString")
+	logAttr            = utf16.S("log")
+	groupAttr          = utf16.S("group")
+	groupCollapsedAttr = utf16.S("groupCollapsed")
+	groupEndAttr       = utf16.S("groupEnd")
+	countAttr          = utf16.S("count")
+	countResetAttr     = utf16.S("countReset")
+	timeAttr           = utf16.S("time")
+	timeEndAttr        = utf16.S("timeEnd")
+	toStringAttr       = utf16.S("toString")
 )
 
-func NewConsole() (*Console, error) {
+// groupIndent is the indentation of each group level.
+const groupIndent = "  "
+
+// NewConsole creates the console object writing on out. The format
+// function converts the arguments of console.log to text. The now
+// function is the clock of console.time, whose times are monotonic
+// if now is time.Now. If now is nil the console has no timers.
+func NewConsole(out io.Writer, format func(types.Value) string, now func() time.Time) (*Console, error) {
 	console := &Console{
 		DataObject: types.NewBaseDataObject(),
+		out:        out,
+		format:     format,
+		now:        now,
+		counts:     map[string]int{},
+		timers:     map[string]time.Time{},
+	}
+
+	type method struct {
+		name utf16.Str
+		fn   types.Execfn
 	}
 
-	logfn, err := newlog()
-	if err != nil {
-		return nil, err
+	methods := []method{
+		{logAttr, console.log},
+		{groupAttr, console.group},
+		{groupCollapsedAttr, console.group},
+		{groupEndAttr, console.groupEnd},
+		{countAttr, console.count},
+		{countResetAttr, console.countReset},
 	}
 
-	err = console.Put(logAttr, logfn, true)
-	if err != nil {
-		return nil, err
+	if now != nil {
+		methods = append(methods,
+			method{timeAttr, console.time},
+			method{timeEndAttr, console.timeEnd})
+	}
+
+	for _, m := range methods {
+		fn, err := newConsoleMethod(m.fn)
+		if err != nil {
+			return nil, err
+		}
+
+		err = console.DefineBuiltin(m.name, fn)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	toStrfn := types.NewBuiltinfn(
 		toStringer("[object Object]"),
 	)
 
-	// TODO: handle error
-	console.Put(toStringAttr, toStrfn, true)
-	return console, nil
+	err := console.DefineBuiltin(toStringAttr, toStrfn)
+	return console, err
 }
 
-func newlog() (*types.Builtinfn, error) {
-	logfn := types.NewBuiltinfn(log)
+func newConsoleMethod(method types.Execfn) (*types.Builtinfn, error) {
+	fn := types.NewBuiltinfn(method)
 	toStrfn := types.NewBuiltinfn(
 		toStringer("function () { [native code] }"),
 	)
-	err := logfn.Put(toStringAttr, toStrfn, true)
-	return logfn, err
+	err := fn.DefineBuiltin(toStringAttr, toStrfn)
+	return fn, err
 }
 
-func log(_ types.Object, args []types.Value) types.Value {
+// log writes the arguments on the output, failing
+// if they can't be written.
+func (c *Console) log(_ types.Object, args []types.Value) (types.Value, error) {
 	// This will not handle errors in formatting properly
 	// But it will work for well formatted messages
 	if len(args) == 0 {
-		fmt.Println("")
-		return types.Undefined
+		return types.Undefined, c.println("")
 	}
 
 	vals := []string{}
 	for _, v := range args {
-		vals = append(vals, v.ToString().String())
+		vals = append(vals, c.format(v))
 	}
 	msg := ""
 	if hasFormatting(vals[0]) {
@@ -70,8 +125,97 @@ func log(_ types.Object, args []types.Value) types.Value {
 	} else {
 		msg = strings.Join(vals, " ")
 	}
-	fmt.Println(msg)
-	return types.Undefined
+	return types.Undefined, c.println(msg)
+}
+
+// group logs its arguments, if any, and indents the output until
+// the matching groupEnd. Collapsed groups are the same, there is
+// nothing to collapse on a terminal.
+// https://console.spec.whatwg.org/#group
+func (c *Console) group(this types.Object, args []types.Value) (types.Value, error) {
+	if len(args) > 0 {
+		_, err := c.log(this, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.depth++
+	return types.Undefined, nil
+}
+
+// https://console.spec.whatwg.org/#groupend
+func (c *Console) groupEnd(types.Object, []types.Value) (types.Value, error) {
+	if c.depth > 0 {
+		c.depth--
+	}
+
+	return types.Undefined, nil
+}
+
+// count logs how many times it was called with the label.
+// https://console.spec.whatwg.org/#count
+func (c *Console) count(_ types.Object, args []types.Value) (types.Value, error) {
+	label := consoleLabel(args)
+	c.counts[label]++
+	return types.Undefined, c.println(fmt.Sprintf("%s: %d", label, c.counts[label]))
+}
+
+// https://console.spec.whatwg.org/#countreset
+func (c *Console) countReset(_ types.Object, args []types.Value) (types.Value, error) {
+	delete(c.counts, consoleLabel(args))
+	return types.Undefined, nil
+}
+
+// time starts the timer of the label, unless it's already started.
+// https://console.spec.whatwg.org/#time
+func (c *Console) time(_ types.Object, args []types.Value) (types.Value, error) {
+	label := consoleLabel(args)
+	if _, ok := c.timers[label]; !ok {
+		c.timers[label] = c.now()
+	}
+
+	return types.Undefined, nil
+}
+
+// timeEnd logs the elapsed time of the timer of the label, in
+// milliseconds, and stops it.
+// https://console.spec.whatwg.org/#timeend
+func (c *Console) timeEnd(_ types.Object, args []types.Value) (types.Value, error) {
+	label := consoleLabel(args)
+	start, ok := c.timers[label]
+	if !ok {
+		return types.Undefined, nil
+	}
+
+	delete(c.timers, label)
+
+	elapsed := c.now().Sub(start)
+	ms := float64(elapsed) / float64(time.Millisecond)
+	return types.Undefined, c.println(fmt.Sprintf("%s: %.3fms", label, ms))
+}
+
+// println writes msg on the output, each of its lines
+// indented by the current group.
+func (c *Console) println(msg string) error {
+	if c.depth > 0 {
+		indent := strings.Repeat(groupIndent, c.depth)
+		msg = indent + strings.Replace(msg, "\n", "\n"+indent, -1)
+	}
+
+	_, err := fmt.Fprintln(c.out, msg)
+	return err
+}
+
+// consoleLabel is the label argument of count and time, it's
+// "default" if not given.
+func consoleLabel(args []types.Value) string {
+	label := argAt(args, 0)
+	if label.Kind() == types.KindUndefined {
+		return "default"
+	}
+
+	return label.ToString().String()
 }
 
 func sprintf(vals []string) string {
@@ -98,7 +242,7 @@ func hasFormatting(a string) bool {
 }
 
 func toStringer(str string) types.Execfn {
-	return func(_ types.Object, args []types.Value) types.Value {
-		return types.NewString(str)
+	return func(_ types.Object, args []types.Value) (types.Value, error) {
+		return types.NewString(str), nil
 	}
 }
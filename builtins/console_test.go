@@ -1,16 +1,20 @@
 package builtins_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/NeowayLabs/abad/builtins"
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 	"github.com/madlambda/spells/assert"
 )
 
 func TestConsoleToString(t *testing.T) {
-	console, err := builtins.NewConsole()
+	var out bytes.Buffer
+	console, err := builtins.NewConsole(&out, func(v types.Value) string {
+		return v.ToString().String()
+	}, nil)
 	assert.NoError(t, err, "console creation")
 	assert.EqualStrings(t, console.String(),
 		"[object Object]", "console toString")
@@ -23,9 +27,7 @@ func TestConsoleToString(t *testing.T) {
 		t.Fatalf("log is not a function")
 	}
 
-	call(logfn)
-}
-
-func call(fn types.Function) {
-	fn.Call(nil, nil)
+	_, err = logfn.Call(nil, []types.Value{types.NewString("a"), types.NewNumber(1)})
+	assert.NoError(t, err, "calling log")
+	assert.EqualStrings(t, "a 1\n", out.String(), "console output")
 }
@@ -0,0 +1,321 @@
+package builtins
+
+import (
+	"math"
+	"time"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// Date is the Date builtin object.
+	// https://es5.github.io/#x15.9
+	Date struct {
+		*types.DataObject
+
+		now   func() time.Time
+		loc   *time.Location
+		proto *types.DataObject
+	}
+
+	// DateObject is a date created by the Date constructor, it holds
+	// a time value in milliseconds since the epoch, or NaN for an
+	// invalid date.
+	// https://es5.github.io/#x15.9.1.1
+	DateObject struct {
+		*types.DataObject
+
+		tv  float64
+		loc *time.Location
+	}
+
+	// dateMethod is a method of the Date prototype, it gets the
+	// date object it was called on.
+	dateMethod func(d *DateObject, args []types.Value) (types.Value, error)
+)
+
+// maxTime is the biggest time value, in milliseconds, a date
+// can hold.
+// https://es5.github.io/#x15.9.1.1
+const maxTime = 8.64e15
+
+const dateLayout = "Mon Jan 02 2006 15:04:05 GMT-0700 (MST)"
+
+var (
+	nowAttr = utf16.S("now")
+
+	// getters of the local and the UTC date components, the
+	// setters are not supported yet.
+	dateGetters = map[string]func(t time.Time) int{
+		"FullYear":     func(t time.Time) int { return t.Year() },
+		"Month":        func(t time.Time) int { return int(t.Month()) - 1 },
+		"Date":         func(t time.Time) int { return t.Day() },
+		"Day":          func(t time.Time) int { return int(t.Weekday()) },
+		"Hours":        func(t time.Time) int { return t.Hour() },
+		"Minutes":      func(t time.Time) int { return t.Minute() },
+		"Seconds":      func(t time.Time) int { return t.Second() },
+		"Milliseconds": func(t time.Time) int { return t.Nanosecond() / int(time.Millisecond) },
+	}
+)
+
+// NewDate creates the Date object. The now function is the
+// source of the current time used by Date.now and new Date(), and
+// loc is the local timezone of the dates.
+func NewDate(now func() time.Time, loc *time.Location) (*Date, error) {
+	date := &Date{
+		DataObject: types.NewBaseDataObject(),
+		now:        now,
+		loc:        loc,
+	}
+
+	nowfn := types.NewBuiltinfnArgs(func(_ types.Object, _ []types.Value) (types.Value, error) {
+		return types.NewNumber(date.timeNow()), nil
+	})
+
+	err := date.DefineBuiltin(nowAttr, nowfn)
+	if err != nil {
+		return nil, err
+	}
+
+	date.proto, err = newDatePrototype()
+	if err != nil {
+		return nil, err
+	}
+
+	toStrfn := types.NewBuiltinfn(
+		toStringer("function Date() { [native code] }"),
+	)
+	err = date.DefineBuiltin(toStringAttr, toStrfn)
+	return date, err
+}
+
+// Construct creates a date object. Without arguments it's the
+// current time, with one argument it's a time value or a date
+// string and with two or more it's the year, month, day, hours,
+// minutes, seconds and milliseconds in the local timezone.
+// https://es5.github.io/#x15.9.3
+func (d *Date) Construct(args []types.Value) (types.Value, error) {
+	var tv float64
+
+	switch len(args) {
+	case 0:
+		tv = d.timeNow()
+	case 1:
+		v, err := args[0].ToPrimitive(types.KindNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		if str, ok := v.(types.String); ok {
+			tv = parseDate(str.String())
+		} else {
+			tv = timeClip(v.ToNumber().Value())
+		}
+	default:
+		tv = timeClip(makeTime(args, d.loc))
+	}
+
+	return d.newDateObject(tv), nil
+}
+
+func (d *Date) newDateObject(tv float64) *DateObject {
+	return &DateObject{
+		DataObject: types.NewDataObject(d.proto),
+		tv:         tv,
+		loc:        d.loc,
+	}
+}
+
+func (d *Date) timeNow() float64 {
+	return float64(d.now().UnixNano() / int64(time.Millisecond))
+}
+
+// ToObject returns itself.
+func (d *DateObject) ToObject() (types.Object, error) {
+	return d, nil
+}
+
+// ToPrimitive converts the date with its valueOf and toString
+// methods, toString first unless hint is a number: without a hint,
+// eg.: date + 1, dates convert to strings.
+// https://es5.github.io/#x15.9.6
+func (d *DateObject) ToPrimitive(hint types.Kind) (types.Value, error) {
+	if hint != types.KindNumber {
+		hint = types.KindString
+	}
+
+	return types.OrdinaryToPrimitive(d, hint)
+}
+
+// ToNumber returns the time value of the date.
+func (d *DateObject) ToNumber() types.Number {
+	return types.NewNumber(d.tv)
+}
+
+// ToString returns the date string.
+func (d *DateObject) ToString() types.String {
+	return types.NewString(d.String())
+}
+
+// String formats the date in its local timezone.
+// https://es5.github.io/#x15.9.5.2
+func (d *DateObject) String() string {
+	if math.IsNaN(d.tv) {
+		return "Invalid Date"
+	}
+
+	return d.time(d.loc).Format(dateLayout)
+}
+
+func (d *DateObject) time(loc *time.Location) time.Time {
+	sec := math.Floor(d.tv / 1000)
+	msec := d.tv - sec*1000
+	return time.Unix(int64(sec), int64(msec)*int64(time.Millisecond)).In(loc)
+}
+
+func newDatePrototype() (*types.DataObject, error) {
+	proto := types.NewBaseDataObject()
+
+	methods := map[string]dateMethod{
+		"getTime":           dateGetTime,
+		"valueOf":           dateGetTime,
+		"setTime":           dateSetTime,
+		"getTimezoneOffset": dateGetTimezoneOffset,
+		"toString": func(d *DateObject, _ []types.Value) (types.Value, error) {
+			return d.ToString(), nil
+		},
+	}
+
+	for name, get := range dateGetters {
+		methods["get"+name] = dateGetter(get, false)
+		methods["getUTC"+name] = dateGetter(get, true)
+	}
+
+	for name, method := range methods {
+		params := []types.ArgKind{}
+		if name == "setTime" {
+			params = append(params, types.ArgNumber)
+		}
+
+		fn := types.NewBuiltinfnArgs(dateMethodFn(name, method), params...)
+		err := proto.DefineBuiltin(utf16.S(name), fn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return proto, nil
+}
+
+// dateMethodFn checks that method is called on a date object.
+func dateMethodFn(name string, method dateMethod) types.Execfn {
+	return func(this types.Object, args []types.Value) (types.Value, error) {
+		d, ok := this.(*DateObject)
+		if !ok {
+			return nil, types.NewTypeError("Date.prototype.%s: this is not a Date object", name)
+		}
+
+		return method(d, args)
+	}
+}
+
+// dateGetter returns the date component got by get, from the local
+// time or from the UTC time. Invalid dates have NaN components.
+// https://es5.github.io/#x15.9.5.10
+func dateGetter(get func(t time.Time) int, utc bool) dateMethod {
+	return func(d *DateObject, _ []types.Value) (types.Value, error) {
+		if math.IsNaN(d.tv) {
+			return types.NewNumber(math.NaN()), nil
+		}
+
+		loc := d.loc
+		if utc {
+			loc = time.UTC
+		}
+
+		return types.NewNumber(float64(get(d.time(loc)))), nil
+	}
+}
+
+// https://es5.github.io/#x15.9.5.9
+func dateGetTime(d *DateObject, _ []types.Value) (types.Value, error) {
+	return types.NewNumber(d.tv), nil
+}
+
+// https://es5.github.io/#x15.9.5.27
+func dateSetTime(d *DateObject, args []types.Value) (types.Value, error) {
+	d.tv = timeClip(args[0].ToNumber().Value())
+	return types.NewNumber(d.tv), nil
+}
+
+// dateGetTimezoneOffset returns the difference, in minutes, between
+// the UTC time and the local time of the date.
+// https://es5.github.io/#x15.9.5.26
+func dateGetTimezoneOffset(d *DateObject, _ []types.Value) (types.Value, error) {
+	if math.IsNaN(d.tv) {
+		return types.NewNumber(math.NaN()), nil
+	}
+
+	_, offset := d.time(d.loc).Zone()
+	return types.NewNumber(float64(-offset / 60)), nil
+}
+
+// makeTime converts the year, month, day, hours, minutes, seconds
+// and milliseconds of args to a time value. Missing components are
+// the first day of the month or zero, and years from 0 to 99 are
+// in the 1900s. Out of range components are carried over.
+// https://es5.github.io/#x15.9.3.1
+func makeTime(args []types.Value, loc *time.Location) float64 {
+	fields := []float64{0, 0, 1, 0, 0, 0, 0}
+	for i := 0; i < len(args) && i < len(fields); i++ {
+		n := args[i].ToNumber().Value()
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return math.NaN()
+		}
+
+		fields[i] = math.Trunc(n)
+	}
+
+	if year := fields[0]; year >= 0 && year <= 99 {
+		fields[0] = 1900 + year
+	}
+
+	for _, f := range fields {
+		// beyond this every date is out of range, avoiding
+		// overflows of the time arithmetic.
+		if math.Abs(f) > maxTime {
+			return math.NaN()
+		}
+	}
+
+	ms := fields[6]
+	t := time.Date(int(fields[0]), time.Month(fields[1]+1), int(fields[2]),
+		int(fields[3]), int(fields[4]), int(fields[5]), 0, loc)
+	return float64(t.Unix())*1000 + ms
+}
+
+// parseDate parses the date time string format (RFC 3339) or
+// a date without time, in UTC. Other formats are invalid dates.
+// https://es5.github.io/#x15.9.1.15
+func parseDate(str string) float64 {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		t, err := time.Parse(layout, str)
+		if err == nil {
+			return timeClip(float64(t.UnixNano() / int64(time.Millisecond)))
+		}
+	}
+
+	return math.NaN()
+}
+
+// timeClip truncates the time value tv to milliseconds, it's NaN
+// if tv is out of the range of dates.
+// https://es5.github.io/#x15.9.1.14
+func timeClip(tv float64) float64 {
+	if math.IsNaN(tv) || math.IsInf(tv, 0) || math.Abs(tv) > maxTime {
+		return math.NaN()
+	}
+
+	return math.Trunc(tv)
+}
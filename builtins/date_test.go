@@ -0,0 +1,192 @@
+package builtins_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/NeowayLabs/abad/builtins"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+// brt is 3 hours behind UTC, without daylight saving time.
+var brt = time.FixedZone("BRT", -3*60*60)
+
+func TestDateGetters(t *testing.T) {
+	date := newDate(t)
+
+	// 1970-01-01T00:00:00Z is the last day of 1969 on BRT.
+	d := construct(t, date, num(0))
+	for method, want := range map[string]float64{
+		"getTime":            0,
+		"valueOf":            0,
+		"getFullYear":        1969,
+		"getMonth":           11,
+		"getDate":            31,
+		"getDay":             3,
+		"getHours":           21,
+		"getMinutes":         0,
+		"getSeconds":         0,
+		"getMilliseconds":    0,
+		"getUTCFullYear":     1970,
+		"getUTCMonth":        0,
+		"getUTCDate":         1,
+		"getUTCDay":          4,
+		"getUTCHours":        0,
+		"getUTCMilliseconds": 0,
+		"getTimezoneOffset":  180,
+		"getUTCMinutes":      0,
+		"getUTCSeconds":      0,
+	} {
+		assertNumber(t, callMethod(t, d, method), want)
+	}
+}
+
+func TestDateConstruct(t *testing.T) {
+	date := newDate(t)
+
+	for _, tc := range []struct {
+		name string
+		args []types.Value
+		want float64
+	}{
+		{
+			name: "Now",
+			want: 1500000000123,
+		},
+		{
+			name: "TimeValue",
+			args: []types.Value{num(-1.5)},
+			want: -1,
+		},
+		{
+			name: "String",
+			args: []types.Value{str("2000-01-01")},
+			want: 946684800000,
+		},
+		{
+			name: "DateTimeString",
+			args: []types.Value{str("2000-02-29T12:30:15.25-03:00")},
+			want: 951838215250,
+		},
+		{
+			name: "InvalidString",
+			args: []types.Value{str("tomorrow")},
+			want: math.NaN(),
+		},
+		{
+			name: "OutOfRange",
+			args: []types.Value{num(8.64e15 + 1)},
+			want: math.NaN(),
+		},
+		{
+			name: "LocalComponents",
+			args: []types.Value{
+				num(2000), num(1), num(29), num(12), num(30), num(15), num(250),
+			},
+			want: 951838215250,
+		},
+		{
+			name: "YearAndMonth",
+			args: []types.Value{num(2000), num(0)},
+			want: 946695600000,
+		},
+		{
+			name: "TwoDigitYear",
+			args: []types.Value{num(99), num(11), num(31), num(21)},
+			want: 946684800000,
+		},
+		{
+			name: "CarriedMonth",
+			args: []types.Value{num(1999), num(12), num(1)},
+			want: 946695600000,
+		},
+		{
+			name: "NaNComponent",
+			args: []types.Value{num(2000), num(math.NaN())},
+			want: math.NaN(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := construct(t, date, tc.args...)
+			assertNumber(t, callMethod(t, d, "getTime"), tc.want)
+		})
+	}
+}
+
+func TestDateSetTime(t *testing.T) {
+	date := newDate(t)
+
+	d := construct(t, date, num(0))
+	assertNumber(t, callMethod(t, d, "setTime", num(946684800000.9)), 946684800000)
+	assertNumber(t, callMethod(t, d, "getUTCFullYear"), 2000)
+	assertNumber(t, callMethod(t, d, "getFullYear"), 1999)
+
+	assertNumber(t, callMethod(t, d, "setTime", str("invalid")), math.NaN())
+	assertNumber(t, callMethod(t, d, "getFullYear"), math.NaN())
+	assertNumber(t, callMethod(t, d, "getUTCHours"), math.NaN())
+	assertNumber(t, callMethod(t, d, "getTimezoneOffset"), math.NaN())
+}
+
+func TestDateToString(t *testing.T) {
+	date := newDate(t)
+
+	d := construct(t, date, num(0))
+	want := "Wed Dec 31 1969 21:00:00 GMT-0300 (BRT)"
+	assert.EqualStrings(t, want, d.ToString().String(), "date string")
+	assertNumber(t, d.ToNumber(), 0)
+
+	invalid := construct(t, date, num(math.NaN()))
+	assert.EqualStrings(t, "Invalid Date", invalid.ToString().String(), "invalid date string")
+}
+
+func TestDateMethodOnNonDate(t *testing.T) {
+	date := newDate(t)
+	d := construct(t, date, num(0))
+
+	getTime, err := d.Get(utf16.S("getTime"))
+	assert.NoError(t, err, "getting getTime")
+
+	_, err = getTime.(types.Function).Call(types.NewBaseDataObject(), nil)
+	if _, ok := err.(types.TypeError); !ok {
+		t.Fatalf("got error %v, want a TypeError", err)
+	}
+}
+
+func newDate(t *testing.T) *builtins.Date {
+	epoch := time.Unix(1500000000, 123456789)
+	date, err := builtins.NewDate(func() time.Time { return epoch }, brt)
+	assert.NoError(t, err, "date creation")
+	return date
+}
+
+func construct(t *testing.T, date *builtins.Date, args ...types.Value) *builtins.DateObject {
+	t.Helper()
+
+	val, err := date.Construct(args)
+	assert.NoError(t, err, "constructing date")
+	return val.(*builtins.DateObject)
+}
+
+func assertNumber(t *testing.T, got types.Value, want float64) {
+	t.Helper()
+
+	n, ok := got.(types.Number)
+	if !ok {
+		t.Fatalf("got %v, want the number %v", got, want)
+	}
+
+	if math.IsNaN(want) && math.IsNaN(n.Value()) {
+		return
+	}
+
+	if n.Value() != want {
+		t.Fatalf("got %v, want %v", n.Value(), want)
+	}
+}
+
+func num(n float64) types.Number {
+	return types.NewNumber(n)
+}
@@ -0,0 +1,26 @@
+package builtins
+
+import (
+	"github.com/NeowayLabs/abad/internal/numparse"
+	"github.com/NeowayLabs/abad/types"
+)
+
+// Step is called by the builtins on every iteration of their loops,
+// which the scripts can make as long as they want, eg.: by the length
+// of an array-like object. It accounts an evaluation step, returning
+// the error that must stop the builtin when the evaluation is out of
+// budget or interrupted.
+type Step func() error
+
+// NewParseInt creates the parseInt function of the global object.
+// https://es5.github.io/#x15.1.2.2
+func NewParseInt() *types.Builtinfn {
+	return types.NewBuiltinfnArgs(parseInt, types.ArgString, types.ArgNumber)
+}
+
+// parseInt converts the string to an integer in the radix, the
+// radix is converted as ToInt32, so undefined is zero.
+func parseInt(_ types.Object, args []types.Value) (types.Value, error) {
+	str, radix := args[0].ToString(), args[1].ToNumber()
+	return types.NewNumber(numparse.Int(str.String(), int(radix.ToInt32()))), nil
+}
@@ -0,0 +1,392 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf16"
+
+	"github.com/NeowayLabs/abad/types"
+	abadutf16 "github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// JSON is the JSON builtin object.
+	// https://es5.github.io/#x15.12
+	JSON struct {
+		*types.DataObject
+
+		step Step
+	}
+
+	// jsonWriter holds the state of a JSON.stringify call.
+	// https://es5.github.io/#x15.12.3
+	jsonWriter struct {
+		step     Step
+		replacer types.Function
+
+		// keys of the replacer array, nil if there's none
+		keys []abadutf16.Str
+
+		gap    abadutf16.Str
+		indent abadutf16.Str
+
+		// objects being serialized, to detect cycles
+		stack []types.Object
+	}
+)
+
+// maxGap is the maximum number of spaces of the indentation.
+const maxGap = 10
+
+var (
+	stringifyAttr = abadutf16.S("stringify")
+	toJSONAttr    = abadutf16.S("toJSON")
+)
+
+// NewJSON creates the JSON object, its functions call step on every
+// value.
+func NewJSON(step Step) (*JSON, error) {
+	json := &JSON{
+		DataObject: types.NewBaseDataObject(),
+		step:       step,
+	}
+
+	stringifyfn := types.NewBuiltinfnArgs(json.stringify,
+		types.ArgAny, types.ArgAny, types.ArgAny)
+	err := json.DefineBuiltin(stringifyAttr, stringifyfn)
+	if err != nil {
+		return nil, err
+	}
+
+	toStrfn := types.NewBuiltinfn(toStringer("[object JSON]"))
+	err = json.DefineBuiltin(toStringAttr, toStrfn)
+	return json, err
+}
+
+// stringify serializes value to JSON. The replacer is a function
+// transforming the values or an array of the keys serialized, and
+// space is the indentation, a number of spaces or a string.
+// Undefined values and functions are dropped from objects and are
+// null in arrays. It's undefined if value can't be serialized.
+// https://es5.github.io/#x15.12.3
+func (json *JSON) stringify(_ types.Object, args []types.Value) (types.Value, error) {
+	value, replacer, space := args[0], args[1], args[2]
+
+	w := &jsonWriter{step: json.step}
+
+	switch r := replacer.(type) {
+	case types.Function:
+		w.replacer = r
+	case *types.Slice:
+		w.keys = replacerKeys(r)
+	}
+
+	w.gap = jsonGap(space)
+
+	wrapper := types.NewBaseDataObject()
+	err := wrapper.Put(abadutf16.Str{}, value, true)
+	if err != nil {
+		return nil, err
+	}
+
+	str, ok, err := w.serializeProperty(wrapper, abadutf16.Str{})
+	if err != nil || !ok {
+		return types.Undefined, err
+	}
+
+	return types.String(str), nil
+}
+
+// replacerKeys are the string and number elements of the replacer,
+// without duplicates.
+func replacerKeys(replacer *types.Slice) []abadutf16.Str {
+	keys := []abadutf16.Str{}
+	seen := map[string]bool{}
+
+	for _, v := range replacer.Values() {
+		var key abadutf16.Str
+
+		switch val := v.(type) {
+		case types.String, types.Number:
+			key = abadutf16.Str(val.ToString())
+		case *types.StringObject:
+			key = abadutf16.Str(val.PrimitiveValue())
+		default:
+			continue
+		}
+
+		if !seen[key.String()] {
+			seen[key.String()] = true
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// jsonGap is the indentation of space: up to 10 spaces if it's a
+// number or the first 10 code units if it's a string.
+func jsonGap(space types.Value) abadutf16.Str {
+	if obj, ok := space.(*types.StringObject); ok {
+		space = obj.PrimitiveValue()
+	}
+
+	switch s := space.(type) {
+	case types.Number:
+		n := math.Min(maxGap, s.Value())
+		gap := abadutf16.Str{}
+		for i := 0; i < int(n); i++ {
+			gap = append(gap, ' ')
+		}
+		return gap
+	case types.String:
+		gap := abadutf16.Str(s)
+		if len(gap) > maxGap {
+			gap = gap[:maxGap]
+		}
+		return gap
+	}
+
+	return abadutf16.Str{}
+}
+
+// serializeProperty serializes the property key of holder, it
+// returns false if the value isn't serialized.
+// https://es5.github.io/#x15.12.3 (Str)
+func (w *jsonWriter) serializeProperty(holder types.Object, key abadutf16.Str) (abadutf16.Str, bool, error) {
+	if err := w.step(); err != nil {
+		return nil, false, err
+	}
+
+	value, err := holder.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if obj, ok := value.(types.Object); ok {
+		toJSON, err := obj.Get(toJSONAttr)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if fn, ok := toJSON.(types.Function); ok {
+			value, err = fn.Call(obj, []types.Value{types.String(key)})
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	if w.replacer != nil {
+		value, err = w.replacer.Call(holder, []types.Value{types.String(key), value})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return w.serialize(value)
+}
+
+func (w *jsonWriter) serialize(value types.Value) (abadutf16.Str, bool, error) {
+	if obj, ok := value.(*types.StringObject); ok {
+		value = obj.PrimitiveValue()
+	}
+
+	switch val := value.(type) {
+	case types.String:
+		return jsonQuote(abadutf16.Str(val)), true, nil
+	case types.Number:
+		f := val.Value()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return abadutf16.S("null"), true, nil
+		}
+		return abadutf16.Str(val.ToString()), true, nil
+	case types.Bool:
+		return abadutf16.Str(val.ToString()), true, nil
+	case types.Function:
+		return nil, false, nil
+	case *types.Slice:
+		str, err := w.serializeArray(val)
+		return str, err == nil, err
+	case types.Object:
+		str, err := w.serializeObject(val)
+		return str, err == nil, err
+	}
+
+	if value.Kind() == types.KindNull {
+		return abadutf16.S("null"), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// https://es5.github.io/#x15.12.3 (JO)
+func (w *jsonWriter) serializeObject(obj types.Object) (abadutf16.Str, error) {
+	if err := w.push(obj); err != nil {
+		return nil, err
+	}
+	defer w.pop()
+
+	keys := w.keys
+	if keys == nil {
+		keys = obj.OwnPropertyKeys(types.EnumerableKeys)
+	}
+
+	var members []abadutf16.Str
+	for _, key := range keys {
+		str, ok, err := w.serializeProperty(obj, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		member := jsonQuote(key)
+		member = append(member, ':')
+		if len(w.gap) > 0 {
+			member = append(member, ' ')
+		}
+		members = append(members, append(member, str...))
+	}
+
+	return w.join('{', '}', members), nil
+}
+
+// https://es5.github.io/#x15.12.3 (JA)
+func (w *jsonWriter) serializeArray(slice *types.Slice) (abadutf16.Str, error) {
+	if err := w.push(slice); err != nil {
+		return nil, err
+	}
+	defer w.pop()
+
+	values := slice.Values()
+	elems := make([]abadutf16.Str, 0, len(values))
+
+	for i := range values {
+		key := abadutf16.Str(types.NewNumber(float64(i)).ToString())
+		str, ok, err := w.serializeProperty(slice, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			str = abadutf16.S("null")
+		}
+
+		elems = append(elems, str)
+	}
+
+	return w.join('[', ']', elems), nil
+}
+
+// join the members of an object or the elements of an array, one per
+// line if there's a gap. The items are indented by the current indent
+// and the close by the indent of the enclosing value.
+func (w *jsonWriter) join(open, close uint16, items []abadutf16.Str) abadutf16.Str {
+	if len(items) == 0 {
+		return abadutf16.Str{open, close}
+	}
+
+	res := abadutf16.Str{open}
+
+	if len(w.gap) == 0 {
+		for i, item := range items {
+			if i > 0 {
+				res = append(res, ',')
+			}
+			res = append(res, item...)
+		}
+		return append(res, close)
+	}
+
+	for i, item := range items {
+		if i > 0 {
+			res = append(res, ',')
+		}
+		res = append(res, '\n')
+		res = append(res, w.indent...)
+		res = append(res, item...)
+	}
+
+	stepback := w.indent[:len(w.indent)-len(w.gap)]
+	res = append(res, '\n')
+	res = append(res, stepback...)
+	return append(res, close)
+}
+
+// push obj on the stack of serialized objects and indents the
+// serialization, it fails if the obj is already being serialized.
+func (w *jsonWriter) push(obj types.Object) error {
+	for _, o := range w.stack {
+		if o == obj {
+			return types.NewTypeError("Converting circular structure to JSON")
+		}
+	}
+
+	w.stack = append(w.stack, obj)
+	w.indent = append(w.indent, w.gap...)
+	return nil
+}
+
+func (w *jsonWriter) pop() {
+	w.stack = w.stack[:len(w.stack)-1]
+	w.indent = w.indent[:len(w.indent)-len(w.gap)]
+}
+
+// jsonQuote quotes str as a JSON string, escaping the control
+// characters and the lone surrogates.
+// https://es5.github.io/#x15.12.3 (Quote)
+func jsonQuote(str abadutf16.Str) abadutf16.Str {
+	res := abadutf16.Str{'"'}
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+
+		switch c {
+		case '"', '\\':
+			res = append(res, '\\', c)
+		case '\b':
+			res = append(res, '\\', 'b')
+		case '\f':
+			res = append(res, '\\', 'f')
+		case '\n':
+			res = append(res, '\\', 'n')
+		case '\r':
+			res = append(res, '\\', 'r')
+		case '\t':
+			res = append(res, '\\', 't')
+		default:
+			if c < 0x20 || isLoneSurrogate(str, i) {
+				res = append(res, abadutf16.S(fmt.Sprintf(`\u%04x`, c))...)
+				continue
+			}
+
+			if utf16.IsSurrogate(rune(c)) {
+				// valid pair, its trailing surrogate is next
+				res = append(res, c, str[i+1])
+				i++
+				continue
+			}
+
+			res = append(res, c)
+		}
+	}
+
+	return append(res, '"')
+}
+
+func isLoneSurrogate(str abadutf16.Str, i int) bool {
+	c := rune(str[i])
+	if !utf16.IsSurrogate(c) {
+		return false
+	}
+
+	if c >= 0xDC00 || i+1 == len(str) {
+		return true
+	}
+
+	next := rune(str[i+1])
+	return next < 0xDC00 || next > 0xDFFF
+}
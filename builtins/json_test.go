@@ -0,0 +1,213 @@
+package builtins_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/NeowayLabs/abad/builtins"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestJSONStringify(t *testing.T) {
+	json := newJSON(t)
+
+	fn := types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		return types.Undefined, nil
+	})
+
+	obj := types.NewBaseDataObject()
+	put(t, obj, "a", num(1))
+	put(t, obj, "b", slice(str("x"), types.Undefined, fn, types.Null))
+	put(t, obj, "c", types.Undefined)
+	put(t, obj, "d", fn)
+	put(t, obj, "e", types.NewBaseDataObject())
+
+	for _, tc := range []struct {
+		name string
+		args []types.Value
+		want string
+	}{
+		{
+			name: "Number",
+			args: []types.Value{num(1.5)},
+			want: `1.5`,
+		},
+		{
+			name: "NaN",
+			args: []types.Value{num(math.NaN())},
+			want: `null`,
+		},
+		{
+			name: "Infinity",
+			args: []types.Value{num(math.Inf(-1))},
+			want: `null`,
+		},
+		{
+			name: "Bool",
+			args: []types.Value{types.True},
+			want: `true`,
+		},
+		{
+			name: "Null",
+			args: []types.Value{types.Null},
+			want: `null`,
+		},
+		{
+			name: "String",
+			args: []types.Value{str("a\"b\\c\n\t\x01")},
+			want: `"a\"b\\c\n\t\u0001"`,
+		},
+		{
+			name: "StringObject",
+			args: []types.Value{types.NewStringObject(str("a"))},
+			want: `"a"`,
+		},
+		{
+			name: "LoneSurrogate",
+			args: []types.Value{types.String(utf16.Str{0xD800, 'a', 0xD83D, 0xDE00})},
+			want: "\"\\ud800a\U0001F600\"",
+		},
+		{
+			name: "Object",
+			args: []types.Value{obj},
+			want: `{"a":1,"b":["x",null,null,null],"e":{}}`,
+		},
+		{
+			name: "EmptyArray",
+			args: []types.Value{slice()},
+			want: `[]`,
+		},
+		{
+			name: "SpaceNumber",
+			args: []types.Value{obj, types.Undefined, num(2)},
+			want: "{\n  \"a\": 1,\n  \"b\": [\n    \"x\",\n    null,\n    null,\n    null\n  ],\n  \"e\": {}\n}",
+		},
+		{
+			name: "SpaceNumberLimit",
+			args: []types.Value{slice(num(1)), types.Undefined, num(20)},
+			want: "[\n          1\n]",
+		},
+		{
+			name: "SpaceLessThanOne",
+			args: []types.Value{slice(num(1)), types.Undefined, num(0.5)},
+			want: "[1]",
+		},
+		{
+			name: "SpaceString",
+			args: []types.Value{obj, types.Null, str("\t")},
+			want: "{\n\t\"a\": 1,\n\t\"b\": [\n\t\t\"x\",\n\t\tnull,\n\t\tnull,\n\t\tnull\n\t],\n\t\"e\": {}\n}",
+		},
+		{
+			name: "SpaceStringLimit",
+			args: []types.Value{slice(num(1)), types.Undefined, str("0123456789ab")},
+			want: "[\n01234567891\n]",
+		},
+		{
+			name: "ReplacerArray",
+			args: []types.Value{obj, slice(str("e"), num(1), str("a"), str("e"), str("c"))},
+			want: `{"e":{},"a":1}`,
+		},
+		{
+			name: "ReplacerArrayNested",
+			args: []types.Value{obj, slice(str("b"), str("0"))},
+			want: `{"b":["x",null,null,null]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := callMethod(t, json, "stringify", tc.args...)
+			assert.EqualStrings(t, tc.want, got.ToString().String(), "stringify")
+		})
+	}
+}
+
+func TestJSONStringifyUndefined(t *testing.T) {
+	json := newJSON(t)
+
+	fn := types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		return types.Undefined, nil
+	})
+
+	for _, v := range []types.Value{types.Undefined, fn} {
+		got := callMethod(t, json, "stringify", v)
+		if got.Kind() != types.KindUndefined {
+			t.Fatalf("got %v, want undefined", got)
+		}
+	}
+}
+
+func TestJSONStringifyReplacerFunction(t *testing.T) {
+	json := newJSON(t)
+
+	obj := types.NewBaseDataObject()
+	put(t, obj, "a", num(1))
+	put(t, obj, "b", num(2))
+
+	var holders []types.Object
+	replacer := types.NewBuiltinfn(func(this types.Object, args []types.Value) (types.Value, error) {
+		holders = append(holders, this)
+
+		switch args[0].ToString().String() {
+		case "a":
+			return str("replaced"), nil
+		case "b":
+			return types.Undefined, nil
+		}
+
+		return args[1], nil
+	})
+
+	got := callMethod(t, json, "stringify", obj, replacer)
+	assert.EqualStrings(t, `{"a":"replaced"}`, got.ToString().String(), "stringify")
+
+	if len(holders) != 3 || holders[1] != obj || holders[2] != obj {
+		t.Fatalf("got holders %v, want the wrapper and then the object", holders)
+	}
+}
+
+func TestJSONStringifyToJSON(t *testing.T) {
+	json := newJSON(t)
+
+	inner := types.NewBaseDataObject()
+	put(t, inner, "toJSON", types.NewBuiltinfn(
+		func(_ types.Object, args []types.Value) (types.Value, error) {
+			return args[0], nil
+		}))
+
+	obj := types.NewBaseDataObject()
+	put(t, obj, "key", inner)
+
+	got := callMethod(t, json, "stringify", obj)
+	assert.EqualStrings(t, `{"key":"key"}`, got.ToString().String(), "stringify")
+}
+
+func TestJSONStringifyCircular(t *testing.T) {
+	json := newJSON(t)
+
+	obj := types.NewBaseDataObject()
+	put(t, obj, "self", slice(obj))
+
+	stringify, err := json.Get(utf16.S("stringify"))
+	assert.NoError(t, err, "getting stringify")
+
+	_, err = stringify.(types.Function).Call(json, []types.Value{obj})
+	if _, ok := err.(types.TypeError); !ok {
+		t.Fatalf("got error %v, want a TypeError", err)
+	}
+
+	// the same object twice isn't circular
+	put(t, obj, "self", types.Null)
+	got := callMethod(t, json, "stringify", slice(obj, obj))
+	assert.EqualStrings(t, `[{"self":null},{"self":null}]`, got.ToString().String(), "stringify")
+}
+
+func newJSON(t *testing.T) *builtins.JSON {
+	json, err := builtins.NewJSON(noStep)
+	assert.NoError(t, err, "json creation")
+	return json
+}
+
+func slice(values ...types.Value) *types.Slice {
+	return types.NewSlice(values, types.SliceShared)
+}
@@ -0,0 +1,37 @@
+package builtins
+
+import (
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// Math is the Math builtin object.
+	// https://es5.github.io/#x15.8
+	Math struct {
+		*types.DataObject
+	}
+)
+
+var randomAttr = utf16.S("random")
+
+// NewMath creates the Math object. The random function is the
+// source of Math.random numbers and must return values in [0, 1).
+func NewMath(random func() float64) (*Math, error) {
+	math := &Math{
+		DataObject: types.NewBaseDataObject(),
+	}
+
+	randomfn := types.NewBuiltinfnArgs(func(_ types.Object, _ []types.Value) (types.Value, error) {
+		return types.NewNumber(random()), nil
+	})
+
+	err := math.DefineBuiltin(randomAttr, randomfn)
+	if err != nil {
+		return nil, err
+	}
+
+	toStrfn := types.NewBuiltinfn(toStringer("[object Math]"))
+	err = math.DefineBuiltin(toStringAttr, toStrfn)
+	return math, err
+}
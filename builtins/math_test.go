@@ -0,0 +1,49 @@
+package builtins_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NeowayLabs/abad/builtins"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestMathRandom(t *testing.T) {
+	math, err := builtins.NewMath(func() float64 { return 0.25 })
+	assert.NoError(t, err, "math creation")
+	assert.EqualStrings(t, "[object Math]", math.String(), "math toString")
+
+	got := callMethod(t, math, "random")
+	if !types.StrictEqual(types.NewNumber(0.25), got) {
+		t.Fatalf("got %v but want 0.25", got)
+	}
+}
+
+func TestDateNow(t *testing.T) {
+	epoch := time.Unix(1500000000, 123456789)
+	date, err := builtins.NewDate(func() time.Time { return epoch }, time.UTC)
+	assert.NoError(t, err, "date creation")
+
+	got := callMethod(t, date, "now")
+	if !types.StrictEqual(types.NewNumber(1500000000123), got) {
+		t.Fatalf("got %v but want 1500000000123", got)
+	}
+}
+
+func callMethod(t *testing.T, obj types.Object, name string, args ...types.Value) types.Value {
+	t.Helper()
+
+	method, err := obj.Get(utf16.S(name))
+	assert.NoError(t, err, "getting method %s", name)
+
+	fn, ok := method.(types.Function)
+	if !ok {
+		t.Fatalf("%s is not a function", name)
+	}
+
+	val, err := fn.Call(obj, args)
+	assert.NoError(t, err, "calling %s", name)
+	return val
+}
@@ -0,0 +1,179 @@
+package builtins
+
+import (
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// Object is the Object builtin object.
+	// https://es5.github.io/#x15.2
+	Object struct {
+		*types.DataObject
+
+		step Step
+	}
+)
+
+var (
+	assignAttr  = utf16.S("assign")
+	keysAttr    = utf16.S("keys")
+	valuesAttr  = utf16.S("values")
+	entriesAttr = utf16.S("entries")
+)
+
+// NewObject creates the Object object, its functions call step on
+// every property.
+func NewObject(step Step) (*Object, error) {
+	object := &Object{
+		DataObject: types.NewBaseDataObject(),
+		step:       step,
+	}
+
+	type method struct {
+		name utf16.Str
+		fn   types.Execfn
+	}
+
+	for _, m := range []method{
+		{assignAttr, object.assign},
+		{keysAttr, object.keys},
+		{valuesAttr, object.values},
+		{entriesAttr, object.entries},
+	} {
+		err := object.DefineBuiltin(m.name, types.NewBuiltinfn(m.fn))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	toStrfn := types.NewBuiltinfn(
+		toStringer("function Object() { [native code] }"),
+	)
+	err := object.DefineBuiltin(toStringAttr, toStrfn)
+	return object, err
+}
+
+// assign copies the own enumerable properties of each source to
+// the target, in order, and returns the target. The properties are
+// read with Get, calling the getters of the sources, and written with
+// Put, calling the setters of the target. Null and undefined sources
+// are skipped, as are numbers and booleans, whose wrapper objects have
+// no own enumerable properties.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-object.assign
+func (object *Object) assign(_ types.Object, args []types.Value) (types.Value, error) {
+	target, err := objectArg("assign", argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range args[1:] {
+		switch source.Kind() {
+		case types.KindUndefined, types.KindNull, types.KindNumber, types.KindBool:
+			continue
+		}
+
+		from, err := objectArg("assign", source)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range from.OwnPropertyKeys(types.EnumerableKeys) {
+			if err := object.step(); err != nil {
+				return nil, err
+			}
+
+			val, err := from.Get(key)
+			if err != nil {
+				return nil, err
+			}
+
+			err = target.Put(key, val, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// every object is a value, but not every Object
+	return target.(types.Value), nil
+}
+
+// keys creates a slice with the names of the own enumerable
+// properties of the object, in the order of OwnPropertyKeys.
+// https://www.ecma-international.org/ecma-262/6.0/#sec-object.keys
+func (object *Object) keys(_ types.Object, args []types.Value) (types.Value, error) {
+	return object.properties("keys", argAt(args, 0), func(key utf16.Str, _ types.Value) types.Value {
+		return types.String(key)
+	})
+}
+
+// values creates a slice with the values of the own enumerable
+// properties of the object, in the order of Object.keys.
+// https://www.ecma-international.org/ecma-262/8.0/#sec-object.values
+func (object *Object) values(_ types.Object, args []types.Value) (types.Value, error) {
+	return object.properties("values", argAt(args, 0), func(_ utf16.Str, val types.Value) types.Value {
+		return val
+	})
+}
+
+// entries creates a slice with the [name, value] pairs of the
+// own enumerable properties of the object, in the order of Object.keys.
+// https://www.ecma-international.org/ecma-262/8.0/#sec-object.entries
+func (object *Object) entries(_ types.Object, args []types.Value) (types.Value, error) {
+	return object.properties("entries", argAt(args, 0), func(key utf16.Str, val types.Value) types.Value {
+		entry := []types.Value{types.String(key), val}
+		return types.NewSlice(entry, types.SliceGrowable)
+	})
+}
+
+// properties creates a slice with the own enumerable properties
+// of v, converted by the function fn. The values are read with Get,
+// calling the getters. Numbers and booleans have none, as their
+// wrapper objects.
+func (object *Object) properties(fn string, v types.Value, conv func(utf16.Str, types.Value) types.Value) (types.Value, error) {
+	switch v.Kind() {
+	case types.KindNumber, types.KindBool:
+		return types.NewSlice(nil, types.SliceGrowable), nil
+	}
+
+	obj, err := objectArg(fn, v)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := obj.OwnPropertyKeys(types.EnumerableKeys)
+	values := make([]types.Value, 0, len(keys))
+	for _, key := range keys {
+		if err := object.step(); err != nil {
+			return nil, err
+		}
+
+		val, err := obj.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, conv(key, val))
+	}
+
+	return types.NewSlice(values, types.SliceGrowable), nil
+}
+
+// objectArg converts the argument of the Object function fn to an
+// object, eg.: the target of Object.assign.
+func objectArg(fn string, v types.Value) (types.Object, error) {
+	// WHY: ToObject of the builtins returns their DataObject, which
+	// is not the same value for the script, eg.: for Math.
+	if obj, ok := v.(types.Object); ok {
+		return obj, nil
+	}
+
+	switch v.Kind() {
+	case types.KindNumber, types.KindBool:
+		// TODO: wrap them when Number and Boolean objects exist
+		return nil, types.NewTypeError("Object.%s: %s objects are not supported yet", fn, v.Kind())
+	}
+
+	return v.ToObject()
+}
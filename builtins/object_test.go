@@ -0,0 +1,191 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/builtins"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestObjectAssign(t *testing.T) {
+	object := newObject(t)
+
+	target := types.NewBaseDataObject()
+	put(t, target, "a", str("target"))
+	put(t, target, "b", str("target"))
+
+	first := types.NewBaseDataObject()
+	put(t, first, "b", str("first"))
+	put(t, first, "c", str("first"))
+
+	second := types.NewBaseDataObject()
+	put(t, second, "c", str("second"))
+	_, err := second.DefineOwnPropertyP(utf16.S("hidden"),
+		types.NewDataPropDesc(str("second"), true, false, true), true)
+	assert.NoError(t, err, "defining hidden property")
+
+	got := callMethod(t, object, "assign", target, first, types.Null, second, types.Undefined,
+		types.NewNumber(1), types.True)
+	if got != target {
+		t.Fatalf("got %v, want the target", got)
+	}
+
+	assertKeys(t, target, "a", "b", "c")
+	assertProp(t, target, "a", str("target"))
+	assertProp(t, target, "b", str("first"))
+	assertProp(t, target, "c", str("second"))
+}
+
+func TestObjectAssignAccessors(t *testing.T) {
+	object := newObject(t)
+
+	getter := types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		return str("got"), nil
+	})
+	source := types.NewBaseDataObject()
+	_, err := source.DefineOwnPropertyP(utf16.S("a"),
+		types.NewAcessorPropDesc(getter, types.Undefined, true, true), true)
+	assert.NoError(t, err, "defining getter")
+
+	var set types.Value
+	setter := types.NewBuiltinfn(func(_ types.Object, args []types.Value) (types.Value, error) {
+		set = args[0]
+		return types.Undefined, nil
+	})
+	target := types.NewBaseDataObject()
+	_, err = target.DefineOwnPropertyP(utf16.S("a"),
+		types.NewAcessorPropDesc(types.Undefined, setter, true, true), true)
+	assert.NoError(t, err, "defining setter")
+
+	callMethod(t, object, "assign", target, source)
+
+	if !types.StrictEqual(set, str("got")) {
+		t.Fatalf("setter got %v, want the getter value", set)
+	}
+}
+
+func TestObjectAssignString(t *testing.T) {
+	object := newObject(t)
+
+	target := types.NewBaseDataObject()
+	callMethod(t, object, "assign", target, str("ab"))
+
+	assertKeys(t, target, "0", "1")
+	assertProp(t, target, "1", str("b"))
+}
+
+func TestObjectAssignErrors(t *testing.T) {
+	object := newObject(t)
+
+	method, err := object.Get(utf16.S("assign"))
+	assert.NoError(t, err, "getting assign")
+	assign := method.(types.Function)
+
+	readOnly := types.NewBaseDataObject()
+	_, err = readOnly.DefineOwnPropertyP(utf16.S("a"),
+		types.NewDataPropDesc(str("a"), false, true, true), true)
+	assert.NoError(t, err, "defining read only property")
+
+	source := types.NewBaseDataObject()
+	put(t, source, "a", str("b"))
+
+	for name, args := range map[string][]types.Value{
+		"NoArgs":          nil,
+		"UndefinedTarget": {types.Undefined},
+		"NullTarget":      {types.Null, source},
+		"ReadOnly":        {readOnly, source},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := assign.Call(object, args)
+			if _, ok := err.(types.TypeError); !ok {
+				t.Fatalf("got error %v, want a TypeError", err)
+			}
+		})
+	}
+}
+
+func TestObjectKeysValuesEntries(t *testing.T) {
+	object := newObject(t)
+
+	obj := types.NewBaseDataObject()
+	put(t, obj, "b", str("b"))
+	put(t, obj, "10", str("10"))
+	put(t, obj, "a", str("a"))
+	put(t, obj, "2", str("2"))
+	put(t, obj, "1", str("1"))
+	_, err := obj.DefineOwnPropertyP(utf16.S("hidden"),
+		types.NewDataPropDesc(str("hidden"), true, false, true), true)
+	assert.NoError(t, err, "defining hidden property")
+
+	// integer keys come first, in ascending order, then the
+	// others in insertion order.
+	order := []types.Value{str("1"), str("2"), str("10"), str("b"), str("a")}
+
+	assertSlice(t, callMethod(t, object, "keys", obj), order...)
+	assertSlice(t, callMethod(t, object, "values", obj), order...)
+
+	entries := callMethod(t, object, "entries", obj).(*types.Slice)
+	assert.EqualInts(t, len(order), entries.Len(), "number of entries")
+	for i, key := range order {
+		assertSlice(t, entries.Index(i), key, key)
+	}
+
+	assertSlice(t, callMethod(t, object, "keys", str("ab")), str("0"), str("1"))
+
+	for _, name := range []string{"keys", "values", "entries"} {
+		assertSlice(t, callMethod(t, object, name, types.NewNumber(1)))
+		assertSlice(t, callMethod(t, object, name, types.True))
+	}
+}
+
+func TestObjectKeysErrors(t *testing.T) {
+	object := newObject(t)
+
+	for _, name := range []string{"keys", "values", "entries"} {
+		method, err := object.Get(utf16.S(name))
+		assert.NoError(t, err, "getting %s", name)
+		fn := method.(types.Function)
+
+		for _, arg := range []types.Value{types.Undefined, types.Null} {
+			_, err := fn.Call(object, []types.Value{arg})
+			if _, ok := err.(types.TypeError); !ok {
+				t.Fatalf("%s(%v): got error %v, want a TypeError", name, arg, err)
+			}
+		}
+	}
+}
+
+func newObject(t *testing.T) *builtins.Object {
+	object, err := builtins.NewObject(noStep)
+	assert.NoError(t, err, "object creation")
+	assert.EqualStrings(t, "function Object() { [native code] }", object.String(), "object toString")
+	return object
+}
+
+func assertKeys(t *testing.T, obj types.Object, want ...string) {
+	t.Helper()
+
+	keys := obj.OwnPropertyKeys(types.AllKeys)
+	if len(keys) != len(want) {
+		t.Fatalf("got keys %v, want %v", keys, want)
+	}
+
+	for i, key := range keys {
+		if key.String() != want[i] {
+			t.Fatalf("got keys %v, want %v", keys, want)
+		}
+	}
+}
+
+func assertProp(t *testing.T, obj types.Object, name string, want types.Value) {
+	t.Helper()
+
+	got, err := obj.Get(utf16.S(name))
+	assert.NoError(t, err, "getting %s", name)
+
+	if !types.StrictEqual(got, want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+}
@@ -4,21 +4,47 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
+	"sync/atomic"
 
 	"github.com/NeowayLabs/abad"
+	"github.com/NeowayLabs/abad/types"
 )
 
 type (
 	Cli struct {
-		in  io.Reader
+		in  *bufio.Reader
 		out io.Writer
 
 		js *abad.Abad
+
+		// WHY: evaluations run on a child goroutine so the
+		// REPL goroutine can interrupt them.
+		requests chan string
+		results  chan result
+		state    int32
+	}
+
+	result struct {
+		val types.Value
+		err error
+	}
+
+	// flusher is a buffered output.
+	flusher interface {
+		Flush() error
 	}
 )
 
-func NewCli(in io.Reader, out io.Writer) (*Cli, error) {
-	ecma, err := abad.NewAbad()
+// states of the evaluation, set atomically in Cli.state
+const (
+	idle int32 = iota
+	running
+	interrupted
+)
+
+func NewCli(in io.Reader, out io.Writer, opts ...abad.Option) (*Cli, error) {
+	ecma, err := abad.NewAbad(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,40 +52,144 @@ func NewCli(in io.Reader, out io.Writer) (*Cli, error) {
 	return NewWithJS(ecma, in, out), nil
 }
 
+// NewWithJS creates a new Cli evaluating code with js. If out is
+// buffered, ie. it has a Flush method, it is flushed before the
+// input is read.
+// The Cli must be closed after use.
 func NewWithJS(js *abad.Abad, in io.Reader, out io.Writer) *Cli {
-	return &Cli{
-		in:  in,
-		out: out,
-		js:  js,
+	c := &Cli{
+		in:       bufio.NewReader(in),
+		out:      out,
+		js:       js,
+		requests: make(chan string),
+		results:  make(chan result),
+	}
+
+	go c.evaluator()
+	return c
+}
+
+// Interrupt the running evaluation, if any. It returns false
+// if there was no evaluation to interrupt or if it was already
+// interrupted, eg.: it's blocked waiting for input, so the caller
+// can exit instead.
+func (c *Cli) Interrupt() bool {
+	if !atomic.CompareAndSwapInt32(&c.state, running, interrupted) {
+		return false
+	}
+
+	c.js.Interrupt()
+	return true
+}
+
+// WarnAfter warns when an evaluation runs the given number of steps
+// without output, asking if it must be aborted, so typos like
+// while(i<10); don't freeze the session silently. The warning is
+// repeated every steps. Zero steps disables the warning.
+func (c *Cli) WarnAfter(steps uint) {
+	c.js.OnLongEvaluation(steps, c.longEvaluation)
+}
+
+// longEvaluation runs on the evaluator goroutine, while the REPL
+// goroutine waits the result, so the input is read by one of them
+// at a time.
+func (c *Cli) longEvaluation(steps uint) bool {
+	fmt.Fprintf(c.out, "warning: %d steps without output, abort the evaluation? [y/N] ", steps)
+	c.flush()
+
+	answer, err := c.in.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(c.out)
+		return true
 	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return false
+	}
+
+	return true
+}
+
+// Close stops the evaluator goroutine of the Cli.
+func (c *Cli) Close() {
+	close(c.requests)
+}
+
+func (c *Cli) evaluator() {
+	for code := range c.requests {
+		val, err := c.js.Eval(code)
+		c.results <- result{val: val, err: err}
+	}
+}
+
+func (c *Cli) eval(code string) (types.Value, error) {
+	// WHY: an interrupt received after the previous evaluation
+	// ended is discarded before this one can be interrupted, so
+	// an interrupt received from now on stops it, even before
+	// the evaluator goroutine starts it.
+	c.js.ClearInterrupt()
+	atomic.StoreInt32(&c.state, running)
+	defer atomic.StoreInt32(&c.state, idle)
+
+	c.requests <- code
+	res := <-c.results
+	return res.val, res.err
 }
 
-func (c *Cli) ReadEval() {
+// ReadEval reads a line from the input and evaluates it.
+// It returns io.EOF when there is no more input to read.
+func (c *Cli) ReadEval() error {
 	fmt.Fprintf(c.out, "> ")
-	bio := bufio.NewReader(c.in)
-	line, err := bio.ReadString('\n')
+	c.flush()
+
+	line, err := c.in.ReadString('\n')
 	if err != nil {
-		c.error(err)
-		return
+		if err == io.EOF && line == "" {
+			fmt.Fprintln(c.out)
+			return io.EOF
+		}
+
+		if err != io.EOF {
+			c.error(err)
+			return err
+		}
 	}
 
 	line = trimnl(line)
 
-	obj, err := c.js.Eval(line)
+	obj, err := c.eval(line)
 	if err != nil {
 		c.error(err)
-		return
+		return nil
 	}
 
 	if obj != nil {
-		fmt.Fprintf(c.out, "< %s\n", obj.ToString().String())
+		fmt.Fprintf(c.out, "< %s\n", abad.FormatValue(obj, abad.ReplFormat))
 	}
 
+	return nil
 }
 
-func (c *Cli) Repl() {
+// Repl reads and evaluates lines until the input is over.
+func (c *Cli) Repl() error {
 	for {
-		c.ReadEval()
+		err := c.ReadEval()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// flush writes the buffered output, if out is buffered, so it's
+// seen before the REPL waits for input.
+func (c *Cli) flush() {
+	if out, ok := c.out.(flusher); ok {
+		_ = out.Flush()
 	}
 }
 
@@ -68,5 +198,5 @@ func (c *Cli) error(err error) {
 }
 
 func trimnl(line string) string {
-	return line[:len(line)-1]
+	return strings.TrimSuffix(line, "\n")
 }
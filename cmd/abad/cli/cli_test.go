@@ -1,10 +1,13 @@
 package cli_test
 
 import (
+	"bufio"
 	"bytes"
+	"io"
 	"strings"
 	"testing"
 
+	"github.com/NeowayLabs/abad"
 	"github.com/NeowayLabs/abad/cmd/abad/cli"
 	"github.com/madlambda/spells/assert"
 )
@@ -30,7 +33,7 @@ func TestCli(t *testing.T) {
 		},
 		{
 			in:  "1e-10",
-			out: "0.0000000001",
+			out: "1e-10",
 		},
 		{
 			in:  "1e10",
@@ -41,6 +44,7 @@ func TestCli(t *testing.T) {
 		var outb bytes.Buffer
 		cli, err := cli.NewCli(&inb, &outb)
 		assert.NoError(t, err, "failed to start the cli")
+		defer cli.Close()
 
 		_, err = inb.WriteString(tc.in + "\n")
 		assert.NoError(t, err)
@@ -51,3 +55,184 @@ func TestCli(t *testing.T) {
 		assert.EqualStrings(t, expected, got, "cli output")
 	}
 }
+
+func TestCliInterruptWhenIdle(t *testing.T) {
+	var inb bytes.Buffer
+	var outb bytes.Buffer
+	cli, err := cli.NewCli(&inb, &outb)
+	assert.NoError(t, err, "failed to start the cli")
+	defer cli.Close()
+
+	if cli.Interrupt() {
+		t.Fatal("no evaluation is running but got interrupted")
+	}
+
+	_, err = inb.WriteString("1\n")
+	assert.NoError(t, err)
+	err = cli.ReadEval()
+	assert.NoError(t, err, "evaluation after interrupt")
+	assert.EqualStrings(t, "> < 1", trim(outb.String()), "cli output")
+}
+
+func TestCliDiscardsStaleInterrupt(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	var inb bytes.Buffer
+	var outb bytes.Buffer
+	cli := cli.NewWithJS(js, &inb, &outb)
+	defer cli.Close()
+
+	// WHY: an interrupt received while idle was meant for an
+	// evaluation that already ended.
+	js.Interrupt()
+
+	_, err = inb.WriteString("1\n")
+	assert.NoError(t, err)
+	err = cli.ReadEval()
+	assert.NoError(t, err, "evaluation")
+	assert.EqualStrings(t, "> < 1", trim(outb.String()), "cli output")
+}
+
+func TestCliWarnAfter(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		answer string
+		out    string
+	}{
+		{
+			name:   "Abort",
+			answer: "y",
+			out:    "evaluation interrupted",
+		},
+		{
+			name:   "AbortUpperCase",
+			answer: " YES ",
+			out:    "evaluation interrupted",
+		},
+		{
+			name:   "Continue",
+			answer: "n",
+			out:    "< 4",
+		},
+		{
+			name:   "DefaultContinues",
+			answer: "",
+			out:    "< 4",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var inb bytes.Buffer
+			var outb bytes.Buffer
+			cli, err := cli.NewCli(&inb, &outb)
+			assert.NoError(t, err, "failed to start the cli")
+			defer cli.Close()
+
+			cli.WarnAfter(3)
+
+			_, err = inb.WriteString("1; 2; 3; 4\n" + tc.answer + "\n")
+			assert.NoError(t, err)
+			err = cli.ReadEval()
+			assert.NoError(t, err, "evaluation")
+
+			got := trim(outb.String())
+			want := "> warning: 3 steps without output, abort the evaluation? [y/N] " + tc.out
+			assert.EqualStrings(t, want, got, "cli output")
+		})
+	}
+}
+
+func TestCliInterruptTwice(t *testing.T) {
+	in, input := io.Pipe()
+	output, out := io.Pipe()
+	cli, err := cli.NewCli(in, out)
+	assert.NoError(t, err, "failed to start the cli")
+	defer cli.Close()
+
+	cli.WarnAfter(3)
+
+	done := make(chan error)
+	go func() {
+		done <- cli.ReadEval()
+	}()
+
+	outr := bufio.NewReader(output)
+	readUntil(t, outr, "> ")
+
+	go input.Write([]byte("1; 2; 3; 4\n"))
+
+	// WHY: the evaluation is blocked on the warning until it's
+	// answered, so the interrupt can't stop it before.
+	readUntil(t, outr, "[y/N] ")
+
+	if !cli.Interrupt() {
+		t.Fatal("the running evaluation was not interrupted")
+	}
+
+	if cli.Interrupt() {
+		t.Fatal("interrupted the same evaluation twice")
+	}
+
+	go input.Write([]byte("n\n"))
+	got := readUntil(t, outr, "\n")
+	assert.EqualStrings(t, "evaluation interrupted\n", got, "cli output")
+	assert.NoError(t, <-done, "evaluation")
+}
+
+// readUntil reads r until the read text ends with suffix.
+func readUntil(t *testing.T, r *bufio.Reader, suffix string) string {
+	t.Helper()
+
+	var got []byte
+	for !strings.HasSuffix(string(got), suffix) {
+		b, err := r.ReadByte()
+		assert.NoError(t, err, "reading %q", suffix)
+		got = append(got, b)
+	}
+
+	return string(got)
+}
+
+func TestCliFlushesBeforeInput(t *testing.T) {
+	var outb bytes.Buffer
+	out := bufio.NewWriter(&outb)
+	in := &lineReader{
+		lines: []string{"console.log(1); 2\n", "3\n"},
+		out:   &outb,
+	}
+
+	cli, err := cli.NewCli(in, out, abad.Sandbox(abad.CapConsole), abad.Stdout(out))
+	assert.NoError(t, err, "failed to start the cli")
+	defer cli.Close()
+
+	for range in.lines {
+		err = cli.ReadEval()
+		assert.NoError(t, err, "evaluation")
+	}
+
+	want := []string{"> ", "> 1\n< 2\n> "}
+	if len(in.seen) != len(want) {
+		t.Fatalf("got %d reads, want %d", len(in.seen), len(want))
+	}
+	for i := range want {
+		assert.EqualStrings(t, want[i], in.seen[i], "output seen by read %d", i)
+	}
+}
+
+// lineReader reads a line at a time, saving the output written
+// before each read.
+type lineReader struct {
+	lines []string
+	out   *bytes.Buffer
+	seen  []string
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	r.seen = append(r.seen, r.out.String())
+
+	if len(r.seen) > len(r.lines) {
+		return 0, io.EOF
+	}
+
+	return copy(p, r.lines[len(r.seen)-1]), nil
+}
@@ -6,69 +6,204 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/NeowayLabs/abad"
 	"github.com/NeowayLabs/abad/cmd/abad/cli"
+	"github.com/NeowayLabs/abad/lexer"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/sourcemap"
 )
 
-func repl() error {
+func repl(opts []abad.Option, warnSteps uint) error {
 
-	cli, err := cli.NewCli(os.Stdin, os.Stdout)
+	cli, err := cli.NewCli(os.Stdin, stdout, opts...)
 	if err != nil {
 		return err
 	}
+	defer cli.Close()
 
-	cli.Repl()
-	return nil
+	cli.WarnAfter(warnSteps)
+
+	// WHY: Ctrl-C interrupts the running evaluation and
+	// only exits when the REPL is waiting for input or when
+	// the evaluation was already interrupted, eg.: it's
+	// blocked on the prompt of a long evaluation.
+	stop := handleSignals(func(sig os.Signal) bool {
+		interrupted := cli.Interrupt()
+		if sig == syscall.SIGTERM || !interrupted {
+			fmt.Fprintln(stdout)
+			return false
+		}
+		return true
+	})
+	defer stop()
+
+	return cli.Repl()
 }
 
-func eval(codepath string) error {
-	code, err := ioutil.ReadFile(codepath)
-	if err != nil {
-		return err
+func eval(codepaths []string, opts []abad.Option) error {
+	var files []parser.File
+
+	// the files are named by their base names, the source maps
+	// are loaded from the directory of the file.
+	dirs := map[string]string{}
+
+	for _, codepath := range codepaths {
+		code, err := ioutil.ReadFile(codepath)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Base(codepath)
+		dirs[name] = filepath.Dir(codepath)
+		files = append(files, parser.File{
+			Name: name,
+			Code: string(code),
+		})
 	}
-	abadjs, err := abad.NewAbad()
+
+	opts = append(opts, abad.SourceMaps(func(file, url string) (*sourcemap.Map, error) {
+		return sourcemap.Load(url, dirs[file])
+	}))
+
+	return run(opts, func(abadjs *abad.Abad) error {
+		_, err := abadjs.EvalFiles(files)
+		return err
+	})
+}
+
+// run evaluates code with a new interpreter, interrupting the
+// evaluation and exiting when a signal is received. A second signal
+// exits right away, without waiting the evaluation to stop.
+func run(opts []abad.Option, evaluate func(*abad.Abad) error) error {
+	abadjs, err := abad.NewAbad(opts...)
 	if err != nil {
 		return err
 	}
-	_, err = abadjs.EvalFile(filepath.Base(codepath), string(code))
+
+	// only accessed by the goroutine handling the signals
+	interrupted := false
+	stop := handleSignals(func(os.Signal) bool {
+		if interrupted {
+			return false
+		}
+
+		interrupted = true
+		abadjs.Interrupt()
+		return true
+	})
+
+	err = evaluate(abadjs)
+	if sig := stop(); sig != nil {
+		exit(signalExitCode(sig))
+	}
+
 	return err
 }
 
 func main() {
 	var execute string
 	var help bool
+	var deterministic bool
+	var seed int64
+	var epoch int64
+	var sandbox string
+	var trailingCommas bool
+	var es6 bool
+	var strict bool
+	var timezone string
+	var warnSteps uint
+	var trapNaN bool
+	var printTokens bool
+	var tokensJSON bool
+	var unbuffered bool
 
 	flag.BoolVar(&help, "help", false, "prints usage")
 	flag.StringVar(&execute, "e", "", "execute code")
+	flag.BoolVar(&deterministic, "deterministic", false, "reproducible runs (seeded Math.random and frozen Date.now)")
+	flag.Int64Var(&seed, "seed", 0, "seed of Math.random on deterministic mode")
+	flag.Int64Var(&epoch, "epoch", 0, "Date.now in milliseconds on deterministic mode")
+	flag.StringVar(&sandbox, "sandbox", "cli", "sandbox profile (pure, cli or server)")
+	flag.BoolVar(&trailingCommas, "trailing-commas", false, "accept trailing commas in argument and parameter lists")
+	flag.BoolVar(&es6, "es6", false, "accept the binary and octal literals of ES6, eg.: 0b1010 and 0o755")
+	flag.BoolVar(&strict, "strict", false, "parse the code as strict mode code")
+	flag.StringVar(&timezone, "timezone", "", "local timezone of dates, eg.: UTC or America/Sao_Paulo (defaults to the host timezone)")
+	flag.UintVar(&warnSteps, "warn-steps", 10000000, "on the REPL, offer to abort evaluations running this many steps without output (0 disables)")
+	flag.BoolVar(&trapNaN, "trap-nan", false, "throw an error when an operation or builtin function produces NaN from operands that aren't NaN")
+	flag.BoolVar(&printTokens, "tokens", false, "only lex the code and print its tokens (line:column, type and value)")
+	flag.BoolVar(&tokensJSON, "json", false, "print the tokens as JSON objects, one per line, with -tokens")
+	flag.BoolVar(&unbuffered, "unbuffered", false, "write the output right away, instead of buffering it until abad exits or waits for input")
 	flag.Parse()
 
-	if help {
-		fmt.Println("Abad: the bad JS interpreter")
-		flag.PrintDefaults()
-		return
+	if !unbuffered {
+		bufferStdout()
 	}
 
-	if execute != "" {
-		abadjs, err := abad.NewAbad()
-		abortonerr(err)
-		_, err = abadjs.Eval(execute)
+	caps, err := abad.Profile(sandbox)
+	abortonerr(err)
+
+	opts := []abad.Option{abad.Sandbox(caps), abad.Stdout(stdout)}
+	var lexopts []lexer.Option
+	if deterministic {
+		epochtime := time.Unix(0, epoch*int64(time.Millisecond))
+		opts = append(opts, abad.Deterministic(seed, epochtime))
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
 		abortonerr(err)
-		return
+		opts = append(opts, abad.Timezone(loc))
+	}
+
+	if trailingCommas {
+		opts = append(opts, abad.ParserOptions(parser.TrailingCommas()))
+	}
+
+	if es6 {
+		opts = append(opts, abad.ParserOptions(parser.ES6()))
+		lexopts = append(lexopts, lexer.ES6())
+	}
+
+	if strict {
+		opts = append(opts, abad.ParserOptions(parser.Strict()))
+		lexopts = append(lexopts, lexer.Strict())
+	}
+
+	if trapNaN {
+		opts = append(opts, abad.TrapNaN())
+	}
+
+	if help {
+		fmt.Fprintln(stdout, "Abad: the bad JS interpreter")
+		flag.PrintDefaults()
+		exit(0)
 	}
 
-	if len(flag.Args()) == 0 {
-		abortonerr(repl())
-		return
+	if printTokens {
+		if execute != "" {
+			abortonerr(dumpTokens(stdout, "<interactive>", execute, tokensJSON, lexopts))
+		} else {
+			abortonerr(tokens(stdout, flag.Args(), tokensJSON, lexopts))
+		}
+	} else if execute != "" {
+		abortonerr(run(opts, func(abadjs *abad.Abad) error {
+			_, err := abadjs.Eval(execute)
+			return err
+		}))
+	} else if len(flag.Args()) == 0 {
+		abortonerr(repl(opts, warnSteps))
+	} else {
+		abortonerr(eval(flag.Args(), opts))
 	}
 
-	filepath := flag.Args()[0]
-	abortonerr(eval(filepath))
+	exit(0)
 }
 
 func abortonerr(err error) {
 	if err != nil {
-		fmt.Printf("error: %s\n", err)
-		os.Exit(1)
+		fmt.Fprintf(stdout, "error: %s\n", err)
+		exit(1)
 	}
 }
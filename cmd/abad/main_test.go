@@ -0,0 +1,239 @@
+package main_test
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/madlambda/spells/assert"
+)
+
+// Each sample inside testdata is run against the abad binary and
+// its output is compared with the golden file of the same name
+// (but with the .golden extension). The sample extension defines
+// how abad is invoked:
+//
+//	.js:   the sample is given as the script to run.
+//	.repl: the sample is piped to the stdin of the REPL.
+//	.args: each line of the sample is a command line argument.
+//
+// Directories are ignored, so they can hold files used by the samples.
+//
+// Run with -update to rewrite the golden files after an intended
+// change of behavior.
+var update = flag.Bool("update", false, "update golden files")
+
+var abadbin string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	tmpdir, err := ioutil.TempDir("", "abadgolden")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	abadbin = filepath.Join(tmpdir, "abad")
+	build := exec.Command("go", "build", "-o", abadbin, ".")
+	output, err := build.CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error building abad: %s\n%s\n", err, output)
+		os.RemoveAll(tmpdir)
+		os.Exit(1)
+	}
+
+	status := m.Run()
+	os.RemoveAll(tmpdir)
+	os.Exit(status)
+}
+
+func TestGolden(t *testing.T) {
+	samples, err := filepath.Glob(filepath.Join("testdata", "*"))
+	assert.NoError(t, err)
+
+	for _, sample := range samples {
+		info, err := os.Stat(sample)
+		assert.NoError(t, err)
+
+		ext := filepath.Ext(sample)
+		if ext == ".golden" || info.IsDir() {
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(sample), ext)
+		golden := strings.TrimSuffix(sample, ext) + ".golden"
+
+		t.Run(name, func(t *testing.T) {
+			got := runSample(t, sample)
+
+			if *update {
+				err := ioutil.WriteFile(golden, []byte(got), 0644)
+				assert.NoError(t, err, "updating golden file")
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			assert.NoError(t, err, "reading golden file")
+			assert.EqualStrings(t, string(want), got, "sample[%s]", sample)
+		})
+	}
+}
+
+func runSample(t *testing.T, sample string) string {
+	t.Helper()
+
+	var cmd *exec.Cmd
+
+	switch filepath.Ext(sample) {
+	case ".js":
+		cmd = exec.Command(abadbin, sample)
+	case ".repl":
+		input, err := os.Open(sample)
+		assert.NoError(t, err, "opening repl transcript")
+		defer input.Close()
+
+		cmd = exec.Command(abadbin)
+		cmd.Stdin = input
+	case ".args":
+		content, err := ioutil.ReadFile(sample)
+		assert.NoError(t, err, "reading arguments")
+
+		args := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		cmd = exec.Command(abadbin, args...)
+	default:
+		t.Fatalf("unknown sample type: %s", sample)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	exitcode := 0
+	err := cmd.Run()
+	if err != nil {
+		exiterr, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatalf("unable to run abad: %s", err)
+		}
+		exitcode = exiterr.Sys().(syscall.WaitStatus).ExitStatus()
+	}
+
+	return fmt.Sprintf("-- exitcode --\n%d\n-- stdout --\n%s-- stderr --\n%s",
+		exitcode, stdout, stderr)
+}
+
+func TestReplExitOnSignal(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sig      syscall.Signal
+		exitcode int
+	}{
+		{name: "SIGINT", sig: syscall.SIGINT, exitcode: 130},
+		{name: "SIGTERM", sig: syscall.SIGTERM, exitcode: 143},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(abadbin)
+
+			stdin, err := cmd.StdinPipe()
+			assert.NoError(t, err)
+			stdout, err := cmd.StdoutPipe()
+			assert.NoError(t, err)
+
+			assert.NoError(t, cmd.Start(), "starting repl")
+			defer stdin.Close()
+
+			// WHY: signals are handled only after the REPL
+			// starts, waiting an evaluation guarantees it.
+			_, err = stdin.Write([]byte("1\n"))
+			assert.NoError(t, err)
+
+			output := bufio.NewReader(stdout)
+			for {
+				line, err := output.ReadString('\n')
+				assert.NoError(t, err, "waiting evaluation")
+				if strings.Contains(line, "< 1") {
+					break
+				}
+			}
+
+			assert.NoError(t, cmd.Process.Signal(tc.sig), "sending signal")
+			ioutil.ReadAll(output)
+
+			err = cmd.Wait()
+			exiterr, ok := err.(*exec.ExitError)
+			if !ok {
+				t.Fatalf("expected exit error, got: %v", err)
+			}
+
+			status := exiterr.Sys().(syscall.WaitStatus)
+			assert.EqualInts(t, tc.exitcode, status.ExitStatus(), "exit code")
+		})
+	}
+}
+
+func TestReplExitOnSecondInterrupt(t *testing.T) {
+	cmd := exec.Command(abadbin, "-warn-steps", "3")
+
+	stdin, err := cmd.StdinPipe()
+	assert.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+
+	assert.NoError(t, cmd.Start(), "starting repl")
+	defer stdin.Close()
+
+	// WHY: the evaluation blocks on the prompt of the long
+	// evaluation, so only a second interrupt can stop abad.
+	_, err = stdin.Write([]byte("1; 2; 3; 4\n"))
+	assert.NoError(t, err)
+
+	output := bufio.NewReader(stdout)
+	var seen []byte
+	for !bytes.HasSuffix(seen, []byte("[y/N] ")) {
+		b, err := output.ReadByte()
+		assert.NoError(t, err, "waiting the prompt")
+		seen = append(seen, b)
+	}
+
+	exited := make(chan error)
+	go func() {
+		ioutil.ReadAll(output)
+		exited <- cmd.Wait()
+	}()
+
+	// WHY: signals received before the previous one is handled
+	// are dropped, so they're sent until abad exits.
+	var waiterr error
+	timeout := time.After(10 * time.Second)
+	for done := false; !done; {
+		assert.NoError(t, cmd.Process.Signal(syscall.SIGINT), "sending signal")
+
+		select {
+		case waiterr = <-exited:
+			done = true
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			cmd.Process.Kill()
+			t.Fatal("abad didn't exit on interrupts")
+		}
+	}
+
+	exiterr, ok := waiterr.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected exit error, got: %v", waiterr)
+	}
+
+	status := exiterr.Sys().(syscall.WaitStatus)
+	assert.EqualInts(t, 130, status.ExitStatus(), "exit code")
+}
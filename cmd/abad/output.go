@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+)
+
+// stdout is the output of abad, buffered unless -unbuffered is
+// given. It's flushed when abad exits and when the REPL waits for
+// input.
+var stdout io.Writer = os.Stdout
+
+// bufferedOutput is a buffered writer that can be flushed by the
+// exit hooks while the evaluation writes on it.
+type bufferedOutput struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+}
+
+// bufferStdout buffers the writes on stdout.
+func bufferStdout() {
+	out := &bufferedOutput{buf: bufio.NewWriter(os.Stdout)}
+	stdout = out
+
+	onExit(func() {
+		_ = out.Flush()
+	})
+}
+
+func (o *bufferedOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+// Flush writes the buffered data on the standard output.
+func (o *bufferedOutput) Flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Flush()
+}
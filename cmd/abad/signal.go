@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// exitSignals interrupt the running evaluation and
+// make abad exit with the conventional 128+signal code.
+var exitSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+var exitHooks struct {
+	sync.Mutex
+	fns []func()
+}
+
+// onExit registers fn to be called before abad exits.
+func onExit(fn func()) {
+	exitHooks.Lock()
+	defer exitHooks.Unlock()
+
+	exitHooks.fns = append(exitHooks.fns, fn)
+}
+
+// exit runs the exit hooks, in reverse order of registration,
+// and then exits with the given status code.
+func exit(code int) {
+	exitHooks.Lock()
+	fns := exitHooks.fns
+	exitHooks.fns = nil
+	exitHooks.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
+
+	os.Exit(code)
+}
+
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// handleSignals calls interrupt for each exit signal received.
+// If interrupt returns false, meaning that abad must not keep
+// running, it exits right away.
+//
+// The returned function stops the handling of signals and returns
+// the last signal received (nil if none).
+func handleSignals(interrupt func(os.Signal) bool) func() os.Signal {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, exitSignals...)
+
+	var (
+		mu       sync.Mutex
+		received os.Signal
+	)
+
+	go func() {
+		for sig := range sigs {
+			mu.Lock()
+			received = sig
+			mu.Unlock()
+
+			if !interrupt(sig) {
+				exit(signalExitCode(sig))
+			}
+		}
+	}()
+
+	return func() os.Signal {
+		signal.Stop(sigs)
+
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
@@ -0,0 +1,3 @@
+console.log("hello world");
+console.log(666, 0xFF, .1);
+console.log("%s is %d", "answer", 42);
@@ -0,0 +1,17 @@
+console.log("start");
+console.group("outer");
+console.log("in outer");
+console.count();
+console.count("x");
+console.groupCollapsed();
+console.log("multi\nline");
+console.count();
+console.groupEnd();
+console.countReset();
+console.count();
+console.groupEnd();
+console.groupEnd();
+console.time("t");
+console.timeEnd("t");
+console.timeEnd("t");
+console.log("end");
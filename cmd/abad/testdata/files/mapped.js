@@ -0,0 +1,3 @@
+console.log("before");
+console.doStuff();
+//# sourceMappingURL=mapped.js.map
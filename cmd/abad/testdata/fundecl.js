@@ -0,0 +1,10 @@
+greet("world");
+
+function greet(name) {
+	log("hello");
+	log(name);
+
+	function log(msg) {
+		console.log(msg);
+	}
+}
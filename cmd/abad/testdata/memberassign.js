@@ -0,0 +1,6 @@
+console.mylog = function (msg) {
+	console.log("mylog:");
+	console.log(msg);
+};
+
+console.mylog("hello");
@@ -0,0 +1,4 @@
+console.log("before");
+a = 1;
+console.doStuff(a);
+console.log("after");
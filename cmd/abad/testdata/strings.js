@@ -0,0 +1,7 @@
+/*
+ * String literals spanning lines.
+ */
+console.log("first line \
+still first line")
+console.log("tab:\tend") // escapes are decoded
+console.log("quote: \" backslash: \\ \x41ç")
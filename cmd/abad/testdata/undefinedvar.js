@@ -0,0 +1,3 @@
+console.log("before");
+angular;
+console.log("after");
@@ -0,0 +1 @@
+﻿console.log("encoded as UTF-8 ☃");
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/NeowayLabs/abad/lexer"
+	"github.com/NeowayLabs/abad/token"
+)
+
+// jsonToken is a token as printed by dumpTokens in JSON.
+type jsonToken struct {
+	File   string `json:"file"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Raw    string `json:"raw,omitempty"`
+	Line   uint   `json:"line"`
+	Column uint   `json:"column"`
+	Error  string `json:"error,omitempty"`
+}
+
+// tokens prints the tokens of the code of the given files, without
+// parsing or evaluating it.
+func tokens(w io.Writer, codepaths []string, asJSON bool, opts []lexer.Option) error {
+	if len(codepaths) == 0 {
+		return errors.New("-tokens needs the code of -e or script files")
+	}
+
+	for _, codepath := range codepaths {
+		code, err := ioutil.ReadFile(codepath)
+		if err != nil {
+			return err
+		}
+
+		file := filepath.Base(codepath)
+		if !asJSON {
+			_, err = fmt.Fprintf(w, "%s:\n", file)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = dumpTokens(w, file, string(code), asJSON, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dumpTokens prints the tokens of code one per line, up to the EOF
+// or the first illegal token, whose error is returned. The lines are
+// formatted by lexer.PrintTokens, or are JSON objects if asJSON is
+// true.
+func dumpTokens(w io.Writer, file string, code string, asJSON bool, opts []lexer.Option) error {
+	if !asJSON {
+		err := lexer.PrintTokens(w, lexer.Decode(code), opts...)
+		if lexerr, ok := err.(*lexer.Error); ok {
+			return fmt.Errorf("%s:%s", file, lexerr)
+		}
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	lx := lexer.New(lexer.Decode(code), opts...)
+
+	for {
+		tok := lx.Next()
+
+		jtok := jsonToken{
+			File:   file,
+			Type:   tok.Type.String(),
+			Value:  tok.Value.String(),
+			Raw:    tok.Raw.String(),
+			Line:   tok.Line,
+			Column: tok.Column,
+		}
+		if tok.Err != nil {
+			jtok.Error = tok.Err.Msg
+		}
+
+		err := enc.Encode(jtok)
+		if err != nil {
+			return err
+		}
+
+		switch tok.Type {
+		case token.EOF:
+			return nil
+		case token.Illegal:
+			return fmt.Errorf("%s:%s", file, tok.Err)
+		}
+	}
+}
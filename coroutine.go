@@ -0,0 +1,163 @@
+package abad
+
+import (
+	"fmt"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/types"
+)
+
+type (
+	// Coroutine is an evaluation that runs in slices, suspending
+	// at statement boundaries, so the host can time slice many
+	// scripts cooperatively. It runs on its own goroutine, but only
+	// while Resume waits for it, so suspended coroutines take no
+	// processor.
+	Coroutine struct {
+		a       *Abad
+		file    string
+		program *ast.Program
+
+		// until is the step at which the evaluation suspends,
+		// zero runs it to completion.
+		until uint
+
+		// entered is set at the first statement boundary, where
+		// the evaluation doesn't suspend as nothing ran yet.
+		entered bool
+
+		resume chan uint
+		events chan coroutineEvent
+
+		started bool
+		done    bool
+		val     types.Value
+		err     error
+	}
+
+	// coroutineEvent is sent when the evaluation suspends or, if
+	// done is set, ends.
+	coroutineEvent struct {
+		done bool
+		val  types.Value
+		err  error
+	}
+)
+
+// EvalCoroutine parses the code of filename and returns a coroutine
+// to evaluate it with Resume. The interpreter must not evaluate
+// other code until the coroutine is done.
+func (a *Abad) EvalCoroutine(filename string, code string) (*Coroutine, error) {
+	program, err := parser.Parse(filename, code, a.parserOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("parser error: %s", err)
+	}
+
+	return &Coroutine{
+		a:       a,
+		file:    filename,
+		program: program,
+		resume:  make(chan uint),
+		events:  make(chan coroutineEvent),
+	}, nil
+}
+
+// Resume runs the evaluation for at least steps evaluation steps,
+// as counted by EvalWithBudget, suspending it at the next statement
+// boundary. Zero steps runs it to completion. It returns true, with
+// the result of the evaluation, when the evaluation is done, then
+// the result is returned again by the next calls.
+func (c *Coroutine) Resume(steps uint) (bool, types.Value, error) {
+	if c.done {
+		return true, c.val, c.err
+	}
+
+	if c.started {
+		c.resume <- steps
+	} else {
+		c.started = true
+		c.a.begin()
+		c.slice(steps)
+		go c.run()
+	}
+
+	return c.wait()
+}
+
+// Cancel ends a suspended evaluation, which is done with
+// ErrInterrupted.
+func (c *Coroutine) Cancel() {
+	if c.done {
+		return
+	}
+
+	if !c.started {
+		c.done, c.err = true, ErrInterrupted
+		return
+	}
+
+	close(c.resume)
+	_, _, _ = c.wait()
+}
+
+func (c *Coroutine) run() {
+	a := c.a
+	a.file = c.file
+	a.coroutine = c
+	a.analyze(c.program)
+
+	val, err := a.eval(c.program)
+	a.coroutine = nil
+	if err == nil {
+		val = a.complete(val)
+	}
+
+	c.events <- coroutineEvent{done: true, val: val, err: err}
+}
+
+func (c *Coroutine) wait() (bool, types.Value, error) {
+	event := <-c.events
+	if event.done {
+		c.done, c.val, c.err = true, event.val, event.err
+	}
+
+	return event.done, event.val, event.err
+}
+
+// slice sets the end of the next slice of the evaluation.
+func (c *Coroutine) slice(steps uint) {
+	c.until = 0
+	if steps > 0 {
+		c.until = c.a.ops + steps
+	}
+}
+
+// checkpoint suspends the evaluation of a coroutine, at a statement
+// boundary, when its slice is over, failing with ErrInterrupted if
+// the coroutine is canceled.
+func (a *Abad) checkpoint() error {
+	c := a.coroutine
+	if c == nil {
+		return nil
+	}
+
+	if !c.entered {
+		c.entered = true
+		return nil
+	}
+
+	if c.until == 0 || a.ops < c.until {
+		return nil
+	}
+
+	c.events <- coroutineEvent{}
+
+	steps, ok := <-c.resume
+	if !ok {
+		return ErrInterrupted
+	}
+
+	c.slice(steps)
+	return nil
+}
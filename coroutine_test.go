@@ -0,0 +1,124 @@
+package abad_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+// newTicker creates an interpreter whose global tick counts how
+// many times it's read.
+func newTicker(t *testing.T, ticks *int) *abad.Abad {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	err = js.DefineAccessor("tick", func(types.Object, []types.Value) (types.Value, error) {
+		*ticks++
+		return types.NewNumber(float64(*ticks)), nil
+	}, nil)
+	assert.NoError(t, err, "defining tick")
+	return js
+}
+
+func TestCoroutineSlices(t *testing.T) {
+	var ticks int
+	js := newTicker(t, &ticks)
+
+	co, err := js.EvalCoroutine("test.js", "tick\nfunction f() { tick; tick }\nf()\ntick")
+	assert.NoError(t, err, "starting coroutine")
+
+	if ticks != 0 {
+		t.Fatalf("coroutine must not run before Resume, got %d ticks", ticks)
+	}
+
+	var slices []int
+	for {
+		done, val, err := co.Resume(1)
+		assert.NoError(t, err, "resuming")
+		slices = append(slices, ticks)
+
+		if done {
+			if !types.StrictEqual(types.NewNumber(4), val) {
+				t.Fatalf("got result %v, want 4", val)
+			}
+			break
+		}
+	}
+
+	want := []int{1, 1, 1, 2, 3, 4}
+	if len(slices) != len(want) {
+		t.Fatalf("got ticks %v after each slice, want %v", slices, want)
+	}
+	for i := range want {
+		if slices[i] != want[i] {
+			t.Fatalf("got ticks %v after each slice, want %v", slices, want)
+		}
+	}
+
+	done, val, err := co.Resume(1)
+	assert.NoError(t, err, "resuming done coroutine")
+	if !done || !types.StrictEqual(types.NewNumber(4), val) {
+		t.Fatalf("done coroutine must return its result, got %t, %v", done, val)
+	}
+}
+
+func TestCoroutineInterleaved(t *testing.T) {
+	var ticks int
+	var coroutines []*abad.Coroutine
+	for i := 0; i < 3; i++ {
+		co, err := newTicker(t, &ticks).EvalCoroutine("test.js", "tick; tick")
+		assert.NoError(t, err, "starting coroutine")
+		coroutines = append(coroutines, co)
+	}
+
+	for round := 1; round <= 2; round++ {
+		for i, co := range coroutines {
+			done, _, err := co.Resume(1)
+			assert.NoError(t, err, "resuming coroutine %d", i)
+
+			if want := (round-1)*3 + i + 1; ticks != want {
+				t.Fatalf("round %d coroutine %d: got %d ticks, want %d", round, i, ticks, want)
+			}
+			// the last statement completes the evaluation
+			if done != (round == 2) {
+				t.Fatalf("round %d coroutine %d: got done %t", round, i, done)
+			}
+		}
+	}
+}
+
+func TestCoroutineCancel(t *testing.T) {
+	var ticks int
+	js := newTicker(t, &ticks)
+
+	co, err := js.EvalCoroutine("test.js", "tick; tick")
+	assert.NoError(t, err, "starting coroutine")
+
+	_, _, err = co.Resume(1)
+	assert.NoError(t, err, "resuming")
+
+	co.Cancel()
+	done, _, err := co.Resume(0)
+	if !done || err != abad.ErrInterrupted {
+		t.Fatalf("got done %t, error %v, want done with %v", done, err, abad.ErrInterrupted)
+	}
+	if ticks != 1 {
+		t.Fatalf("canceled coroutine must not run, got %d ticks", ticks)
+	}
+
+	val, err := js.Eval("tick")
+	assert.NoError(t, err, "evaluating after cancel")
+	if !types.StrictEqual(types.NewNumber(2), val) {
+		t.Fatalf("got %v, want 2", val)
+	}
+}
+
+func TestCoroutineParseError(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.EvalCoroutine("test.js", "a.")
+	assert.Error(t, err, "parsing invalid code")
+}
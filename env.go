@@ -0,0 +1,217 @@
+package abad
+
+import (
+	"fmt"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/scope"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// environment holds the bindings of the global code or of a
+	// function call, linked to the environment of the code that
+	// declared the function. The bindings of a function call are
+	// slots, indexed by the scope analysis of the function, while
+	// the global environment and the one of EvalExprWithScope are
+	// bound to objects.
+	// https://es5.github.io/#x10.2
+	environment struct {
+		// bindings of an object environment, nil for function calls
+		bindings *types.DataObject
+
+		// scope and slots of a function call
+		scope *scope.Scope
+		slots *types.Slots
+
+		parent *environment
+
+		// this is the this value of the global code and of
+		// function calls, nil for the other environments.
+		this types.Value
+	}
+
+	// closure is what a user function captures where it's created:
+	// the environment and the scope analysis of the function.
+	closure struct {
+		env   *environment
+		scope *scope.Scope
+
+		// name of a named function expression, which is bound
+		// to the function in its own environment.
+		name utf16.Str
+	}
+)
+
+// newEnvironment creates an object environment, accounted in the
+// heap of the interpreter with the objects bound in it.
+func (a *Abad) newEnvironment(bindings *types.DataObject, parent *environment) *environment {
+	a.heap.TrackEnvironment(bindings)
+	return &environment{
+		bindings: bindings,
+		parent:   parent,
+	}
+}
+
+// newCallEnvironment creates the environment of a call of the
+// function analyzed as s, with a slot for each name it declares.
+func (a *Abad) newCallEnvironment(s *scope.Scope, parent *environment) *environment {
+	slots := types.NewSlots(s.Len())
+	a.heap.TrackSlots(slots)
+	return &environment{
+		scope:  s,
+		slots:  slots,
+		parent: parent,
+	}
+}
+
+// functionScope returns the scope analysis of the function fn, found
+// in the scope of the function being called or, outside of calls, of
+// the code being evaluated. A function not found there is analyzed
+// alone, as its slots don't depend on the enclosing code.
+func (a *Abad) functionScope(fn ast.Node) *scope.Scope {
+	s := a.scope
+	for env := a.env; env != nil; env = env.parent {
+		if env.scope != nil {
+			s = env.scope
+			break
+		}
+	}
+
+	if s != nil {
+		if fnscope, ok := s.Function(fn); ok {
+			return fnscope
+		}
+	}
+
+	global := scope.Analyze(&ast.Program{Nodes: []ast.Node{fn}}, nil).Global
+	fnscope, _ := global.Function(fn)
+	return fnscope
+}
+
+// thisValue returns the this value of the nearest global code or
+// function call.
+// https://es5.github.io/#x11.1.1
+func (e *environment) thisValue() types.Value {
+	for env := e; env != nil; env = env.parent {
+		if env.this != nil {
+			return env.this
+		}
+	}
+
+	return nil
+}
+
+// slot returns the slot of name if the environment is of a function
+// call declaring it.
+func (e *environment) slot(name utf16.Str) (int, bool) {
+	if e.scope == nil {
+		return 0, false
+	}
+
+	return e.scope.Slot(name.String())
+}
+
+// has tells if name is bound in this environment.
+func (e *environment) has(name utf16.Str) bool {
+	if e.scope != nil {
+		_, ok := e.slot(name)
+		return ok
+	}
+
+	return e.bindings.HasProperty(name)
+}
+
+// declare name in this environment, replacing any previous value.
+func (e *environment) declare(name utf16.Str, val types.Value) error {
+	if e.scope != nil {
+		slot, ok := e.slot(name)
+		if !ok {
+			return fmt.Errorf("%s has no slot in the scope of the function", name)
+		}
+
+		e.slots.Set(slot, val)
+		return nil
+	}
+
+	_, err := e.bindings.DefineOwnPropertyP(name,
+		types.NewDataPropDesc(val, true, true, false), true)
+	return err
+}
+
+// declareVar declares name undefined in this environment, unless it's
+// already declared in it, eg.: by a parameter or a function.
+// https://es5.github.io/#x10.5
+func (e *environment) declareVar(name utf16.Str) error {
+	return e.declareUnset(name, types.Undefined)
+}
+
+// declareUnset declares name with val in this environment, unless
+// it's already declared in it.
+func (e *environment) declareUnset(name utf16.Str, val types.Value) error {
+	if e.scope != nil {
+		slot, ok := e.slot(name)
+		if ok && e.slots.Get(slot) != nil {
+			return nil
+		}
+	} else if e.bindings.HasOwnProperty(name) {
+		return nil
+	}
+
+	return e.declare(name, val)
+}
+
+// lookup name walking the environment chain. Slots not set yet are
+// undefined.
+func (e *environment) lookup(name utf16.Str) (types.Value, bool, error) {
+	for env := e; env != nil; env = env.parent {
+		if env.scope == nil {
+			if env.bindings.HasProperty(name) {
+				val, err := env.bindings.Get(name)
+				return val, true, err
+			}
+			continue
+		}
+
+		if slot, ok := env.slot(name); ok {
+			val := env.slots.Get(slot)
+			if val == nil {
+				val = types.Undefined
+			}
+			return val, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// declared tells if name is declared in the environment chain.
+func (e *environment) declared(name utf16.Str) bool {
+	for env := e; env != nil; env = env.parent {
+		if env.has(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assign val to name in the environment declaring it. Undeclared
+// names are created in the global environment, as in non strict code.
+// https://es5.github.io/#x8.7.2
+func (e *environment) assign(name utf16.Str, val types.Value) error {
+	env := e
+	for ; env.parent != nil; env = env.parent {
+		if env.has(name) {
+			break
+		}
+	}
+
+	if slot, ok := env.slot(name); ok {
+		env.slots.Set(slot, val)
+		return nil
+	}
+
+	return env.bindings.Put(name, val, false)
+}
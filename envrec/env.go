@@ -3,8 +3,8 @@ package envrec
 import (
 	"fmt"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -5,8 +5,8 @@ import (
 	"testing"
 
 	"github.com/NeowayLabs/abad/envrec"
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 	"github.com/madlambda/spells/assert"
 )
 
@@ -0,0 +1,146 @@
+package abad
+
+import (
+	"fmt"
+
+	"github.com/NeowayLabs/abad/sourcemap"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// JSError is an exception thrown by the script and not caught
+	// by it. Eval returns it so the host can inspect the exception
+	// instead of parsing the error message.
+	JSError struct {
+		name    string
+		message string
+		frames  []Frame
+	}
+
+	// Frame is an entry of the stack trace of a JSError.
+	// Line and Column are zero when the position is unknown.
+	Frame struct {
+		Function string
+		File     string
+		Line     uint
+		Column   uint
+	}
+
+	// exception is implemented by the errors thrown by the builtin
+	// types, like types.TypeError.
+	exception interface {
+		error
+		Exception() bool
+		Name() string
+		Message() string
+	}
+)
+
+func newError(name string, format string, args ...interface{}) *JSError {
+	return &JSError{
+		name:    name,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func newReferenceError(format string, args ...interface{}) *JSError {
+	return newError("ReferenceError", format, args...)
+}
+
+func newTypeError(format string, args ...interface{}) *JSError {
+	return newError("TypeError", format, args...)
+}
+
+func newRangeError(format string, args ...interface{}) *JSError {
+	return newError("RangeError", format, args...)
+}
+
+// at sets the position in file where the error was thrown.
+func (e *JSError) at(file string, line, column uint) *JSError {
+	e.frames = []Frame{{
+		Function: "<anonymous>",
+		File:     file,
+		Line:     line,
+		Column:   column,
+	}}
+	return e
+}
+
+// Name of the error constructor, eg.: TypeError.
+func (e *JSError) Name() string { return e.name }
+
+// Message of the error.
+func (e *JSError) Message() string { return e.message }
+
+// StackFrames returns the stack trace of the error, innermost first.
+func (e *JSError) StackFrames() []Frame { return e.frames }
+
+// Value returns the thrown value as seen by the script.
+func (e *JSError) Value() types.Value {
+	obj := types.NewDataObject(types.Null)
+
+	// putting on a new object never fails
+	_ = obj.Put(utf16.S("name"), types.NewString(e.name), false)
+	_ = obj.Put(utf16.S("message"), types.NewString(e.message), false)
+	return obj
+}
+
+// Exception tells the error was thrown by the script.
+func (e *JSError) Exception() bool { return true }
+
+// Error is the name and the message of the error, followed by its
+// position if it's known.
+func (e *JSError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.name, e.message)
+	if len(e.frames) == 0 || e.frames[0].Line == 0 {
+		return msg
+	}
+
+	f := e.frames[0]
+	return fmt.Sprintf("%s (%s:%d:%d)", msg, f.File, f.Line, f.Column)
+}
+
+// uncaught converts the exceptions of the evaluation into a JSError
+// thrown in file, with the positions of its stack frames mapped by
+// the source map returned by smap, called only if a frame has a
+// position in file. Other errors (eg.: ErrInterrupted) are not
+// exceptions and are returned unchanged.
+func uncaught(err error, file string, smap func() *sourcemap.Map) error {
+	var jserr *JSError
+
+	switch e := err.(type) {
+	case *JSError:
+		jserr = e
+	case exception:
+		jserr = newError(e.Name(), "%s", e.Message())
+	default:
+		return err
+	}
+
+	if len(jserr.frames) == 0 {
+		jserr.frames = []Frame{{Function: "<anonymous>", File: file}}
+	}
+
+	var m *sourcemap.Map
+	for i, f := range jserr.frames {
+		if f.File != file || f.Line == 0 {
+			continue
+		}
+
+		if m == nil {
+			if m = smap(); m == nil {
+				break
+			}
+		}
+
+		pos, ok := m.Lookup(f.Line, f.Column)
+		if ok {
+			jserr.frames[i].File = pos.Source
+			jserr.frames[i].Line = pos.Line
+			jserr.frames[i].Column = pos.Column
+		}
+	}
+
+	return jserr
+}
@@ -0,0 +1,205 @@
+package abad
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/NeowayLabs/abad/types"
+	abadutf16 "github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// FormatOptions tells how FormatValue formats values.
+	FormatOptions struct {
+		// Quote strings, as a REPL echoes them. Strings inside
+		// objects are always quoted.
+		Quote bool
+
+		// MaxItems is the number of elements or properties of
+		// an object that are shown, the others are elided.
+		// Zero shows all of them.
+		MaxItems int
+
+		// Depth is how deep nested objects are shown, deeper
+		// objects are abbreviated. Zero shows only the top level.
+		Depth int
+	}
+
+	formatter struct {
+		opts FormatOptions
+
+		// objects being formatted, to detect cycles
+		seen map[types.Object]bool
+	}
+)
+
+var (
+	// ReplFormat is how the REPL echoes the evaluated values.
+	ReplFormat = FormatOptions{Quote: true, MaxItems: 100, Depth: 2}
+
+	// ConsoleFormat is how console.log writes its arguments.
+	ConsoleFormat = FormatOptions{Quote: false, MaxItems: 100, Depth: 2}
+)
+
+// FormatValue formats v for humans, eg.: on console.log or the
+// REPL echo. Unlike ToString, objects are shown by their contents,
+// without calling any script code, and cycles are marked as
+// [Circular].
+func FormatValue(v types.Value, opts FormatOptions) string {
+	f := formatter{
+		opts: opts,
+		seen: map[types.Object]bool{},
+	}
+
+	if str, ok := v.(types.String); ok && !opts.Quote {
+		return str.String()
+	}
+
+	return f.format(v, 0)
+}
+
+func (f *formatter) format(v types.Value, depth int) string {
+	switch val := v.(type) {
+	case types.String:
+		return quote(abadutf16.Str(val))
+	case *types.StringObject:
+		return fmt.Sprintf("[String: %s]", quote(abadutf16.Str(val.PrimitiveValue())))
+	case *types.Builtinfn:
+		return formatFunction(val.Name())
+	case *types.UserFunction:
+		return formatFunction(val.Name())
+	case *types.Slice:
+		return f.formatObject(val, depth, "[", "]", val.Values(), nil)
+	case types.Object:
+		return f.formatObject(val, depth, "{", "}", nil, val.OwnPropertyKeys(types.EnumerableKeys))
+	}
+
+	return v.ToString().String()
+}
+
+// formatObject formats the elements and then the properties of obj.
+func (f *formatter) formatObject(
+	obj types.Object, depth int, open, close string,
+	elems []types.Value, keys []abadutf16.Str,
+) string {
+	if f.seen[obj] {
+		return "[Circular]"
+	}
+
+	if len(elems) == 0 && len(keys) == 0 {
+		return open + close
+	}
+
+	if depth > f.opts.Depth {
+		if open == "[" {
+			return "[Array]"
+		}
+		return "[Object]"
+	}
+
+	f.seen[obj] = true
+	defer delete(f.seen, obj)
+
+	var items []string
+	total := len(elems) + len(keys)
+	limit := total
+	if f.opts.MaxItems > 0 && f.opts.MaxItems < total {
+		limit = f.opts.MaxItems
+	}
+
+	for i := 0; i < limit; i++ {
+		if i < len(elems) {
+			items = append(items, f.format(elems[i], depth+1))
+			continue
+		}
+
+		key := keys[i-len(elems)]
+		val, err := obj.Get(key)
+		if err != nil {
+			val = types.Undefined
+		}
+
+		items = append(items, fmt.Sprintf("%s: %s", formatKey(key), f.format(val, depth+1)))
+	}
+
+	if limit < total {
+		items = append(items, fmt.Sprintf("... %d more items", total-limit))
+	}
+
+	return fmt.Sprintf("%s %s %s", open, strings.Join(items, ", "), close)
+}
+
+func formatFunction(name abadutf16.Str) string {
+	if len(name) == 0 {
+		return "[Function (anonymous)]"
+	}
+
+	return fmt.Sprintf("[Function: %s]", name)
+}
+
+// formatKey quotes the property key unless it's an identifier.
+func formatKey(key abadutf16.Str) string {
+	name := key.String()
+	for i, r := range name {
+		isStart := r == '$' || r == '_' || unicode.IsLetter(r)
+		if !isStart && (i == 0 || !unicode.IsDigit(r)) {
+			return quote(key)
+		}
+	}
+
+	if name == "" {
+		return `""`
+	}
+
+	return name
+}
+
+// quote str as a string literal, escaping what can't be written as is.
+func quote(str abadutf16.Str) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	for i := 0; i < len(str); i++ {
+		unit := str[i]
+
+		// valid surrogate pairs are written as is
+		if utf16.IsSurrogate(rune(unit)) && i+1 < len(str) {
+			r := utf16.DecodeRune(rune(unit), rune(str[i+1]))
+			if r != unicode.ReplacementChar {
+				b.WriteRune(r)
+				i++
+				continue
+			}
+		}
+
+		switch r := rune(unit); {
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\b':
+			b.WriteString(`\b`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == '\v':
+			b.WriteString(`\v`)
+		case r < 0x20 || r == 0x7F:
+			fmt.Fprintf(&b, `\x%02X`, r)
+		case utf16.IsSurrogate(r), r == '\u2028', r == '\u2029':
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
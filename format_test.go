@@ -0,0 +1,125 @@
+package abad_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad"
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestFormatValue(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		opts abad.FormatOptions
+		want string
+	}{
+		{
+			name: "Number",
+			code: "1.5",
+			opts: abad.ReplFormat,
+			want: "1.5",
+		},
+		{
+			name: "Undefined",
+			code: "undefined",
+			opts: abad.ReplFormat,
+			want: "undefined",
+		},
+		{
+			name: "QuotedString",
+			code: `"say \"hi\"\n"`,
+			opts: abad.ReplFormat,
+			want: `"say \"hi\"\n"`,
+		},
+		{
+			name: "RawString",
+			code: `"say \"hi\""`,
+			opts: abad.ConsoleFormat,
+			want: `say "hi"`,
+		},
+		{
+			name: "LoneSurrogate",
+			code: `"😀\uD83D"`,
+			opts: abad.ReplFormat,
+			want: `"😀\uD83D"`,
+		},
+		{
+			name: "Function",
+			code: "function f() {} f",
+			opts: abad.ReplFormat,
+			want: "[Function: f]",
+		},
+		{
+			name: "AnonymousFunction",
+			code: "Math.f = function () {}; Math.f",
+			opts: abad.ReplFormat,
+			want: "[Function (anonymous)]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad()
+			assert.NoError(t, err, "failed to start interpreter")
+
+			val, err := js.Eval(tc.code)
+			assert.NoError(t, err, "evaluating %s", tc.code)
+
+			got := abad.FormatValue(val, tc.opts)
+			assert.EqualStrings(t, tc.want, got, "formatted value")
+		})
+	}
+}
+
+func TestFormatObject(t *testing.T) {
+	S := utf16.S
+
+	obj := types.NewBaseDataObject()
+	inner := types.NewBaseDataObject()
+	deeper := types.NewBaseDataObject()
+
+	put := func(o types.Object, key string, val types.Value) {
+		err := o.Put(S(key), val, true)
+		assert.NoError(t, err, "putting %s", key)
+	}
+
+	put(deeper, "end", types.True)
+	put(inner, "deeper", deeper)
+	put(obj, "a", types.NewNumber(1))
+	put(obj, "b c", types.NewString("x"))
+	put(obj, "inner", inner)
+	put(obj, "self", obj)
+	put(obj, "list", types.NewSlice([]types.Value{
+		types.NewNumber(1), types.NewString("2"), types.Null,
+	}, types.SliceShared))
+
+	for _, tc := range []struct {
+		name string
+		opts abad.FormatOptions
+		want string
+	}{
+		{
+			name: "Default",
+			opts: abad.ReplFormat,
+			want: `{ a: 1, "b c": "x", inner: { deeper: { end: true } }, ` +
+				`self: [Circular], list: [ 1, "2", null ] }`,
+		},
+		{
+			name: "Shallow",
+			opts: abad.FormatOptions{Depth: 1},
+			want: `{ a: 1, "b c": "x", inner: { deeper: [Object] }, ` +
+				`self: [Circular], list: [ 1, "2", null ] }`,
+		},
+		{
+			name: "Truncated",
+			opts: abad.FormatOptions{MaxItems: 2, Depth: 2},
+			want: `{ a: 1, "b c": "x", ... 3 more items }`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := abad.FormatValue(obj, tc.opts)
+			assert.EqualStrings(t, tc.want, got, "formatted object")
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Package numparse converts the textual representations of numbers
+// into float64, so numeric literals and the runtime conversions
+// agree on the edge cases.
+package numparse
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrSyntax is returned when the string is not a valid number.
+var ErrSyntax = errors.New("invalid number syntax")
+
+// Literal converts a numeric literal, decimal, hexadecimal, binary,
+// octal or legacy octal, to a float64. Literals too big to be
+// represented are Infinity.
+// http://es5.github.io/#x7.8.3
+// http://es5.github.io/#B.1.1
+// http://www.ecma-international.org/ecma-262/6.0/#sec-literals-numeric-literals
+func Literal(s string) (float64, error) {
+	if isHex(s) {
+		return hex(s[2:])
+	}
+
+	if hasPrefix(s, 'b') {
+		return integer(s[2:], 2)
+	}
+
+	if hasPrefix(s, 'o') {
+		return integer(s[2:], 8)
+	}
+
+	if isLegacyOctal(s) {
+		return octal(s[1:]), nil
+	}
+
+	return decimal(s)
+}
+
+// String converts a string to a number as the ToNumber abstract
+// operation does: surrounding white spaces are ignored, the empty
+// string is 0 and invalid numbers are NaN.
+// http://es5.github.io/#x9.3.1
+func String(s string) float64 {
+	s = strings.TrimFunc(s, isStrWhiteSpace)
+	if s == "" {
+		return 0
+	}
+
+	if isHex(s) {
+		f, err := hex(s[2:])
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+
+	sign := 1.0
+	unsigned := s
+	switch s[0] {
+	case '-':
+		sign = -1
+		unsigned = s[1:]
+	case '+':
+		unsigned = s[1:]
+	}
+
+	if unsigned == "Infinity" {
+		return math.Inf(int(sign))
+	}
+
+	f, err := decimal(unsigned)
+	if err != nil {
+		return math.NaN()
+	}
+
+	return sign * f
+}
+
+// Int parses the integer at the start of s, in the radix from 2 to
+// 36, as parseInt does. Leading white spaces are ignored and the
+// parsing stops at the first character that's not a digit of the
+// radix. The radix zero is 10, or 16 if s starts with 0x or 0X, and
+// the 0x prefix is also skipped with the radix 16. It's NaN if there
+// are no digits or the radix is out of range.
+// http://es5.github.io/#x15.1.2.2
+func Int(s string, radix int) float64 {
+	s = strings.TrimLeftFunc(s, isStrWhiteSpace)
+
+	sign := 1.0
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+
+	stripPrefix := true
+	if radix != 0 {
+		if radix < 2 || radix > 36 {
+			return math.NaN()
+		}
+
+		stripPrefix = radix == 16
+	} else {
+		radix = 10
+	}
+
+	if stripPrefix && len(s) >= 2 && s[0] == '0' && (s[1]|0x20) == 'x' {
+		s = s[2:]
+		radix = 16
+	}
+
+	end := 0
+	for end < len(s) {
+		v, ok := digitValue(rune(s[end]))
+		if !ok || v >= radix {
+			break
+		}
+		end++
+	}
+
+	if end == 0 {
+		return math.NaN()
+	}
+
+	if radix == 10 {
+		// WHY: correctly rounded, even with more than 20
+		// significant digits.
+		f, _ := strconv.ParseFloat(s[:end], 64)
+		return sign * f
+	}
+
+	var f float64
+	for _, d := range s[:end] {
+		v, _ := digitValue(d)
+		f = f*float64(radix) + float64(v)
+	}
+
+	return sign * f
+}
+
+func isHex(s string) bool {
+	return hasPrefix(s, 'x')
+}
+
+// hasPrefix tells if s starts with a zero followed by the
+// letter, in lower or upper case, eg.: 0x or 0X.
+func hasPrefix(s string, letter byte) bool {
+	return len(s) > 2 && s[0] == '0' && (s[1]|0x20) == letter
+}
+
+func hex(digits string) (float64, error) {
+	return integer(digits, 16)
+}
+
+// integer converts the digits of an integer in base 2, 8 or 16.
+func integer(digits string, base int) (float64, error) {
+	if digits == "" {
+		return 0, ErrSyntax
+	}
+
+	// WHY: literals can have any number of digits,
+	// strconv.ParseInt fails beyond 64 bits.
+	var f float64
+	for _, d := range digits {
+		v, ok := digitValue(d)
+		if !ok || v >= base {
+			return 0, ErrSyntax
+		}
+		f = f*float64(base) + float64(v)
+	}
+
+	return f, nil
+}
+
+// isLegacyOctal tells if s is a zero followed by octal digits.
+// Zeros followed by other digits, eg.: 08, are decimals.
+func isLegacyOctal(s string) bool {
+	if len(s) < 2 || s[0] != '0' {
+		return false
+	}
+
+	for i := 1; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '7' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func octal(digits string) float64 {
+	var f float64
+	for _, d := range digits {
+		f = f*8 + float64(d-'0')
+	}
+
+	return f
+}
+
+// digitValue is the value of a digit of the radixes up to 36, the
+// letters from a to z, in lower or upper case, are 10 to 35.
+func digitValue(d rune) (int, bool) {
+	switch {
+	case d >= '0' && d <= '9':
+		return int(d - '0'), true
+	case d >= 'a' && d <= 'z':
+		return int(d-'a') + 10, true
+	case d >= 'A' && d <= 'Z':
+		return int(d-'A') + 10, true
+	}
+
+	return 0, false
+}
+
+// decimal converts an unsigned decimal, eg.: 1, 1.5, .5, 1., 1e10.
+// strconv.ParseFloat accepts more (hex floats, Inf, NaN and
+// underscores), then the syntax is checked first.
+func decimal(s string) (float64, error) {
+	if !isDecimal(s) {
+		return 0, ErrSyntax
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, ErrSyntax
+	}
+
+	// out of range values are ±Inf or 0, as in the spec
+	return f, nil
+}
+
+func isDecimal(s string) bool {
+	i := 0
+	digits := 0
+
+	for i < len(s) && isDigit(s[i]) {
+		i++
+		digits++
+	}
+
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isDigit(s[i]) {
+			i++
+			digits++
+		}
+	}
+
+	if digits == 0 {
+		return false
+	}
+
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+
+		expdigits := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+			expdigits++
+		}
+
+		if expdigits == 0 {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// http://es5.github.io/#x7.2
+// http://es5.github.io/#x7.3
+func isStrWhiteSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', ' ', '\u00A0', '\uFEFF',
+		'\n', '\r', '\u2028', '\u2029':
+		return true
+	}
+
+	return unicode.Is(unicode.Zs, r)
+}
@@ -0,0 +1,172 @@
+package numparse_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/NeowayLabs/abad/internal/numparse"
+)
+
+func TestLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		lit  string
+		want float64
+		fail bool
+	}{
+		{lit: "0", want: 0},
+		{lit: "10", want: 10},
+		{lit: "1.5", want: 1.5},
+		{lit: ".5", want: 0.5},
+		{lit: "5.", want: 5},
+		{lit: "1e3", want: 1000},
+		{lit: "1E-3", want: 0.001},
+		{lit: "1.5e+2", want: 150},
+		{lit: "1e400", want: math.Inf(1)},
+		{lit: "1e-400", want: 0},
+		{lit: "0xff", want: 255},
+		{lit: "0XFF", want: 255},
+		{lit: "0x10000000000000000", want: math.Pow(2, 64)},
+		{lit: "0777", want: 511},
+		{lit: "00", want: 0},
+		{lit: "08", want: 8},
+		{lit: "019", want: 19},
+		{lit: "0b1010", want: 10},
+		{lit: "0B11", want: 3},
+		{lit: "0o755", want: 493},
+		{lit: "0O17", want: 15},
+		{lit: "0b", fail: true},
+		{lit: "0b2", fail: true},
+		{lit: "0o8", fail: true},
+		{lit: "0x", fail: true},
+		{lit: "0xG", fail: true},
+		{lit: ".", fail: true},
+		{lit: "1e", fail: true},
+		{lit: "1e+", fail: true},
+		{lit: "1_000", fail: true},
+		{lit: "Inf", fail: true},
+		{lit: "0x1p-2", fail: true},
+	} {
+		t.Run(tc.lit, func(t *testing.T) {
+			got, err := numparse.Literal(tc.lit)
+			if tc.fail {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.want {
+				t.Fatalf("got %v but want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	nan := math.NaN()
+
+	for _, tc := range []struct {
+		str  string
+		want float64
+	}{
+		{str: "", want: 0},
+		{str: " \t\n  ", want: 0},
+		{str: "  12  ", want: 12},
+		{str: "-1.5", want: -1.5},
+		{str: "+.5e1", want: 5},
+		{str: "0x1F", want: 31},
+		{str: "-0x1F", want: nan},
+		{str: "Infinity", want: math.Inf(1)},
+		{str: "-Infinity", want: math.Inf(-1)},
+		{str: "+Infinity", want: math.Inf(1)},
+		{str: "infinity", want: nan},
+		{str: "Inf", want: nan},
+		{str: "NaN", want: nan},
+		{str: "1e1000", want: math.Inf(1)},
+		{str: "-1e1000", want: math.Inf(-1)},
+		{str: "12px", want: nan},
+		{str: "1 2", want: nan},
+		{str: "-", want: nan},
+	} {
+		t.Run(tc.str, func(t *testing.T) {
+			got := numparse.String(tc.str)
+			if math.IsNaN(tc.want) {
+				if !math.IsNaN(got) {
+					t.Fatalf("got %v but want NaN", got)
+				}
+				return
+			}
+
+			if got != tc.want {
+				t.Fatalf("got %v but want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	nan := math.NaN()
+
+	for _, tc := range []struct {
+		str   string
+		radix int
+		want  float64
+	}{
+		{str: "15", want: 15},
+		{str: "  \t\n15", want: 15},
+		{str: "15.99", want: 15},
+		{str: "15e2", want: 15},
+		{str: "15px", want: 15},
+		{str: "-15", want: -15},
+		{str: "+15", want: 15},
+		{str: "-0", want: math.Copysign(0, -1)},
+		{str: "012", want: 12},
+		{str: "0x1F", want: 31},
+		{str: "0X1f", want: 31},
+		{str: "-0xF", want: -15},
+		{str: "0x", want: nan},
+		{str: "0xG", want: nan},
+		{str: "", want: nan},
+		{str: "-", want: nan},
+		{str: "- 1", want: nan},
+		{str: "+-1", want: nan},
+		{str: "Infinity", want: nan},
+		{str: "abc", want: nan},
+		{str: "123456789012345678901234567890", want: 123456789012345678901234567890},
+		{str: "F", radix: 16, want: 15},
+		{str: "0xF", radix: 16, want: 15},
+		{str: "0x10", radix: 10, want: 0},
+		{str: "0x10", radix: 36, want: 42804},
+		{str: "17", radix: 8, want: 15},
+		{str: "018", radix: 8, want: 1},
+		{str: "8", radix: 8, want: nan},
+		{str: "1111", radix: 2, want: 15},
+		{str: "-1012", radix: 2, want: -5},
+		{str: "z", radix: 36, want: 35},
+		{str: "Zz", radix: 36, want: 1295},
+		{str: "hello", radix: 36, want: 29234652},
+		{str: "z", radix: 35, want: nan},
+		{str: "15", radix: 1, want: nan},
+		{str: "15", radix: 37, want: nan},
+		{str: "15", radix: -16, want: nan},
+	} {
+		t.Run(fmt.Sprintf("%s/%d", tc.str, tc.radix), func(t *testing.T) {
+			got := numparse.Int(tc.str, tc.radix)
+			if math.IsNaN(tc.want) {
+				if !math.IsNaN(got) {
+					t.Fatalf("got %v but want NaN", got)
+				}
+				return
+			}
+
+			if got != tc.want || math.Signbit(got) != math.Signbit(tc.want) {
+				t.Fatalf("got %v but want %v", got, tc.want)
+			}
+		})
+	}
+}
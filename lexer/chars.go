@@ -0,0 +1,127 @@
+package lexer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// charClass is a bit set of the classes of an ASCII character, so
+// the hot paths of the lexer classify characters with a table lookup
+// instead of scanning lists of runes or the unicode tables.
+type charClass uint8
+
+const (
+	classDigit charClass = 1 << iota
+	classHexDigit
+	classOctalDigit
+	classBinaryDigit
+	classIdentStart
+	classIdentPart
+	classWhiteSpace
+	classLineTerminator
+)
+
+var asciiClasses [128]charClass
+
+func init() {
+	for r := '0'; r <= '9'; r++ {
+		asciiClasses[r] |= classDigit | classHexDigit | classIdentPart
+	}
+	for r := '0'; r <= '7'; r++ {
+		asciiClasses[r] |= classOctalDigit
+	}
+	asciiClasses['0'] |= classBinaryDigit
+	asciiClasses['1'] |= classBinaryDigit
+
+	for r := 'a'; r <= 'z'; r++ {
+		asciiClasses[r] |= classIdentStart | classIdentPart
+		asciiClasses[r-'a'+'A'] |= classIdentStart | classIdentPart
+	}
+	for _, r := range "abcdefABCDEF" {
+		asciiClasses[r] |= classHexDigit
+	}
+	for _, r := range "$_" {
+		asciiClasses[r] |= classIdentStart | classIdentPart
+	}
+
+	// http://es5.github.io/#x7.2
+	for _, r := range "\t\v\f " {
+		asciiClasses[r] |= classWhiteSpace
+	}
+	// http://es5.github.io/#x7.3
+	for _, r := range "\n\r" {
+		asciiClasses[r] |= classLineTerminator
+	}
+}
+
+func isASCIIClass(r rune, class charClass) bool {
+	return r >= 0 && r < rune(len(asciiClasses)) && asciiClasses[r]&class != 0
+}
+
+func isDigit(r rune) bool {
+	return isASCIIClass(r, classDigit)
+}
+
+func isHexDigit(r rune) bool {
+	return isASCIIClass(r, classHexDigit)
+}
+
+func isOctalDigit(r rune) bool {
+	return isASCIIClass(r, classOctalDigit)
+}
+
+func isBinaryDigit(r rune) bool {
+	return isASCIIClass(r, classBinaryDigit)
+}
+
+// isLineTerminator tells if r is <LF>, <CR>, <LS> or <PS>.
+// http://es5.github.io/#x7.3
+func isLineTerminator(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIClass(r, classLineTerminator)
+	}
+	return r == lineSep || r == paragraphSep
+}
+
+// isWhiteSpace tells if r is <TAB>, <VT>, <FF>, <SP>, <NBSP>, <BOM>
+// or in the Unicode space separator category (Zs).
+// http://es5.github.io/#x7.2
+func isWhiteSpace(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIClass(r, classWhiteSpace)
+	}
+	return r == noBreakSpace || r == byteOrderMark || unicode.Is(unicode.Zs, r)
+}
+
+// isIdentifierStart tells if r is a UnicodeLetter, $ or _. Unicode
+// escape sequences are not supported yet.
+// http://es5.github.io/#x7.6
+func isIdentifierStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIClass(r, classIdentStart)
+	}
+	return unicode.In(r, unicodeLetters...)
+}
+
+// isIdentifierPart tells if r is an IdentifierStart, a
+// UnicodeCombiningMark, a UnicodeDigit, a
+// UnicodeConnectorPunctuation, <ZWNJ> or <ZWJ>.
+// http://es5.github.io/#x7.6
+func isIdentifierPart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIClass(r, classIdentPart)
+	}
+	return r == zwnj || r == zwj || unicode.In(r, unicodeLetters...) ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+const (
+	lineSep       = '\u2028'
+	paragraphSep  = '\u2029'
+	noBreakSpace  = '\u00A0'
+	byteOrderMark = '\uFEFF'
+)
+
+var unicodeLetters = []*unicode.RangeTable{
+	unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl,
+}
@@ -0,0 +1,186 @@
+package lexer
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf16"
+
+	abadutf16 "github.com/NeowayLabs/abad/utf16"
+)
+
+// singleEscapes are the escape sequences of a single character.
+// http://es5.github.io/#x7.8.4
+var singleEscapes = map[rune]uint16{
+	'b':  0x8,
+	't':  0x9,
+	'n':  0xA,
+	'v':  0xB,
+	'f':  0xC,
+	'r':  0xD,
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
+}
+
+// cookString decodes the escape sequences of the raw string
+// literal, without quotes. It returns false if some escape
+// sequence is invalid.
+//
+// Escaped line terminators are line continuations and are dropped.
+// The code units of \x and \u escapes are kept as is, even if they
+// are lone surrogates. Legacy octal escapes are not supported.
+func cookString(raw []rune) (abadutf16.Str, bool) {
+	val := make(abadutf16.Str, 0, len(raw))
+
+	for i := 0; i < len(raw); i++ {
+		r := raw[i]
+		if r != backslash {
+			val = appendRune(val, r)
+			continue
+		}
+
+		i++
+		if i >= len(raw) {
+			return nil, false
+		}
+
+		r = raw[i]
+
+		if unit, ok := singleEscapes[r]; ok {
+			val = append(val, unit)
+			continue
+		}
+
+		switch {
+		case isLineTerminator(r):
+			// <CR><LF> is a single line terminator
+			if r == carriageRet && i+1 < len(raw) && raw[i+1] == linefeed {
+				i++
+			}
+		case r == '0':
+			if i+1 < len(raw) && isDigit(raw[i+1]) {
+				return nil, false
+			}
+			val = append(val, 0)
+		case isDigit(r):
+			return nil, false
+		case r == 'x' || r == 'u':
+			size := 2
+			if r == 'u' {
+				size = 4
+			}
+
+			if i+size >= len(raw) {
+				return nil, false
+			}
+
+			unit, err := strconv.ParseUint(string(raw[i+1:i+1+size]), 16, 16)
+			if err != nil {
+				return nil, false
+			}
+
+			val = append(val, uint16(unit))
+			i += size
+		default:
+			val = appendRune(val, r)
+		}
+	}
+
+	return val, true
+}
+
+// identifierEscape decodes the unicode escape sequence at the start
+// of raw, eg.: \u0061, returning the escaped character and the size
+// of the sequence. Identifiers have no other escape sequences.
+// http://es5.github.io/#x7.6
+func identifierEscape(raw []rune) (rune, int, bool) {
+	const size = 6
+
+	if len(raw) < size || raw[0] != backslash || raw[1] != 'u' {
+		return 0, 0, false
+	}
+
+	for _, r := range raw[2:size] {
+		if !isHexDigit(r) {
+			return 0, 0, false
+		}
+	}
+
+	unit, err := strconv.ParseUint(string(raw[2:size]), 16, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return rune(unit), size, true
+}
+
+// cookIdentifier decodes the unicode escape sequences of the raw
+// identifier, that were already validated.
+func cookIdentifier(raw []rune) []rune {
+	name := make([]rune, 0, len(raw))
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != backslash {
+			name = append(name, raw[i])
+			continue
+		}
+
+		r, size, _ := identifierEscape(raw[i:])
+		name = append(name, r)
+		i += size - 1
+	}
+
+	return name
+}
+
+// normalizeLineTerminators replaces <CR><LF> and <CR> by <LF>,
+// as the line terminators of templates are read.
+func normalizeLineTerminators(raw []rune) []rune {
+	norm := make([]rune, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != carriageRet {
+			norm = append(norm, raw[i])
+			continue
+		}
+
+		if i+1 < len(raw) && raw[i+1] == linefeed {
+			i++
+		}
+		norm = append(norm, linefeed)
+	}
+
+	return norm
+}
+
+func appendRune(s abadutf16.Str, r rune) abadutf16.Str {
+	if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+		return append(s, uint16(r1), uint16(r2))
+	}
+
+	return append(s, uint16(r))
+}
+
+// decodeRunes decodes the code as utf16.Decode does, but keeping
+// its lone surrogates, which are legal inside string literals, as
+// runes of their code unit, which appendRune encodes back as is.
+func decodeRunes(code abadutf16.Str) []rune {
+	runes := make([]rune, 0, len(code))
+
+	for i := 0; i < len(code); i++ {
+		r := rune(code[i])
+		if isSurrogate(r) && i+1 < len(code) {
+			if pair := utf16.DecodeRune(r, rune(code[i+1])); pair != unicode.ReplacementChar {
+				r = pair
+				i++
+			}
+		}
+
+		runes = append(runes, r)
+	}
+
+	return runes
+}
+
+func isSurrogate(r rune) bool {
+	return utf16.IsSurrogate(r)
+}
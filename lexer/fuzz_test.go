@@ -0,0 +1,117 @@
+//go:build go1.18
+// +build go1.18
+
+package lexer_test
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NeowayLabs/abad/lexer"
+	"github.com/NeowayLabs/abad/token"
+)
+
+// fuzzTimeout is how long the lexing of a single input may take
+// before it's considered an endless loop.
+const fuzzTimeout = 10 * time.Second
+
+// FuzzLex checks that the lexer never panics and always terminates,
+// with EOF or an Illegal token as the last token.
+// Run with: go test -fuzz=FuzzLex ./lexer
+func FuzzLex(f *testing.F) {
+	for _, seed := range seedCorpus(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, code string) {
+		for _, opts := range [][]lexer.Option{
+			nil,
+			{lexer.ES6()},
+			{lexer.Strict()},
+			{lexer.Comments()},
+		} {
+			checkTermination(t, code, opts)
+		}
+	})
+}
+
+func checkTermination(t *testing.T, code string, opts []lexer.Option) {
+	// every token but EOF consumes at least a character
+	maxTokens := utf8.RuneCountInString(code) + 1
+
+	done := make(chan []lexer.Tokval)
+	go func() {
+		var tokens []lexer.Tokval
+		for tok := range lexer.Lex(Str(code), opts...) {
+			tokens = append(tokens, tok)
+			if len(tokens) > maxTokens {
+				break
+			}
+		}
+		done <- tokens
+	}()
+
+	var tokens []lexer.Tokval
+	select {
+	case tokens = <-done:
+	case <-time.After(fuzzTimeout):
+		t.Fatalf("lexing %q takes more than %s", code, fuzzTimeout)
+	}
+
+	if len(tokens) > maxTokens {
+		t.Fatalf("lexing %q produced more than %d tokens: %s",
+			code, maxTokens, lexer.FormatTokens(tokens))
+	}
+
+	if len(tokens) == 0 {
+		t.Fatalf("lexing %q produced no tokens", code)
+	}
+
+	for i, tok := range tokens {
+		last := i == len(tokens)-1
+		ends := tok.Type == token.EOF || tok.Type == token.Illegal
+		if last != ends {
+			t.Fatalf("lexing %q: token %d of %d is %v:\n%s",
+				code, i, len(tokens), tok.Type, lexer.FormatTokens(tokens))
+		}
+	}
+}
+
+// seedCorpus returns the string literals of the lexer tests, which
+// are mostly the code of the test cases.
+func seedCorpus(f *testing.F) []string {
+	files, err := filepath.Glob("*_test.go")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	var seeds []string
+	fset := gotoken.NewFileSet()
+	for _, file := range files {
+		tree, err := goparser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		goast.Inspect(tree, func(node goast.Node) bool {
+			lit, ok := node.(*goast.BasicLit)
+			if !ok || lit.Kind != gotoken.STRING {
+				return true
+			}
+
+			seed, err := strconv.Unquote(lit.Value)
+			if err == nil {
+				seeds = append(seeds, seed)
+			}
+			return true
+		})
+	}
+
+	return seeds
+}
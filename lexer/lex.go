@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"unicode"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type Tokval struct {
@@ -13,6 +13,37 @@ type Tokval struct {
 	Value  utf16.Str
 	Line   uint
 	Column uint
+
+	// Raw is the source of strings, templates and identifiers with
+	// unicode escapes, including quotes and template delimiters,
+	// while their Value is cooked, with the escape sequences
+	// decoded. It's empty for the other tokens, whose Value is
+	// their source.
+	Raw utf16.Str
+
+	// NewlineBefore tells if a line terminator, or a block comment
+	// with line terminators, precedes the token, which is needed
+	// by the automatic semicolon insertion.
+	// http://es5.github.io/#x7.9
+	NewlineBefore bool
+
+	// Err tells why an Illegal token can't be lexed, it's nil
+	// for the other tokens.
+	Err *Error
+}
+
+// Error is the error of an illegal token, describing why the code
+// can't be lexed.
+type Error struct {
+	// Line and Column of the offending character.
+	Line   uint
+	Column uint
+
+	// Char is the offending character, or -1 at the end of the
+	// input.
+	Char rune
+
+	Msg string
 }
 
 // EOF is the End of File token.
@@ -27,11 +58,88 @@ func (t Tokval) EqualPos(other Tokval) bool {
 	return t.Line == other.Line && t.Column == other.Column
 }
 
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
 func (t Tokval) String() string {
 	return fmt.Sprintf(
 		"token:type[%s],value[%s],line[%d],column[%d]", t.Type, t.Value, t.Line, t.Column)
 }
 
+// Option configures the lexer.
+type Option func(*lexer)
+
+// ES6 enables the lexing of template literals and of binary
+// and octal literals, eg.: 0b1010 and 0o755.
+func ES6() Option {
+	return func(l *lexer) {
+		l.es6 = true
+	}
+}
+
+// Strict rejects legacy octal literals, eg.: 0777, as strict mode
+// code does.
+// http://es5.github.io/#C
+func Strict() Option {
+	return func(l *lexer) {
+		l.strict = true
+	}
+}
+
+// Comments emits the comments as Comment tokens, with their
+// delimiters, instead of skipping them, for tools like documentation
+// extractors and formatters. The parser doesn't accept them.
+func Comments() Option {
+	return func(l *lexer) {
+		l.comments = true
+	}
+}
+
+// Lexer scans the tokens of the code on demand, by calling Next.
+// Unlike Lex, it doesn't need a goroutine and may be abandoned at
+// any time.
+type Lexer struct {
+	l     *lexer
+	state lexerState
+}
+
+// New creates a lexer of the given crappy JS code (utf16 yay).
+func New(code utf16.Str, opts ...Option) *Lexer {
+	l := newLexer(decodeRunes(code), opts...)
+	return &Lexer{l: l, state: l.initialState}
+}
+
+// Next scans the next token. The last token is EOF, or Illegal if
+// the code has an error, then Next always returns EOF.
+func (lx *Lexer) Next() Tokval {
+	if lx.state == nil {
+		return EOF
+	}
+
+	tok, state := lx.state()
+	tok.NewlineBefore = lx.l.newline
+	lx.l.newline = false
+	lx.state = state
+
+	if tok.Type == token.Comment {
+		// comments are transparent to the next token, like when
+		// they are skipped.
+		lx.l.newline = lx.l.commentNewline
+		lx.l.commentNewline = false
+		return tok
+	}
+
+	if tok.Type.IsKeyword() && lx.l.prev == token.Dot {
+		// a keyword after a dot is a property name, eg.: a.new
+		lx.l.prev = token.Ident
+		return tok
+	}
+
+	lx.l.prev = tok.Type
+	return tok
+}
+
 // Lex will lex the given crappy JS code (utf16 yay) and provide a
 // stream of tokens as a result (the returned channel).
 //
@@ -41,20 +149,15 @@ func (t Tokval) String() string {
 //
 // A goroutine will be started to lex the given code, if you
 // do not iterate the returned channel the goroutine will leak,
-// you MUST drain the provided channel.
-func Lex(code utf16.Str) <-chan Tokval {
-
+// you MUST drain the provided channel. Prefer New, which has no
+// such pitfall, Lex is kept for compatibility.
+func Lex(code utf16.Str, opts ...Option) <-chan Tokval {
 	tokens := make(chan Tokval)
 
 	go func() {
-
-		decodedCode := code.Runes()
-		currentState := newLexer(decodedCode).initialState
-
-		for currentState != nil {
-			token, newState := currentState()
-			tokens <- token
-			currentState = newState
+		lx := New(code, opts...)
+		for lx.state != nil {
+			tokens <- lx.Next()
 		}
 
 		close(tokens)
@@ -69,7 +172,31 @@ type lexer struct {
 	line     uint
 	column   uint
 
+	// src reads the code on demand, it's nil if the code is
+	// in memory.
+	src *source
+
 	puncStates map[rune]lexerState
+
+	es6      bool
+	strict   bool
+	comments bool
+
+	// prev is the type of the last token, a slash after it
+	// starts either a division or a regular expression.
+	prev token.Type
+
+	// newline is set when a line terminator is skipped before
+	// the next token.
+	newline bool
+
+	// commentNewline is set when a comment token has line
+	// terminators, which precede the next token.
+	commentNewline bool
+
+	// open braces inside each template substitution being
+	// lexed, the innermost last.
+	templates []uint
 }
 
 type match struct {
@@ -79,22 +206,32 @@ type match struct {
 
 type lexerState func() (Tokval, lexerState)
 
-func newLexer(code []rune) *lexer {
+func newLexer(code []rune, opts ...Option) *lexer {
 	l := &lexer{code: code, line: 1, column: 1}
+	for _, opt := range opts {
+		opt(l)
+	}
 	l.initPuncStates()
 	return l
 }
 
 func (l *lexer) initialState() (Tokval, lexerState) {
 
-	l.skipSpaces()
+	if !l.skipSpaces() {
+		// unterminated block comment
+		return l.illegalToken("unterminated comment")
+	}
 
 	if l.isEOF() {
-		return EOF, nil
+		return l.eofToken()
+	}
+
+	if l.comments && l.isComment() {
+		return l.commentState()
 	}
 
 	if l.isInvalidRune() {
-		return l.illegalToken()
+		return l.unexpected("")
 	}
 
 	if l.isNumber() {
@@ -128,22 +265,19 @@ func (l *lexer) initPuncStates() {
 		comma:      state(token.Comma),
 		semiColon:  state(token.SemiColon),
 		leftParen:  state(token.LParen),
-		rightParen: state(token.RParen),
+		rightParen: l.rightParenState,
 		rune('~'):  state(token.Not),
 		rune('?'):  state(token.Ternary),
 		rune(':'):  state(token.Colon),
 		rune('['):  state(token.LBrack),
-		rune(']'):  state(token.RBrack),
-		rune('{'):  state(token.LBrace),
-		rune('}'):  state(token.RBrace),
-		rune('*'): l.acceptFirst([]match{
+		rune(']'):  l.rightBrackState,
+		rune('{'):  l.leftBraceState,
+		rune('}'):  l.rightBraceState,
+		asterisk: l.acceptFirst([]match{
 			{str: "*=", token: token.MulAssign},
 			{str: "*", token: token.Mul},
 		}),
-		rune('/'): l.acceptFirst([]match{
-			{str: "/=", token: token.QuoAssign},
-			{str: "/", token: token.Quo},
-		}),
+		slash: l.slashState,
 		rune('%'): l.acceptFirst([]match{
 			{str: "%=", token: token.RemAssign},
 			{str: "%", token: token.Rem},
@@ -197,6 +331,10 @@ func (l *lexer) initPuncStates() {
 			{str: "+", token: token.Plus},
 		}),
 	}
+
+	if l.es6 {
+		l.puncStates[backtick] = l.templateState
+	}
 }
 
 // acceptFirst takes a list of matches and returns the
@@ -214,20 +352,18 @@ func (l *lexer) acceptFirst(matches []match) lexerState {
 				return tok, l.initialState
 			}
 		}
-		return l.illegalToken()
+		return l.unexpected("")
 	}
 }
 
 func (l *lexer) accept(m match) (Tokval, bool) {
 	want := []rune(m.str)
-	code := l.code[l.position:]
-
-	if len(code) < len(want) {
+	if !l.has(l.position + uint(len(want)-1)) {
 		return Tokval{}, false
 	}
 
 	for i, r := range want {
-		if r != code[i] {
+		if r != l.code[l.position+uint(i)] {
 			return Tokval{}, false
 		}
 	}
@@ -238,14 +374,178 @@ func (l *lexer) accept(m match) (Tokval, bool) {
 
 func (l *lexer) dotState() (Tokval, lexerState) {
 	l.fwd()
-	if l.isTokenEnd() {
-		return l.illegalToken()
+	if l.isNumberEnd() {
+		return l.unexpected("after dot")
 	}
 	allowExponent := true
 	allowDot := false
 	return l.decimalState(allowExponent, allowDot)
 }
 
+func (l *lexer) rightParenState() (Tokval, lexerState) {
+	return l.token(token.RParen), l.afterCloseState
+}
+
+func (l *lexer) rightBrackState() (Tokval, lexerState) {
+	return l.token(token.RBrack), l.afterCloseState
+}
+
+// afterCloseState handles a dot right after a parenthesis, bracket or
+// regular expression not followed by a digit, eg.: (a).b or a[0].b,
+// which starts a member access and not a decimal.
+func (l *lexer) afterCloseState() (Tokval, lexerState) {
+	if l.isEOF() || !l.isDot() {
+		return l.initialState()
+	}
+
+	next := l.position + 1
+	if l.has(next) && isDigit(l.code[next]) {
+		return l.initialState()
+	}
+
+	return l.accessMemberState()
+}
+
+func (l *lexer) leftBraceState() (Tokval, lexerState) {
+	if n := len(l.templates); n > 0 {
+		l.templates[n-1]++
+	}
+
+	return l.token(token.LBrace), l.initialState
+}
+
+// rightBraceState closes a block or object literal, or the
+// substitution of a template, which continues the template.
+func (l *lexer) rightBraceState() (Tokval, lexerState) {
+	if n := len(l.templates); n > 0 {
+		if l.templates[n-1] == 0 {
+			l.templates = l.templates[:n-1]
+			return l.templateSpanState(token.TemplateMiddle, token.TemplateTail)
+		}
+
+		l.templates[n-1]--
+	}
+
+	return l.token(token.RBrace), l.initialState
+}
+
+func (l *lexer) templateState() (Tokval, lexerState) {
+	return l.templateSpanState(token.TemplateHead, token.Template)
+}
+
+// templateSpanState lexes the characters of a template from the
+// current ` or } until the next ${, producing a head token, or until
+// the closing `, producing an end token.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-template-literal-lexical-components
+func (l *lexer) templateSpanState(head, end token.Type) (Tokval, lexerState) {
+	l.fwd()
+
+	for !l.isEOF() {
+		if l.cur() == backtick {
+			return l.templateToken(end, l.code[1:l.position])
+		}
+
+		if l.startsWith(dollar, rune('{')) {
+			raw := l.code[1:l.position]
+			l.fwd()
+			l.templates = append(l.templates, 0)
+			return l.templateToken(head, raw)
+		}
+
+		if l.cur() == backslash {
+			// the escaped char can't end the template
+			l.fwd()
+		}
+
+		l.fwd()
+	}
+
+	return l.illegalToken("unterminated template literal")
+}
+
+func (l *lexer) templateToken(t token.Type, raw []rune) (Tokval, lexerState) {
+	val, ok := cookString(normalizeLineTerminators(raw))
+	if !ok {
+		return l.illegalToken("invalid escape sequence in template literal")
+	}
+
+	return l.cookedToken(t, val), l.initialState
+}
+
+// slashState lexes a regular expression where an expression may
+// start and a division elsewhere.
+// http://es5.github.io/#x7
+func (l *lexer) slashState() (Tokval, lexerState) {
+	if !l.divisionAllowed() {
+		return l.regexpState()
+	}
+
+	return l.acceptFirst([]match{
+		{str: "/=", token: token.QuoAssign},
+		{str: "/", token: token.Quo},
+	})()
+}
+
+// divisionAllowed tells if the previous token ends an operand,
+// so a following slash is a division.
+func (l *lexer) divisionAllowed() bool {
+	switch l.prev {
+	case token.Ident, token.Decimal, token.Hexadecimal, token.Octal, token.Binary,
+		token.BigInt,
+		token.String, token.Bool, token.Null,
+		token.This, token.Template, token.TemplateTail, token.Regexp,
+		token.RParen, token.RBrack, token.RBrace,
+		token.Inc, token.Dec:
+		return true
+	}
+
+	return false
+}
+
+// regexpState lexes a regular expression literal, eg.: /a[/]b/gi.
+// The body is only delimited, its pattern is not validated.
+// http://es5.github.io/#x7.8.5
+func (l *lexer) regexpState() (Tokval, lexerState) {
+	inClass := false
+
+	for l.fwd(); !l.isEOF(); l.fwd() {
+		if l.isNewline() {
+			return l.illegalToken("unterminated regular expression literal")
+		}
+
+		switch l.cur() {
+		case backslash:
+			l.fwd()
+			if l.isEOF() || l.isNewline() {
+				return l.illegalToken("unterminated regular expression literal")
+			}
+		case rune('['):
+			inClass = true
+		case rune(']'):
+			inClass = false
+		case slash:
+			if !inClass {
+				return l.regexpFlagsState()
+			}
+		}
+	}
+
+	return l.illegalToken("unterminated regular expression literal")
+}
+
+func (l *lexer) regexpFlagsState() (Tokval, lexerState) {
+	for next := l.position + 1; l.has(next); next++ {
+		r := l.code[next]
+		if r != dollar && r != rune('_') &&
+			!unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		l.fwd()
+	}
+
+	return l.token(token.Regexp), l.afterCloseState
+}
+
 func (l *lexer) punctuator() (Tokval, lexerState) {
 	return l.puncStates[l.cur()]()
 }
@@ -264,16 +564,38 @@ func (l *lexer) stringState() (Tokval, lexerState) {
 
 	for !l.isEOF() && !l.isDoubleQuote() {
 		if l.isNewline() {
-			return l.illegalToken()
+			return l.illegalToken("unterminated string literal")
+		}
+
+		if l.cur() == backslash {
+			// the escaped char can't end the string,
+			// even if it's a line terminator.
+			l.fwd()
+			if l.isEOF() {
+				break
+			}
+
+			if l.startsWith(carriageRet, linefeed) {
+				l.fwd()
+			}
 		}
+
 		l.fwd()
 	}
 
 	if l.isEOF() {
-		return l.illegalToken()
+		return l.illegalToken("unterminated string literal")
+	}
+
+	// WHY: we need to remove the double quotes
+	// around the string.
+	val, ok := cookString(l.code[1:l.position])
+	if !ok {
+		return l.illegalToken("invalid escape sequence in string literal")
 	}
 
-	return l.stringToken(), l.initialState
+	// a dot after the string is a member access, eg.: "abc".length
+	return l.cookedToken(token.String, val), l.afterCloseState
 }
 
 func (l *lexer) numberState() (Tokval, lexerState) {
@@ -286,49 +608,166 @@ func (l *lexer) numberState() (Tokval, lexerState) {
 		l.fwd()
 
 		if l.isEOF() || l.isNewline() {
-			return l.illegalToken()
+			return l.unexpected("after numeric literal")
 		}
 
 		return l.hexadecimalState()
 	}
 
+	if l.es6 && l.code[0] == '0' && (l.cur() == 'b' || l.cur() == 'B') {
+		l.fwd()
+		return l.radixState(token.Binary, isBinaryDigit)
+	}
+
+	if l.es6 && l.code[0] == '0' && (l.cur() == 'o' || l.cur() == 'O') {
+		l.fwd()
+		return l.radixState(token.Octal, isOctalDigit)
+	}
+
+	if l.code[0] == '0' && l.isNumber() {
+		return l.legacyOctalState()
+	}
+
 	allowExponent := true
 	allowDot := true
 	return l.decimalState(allowExponent, allowDot)
 }
 
-func (l *lexer) illegalToken() (Tokval, lexerState) {
+// illegalToken generates the token of the code that can't be lexed,
+// with the error at the current position. It ends the lexing. An
+// error reading the source is reported instead of msg.
+func (l *lexer) illegalToken(msg string) (Tokval, lexerState) {
+	if l.src != nil && l.src.failed() != nil {
+		msg = l.src.failed().Error()
+	}
+
+	line, column := l.currentPos()
+	char := rune(-1)
+	if !l.isEOF() {
+		char = l.cur()
+	}
+
 	return Tokval{
-		Type:  token.Illegal,
-		Value: newStr(l.code),
+		Type:   token.Illegal,
+		Value:  newStr(l.code),
+		Line:   l.line,
+		Column: l.column,
+		Err: &Error{
+			Line:   line,
+			Column: column,
+			Char:   char,
+			Msg:    msg,
+		},
 	}, nil
 }
 
+// unexpected generates the illegal token of an unexpected current
+// character, eg.: "unexpected character U+2603 after numeric literal".
+func (l *lexer) unexpected(context string) (Tokval, lexerState) {
+	msg := "unexpected end of input"
+	if !l.isEOF() {
+		msg = fmt.Sprintf("unexpected character %U", l.cur())
+	}
+
+	if context != "" {
+		msg += " " + context
+	}
+
+	return l.illegalToken(msg)
+}
+
+// illegalIdentifierChar generates the illegal token of a character
+// or an escape sequence that is not valid in an identifier.
+func (l *lexer) illegalIdentifierChar(context string) (Tokval, lexerState) {
+	if l.cur() == backslash {
+		return l.illegalToken("invalid escape sequence in identifier")
+	}
+
+	return l.unexpected(context)
+}
+
+// identifierState lexes identifiers starting with a UnicodeLetter,
+// $ or _, followed by any IdentifierPart. Any of them may be written
+// as a unicode escape sequence, eg.: \u0061bc is abc.
+// http://es5.github.io/#x7.6
 func (l *lexer) identifierState() (Tokval, lexerState) {
 
-	// TODO: handle keywords followed by dot and ( ? like null() ? or leave the parser to handle it ?
+	escaped, ok := l.identifierChar(isIdentifierStart)
+	if !ok {
+		return l.illegalIdentifierChar("")
+	}
+
+	l.fwd()
 
 	for !l.isEOF() {
 
 		if l.isDot() {
 			l.bwd()
-			return l.token(token.Ident), l.accessMemberState
+			if escaped {
+				return l.escapedIdentToken(l.accessMemberState)
+			}
+			return l.identOrKeywordToken(), l.accessMemberState
 		}
 
-		if l.isLeftParen() || l.isTokenEnd() {
+		if l.isPunctuator() || l.isTokenEnd() {
 			l.bwd()
+			if escaped {
+				return l.escapedIdentToken(l.initialState)
+			}
 			return l.identOrKeywordToken(), l.initialState
 		}
 
+		escapedPart, ok := l.identifierChar(isIdentifierPart)
+		if !ok {
+			return l.illegalIdentifierChar("in identifier")
+		}
+
+		escaped = escaped || escapedPart
 		l.fwd()
 	}
 
+	if escaped {
+		return l.escapedIdentToken(l.initialState)
+	}
+
 	return l.identOrKeywordToken(), l.initialState
 }
 
+// identifierChar tells if the current character, or the unicode
+// escape sequence starting on it, is valid. The escape sequence is
+// skipped up to its last character.
+func (l *lexer) identifierChar(valid func(rune) bool) (escaped bool, ok bool) {
+	if l.cur() != backslash {
+		return false, valid(l.cur())
+	}
+
+	// the longest escape sequence is \uXXXX
+	l.has(l.position + 5)
+	r, size, ok := identifierEscape(l.code[l.position:])
+	if !ok || !valid(r) {
+		return true, false
+	}
+
+	l.position += uint(size - 1)
+	return true, true
+}
+
+// escapedIdentToken generates the identifier token with its escape
+// sequences decoded. Reserved words can't be escaped.
+func (l *lexer) escapedIdentToken(next lexerState) (Tokval, lexerState) {
+	name := cookIdentifier(l.curValue())
+	if _, isKeyword := token.Keyword(string(name)); isKeyword {
+		// the error is at the start of the keyword
+		l.position = 0
+		return l.illegalToken(fmt.Sprintf("keyword %s can't contain escape sequences", string(name)))
+	}
+
+	return l.cookedToken(token.Ident, newStr(name)), next
+}
+
 func (l *lexer) identOrKeywordToken() Tokval {
 	val := l.curValue()
-	keywordType, isKeyword := keywords[string(val)]
+	keywordType, isKeyword := token.Keyword(string(val))
 	if isKeyword {
 		return l.token(keywordType)
 	}
@@ -338,15 +777,15 @@ func (l *lexer) identOrKeywordToken() Tokval {
 func (l *lexer) startIdentifierState() (Tokval, lexerState) {
 
 	if l.isEOF() {
-		return EOF, nil
+		return l.eofToken()
 	}
 
 	if l.isNumber() {
-		return l.illegalToken()
+		return l.unexpected("after dot")
 	}
 
 	if l.isDot() {
-		return l.illegalToken()
+		return l.unexpected("after dot")
 	}
 
 	return l.identifierState()
@@ -359,12 +798,18 @@ func (l *lexer) accessMemberState() (Tokval, lexerState) {
 func (l *lexer) hexadecimalState() (Tokval, lexerState) {
 
 	for !l.isEOF() {
-		if l.isTokenEnd() {
+		if l.isNumberEnd() {
+			if l.position == 2 {
+				return l.unexpected("after numeric literal")
+			}
 			l.bwd()
 			return l.token(token.Hexadecimal), l.initialState
 		}
+		if l.isBigIntSuffix() && l.position > 2 {
+			return l.bigIntState()
+		}
 		if !l.isHexadecimal() {
-			return l.illegalToken()
+			return l.unexpected("after numeric literal")
 		}
 		l.fwd()
 	}
@@ -372,12 +817,64 @@ func (l *lexer) hexadecimalState() (Tokval, lexerState) {
 	return l.token(token.Hexadecimal), l.initialState
 }
 
+// radixState lexes the digits of a binary or octal literal,
+// after its prefix.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-literals-numeric-literals
+func (l *lexer) radixState(t token.Type, valid func(rune) bool) (Tokval, lexerState) {
+	if l.isNumberEnd() {
+		return l.unexpected("after numeric literal")
+	}
+
+	for !l.isEOF() {
+		if l.isNumberEnd() {
+			l.bwd()
+			return l.token(t), l.initialState
+		}
+		if l.isBigIntSuffix() && l.position > 2 {
+			return l.bigIntState()
+		}
+		if !valid(l.cur()) {
+			return l.unexpected("after numeric literal")
+		}
+		l.fwd()
+	}
+
+	return l.token(t), l.initialState
+}
+
+// legacyOctalState lexes the digits after a leading zero, which are
+// an octal literal, unless some digit is 8 or 9 (eg.: 08), which makes
+// it a decimal.
+// http://es5.github.io/#B.1.1
+func (l *lexer) legacyOctalState() (Tokval, lexerState) {
+	for !l.isEOF() && l.isNumber() {
+		if !l.isOctal() {
+			allowExponent := true
+			allowDot := true
+			return l.decimalState(allowExponent, allowDot)
+		}
+		l.fwd()
+	}
+
+	// octals have no fraction, eg.: 07.5
+	if !l.isNumberEnd() || (!l.isEOF() && l.isDot()) {
+		return l.unexpected("after numeric literal")
+	}
+
+	if l.strict {
+		return l.illegalToken("octal literals are not allowed in strict mode")
+	}
+
+	l.bwd()
+	return l.token(token.Octal), l.initialState
+}
+
 func (l *lexer) decimalState(allowExponent bool, allowDot bool) (Tokval, lexerState) {
 
 	for !l.isEOF() {
 		if l.isExponentPartStart() {
 			if !allowExponent {
-				return l.illegalToken()
+				return l.unexpected("after numeric literal")
 			}
 			l.fwd()
 			return l.exponentPartState()
@@ -385,19 +882,25 @@ func (l *lexer) decimalState(allowExponent bool, allowDot bool) (Tokval, lexerSt
 
 		if l.isDot() {
 			if !allowDot {
-				return l.illegalToken()
+				return l.unexpected("after numeric literal")
 			}
 			l.fwd()
 			return l.decimalState(allowExponent, false)
 		}
 
-		if l.isTokenEnd() {
+		if l.isNumberEnd() {
 			l.bwd()
 			return l.token(token.Decimal), l.initialState
 		}
 
+		// only integers without leading zeros, eg.: 0n and 10n
+		if l.isBigIntSuffix() && allowExponent && allowDot &&
+			(l.code[0] != '0' || l.position == 1) {
+			return l.bigIntState()
+		}
+
 		if !l.isNumber() {
-			return l.illegalToken()
+			return l.unexpected("after numeric literal")
 		}
 
 		l.fwd()
@@ -406,31 +909,98 @@ func (l *lexer) decimalState(allowExponent bool, allowDot bool) (Tokval, lexerSt
 	return l.token(token.Decimal), l.initialState
 }
 
-func (l *lexer) exponentPartState() (Tokval, lexerState) {
+// bigIntState lexes the n suffix of a BigInt literal, eg.: 10n
+// https://tc39.es/ecma262/#sec-literals-numeric-literals
+func (l *lexer) bigIntState() (Tokval, lexerState) {
+	l.fwd()
+	if l.isEOF() {
+		return l.token(token.BigInt), l.initialState
+	}
 
-	if l.isTokenEnd() {
-		return l.illegalToken()
+	if !l.isNumberEnd() {
+		return l.unexpected("after numeric literal")
 	}
 
-	if l.isMinusSign() || l.isPlusSign() {
-		// TODO: test 1.0e- and 1.0e+
+	l.bwd()
+	return l.token(token.BigInt), l.initialState
+}
+
+func (l *lexer) exponentPartState() (Tokval, lexerState) {
+
+	if !l.isEOF() && (l.isMinusSign() || l.isPlusSign()) {
 		l.fwd()
 	}
 
+	if l.isNumberEnd() {
+		return l.unexpected("after numeric literal")
+	}
+
 	allowExponent := false
 	allowDot := true
 	return l.decimalState(allowExponent, allowDot)
 }
 
-func (l *lexer) skipSpaces() {
-	for l.isNewline() || l.isWhiteSpace() {
+// skipSpaces skips white spaces, line terminators and comments.
+// It returns false if a block comment is not terminated.
+func (l *lexer) skipSpaces() bool {
+	for l.isNewline() || l.isWhiteSpace() || (!l.comments && l.isComment()) {
+		if l.isLineComment() {
+			l.skipLineComment()
+			continue
+		}
+
+		if l.isBlockComment() {
+			if !l.skipBlockComment() {
+				return false
+			}
+			continue
+		}
+
+		if l.isNewline() {
+			l.updateLine()
+			l.newline = true
+		} else {
+			l.updateColumn()
+		}
+		l.consume()
+	}
+
+	return true
+}
+
+// skipLineComment skips a comment until the end of the line,
+// the line terminator isn't part of the comment.
+// http://es5.github.io/#x7.4
+func (l *lexer) skipLineComment() {
+	for l.has(l.position+1) &&
+		!isLineTerminator(l.code[l.position+1]) {
+		l.fwd()
+	}
+
+	l.updateColumn()
+	l.consume()
+}
+
+// skipBlockComment skips a comment until */, counting the line
+// terminators inside it. It returns false, skipping nothing, if the
+// comment is not terminated.
+func (l *lexer) skipBlockComment() bool {
+	end, ok := l.blockCommentEnd()
+	if !ok {
+		return false
+	}
+
+	for i := uint(0); i <= end; i++ {
 		if l.isNewline() {
 			l.updateLine()
+			l.newline = true
 		} else {
 			l.updateColumn()
 		}
 		l.consume()
 	}
+
+	return true
 }
 
 func (l *lexer) cur() rune {
@@ -438,11 +1008,39 @@ func (l *lexer) cur() rune {
 }
 
 func (l *lexer) isNumber() bool {
-	return containsRune(numbers, l.cur())
+	return isDigit(l.cur())
 }
 
 func (l *lexer) isEOF() bool {
-	return l.position >= uint(len(l.code))
+	return !l.has(l.position)
+}
+
+// has tells if the code has a character at the index i, reading
+// it from the source if needed.
+func (l *lexer) has(i uint) bool {
+	for i >= uint(len(l.code)) {
+		if l.src == nil {
+			return false
+		}
+
+		code, ok := l.src.read(l.code)
+		if !ok {
+			return false
+		}
+		l.code = code
+	}
+
+	return true
+}
+
+// eofToken generates the EOF token, or the illegal token of the
+// error reading the source.
+func (l *lexer) eofToken() (Tokval, lexerState) {
+	if l.src != nil && l.src.failed() != nil {
+		return l.illegalToken("")
+	}
+
+	return EOF, nil
 }
 
 func (l *lexer) isDot() bool {
@@ -450,11 +1048,16 @@ func (l *lexer) isDot() bool {
 }
 
 func (l *lexer) isHexStart() bool {
-	return containsRune(hexStart, l.cur())
+	r := l.cur()
+	return r == 'x' || r == 'X'
 }
 
+// isInvalidRune tells if the current character is U+FFFD, the
+// decoding of corrupt input, or a lone surrogate. Both are only
+// legal inside string and template literals and comments.
 func (l *lexer) isInvalidRune() bool {
-	return unicode.ReplacementChar == l.cur()
+	r := l.cur()
+	return r == unicode.ReplacementChar || isSurrogate(r)
 }
 
 func (l *lexer) isMinusSign() bool {
@@ -473,26 +1076,81 @@ func (l *lexer) isRightParen() bool {
 	return l.cur() == rightParen
 }
 
+func (l *lexer) isRightBrack() bool {
+	return l.cur() == rune(']')
+}
+
 func (l *lexer) isNewline() bool {
 	if l.isEOF() {
 		return false
 	}
-	return containsRune(lineTerminators, l.cur())
+	return isLineTerminator(l.cur())
 }
 
 func (l *lexer) isWhiteSpace() bool {
 	if l.isEOF() {
 		return false
 	}
-	return containsRune(whiteSpaces, l.cur())
+	return isWhiteSpace(l.cur())
 }
 
 func (l *lexer) isHexadecimal() bool {
-	return containsRune(hexnumbers, l.cur())
+	return isHexDigit(l.cur())
+}
+
+func (l *lexer) isBigIntSuffix() bool {
+	return l.cur() == 'n'
+}
+
+func (l *lexer) isOctal() bool {
+	return isOctalDigit(l.cur())
 }
 
 func (l *lexer) isExponentPartStart() bool {
-	return containsRune(exponentPartStart, l.cur())
+	r := l.cur()
+	return r == 'e' || r == 'E'
+}
+
+// blockCommentEnd returns the position of the slash of */, ending
+// the block comment at the current position, or false if the
+// comment is not terminated.
+func (l *lexer) blockCommentEnd() (uint, bool) {
+	end := l.position + 2
+	for ; l.has(end + 1); end++ {
+		if l.code[end] == asterisk && l.code[end+1] == slash {
+			return end + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// commentState lexes a comment as a Comment token, the line
+// terminator after a line comment isn't part of it.
+// http://es5.github.io/#x7.4
+func (l *lexer) commentState() (Tokval, lexerState) {
+	if l.isLineComment() {
+		for l.has(l.position+1) &&
+			!isLineTerminator(l.code[l.position+1]) {
+			l.fwd()
+		}
+
+		return l.token(token.Comment), l.initialState
+	}
+
+	end, ok := l.blockCommentEnd()
+	if !ok {
+		return l.illegalToken("unterminated comment")
+	}
+
+	l.position = end
+	for _, r := range l.code[:end] {
+		if isLineTerminator(r) {
+			l.commentNewline = true
+		}
+	}
+
+	return l.multilineToken(token.Comment, newStr(l.curValue())), l.initialState
 }
 
 func (l *lexer) isComma() bool {
@@ -503,6 +1161,29 @@ func (l *lexer) isDoubleQuote() bool {
 	return l.cur() == doubleQuote
 }
 
+func (l *lexer) isComment() bool {
+	return l.isLineComment() || l.isBlockComment()
+}
+
+func (l *lexer) isLineComment() bool {
+	return l.startsWith(slash, slash)
+}
+
+func (l *lexer) isBlockComment() bool {
+	return l.startsWith(slash, asterisk)
+}
+
+// startsWith tells if the code at the current position
+// starts with first and second.
+func (l *lexer) startsWith(first, second rune) bool {
+	next := l.position + 1
+	if !l.has(next) {
+		return false
+	}
+
+	return l.cur() == first && l.code[next] == second
+}
+
 func (l *lexer) isSemiColon() bool {
 	return l.cur() == semiColon
 }
@@ -512,7 +1193,16 @@ func (l *lexer) isTokenEnd() bool {
 	if l.isEOF() {
 		return true
 	}
-	return l.isRightParen() || l.isComma() || l.isNewline() || l.isSemiColon() || l.isWhiteSpace()
+	return l.isRightParen() || l.isRightBrack() || l.isComma() ||
+		l.isNewline() || l.isSemiColon() || l.isWhiteSpace() ||
+		l.isComment()
+}
+
+// isNumberEnd tells if a numeric literal ends at the current
+// character, a token end or any punctuator, eg.: 1+2
+// The dot and the exponent are handled by the numeric states.
+func (l *lexer) isNumberEnd() bool {
+	return l.isTokenEnd() || l.isPunctuator()
 }
 
 func (l *lexer) fwd() {
@@ -556,33 +1246,70 @@ func (l *lexer) token(t token.Type) Tokval {
 	return Tokval{Type: t, Value: newStr(val), Line: l.line, Column: column}
 }
 
+// currentPos returns the line and column of the current position,
+// counting the line terminators of the token being lexed.
+func (l *lexer) currentPos() (uint, uint) {
+	line, column := l.line, l.column
+
+	for i := uint(0); i < l.position && i < uint(len(l.code)); i++ {
+		r := l.code[i]
+		if !isLineTerminator(r) {
+			column++
+			continue
+		}
+
+		// <CR><LF> is a single line terminator
+		if r == carriageRet && i+1 < l.position && l.code[i+1] == linefeed {
+			continue
+		}
+
+		line++
+		column = 1
+	}
+
+	return line, column
+}
+
 func (l *lexer) updateColumn() uint {
 	column := l.column
 	l.column += l.position + 1
 	return column
 }
 
-func (l *lexer) stringToken() Tokval {
-	// WHY: we need to remove the double quotes
-	// around the string.
-
-	val := l.code[1:l.position]
+// cookedToken generates a token of a literal with escape sequences,
+// whose value is val and whose raw value is the code. The literal
+// may span many lines.
+func (l *lexer) cookedToken(t token.Type, val utf16.Str) Tokval {
+	raw := newStr(l.curValue())
+	tok := l.multilineToken(t, val)
+	tok.Raw = raw
+	return tok
+}
 
+// multilineToken generates a token whose value is val, spanning the
+// lines of the code.
+func (l *lexer) multilineToken(t token.Type, val utf16.Str) Tokval {
+	line := l.line
 	column := l.updateColumn()
+
+	// line continuations, eg.: "a\<LF>b", or the lines of templates
+	for i, r := range l.code[:l.position] {
+		if isLineTerminator(r) {
+			l.updateLine()
+			l.column = l.position - uint(i) + 1
+		}
+	}
+
 	l.consume()
 
 	return Tokval{
-		Type:   token.String,
-		Value:  newStr(val),
-		Line:   l.line,
+		Type:   t,
+		Value:  val,
+		Line:   line,
 		Column: column,
 	}
 }
 
-var numbers []rune
-var hexnumbers []rune
-var lineTerminators []rune
-var whiteSpaces []rune
 var linefeed rune
 var carriageRet rune
 var semiColon rune
@@ -593,19 +1320,19 @@ var leftParen rune
 var rightParen rune
 var comma rune
 var doubleQuote rune
+var backtick rune
+var dollar rune
+var underscore rune
+var zwnj rune
+var zwj rune
+var slash rune
+var backslash rune
+var asterisk rune
 var assign rune
-var hexStart []rune
-var exponentPartStart []rune
-var keywords map[string]token.Type
 
 func init() {
-	numbers = []rune("0123456789")
-	hexnumbers = append(numbers, []rune("abcdefABCDEF")...)
 	linefeed = rune('\u000A')
 	carriageRet = rune('\u000D')
-	lineSep := rune('\u2028')
-	paragraphSep := rune('\u2029')
-	lineTerminators = []rune{linefeed, carriageRet, lineSep, paragraphSep}
 	dot = rune('.')
 	minusSign = rune('-')
 	plusSign = rune('+')
@@ -613,69 +1340,24 @@ func init() {
 	rightParen = rune(')')
 	comma = rune(',')
 	doubleQuote = rune('"')
+	backtick = rune('`')
+	dollar = rune('$')
+	underscore = rune('_')
+	zwnj = rune('\u200C')
+	zwj = rune('\u200D')
+	slash = rune('/')
+	backslash = rune('\\')
+	asterisk = rune('*')
 	semiColon = rune(';')
-	hexStart = []rune("xX")
-	exponentPartStart = []rune("eE")
 	assign = rune('=')
-	keywords = newKeywords()
-	whiteSpaces = newWhiteSpaces()
-}
-
-func newKeywords() map[string]token.Type {
-	return map[string]token.Type{
-		"null":       token.Null,
-		"undefined":  token.Undefined,
-		"false":      token.Bool,
-		"true":       token.Bool,
-		"break":      token.Break,
-		"case":       token.Case,
-		"continue":   token.Continue,
-		"debugger":   token.Debugger,
-		"default":    token.Default,
-		"delete":     token.Delete,
-		"do":         token.Do,
-		"else":       token.Else,
-		"finally":    token.Finally,
-		"for":        token.For,
-		"function":   token.Function,
-		"if":         token.If,
-		"in":         token.In,
-		"instanceof": token.InstanceOf,
-		"new":        token.New,
-		"return":     token.Return,
-		"switch":     token.Switch,
-		"this":       token.This,
-		"throw":      token.Throw,
-		"try":        token.Try,
-		"typeof":     token.TypeOf,
-		"var":        token.Var,
-		"void":       token.Void,
-		"while":      token.While,
-		"with":       token.With,
-	}
-}
-
-func newWhiteSpaces() []rune {
-
-	tab := rune('\u0009')
-	verticalTab := rune('\u000B')
-	formFeed := rune('\u000C')
-	space := rune('\u0020')
-	noBreakSpace := rune('\u00A0')
-	byteOrderMark := rune('\uFEFF')
-
-	return []rune{tab, verticalTab, formFeed, space, noBreakSpace, byteOrderMark}
-}
-
-func containsRune(runes []rune, r rune) bool {
-	for _, n := range runes {
-		if r == n {
-			return true
-		}
-	}
-	return false
 }
 
+// newStr encodes the runes of a token, keeping lone surrogates.
 func newStr(r []rune) utf16.Str {
-	return utf16.NewFromRunes(r)
+	s := make(utf16.Str, 0, len(r))
+	for _, c := range r {
+		s = appendRune(s, c)
+	}
+
+	return s
 }
@@ -2,13 +2,15 @@ package lexer_test
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"unicode"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/lexer"
 	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type TestCase struct {
@@ -16,6 +18,7 @@ type TestCase struct {
 	code          utf16.Str
 	want          []lexer.Tokval
 	checkPosition bool
+	opts          []lexer.Option
 }
 
 func (tc TestCase) String() string {
@@ -232,9 +235,6 @@ func TestNumericLiterals(t *testing.T) {
 }
 
 func TestStrings(t *testing.T) {
-	// TODO: multiline strings
-	// - escaped double quotes
-
 	cases := []TestCase{
 		{
 			name: "Empty",
@@ -280,9 +280,9 @@ func TestKeywords(t *testing.T) {
 			want: tokens(nullToken()),
 		},
 		{
-			name: "Undefined",
+			name: "UndefinedIsIdentifier",
 			code: Str("undefined"),
-			want: tokens(undefinedToken()),
+			want: tokens(identToken("undefined")),
 		},
 		{
 			name: "False",
@@ -304,6 +304,11 @@ func TestKeywords(t *testing.T) {
 			code: Str("case"),
 			want: keyword(token.Case, "case"),
 		},
+		{
+			name: "Catch",
+			code: Str("catch"),
+			want: keyword(token.Catch, "catch"),
+		},
 		{
 			name: "Continue",
 			code: Str("continue"),
@@ -426,6 +431,35 @@ func TestKeywords(t *testing.T) {
 	runWhiteSpaceTests(t, cases)
 }
 
+func TestKeywordsBeforeDot(t *testing.T) {
+	member := func(keyword lexer.Tokval) []lexer.Tokval {
+		return tokens(keyword, dotToken(), identToken("x"))
+	}
+
+	runTests(t, []TestCase{
+		{
+			name: "This",
+			code: Str("this.x"),
+			want: member(tokval(token.This, "this")),
+		},
+		{
+			name: "Null",
+			code: Str("null.x"),
+			want: member(nullToken()),
+		},
+		{
+			name: "True",
+			code: Str("true.x"),
+			want: member(boolToken("true")),
+		},
+		{
+			name: "EscapedKeyword",
+			code: Str(`th\u0069s.x`),
+			want: []lexer.Tokval{illegalToken(`th\u0069s.x`)},
+		},
+	})
+}
+
 func TestPunctuators(t *testing.T) {
 
 	punc := func(t token.Type, s string) []lexer.Tokval {
@@ -438,11 +472,6 @@ func TestPunctuators(t *testing.T) {
 			code: Str("*"),
 			want: punc(token.Mul, "*"),
 		},
-		{
-			name: "Quotient",
-			code: Str("/"),
-			want: punc(token.Quo, "/"),
-		},
 		{
 			name: "Remainder",
 			code: Str("%"),
@@ -603,11 +632,6 @@ func TestPunctuators(t *testing.T) {
 			code: Str("%="),
 			want: punc(token.RemAssign, "%="),
 		},
-		{
-			name: "QuoAssign",
-			code: Str("/="),
-			want: punc(token.QuoAssign, "/="),
-		},
 		{
 			name: "LeftShiftAssign",
 			code: Str("<<="),
@@ -645,720 +669,2043 @@ func TestPunctuators(t *testing.T) {
 	runWhiteSpaceTests(t, cases)
 }
 
-func TestSemiColon(t *testing.T) {
-	// Almost all semicolon tests are made interwined on other tests
+func TestRegexp(t *testing.T) {
+	regexp := func(s string) lexer.Tokval {
+		return tokval(token.Regexp, s)
+	}
+
 	runTests(t, []TestCase{
 		{
-			name: "SingleSemiColon",
-			code: Str(";"),
-			want: tokens(semiColonToken()),
+			name: "Simple",
+			code: Str("/abc/"),
+			want: tokens(regexp("/abc/")),
 		},
 		{
-			name: "MultipleSemiColon",
-			code: Str(";;;"),
-			want: tokens(semiColonToken(), semiColonToken(), semiColonToken()),
+			name: "Flags",
+			code: Str("/abc/gim"),
+			want: tokens(regexp("/abc/gim")),
 		},
-	})
-}
-
-func TestLineTerminator(t *testing.T) {
-
-	for name, lt := range lineTerminators() {
-		t.Run(name, func(t *testing.T) {
-			runTests(t, []TestCase{
-				{
-					name: fmt.Sprintf("Single%s", lt),
-					code: Str(lt),
-					want: tokens(),
-				},
-				{
-					name: "Strings",
-					code: sfmt(`"first"%s"second"`, lt),
-					want: tokens(stringToken("first"), stringToken("second")),
-				},
-				{
-					name: "Decimals",
-					code: sfmt("1%s2", lt),
-					want: tokens(decimalToken("1"), decimalToken("2")),
-				},
-				{
-					name: "ExponentDecimals",
-					code: sfmt("1e1%s1e+1%s1e-1%s1", lt, lt, lt),
-					want: tokens(
-						decimalToken("1e1"),
-						decimalToken("1e+1"),
-						decimalToken("1e-1"),
-						decimalToken("1"),
-					),
-				},
-				{
-					name: "RealDecimals",
-					code: sfmt(".1%s245.123", lt),
-					want: tokens(decimalToken(".1"), decimalToken("245.123")),
-				},
-				{
-					name: "Hexadecimals",
-					code: sfmt("0xFF%s0x11", lt),
-					want: tokens(hexToken("0xFF"), hexToken("0x11")),
-				},
-				{
-					name: "Identifiers",
-					code: sfmt("hi%shello", lt),
-					want: tokens(identToken("hi"), identToken("hello")),
-				},
-				{
-					name: "TwoFuncalls", //TODO: update with auto-semicolon insertion
-					code: sfmt("func1(a)%sfunc2(1)%s", lt, lt),
-					want: tokens(
-						identToken("func1"),
-						leftParenToken(),
-						identToken("a"),
-						rightParenToken(),
-						identToken("func2"),
-						leftParenToken(),
-						decimalToken("1"),
-						rightParenToken(),
-					),
-				},
-				{
-					name: "FuncallWithSemiColon",
-					code: sfmt("a();%sb()", lt),
-					want: tokens(
-						identToken("a"),
-						leftParenToken(),
-						rightParenToken(),
-						semiColonToken(),
-						identToken("b"),
-						leftParenToken(),
-						rightParenToken(),
-					),
-				},
-			})
-		})
-	}
-}
-
-func TestIdentifiers(t *testing.T) {
-
-	identCases := []TestCase{
 		{
-			name: "Underscore",
-			code: Str("_"),
-			want: tokens(identToken("_")),
+			name: "EscapedSlash",
+			code: Str(`/a\/b/`),
+			want: tokens(regexp(`/a\/b/`)),
 		},
 		{
-			name: "SingleLetter",
-			code: Str("a"),
-			want: tokens(identToken("a")),
+			name: "SlashInClass",
+			code: Str("/[/]/"),
+			want: tokens(regexp("/[/]/")),
 		},
 		{
-			name: "Self",
-			code: Str("self"),
-			want: tokens(identToken("self")),
+			name: "EscapedBrackInClass",
+			code: Str(`/[\]/]/`),
+			want: tokens(regexp(`/[\]/]/`)),
 		},
 		{
-			name: "Console",
-			code: Str("console"),
-			want: tokens(identToken("console")),
+			name: "Assign",
+			code: Str("a = /b/"),
+			want: tokens(identToken("a"), tokval(token.Assign, "="), regexp("/b/")),
 		},
 		{
-			name: "LotsUnderscores",
-			code: Str("___hyped___"),
-			want: tokens(identToken("___hyped___")),
+			name: "StartsWithEqual",
+			code: Str("a = /=/"),
+			want: tokens(identToken("a"), tokval(token.Assign, "="), regexp("/=/")),
 		},
 		{
-			name: "DollarsIntertwined",
-			code: Str("a$b$c"),
-			want: tokens(identToken("a$b$c")),
+			name: "Argument",
+			code: Str("f(/a/, /b/i)"),
+			want: tokens(
+				identToken("f"),
+				leftParenToken(),
+				regexp("/a/"),
+				commaToken(),
+				regexp("/b/i"),
+				rightParenToken(),
+			),
 		},
 		{
-			name: "NumbersIntertwined",
-			code: Str("a1b2c"),
-			want: tokens(identToken("a1b2c")),
+			name: "MemberAccess",
+			code: Str("/a/.source"),
+			want: tokens(regexp("/a/"), dotToken(), identToken("source")),
 		},
-	}
-
-	accessModCases := []TestCase{
 		{
-			name: "AccessingMember",
-			code: Str("console.log"),
-			want: tokens(
-				identToken("console"),
-				dotToken(),
-				identToken("log"),
-			),
+			name: "Unterminated",
+			code: Str("/abc"),
+			want: []lexer.Tokval{illegalToken("/abc")},
 		},
 		{
-			name: "AccessingNoMember",
-			code: Str("console."),
-			want: tokens(
-				identToken("console"),
-				dotToken(),
-			),
+			name: "Newline",
+			code: Str("/a\nb/"),
+			want: []lexer.Tokval{illegalToken("/a\nb/")},
 		},
 		{
-			name: "AccessingMemberOfMember",
-			code: Str("console.log.toString"),
-			want: tokens(
-				identToken("console"),
-				dotToken(),
-				identToken("log"),
-				dotToken(),
-				identToken("toString"),
-			),
+			name: "EscapedNewline",
+			code: Str("/a\\\nb/"),
+			want: []lexer.Tokval{illegalToken("/a\\\nb/")},
 		},
-	}
-
-	runTests(t, identCases)
-	runTests(t, accessModCases)
-
-	runTokenSepTests(t, identCases)
-	runWhiteSpaceTests(t, identCases)
+	})
 }
 
-func TestFuncall(t *testing.T) {
-	// TODO: add anon funcall "(function (a) { console.log(a); })("hi")"
+func TestDivision(t *testing.T) {
+	quo := tokval(token.Quo, "/")
+
 	runTests(t, []TestCase{
 		{
-			name: "OneLetterFunction",
-			code: Str("a()"),
-			want: tokens(
-				identToken("a"),
-				leftParenToken(),
-				rightParenToken(),
-			),
+			name: "Idents",
+			code: Str("a/b/c"),
+			want: tokens(identToken("a"), quo, identToken("b"), quo, identToken("c")),
 		},
 		{
-			name: "NestingWithSemiColonNewLine",
-			code: Str("a(b(),c(d(),e(),5));\n"),
-			want: tokens(
-				identToken("a"),
-				leftParenToken(),
-				identToken("b"),
-				leftParenToken(),
-				rightParenToken(),
-				commaToken(),
-				identToken("c"),
-				leftParenToken(),
-				identToken("d"),
-				leftParenToken(),
-				rightParenToken(),
-				commaToken(),
-				identToken("e"),
-				leftParenToken(),
-				rightParenToken(),
-				commaToken(),
-				decimalToken("5"),
-				rightParenToken(),
-				rightParenToken(),
-				semiColonToken(),
-			),
+			name: "QuoAssign",
+			code: Str("a /= 2"),
+			want: tokens(identToken("a"), tokval(token.QuoAssign, "/="), decimalToken("2")),
 		},
 		{
-			name: "SeparatedBySemiColon",
-			code: Str("a();b()"),
+			name: "Number",
+			code: Str("1 / 2"),
+			want: tokens(decimalToken("1"), quo, decimalToken("2")),
+		},
+		{
+			name: "String",
+			code: Str(`"a" / 2`),
+			want: tokens(stringToken("a"), quo, decimalToken("2")),
+		},
+		{
+			name: "Paren",
+			code: Str("(a)/b/c"),
 			want: tokens(
-				identToken("a"),
 				leftParenToken(),
+				identToken("a"),
 				rightParenToken(),
-				semiColonToken(),
+				quo,
 				identToken("b"),
-				leftParenToken(),
-				rightParenToken(),
+				quo,
+				identToken("c"),
 			),
 		},
 		{
-			name: "BigFunctionName",
-			code: Str("veryBigFunctionNameThatWouldAnnoyNatel()"),
+			name: "Index",
+			code: Str("a[0] / 2"),
 			want: tokens(
-				identToken("veryBigFunctionNameThatWouldAnnoyNatel"),
-				leftParenToken(),
-				rightParenToken(),
+				identToken("a"),
+				tokval(token.LBrack, "["),
+				decimalToken("0"),
+				tokval(token.RBrack, "]"),
+				quo,
+				decimalToken("2"),
 			),
 		},
 		{
-			name: "MemberFunction",
-			code: Str("console.log()"),
-			want: tokens(
-				identToken("console"),
-				dotToken(),
-				identToken("log"),
-				leftParenToken(),
-				rightParenToken(),
-			),
+			name: "Member",
+			code: Str("a.b / 2"),
+			want: tokens(identToken("a"), dotToken(), identToken("b"), quo, decimalToken("2")),
 		},
 		{
-			name: "WithThreeDigitsDecimalParameter",
-			code: Str("test(666)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				decimalToken("666"),
-				rightParenToken(),
-			),
+			name: "KeywordMember",
+			code: Str("a.in / 2"),
+			want: tokens(identToken("a"), dotToken(), tokval(token.In, "in"), quo, decimalToken("2")),
 		},
 		{
-			name: "WithTwoDigitsDecimalParameter",
-			code: Str("test(66)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				decimalToken("66"),
-				rightParenToken(),
-			),
+			name: "Regexp",
+			code: Str("/a/ / 2"),
+			want: tokens(tokval(token.Regexp, "/a/"), quo, decimalToken("2")),
 		},
 		{
-			name: "WithOneDigitDecimalParameter",
-			code: Str("test(6)"),
+			name: "AfterComment",
+			code: Str("a /* b */ / 2"),
+			want: tokens(identToken("a"), quo, decimalToken("2")),
+		},
+	})
+}
+
+func TestUnspacedNumbers(t *testing.T) {
+	mul := tokval(token.Mul, "*")
+
+	runTests(t, []TestCase{
+		{
+			name: "Arithmetic",
+			code: Str("1+2*3"),
+			want: tokens(decimalToken("1"), plusToken(), decimalToken("2"), mul, decimalToken("3")),
+		},
+		{
+			name: "Division",
+			code: Str("1/2"),
+			want: tokens(decimalToken("1"), tokval(token.Quo, "/"), decimalToken("2")),
+		},
+		{
+			name: "Hexadecimal",
+			code: Str("0x1F+1"),
+			want: tokens(hexToken("0x1F"), plusToken(), decimalToken("1")),
+		},
+		{
+			name: "Real",
+			code: Str("1.5+1"),
+			want: tokens(decimalToken("1.5"), plusToken(), decimalToken("1")),
+		},
+		{
+			name: "Exponent",
+			code: Str("1e5+1"),
+			want: tokens(decimalToken("1e5"), plusToken(), decimalToken("1")),
+		},
+		{
+			name: "SignedExponent",
+			code: Str("1.0e-5-1.0e+5"),
+			want: tokens(decimalToken("1.0e-5"), minusToken(), decimalToken("1.0e+5")),
+		},
+		{
+			name: "LegacyOctal",
+			code: Str("07|1"),
+			want: tokens(tokval(token.Octal, "07"), tokval(token.Or, "|"), decimalToken("1")),
+		},
+		{
+			name: "Binary",
+			code: Str("0b1<<1"),
+			want: tokens(tokval(token.Binary, "0b1"), tokval(token.LShift, "<<"), decimalToken("1")),
+			opts: []lexer.Option{lexer.ES6()},
+		},
+		{
+			name: "BigInt",
+			code: Str("10n*2n"),
+			want: tokens(tokval(token.BigInt, "10n"), mul, tokval(token.BigInt, "2n")),
+		},
+		{
+			name: "Comparison",
+			code: Str("a[0]<=1?1:2"),
 			want: tokens(
-				identToken("test"),
+				identToken("a"),
+				tokval(token.LBrack, "["),
+				decimalToken("0"),
+				tokval(token.RBrack, "]"),
+				tokval(token.LessEq, "<="),
+				decimalToken("1"),
+				tokval(token.Ternary, "?"),
+				decimalToken("1"),
+				tokval(token.Colon, ":"),
+				decimalToken("2"),
+			),
+		},
+		{
+			name: "EmptySignedExponent",
+			code: Str("1e+"),
+			want: []lexer.Tokval{illegalToken("1e+")},
+		},
+		{
+			name: "ExponentSignOnly",
+			code: Str("1e-+1"),
+			want: []lexer.Tokval{illegalToken("1e-+1")},
+		},
+		{
+			name: "EmptyHexadecimal",
+			code: Str("0x+1"),
+			want: []lexer.Tokval{illegalToken("0x+1")},
+		},
+		{
+			name: "DotOperator",
+			code: Str(".+1"),
+			want: []lexer.Tokval{illegalToken(".+1")},
+		},
+	})
+}
+
+// TestPunctuatorsAfterOperands places every punctuator right after
+// a number, an identifier and a string, eg.: 1+ b, a+ b and "s"+ b
+func TestPunctuatorsAfterOperands(t *testing.T) {
+	operands := map[string]lexer.Tokval{
+		"Number": decimalToken("1"),
+		"Ident":  identToken("a"),
+		"String": stringToken("s"),
+	}
+
+	var cases []TestCase
+	for typ := token.Illegal; typ <= token.EOF; typ++ {
+		info := typ.Info()
+		if info.Class != token.ClassPunctuator || typ == token.Dot {
+			continue
+		}
+
+		for name, operand := range operands {
+			code := operand.Value.String()
+			if operand.Type == token.String {
+				code = strconv.Quote(code)
+			}
+
+			cases = append(cases, TestCase{
+				name: fmt.Sprintf("%s%s", name, typ),
+				code: sfmt("%s%s b", code, info.Text),
+				want: tokens(operand, tokval(typ, info.Text), identToken("b")),
+			})
+		}
+	}
+
+	runTests(t, cases)
+}
+
+func TestMemberOfOperands(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Ident",
+			code: Str("a.b"),
+			want: tokens(identToken("a"), dotToken(), identToken("b")),
+		},
+		{
+			name: "String",
+			code: Str(`"abc".length`),
+			want: tokens(stringToken("abc"), dotToken(), identToken("length")),
+		},
+		{
+			name: "StringCall",
+			code: Str(`"a".b(1)`),
+			want: tokens(
+				stringToken("a"),
+				dotToken(),
+				identToken("b"),
 				leftParenToken(),
-				decimalToken("6"),
+				decimalToken("1"),
 				rightParenToken(),
 			),
 		},
 		{
-			name: "DecimalWithExponentParameter",
-			code: Str("test(1e6)"),
+			name: "StringDecimal",
+			code: Str(`"a"+.5`),
+			want: tokens(stringToken("a"), plusToken(), decimalToken(".5")),
+		},
+		{
+			name: "NumberFraction",
+			code: Str("1.+b"),
+			want: tokens(decimalToken("1."), plusToken(), identToken("b")),
+		},
+	})
+}
+
+func TestSemiColon(t *testing.T) {
+	// Almost all semicolon tests are made interwined on other tests
+	runTests(t, []TestCase{
+		{
+			name: "SingleSemiColon",
+			code: Str(";"),
+			want: tokens(semiColonToken()),
+		},
+		{
+			name: "MultipleSemiColon",
+			code: Str(";;;"),
+			want: tokens(semiColonToken(), semiColonToken(), semiColonToken()),
+		},
+	})
+}
+
+func TestLineComments(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Empty",
+			code: Str("//"),
+			want: tokens(),
+		},
+		{
+			name: "OnlyComment",
+			code: Str("// console.log(1)"),
+			want: tokens(),
+		},
+		{
+			name: "AfterIdentifier",
+			code: Str("a// b"),
+			want: tokens(identToken("a")),
+		},
+		{
+			name: "AfterDecimal",
+			code: Str("1//2"),
+			want: tokens(decimalToken("1")),
+		},
+		{
+			name: "AfterHexadecimal",
+			code: Str("0xFF//2"),
+			want: tokens(hexToken("0xFF")),
+		},
+		{
+			name: "AfterParen",
+			code: Str("a()//."),
+			want: tokens(identToken("a"), leftParenToken(), rightParenToken()),
+		},
+		{
+			name: "InsideString",
+			code: Str(`"// a"`),
+			want: tokens(stringToken("// a")),
+		},
+		{
+			name: "Division",
+			code: Str("a / b"),
+			want: tokens(identToken("a"), tokval(token.Quo, "/"), identToken("b")),
+		},
+		{
+			name: "Multiple",
+			code: Str("// a\n// b\nc // d\n"),
+			want: tokens(identToken("c")),
+		},
+	})
+
+	for name, lt := range lineTerminators() {
+		runTests(t, []TestCase{
+			{
+				name:          "EndedBy" + name,
+				code:          sfmt("a // b%sc", lt),
+				checkPosition: true,
+				want: tokens(
+					identTokenPos("a", 1, 1),
+					identTokenPos("c", 2, 1),
+				),
+			},
+		})
+	}
+}
+
+func TestBlockComments(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Empty",
+			code: Str("/**/"),
+			want: tokens(),
+		},
+		{
+			name: "LicenseHeader",
+			code: Str("/*\n * Copyright\n */\na"),
+			want: tokens(identToken("a")),
+		},
+		{
+			name: "Inline",
+			code: Str("f(/* a */ 1, 2/**/)"),
 			want: tokens(
-				identToken("test"),
+				identToken("f"),
 				leftParenToken(),
-				decimalToken("1e6"),
+				decimalToken("1"),
+				commaToken(),
+				decimalToken("2"),
 				rightParenToken(),
 			),
 		},
 		{
-			name: "DecimalWithUpperExponentParameter",
-			code: Str("test(1E6)"),
+			name: "SlashInside",
+			code: Str("/*/ a /*/b"),
+			want: tokens(identToken("b")),
+		},
+		{
+			name: "LineCommentInside",
+			code: Str("/* // */a"),
+			want: tokens(identToken("a")),
+		},
+		{
+			name: "Multiplication",
+			code: Str("a */b/"),
+			want: tokens(identToken("a"), tokval(token.Mul, "*"), tokval(token.Regexp, "/b/")),
+		},
+		{
+			name: "Unterminated",
+			code: Str("a /* b"),
+			want: []lexer.Tokval{identToken("a"), illegalToken("/* b")},
+		},
+		{
+			name: "UnterminatedBySlash",
+			code: Str("/*/"),
+			want: []lexer.Tokval{illegalToken("/*/")},
+		},
+	})
+
+	for name, lt := range lineTerminators() {
+		runTests(t, []TestCase{
+			{
+				name:          "Spanning" + name,
+				code:          sfmt("a /*%s%s*/ b", lt, lt),
+				checkPosition: true,
+				want: tokens(
+					identTokenPos("a", 1, 1),
+					identTokenPos("b", 3, 4),
+				),
+			},
+		})
+	}
+}
+
+func TestCommentTokens(t *testing.T) {
+	comments := []lexer.Option{lexer.Comments()}
+
+	runTests(t, []TestCase{
+		{
+			name: "Line",
+			code: Str("a // b\n// c"),
+			opts: comments,
 			want: tokens(
-				identToken("test"),
+				identToken("a"),
+				tokval(token.Comment, "// b"),
+				tokval(token.Comment, "// c"),
+			),
+		},
+		{
+			name: "Block",
+			code: Str("f(/* a */ 1, /**/)"),
+			opts: comments,
+			want: tokens(
+				identToken("f"),
 				leftParenToken(),
-				decimalToken("1E6"),
+				tokval(token.Comment, "/* a */"),
+				decimalToken("1"),
+				commaToken(),
+				tokval(token.Comment, "/**/"),
 				rightParenToken(),
 			),
 		},
 		{
-			name: "WithSmallestRealDecimalParameter",
-			code: Str("test(.1)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				decimalToken(".1"),
-				rightParenToken(),
-			),
+			name:          "Position",
+			code:          Str("a /*\n b */ c // d\ne"),
+			opts:          comments,
+			checkPosition: true,
+			want: tokens(
+				identTokenPos("a", 1, 1),
+				tokvalPos(token.Comment, "/*\n b */", 1, 3),
+				identTokenPos("c", 2, 7),
+				tokvalPos(token.Comment, "// d", 2, 9),
+				identTokenPos("e", 3, 1),
+			),
+		},
+		{
+			name: "DivisionAfterComment",
+			code: Str("a /* b */ / c"),
+			opts: comments,
+			want: tokens(
+				identToken("a"),
+				tokval(token.Comment, "/* b */"),
+				tokval(token.Quo, "/"),
+				identToken("c"),
+			),
+		},
+		{
+			name: "Unterminated",
+			code: Str("a /* b"),
+			opts: comments,
+			want: []lexer.Tokval{identToken("a"), illegalToken("/* b")},
+		},
+	})
+}
+
+func TestLineTerminator(t *testing.T) {
+
+	for name, lt := range lineTerminators() {
+		t.Run(name, func(t *testing.T) {
+			runTests(t, []TestCase{
+				{
+					name: fmt.Sprintf("Single%s", lt),
+					code: Str(lt),
+					want: tokens(),
+				},
+				{
+					name: "Strings",
+					code: sfmt(`"first"%s"second"`, lt),
+					want: tokens(stringToken("first"), stringToken("second")),
+				},
+				{
+					name: "Decimals",
+					code: sfmt("1%s2", lt),
+					want: tokens(decimalToken("1"), decimalToken("2")),
+				},
+				{
+					name: "ExponentDecimals",
+					code: sfmt("1e1%s1e+1%s1e-1%s1", lt, lt, lt),
+					want: tokens(
+						decimalToken("1e1"),
+						decimalToken("1e+1"),
+						decimalToken("1e-1"),
+						decimalToken("1"),
+					),
+				},
+				{
+					name: "RealDecimals",
+					code: sfmt(".1%s245.123", lt),
+					want: tokens(decimalToken(".1"), decimalToken("245.123")),
+				},
+				{
+					name: "Hexadecimals",
+					code: sfmt("0xFF%s0x11", lt),
+					want: tokens(hexToken("0xFF"), hexToken("0x11")),
+				},
+				{
+					name: "Identifiers",
+					code: sfmt("hi%shello", lt),
+					want: tokens(identToken("hi"), identToken("hello")),
+				},
+				{
+					name: "TwoFuncalls", //TODO: update with auto-semicolon insertion
+					code: sfmt("func1(a)%sfunc2(1)%s", lt, lt),
+					want: tokens(
+						identToken("func1"),
+						leftParenToken(),
+						identToken("a"),
+						rightParenToken(),
+						identToken("func2"),
+						leftParenToken(),
+						decimalToken("1"),
+						rightParenToken(),
+					),
+				},
+				{
+					name: "FuncallWithSemiColon",
+					code: sfmt("a();%sb()", lt),
+					want: tokens(
+						identToken("a"),
+						leftParenToken(),
+						rightParenToken(),
+						semiColonToken(),
+						identToken("b"),
+						leftParenToken(),
+						rightParenToken(),
+					),
+				},
+			})
+		})
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+
+	identCases := []TestCase{
+		{
+			name: "Underscore",
+			code: Str("_"),
+			want: tokens(identToken("_")),
+		},
+		{
+			name: "SingleLetter",
+			code: Str("a"),
+			want: tokens(identToken("a")),
+		},
+		{
+			name: "Self",
+			code: Str("self"),
+			want: tokens(identToken("self")),
+		},
+		{
+			name: "Console",
+			code: Str("console"),
+			want: tokens(identToken("console")),
+		},
+		{
+			name: "LotsUnderscores",
+			code: Str("___hyped___"),
+			want: tokens(identToken("___hyped___")),
+		},
+		{
+			name: "DollarsIntertwined",
+			code: Str("a$b$c"),
+			want: tokens(identToken("a$b$c")),
+		},
+		{
+			name: "NumbersIntertwined",
+			code: Str("a1b2c"),
+			want: tokens(identToken("a1b2c")),
+		},
+		{
+			name: "GreekLetter",
+			code: Str("π"),
+			want: tokens(identToken("π")),
+		},
+		{
+			name: "CJK",
+			code: Str("变量"),
+			want: tokens(identToken("变量")),
+		},
+		{
+			name: "LetterNumber",
+			code: Str("Ⅻ"),
+			want: tokens(identToken("Ⅻ")),
+		},
+		{
+			name: "OutsideBMP",
+			code: Str("𐐀𐐨"),
+			want: tokens(identToken("𐐀𐐨")),
+		},
+		{
+			name: "CombiningMark",
+			code: Str("e\u0301"),
+			want: tokens(identToken("e\u0301")),
+		},
+		{
+			name: "UnicodeDigit",
+			code: Str("x١٢"),
+			want: tokens(identToken("x١٢")),
+		},
+		{
+			name: "ConnectorPunctuation",
+			code: Str("a‿b"),
+			want: tokens(identToken("a‿b")),
+		},
+		{
+			name: "ZWNJAndZWJ",
+			code: Str("a\u200Cb\u200Dc"),
+			want: tokens(identToken("a\u200Cb\u200Dc")),
+		},
+	}
+
+	accessModCases := []TestCase{
+		{
+			name: "AccessingMember",
+			code: Str("console.log"),
+			want: tokens(
+				identToken("console"),
+				dotToken(),
+				identToken("log"),
+			),
+		},
+		{
+			name: "AccessingNoMember",
+			code: Str("console."),
+			want: tokens(
+				identToken("console"),
+				dotToken(),
+			),
+		},
+		{
+			name: "AccessingMemberOfMember",
+			code: Str("console.log.toString"),
+			want: tokens(
+				identToken("console"),
+				dotToken(),
+				identToken("log"),
+				dotToken(),
+				identToken("toString"),
+			),
+		},
+	}
+
+	runTests(t, identCases)
+	runTests(t, accessModCases)
+
+	runTokenSepTests(t, identCases)
+	runWhiteSpaceTests(t, identCases)
+}
+
+func TestIllegalIdentifiers(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Symbol",
+			code: Str("#"),
+			want: []lexer.Tokval{illegalToken("#")},
+		},
+		{
+			name: "SymbolInside",
+			code: Str("a#b = 1"),
+			want: []lexer.Tokval{illegalToken("a#b = 1")},
+		},
+		{
+			name: "CurrencySymbol",
+			code: Str("a€"),
+			want: []lexer.Tokval{illegalToken("a€")},
+		},
+		{
+			name: "StartsWithCombiningMark",
+			code: Str("\u0301e"),
+			want: []lexer.Tokval{illegalToken("\u0301e")},
+		},
+		{
+			name: "StartsWithUnicodeDigit",
+			code: Str("١x"),
+			want: []lexer.Tokval{illegalToken("١x")},
+		},
+		{
+			name: "StartsWithZWJ",
+			code: Str("\u200Da"),
+			want: []lexer.Tokval{illegalToken("\u200Da")},
+		},
+		{
+			name: "Member",
+			code: Str("a.@b"),
+			want: []lexer.Tokval{
+				identToken("a"),
+				dotToken(),
+				illegalToken("@b"),
+			},
+		},
+	})
+}
+
+func TestIdentifierEscapes(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Start",
+			code: Str(`\u0061bc`),
+			want: tokens(identToken("abc")),
+		},
+		{
+			name: "Part",
+			code: Str(`a\u0062\u0063`),
+			want: tokens(identToken("abc")),
+		},
+		{
+			name: "Only",
+			code: Str(`\u03c0`),
+			want: tokens(identToken("π")),
+		},
+		{
+			name: "UpperCaseHex",
+			code: Str(`\u005F\u0024`),
+			want: tokens(identToken("_$")),
+		},
+		{
+			name: "CombiningMark",
+			code: Str(`e\u0301`),
+			want: tokens(identToken("e\u0301")),
+		},
+		{
+			name: "EscapedFutureReservedWord",
+			code: Str(`\u0063lass`),
+			want: tokens(identToken("class")),
+		},
+		{
+			name: "Member",
+			code: Str(`\u0061.\u0062`),
+			want: tokens(identToken("a"), dotToken(), identToken("b")),
+		},
+		{
+			name: "Funcall",
+			code: Str(`\u0066(\u0061)`),
+			want: tokens(
+				identToken("f"),
+				leftParenToken(),
+				identToken("a"),
+				rightParenToken(),
+			),
+		},
+		{
+			name:          "Position",
+			code:          Str(`a\u0062 c`),
+			checkPosition: true,
+			want:          tokens(identTokenPos("ab", 1, 1), identTokenPos("c", 1, 9)),
+		},
+	})
+}
+
+func TestIllegalIdentifierEscapes(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "DigitStart",
+			code: Str(`\u0031a`),
+			want: []lexer.Tokval{illegalToken(`\u0031a`)},
+		},
+		{
+			name: "CombiningMarkStart",
+			code: Str(`\u0301e`),
+			want: []lexer.Tokval{illegalToken(`\u0301e`)},
+		},
+		{
+			name: "InvalidPart",
+			code: Str(`a\u0023`),
+			want: []lexer.Tokval{illegalToken(`a\u0023`)},
+		},
+		{
+			name: "HexEscape",
+			code: Str(`\x61`),
+			want: []lexer.Tokval{illegalToken(`\x61`)},
+		},
+		{
+			name: "Short",
+			code: Str(`a\u006`),
+			want: []lexer.Tokval{illegalToken(`a\u006`)},
+		},
+		{
+			name: "NotHex",
+			code: Str(`\u00g1`),
+			want: []lexer.Tokval{illegalToken(`\u00g1`)},
+		},
+		{
+			name: "Backslash",
+			code: Str(`a\b`),
+			want: []lexer.Tokval{illegalToken(`a\b`)},
+		},
+		{
+			name: "EscapedKeyword",
+			code: Str(`\u0076ar a`),
+			want: []lexer.Tokval{illegalToken(`\u0076ar a`)},
+		},
+		{
+			name: "EscapedSurrogate",
+			code: Str(`\uD801\uDC00`),
+			want: []lexer.Tokval{illegalToken(`\uD801\uDC00`)},
+		},
+	})
+}
+
+func TestFuncall(t *testing.T) {
+	// TODO: add anon funcall "(function (a) { console.log(a); })("hi")"
+	runTests(t, []TestCase{
+		{
+			name: "OneLetterFunction",
+			code: Str("a()"),
+			want: tokens(
+				identToken("a"),
+				leftParenToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "NestingWithSemiColonNewLine",
+			code: Str("a(b(),c(d(),e(),5));\n"),
+			want: tokens(
+				identToken("a"),
+				leftParenToken(),
+				identToken("b"),
+				leftParenToken(),
+				rightParenToken(),
+				commaToken(),
+				identToken("c"),
+				leftParenToken(),
+				identToken("d"),
+				leftParenToken(),
+				rightParenToken(),
+				commaToken(),
+				identToken("e"),
+				leftParenToken(),
+				rightParenToken(),
+				commaToken(),
+				decimalToken("5"),
+				rightParenToken(),
+				rightParenToken(),
+				semiColonToken(),
+			),
+		},
+		{
+			name: "SeparatedBySemiColon",
+			code: Str("a();b()"),
+			want: tokens(
+				identToken("a"),
+				leftParenToken(),
+				rightParenToken(),
+				semiColonToken(),
+				identToken("b"),
+				leftParenToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "BigFunctionName",
+			code: Str("veryBigFunctionNameThatWouldAnnoyNatel()"),
+			want: tokens(
+				identToken("veryBigFunctionNameThatWouldAnnoyNatel"),
+				leftParenToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "MemberFunction",
+			code: Str("console.log()"),
+			want: tokens(
+				identToken("console"),
+				dotToken(),
+				identToken("log"),
+				leftParenToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithThreeDigitsDecimalParameter",
+			code: Str("test(666)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("666"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithTwoDigitsDecimalParameter",
+			code: Str("test(66)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("66"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithOneDigitDecimalParameter",
+			code: Str("test(6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "DecimalWithExponentParameter",
+			code: Str("test(1e6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("1e6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "DecimalWithUpperExponentParameter",
+			code: Str("test(1E6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("1E6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithSmallestRealDecimalParameter",
+			code: Str("test(.1)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken(".1"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "RealDecimalWithExponentParameter",
+			code: Str("test(1.1e6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("1.1e6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "RealDecimalWithUpperExponentParameter",
+			code: Str("test(1.1E6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("1.1E6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithRealDecimalParameter",
+			code: Str("test(6.6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				decimalToken("6.6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithOneDigitHexadecimalParameter",
+			code: Str("test(0x6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				hexToken("0x6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithOneDigitUpperHexadecimalParameter",
+			code: Str("test(0X6)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				hexToken("0X6"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithTwoDigitHexadecimalParameter",
+			code: Str("test(0x66)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				hexToken("0x66"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "WithTwoDigitUpperHexadecimalParameter",
+			code: Str("test(0X66)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				hexToken("0X66"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "CommaSeparatedNumbersParameters",
+			code: Str("test(0X6,0x7,0x78,0X69,8,69,669,6.9,.9,3e1,4E7,4e7)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				hexToken("0X6"),
+				commaToken(),
+				hexToken("0x7"),
+				commaToken(),
+				hexToken("0x78"),
+				commaToken(),
+				hexToken("0X69"),
+				commaToken(),
+				decimalToken("8"),
+				commaToken(),
+				decimalToken("69"),
+				commaToken(),
+				decimalToken("669"),
+				commaToken(),
+				decimalToken("6.9"),
+				commaToken(),
+				decimalToken(".9"),
+				commaToken(),
+				decimalToken("3e1"),
+				commaToken(),
+				decimalToken("4E7"),
+				commaToken(),
+				decimalToken("4e7"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "CommaSeparatedNumbersAndStringsParameters",
+			code: Str(`test("",5,"i",4,"k",6.6,0x5,"jssucks")`),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				stringToken(""),
+				commaToken(),
+				decimalToken("5"),
+				commaToken(),
+				stringToken("i"),
+				commaToken(),
+				decimalToken("4"),
+				commaToken(),
+				stringToken("k"),
+				commaToken(),
+				decimalToken("6.6"),
+				commaToken(),
+				hexToken("0x5"),
+				commaToken(),
+				stringToken("jssucks"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "PassingIdentifierAsArg",
+			code: Str("test(arg)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				identToken("arg"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "PassingIdentifiersAsArg",
+			code: Str("test(arg,arg2,i4k)"),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				identToken("arg"),
+				commaToken(),
+				identToken("arg2"),
+				commaToken(),
+				identToken("i4k"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "CommaSeparatedEverything",
+			code: Str(`test("",5,"i",4,"k",6.6,0x5,arg,"jssucks",false,true,undefined,null)`),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				stringToken(""),
+				commaToken(),
+				decimalToken("5"),
+				commaToken(),
+				stringToken("i"),
+				commaToken(),
+				decimalToken("4"),
+				commaToken(),
+				stringToken("k"),
+				commaToken(),
+				decimalToken("6.6"),
+				commaToken(),
+				hexToken("0x5"),
+				commaToken(),
+				identToken("arg"),
+				commaToken(),
+				stringToken("jssucks"),
+				commaToken(),
+				boolToken("false"),
+				commaToken(),
+				boolToken("true"),
+				commaToken(),
+				identToken("undefined"),
+				commaToken(),
+				nullToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "CommaSeparatedEverythingWithSpaces",
+			code: Str(` test (  "" , 5 , "i" ,4 , "k", 6.6, 0x5, arg, "jssucks", false, true, undefined , null )  `),
+			want: tokens(
+				identToken("test"),
+				leftParenToken(),
+				stringToken(""),
+				commaToken(),
+				decimalToken("5"),
+				commaToken(),
+				stringToken("i"),
+				commaToken(),
+				decimalToken("4"),
+				commaToken(),
+				stringToken("k"),
+				commaToken(),
+				decimalToken("6.6"),
+				commaToken(),
+				hexToken("0x5"),
+				commaToken(),
+				identToken("arg"),
+				commaToken(),
+				stringToken("jssucks"),
+				commaToken(),
+				boolToken("false"),
+				commaToken(),
+				boolToken("true"),
+				commaToken(),
+				identToken("undefined"),
+				commaToken(),
+				nullToken(),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "IndexAndMember",
+			code: Str("s[0].length=a[b]"),
+			want: tokens(
+				identToken("s"),
+				tokval(token.LBrack, "["),
+				decimalToken("0"),
+				tokval(token.RBrack, "]"),
+				dotToken(),
+				identToken("length"),
+				tokval(token.Assign, "="),
+				identToken("a"),
+				tokval(token.LBrack, "["),
+				identToken("b"),
+				tokval(token.RBrack, "]"),
+			),
+		},
+		{
+			name: "MemberOfParenthesizedExpr",
+			code: Str("(console).log(1)"),
+			want: tokens(
+				leftParenToken(),
+				identToken("console"),
+				rightParenToken(),
+				dotToken(),
+				identToken("log"),
+				leftParenToken(),
+				decimalToken("1"),
+				rightParenToken(),
+			),
+		},
+	})
+}
+
+func TestFundecl(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "EmptyFunDecl",
+			code: Str("function a() {}"),
+			want: tokens(
+				tokval(token.Function, "function"),
+				identToken("a"),
+				leftParenToken(), rightParenToken(),
+				tokval(token.LBrace, "{"),
+				tokval(token.RBrace, "}"),
+			),
+		},
+		{
+			name: "UnderscoreFunDecl",
+			code: Str("function _() {}"),
+			want: tokens(
+				tokval(token.Function, "function"),
+				identToken("_"),
+				leftParenToken(), rightParenToken(),
+				tokval(token.LBrace, "{"),
+				tokval(token.RBrace, "}"),
+			),
+		},
+		{
+			name: "FunDeclArgs",
+			code: Str("function split(arr, delim) {}"),
+			want: tokens(
+				tokval(token.Function, "function"),
+				identToken("split"),
+				leftParenToken(),
+				identToken("arr"), commaToken(), identToken("delim"),
+				rightParenToken(),
+				tokval(token.LBrace, "{"),
+				tokval(token.RBrace, "}"),
+			),
+		},
+		{
+			name: "FunDeclArgsBody",
+			code: Str("function split(arr, delim) { something; }"),
+			want: tokens(
+				tokval(token.Function, "function"),
+				identToken("split"),
+				leftParenToken(),
+				identToken("arr"), commaToken(), identToken("delim"),
+				rightParenToken(),
+				tokval(token.LBrace, "{"),
+				identToken("something"),
+				semiColonToken(),
+				tokval(token.RBrace, "}"),
+			),
+		},
+	})
+}
+
+func TestPosition(t *testing.T) {
+	cases := []TestCase{
+		{
+			name:          "MinusDecimal",
+			code:          Str("-1"),
+			checkPosition: true,
+			want:          tokens(minusTokenPos(1, 1), decimalTokenPos("1", 1, 2)),
+		},
+		{
+			name:          "PlusDecimal",
+			code:          Str("+1"),
+			checkPosition: true,
+			want:          tokens(plusTokenPos(1, 1), decimalTokenPos("1", 1, 2)),
+		},
+		{
+			name:          "PlusMinusDecimal",
+			code:          Str("+-666"),
+			checkPosition: true,
+			want:          tokens(plusTokenPos(1, 1), minusTokenPos(1, 2), decimalTokenPos("666", 1, 3)),
+		},
+	}
+
+	for name, lt := range lineTerminators() {
+		code := sfmt(`func(a)%sfuncb(1)%sfuncc("hi")`, lt, lt)
+		cases = append(cases, TestCase{
+			name:          "FuncallsSeparatedBy" + name,
+			code:          code,
+			checkPosition: true,
+			want: tokens(
+				identTokenPos("func", 1, 1),
+				leftParenTokenPos(1, 5),
+				identTokenPos("a", 1, 6),
+				rightParenTokenPos(1, 7),
+				identTokenPos("funcb", 2, 1),
+				leftParenTokenPos(2, 6),
+				decimalTokenPos("1", 2, 7),
+				rightParenTokenPos(2, 8),
+				identTokenPos("funcc", 3, 1),
+				leftParenTokenPos(3, 6),
+				stringTokenPos("hi", 3, 7),
+				rightParenTokenPos(3, 11),
+			),
+		})
+	}
+
+	for name, sp := range whiteSpaces() {
+		code := sfmt(`func(a)%sfuncb(1)`, sp)
+		cases = append(cases, TestCase{
+			name:          "FuncallsSeparatedBy" + name,
+			code:          code,
+			checkPosition: true,
+			want: tokens(
+				identTokenPos("func", 1, 1),
+				leftParenTokenPos(1, 5),
+				identTokenPos("a", 1, 6),
+				rightParenTokenPos(1, 7),
+				identTokenPos("funcb", 1, 9),
+				leftParenTokenPos(1, 14),
+				decimalTokenPos("1", 1, 15),
+				rightParenTokenPos(1, 16),
+			),
+		})
+	}
+
+	runTests(t, cases)
+}
+
+func TestIllegalSingleDot(t *testing.T) {
+	cases := []TestCase{
+		{
+			name: "Nothing",
+			code: Str("."),
+			want: []lexer.Tokval{illegalToken(".")},
+		},
+	}
+
+	for _, ts := range tokenSeparators() {
+		code := sfmt(".%s.", ts.Value.String())
+		cases = append(cases, TestCase{
+			name: ts.Type.String(),
+			code: code,
+			want: []lexer.Tokval{illegalToken(code.String())},
+		})
+	}
+
+	runTests(t, cases)
+}
+
+func TestIllegalMemberAccess(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "CantAccessMemberThatStartsWithNumber",
+			code: Str("test.123"),
+			want: []lexer.Tokval{
+				identToken("test"),
+				dotToken(),
+				illegalToken("123"),
+			},
+		},
+		{
+			name: "CantAccessMemberThatStartsWithDot",
+			code: Str("test.."),
+			want: []lexer.Tokval{
+				identToken("test"),
+				dotToken(),
+				illegalToken("."),
+			},
+		},
+	})
+}
+
+func TestStringEscapes(t *testing.T) {
+	cases := []TestCase{
+		{
+			name: "SingleChars",
+			code: Str(`"\b\t\n\v\f\r\"\'\\"`),
+			want: tokens(stringToken("\b\t\n\v\f\r\"'\\")),
+		},
+		{
+			name: "EscapedQuoteAtEnd",
+			code: Str(`"a\""`),
+			want: tokens(stringToken(`a"`)),
+		},
+		{
+			name: "Null",
+			code: Str(`"\0"`),
+			want: tokens(stringToken("\x00")),
+		},
+		{
+			name: "Hex",
+			code: Str(`"\x41\x7a"`),
+			want: tokens(stringToken("Az")),
+		},
+		{
+			name: "HexBoundaries",
+			code: Str(`"\x00\xff\xFF1"`),
+			want: tokens(lexer.Tokval{Type: token.String, Value: utf16.Str{0, 0xFF, 0xFF, '1'}}),
+		},
+		{
+			name: "Unicode",
+			code: Str(`"\u00e7\u4E16"`),
+			want: tokens(stringToken("ç世")),
+		},
+		{
+			name: "SurrogatePair",
+			code: Str(`"\uD83D\uDE00"`),
+			want: tokens(stringToken("😀")),
+		},
+		{
+			name: "LoneSurrogate",
+			code: Str(`"\uD83D"`),
+			want: tokens(lexer.Tokval{Type: token.String, Value: utf16.Str{0xD83D}}),
+		},
+		{
+			name: "UnicodeNotSurrogates",
+			code: Str(`"\u0041\u0042"`),
+			want: tokens(stringToken("AB")),
+		},
+		{
+			name: "SurrogatePairMixed",
+			code: Str(`"\uD83D\uDE00\u0041\uD83D\uDE01"`),
+			want: tokens(stringToken("😀A😁")),
+		},
+		{
+			name: "NonEscapeChar",
+			code: Str(`"\a\c\ç"`),
+			want: tokens(stringToken("acç")),
+		},
+		{
+			name: "NonBMPChar",
+			code: Str(`"😀\😀"`),
+			want: tokens(stringToken("😀😀")),
+		},
+	}
+
+	for name, lt := range lineTerminators() {
+		cases = append(cases, TestCase{
+			name: "LineContinuation" + name,
+			code: sfmt(`"head\%stail"`, lt),
+			want: tokens(stringToken("headtail")),
+		})
+	}
+
+	cases = append(cases, TestCase{
+		name: "LineContinuationCRLF",
+		code: Str("\"head\\\r\ntail\""),
+		want: tokens(stringToken("headtail")),
+	})
+
+	runTests(t, cases)
+}
+
+func TestStringLineContinuationPosition(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name:          "IdentAfter",
+			code:          Str("a \"b\\\nc\" d"),
+			checkPosition: true,
+			want: tokens(
+				identTokenPos("a", 1, 1),
+				stringTokenPos("bc", 1, 3),
+				identTokenPos("d", 2, 4),
+			),
+		},
+	})
+}
+
+func TestRawValues(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		opts []lexer.Option
+		want []string
+	}{
+		{code: `"a\tb"`, want: []string{`"a\tb"`}},
+		{code: "\"a\\\nb\"", want: []string{"\"a\\\nb\""}},
+		{code: `a = "\x41"`, want: []string{"", "", `"\x41"`}},
+		{code: `\u0061b`, want: []string{`\u0061b`}},
+		{code: "1 + 2", want: []string{"", "", ""}},
+		{
+			code: "`a\\n${b}c\\u0041`",
+			opts: []lexer.Option{lexer.ES6()},
+			want: []string{"`a\\n${", "", "}c\\u0041`"},
+		},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			var raws []string
+			lx := lexer.New(Str(tc.code), tc.opts...)
+			for tok := lx.Next(); tok.Type != token.EOF; tok = lx.Next() {
+				if tok.Type == token.Illegal {
+					t.Fatalf("illegal token: %s", tok.Err)
+				}
+				raws = append(raws, tok.Raw.String())
+			}
+
+			if len(raws) != len(tc.want) {
+				t.Fatalf("got raw values %q, want %q", raws, tc.want)
+			}
+			for i := range raws {
+				if raws[i] != tc.want[i] {
+					t.Fatalf("got raw values %q, want %q", raws, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidStrings(t *testing.T) {
+
+	cases := []TestCase{
+		{
+			name: "SingleDoubleQuote",
+			code: Str(`"`),
+			want: []lexer.Tokval{illegalToken(`"`)},
+		},
+		{
+			name: "NoEndingDoubleQuote",
+			code: Str(`"dsadasdsa123456`),
+			want: []lexer.Tokval{illegalToken(`"dsadasdsa123456`)},
+		},
+		{
+			name: "EscapedEndingDoubleQuote",
+			code: Str(`"a\"`),
+			want: []lexer.Tokval{illegalToken(`"a\"`)},
+		},
+		{
+			name: "BackslashAtEOF",
+			code: Str(`"a\`),
+			want: []lexer.Tokval{illegalToken(`"a\`)},
+		},
+		{
+			name: "ShortHex",
+			code: Str(`"\x4"`),
+			want: []lexer.Tokval{illegalToken(`"\x4"`)},
+		},
+		{
+			name: "HexWithoutDigits",
+			code: Str(`"\x"`),
+			want: []lexer.Tokval{illegalToken(`"\x"`)},
+		},
+		{
+			name: "HexWithOneDigitBeforeChar",
+			code: Str(`"\x4g"`),
+			want: []lexer.Tokval{illegalToken(`"\x4g"`)},
+		},
+		{
+			name: "InvalidHex",
+			code: Str(`"\xZZ"`),
+			want: []lexer.Tokval{illegalToken(`"\xZZ"`)},
+		},
+		{
+			name: "ShortUnicode",
+			code: Str(`"\u123"`),
+			want: []lexer.Tokval{illegalToken(`"\u123"`)},
+		},
+		{
+			name: "InvalidUnicode",
+			code: Str(`"\u12G4"`),
+			want: []lexer.Tokval{illegalToken(`"\u12G4"`)},
+		},
+		{
+			name: "SignedUnicode",
+			code: Str(`"\u+123"`),
+			want: []lexer.Tokval{illegalToken(`"\u+123"`)},
+		},
+		{
+			name: "UnicodeCodePoint",
+			code: Str(`"\u{1F600}"`),
+			want: []lexer.Tokval{illegalToken(`"\u{1F600}"`)},
+		},
+		{
+			name: "Octal",
+			code: Str(`"\12"`),
+			want: []lexer.Tokval{illegalToken(`"\12"`)},
+		},
+		{
+			name: "NullFollowedByDigit",
+			code: Str(`"\01"`),
+			want: []lexer.Tokval{illegalToken(`"\01"`)},
+		},
+	}
+
+	for name, lt := range lineTerminators() {
+		code := fmt.Sprintf(`"head%stail"`, lt)
+		cases = append(cases, TestCase{
+			code: Str(code),
+			name: "NewlineTerminator" + name,
+			want: []lexer.Tokval{illegalToken(code)},
+		})
+	}
+
+	runTests(t, cases)
+}
+
+func TestLegacyOctal(t *testing.T) {
+	octal := func(s string) lexer.Tokval {
+		return tokval(token.Octal, s)
+	}
+
+	cases := []TestCase{
+		{
+			name: "Octal",
+			code: Str("0777"),
+			want: tokens(octal("0777")),
+		},
+		{
+			name: "Zeros",
+			code: Str("00"),
+			want: tokens(octal("00")),
+		},
+		{
+			name: "NonOctalDigitIsDecimal",
+			code: Str("0778"),
+			want: tokens(decimalToken("0778")),
+		},
+		{
+			name: "NonOctalDigitWithFraction",
+			code: Str("09.5"),
+			want: tokens(decimalToken("09.5")),
+		},
+		{
+			name: "Argument",
+			code: Str("f(01, 02)"),
+			want: tokens(
+				identToken("f"),
+				leftParenToken(),
+				octal("01"),
+				commaToken(),
+				octal("02"),
+				rightParenToken(),
+			),
+		},
+		{
+			name: "Fraction",
+			code: Str("07.5"),
+			want: []lexer.Tokval{illegalToken("07.5")},
+		},
+		{
+			name: "Exponent",
+			code: Str("07e1"),
+			want: []lexer.Tokval{illegalToken("07e1")},
+		},
+	}
+
+	runTests(t, cases)
+	runWhiteSpaceTests(t, cases[:3])
+
+	runTests(t, []TestCase{
+		{
+			name: "Strict",
+			code: Str("0777"),
+			opts: []lexer.Option{lexer.Strict()},
+			want: []lexer.Tokval{illegalToken("0777")},
+		},
+		{
+			name: "StrictZero",
+			code: Str("0"),
+			opts: []lexer.Option{lexer.Strict()},
+			want: tokens(decimalToken("0")),
+		},
+		{
+			name: "StrictNonOctalDigit",
+			code: Str("08"),
+			opts: []lexer.Option{lexer.Strict()},
+			want: tokens(decimalToken("08")),
+		},
+	})
+}
+
+func TestES6NumericLiterals(t *testing.T) {
+	es6 := []lexer.Option{lexer.ES6()}
+
+	runTests(t, []TestCase{
+		{
+			name: "Binary",
+			code: Str("0b1010"),
+			opts: es6,
+			want: tokens(tokval(token.Binary, "0b1010")),
+		},
+		{
+			name: "UpperCaseBinary",
+			code: Str("0B1"),
+			opts: es6,
+			want: tokens(tokval(token.Binary, "0B1")),
+		},
+		{
+			name: "Octal",
+			code: Str("0o755"),
+			opts: es6,
+			want: tokens(tokval(token.Octal, "0o755")),
+		},
+		{
+			name: "UpperCaseOctal",
+			code: Str("0O7"),
+			opts: es6,
+			want: tokens(tokval(token.Octal, "0O7")),
 		},
 		{
-			name: "RealDecimalWithExponentParameter",
-			code: Str("test(1.1e6)"),
+			name: "Argument",
+			code: Str("f(0b1, 0o7)"),
+			opts: es6,
 			want: tokens(
-				identToken("test"),
+				identToken("f"),
 				leftParenToken(),
-				decimalToken("1.1e6"),
+				tokval(token.Binary, "0b1"),
+				commaToken(),
+				tokval(token.Octal, "0o7"),
 				rightParenToken(),
 			),
 		},
 		{
-			name: "RealDecimalWithUpperExponentParameter",
-			code: Str("test(1.1E6)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				decimalToken("1.1E6"),
-				rightParenToken(),
-			),
+			name: "Division",
+			code: Str("0b10 / 2"),
+			opts: es6,
+			want: tokens(tokval(token.Binary, "0b10"), tokval(token.Quo, "/"), decimalToken("2")),
 		},
 		{
-			name: "WithRealDecimalParameter",
-			code: Str("test(6.6)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				decimalToken("6.6"),
-				rightParenToken(),
-			),
+			name: "NoBinaryDigits",
+			code: Str("0b"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("0b")},
 		},
 		{
-			name: "WithOneDigitHexadecimalParameter",
-			code: Str("test(0x6)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				hexToken("0x6"),
-				rightParenToken(),
-			),
+			name: "NoOctalDigits",
+			code: Str("0o;"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("0o;")},
 		},
 		{
-			name: "WithOneDigitUpperHexadecimalParameter",
-			code: Str("test(0X6)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				hexToken("0X6"),
-				rightParenToken(),
-			),
+			name: "InvalidBinaryDigit",
+			code: Str("0b102"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("0b102")},
 		},
 		{
-			name: "WithTwoDigitHexadecimalParameter",
-			code: Str("test(0x66)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				hexToken("0x66"),
-				rightParenToken(),
-			),
+			name: "InvalidOctalDigit",
+			code: Str("0o78"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("0o78")},
 		},
 		{
-			name: "WithTwoDigitUpperHexadecimalParameter",
-			code: Str("test(0X66)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				hexToken("0X66"),
-				rightParenToken(),
-			),
+			name: "BinaryWithoutES6",
+			code: Str("0b1"),
+			want: []lexer.Tokval{illegalToken("0b1")},
 		},
 		{
-			name: "CommaSeparatedNumbersParameters",
-			code: Str("test(0X6,0x7,0x78,0X69,8,69,669,6.9,.9,3e1,4E7,4e7)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				hexToken("0X6"),
-				commaToken(),
-				hexToken("0x7"),
-				commaToken(),
-				hexToken("0x78"),
-				commaToken(),
-				hexToken("0X69"),
-				commaToken(),
-				decimalToken("8"),
-				commaToken(),
-				decimalToken("69"),
-				commaToken(),
-				decimalToken("669"),
-				commaToken(),
-				decimalToken("6.9"),
-				commaToken(),
-				decimalToken(".9"),
-				commaToken(),
-				decimalToken("3e1"),
-				commaToken(),
-				decimalToken("4E7"),
-				commaToken(),
-				decimalToken("4e7"),
-				rightParenToken(),
-			),
+			name: "OctalWithoutES6",
+			code: Str("0o7"),
+			want: []lexer.Tokval{illegalToken("0o7")},
 		},
+	})
+}
+
+func TestBigIntLiterals(t *testing.T) {
+	es6 := []lexer.Option{lexer.ES6()}
+	bigint := func(val string) lexer.Tokval {
+		return tokval(token.BigInt, val)
+	}
+
+	runTests(t, []TestCase{
 		{
-			name: "CommaSeparatedNumbersAndStringsParameters",
-			code: Str(`test("",5,"i",4,"k",6.6,0x5,"jssucks")`),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				stringToken(""),
-				commaToken(),
-				decimalToken("5"),
-				commaToken(),
-				stringToken("i"),
-				commaToken(),
-				decimalToken("4"),
-				commaToken(),
-				stringToken("k"),
-				commaToken(),
-				decimalToken("6.6"),
-				commaToken(),
-				hexToken("0x5"),
-				commaToken(),
-				stringToken("jssucks"),
-				rightParenToken(),
-			),
+			name: "Decimal",
+			code: Str("1234567890123456789012345n"),
+			want: tokens(bigint("1234567890123456789012345n")),
 		},
 		{
-			name: "PassingIdentifierAsArg",
-			code: Str("test(arg)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				identToken("arg"),
-				rightParenToken(),
-			),
+			name: "Zero",
+			code: Str("0n"),
+			want: tokens(bigint("0n")),
 		},
 		{
-			name: "PassingIdentifiersAsArg",
-			code: Str("test(arg,arg2,i4k)"),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				identToken("arg"),
-				commaToken(),
-				identToken("arg2"),
-				commaToken(),
-				identToken("i4k"),
-				rightParenToken(),
-			),
+			name: "Hexadecimal",
+			code: Str("0xFFn"),
+			want: tokens(bigint("0xFFn")),
 		},
 		{
-			name: "CommaSeparatedEverything",
-			code: Str(`test("",5,"i",4,"k",6.6,0x5,arg,"jssucks",false,true,undefined,null)`),
-			want: tokens(
-				identToken("test"),
-				leftParenToken(),
-				stringToken(""),
-				commaToken(),
-				decimalToken("5"),
-				commaToken(),
-				stringToken("i"),
-				commaToken(),
-				decimalToken("4"),
-				commaToken(),
-				stringToken("k"),
-				commaToken(),
-				decimalToken("6.6"),
-				commaToken(),
-				hexToken("0x5"),
-				commaToken(),
-				identToken("arg"),
-				commaToken(),
-				stringToken("jssucks"),
-				commaToken(),
-				boolToken("false"),
-				commaToken(),
-				boolToken("true"),
-				commaToken(),
-				undefinedToken(),
-				commaToken(),
-				nullToken(),
-				rightParenToken(),
-			),
+			name: "Binary",
+			code: Str("0b101n"),
+			opts: es6,
+			want: tokens(bigint("0b101n")),
 		},
 		{
-			name: "CommaSeparatedEverythingWithSpaces",
-			code: Str(` test (  "" , 5 , "i" ,4 , "k", 6.6, 0x5, arg, "jssucks", false, true, undefined , null )  `),
+			name: "Octal",
+			code: Str("0o17n"),
+			opts: es6,
+			want: tokens(bigint("0o17n")),
+		},
+		{
+			name: "Argument",
+			code: Str("f(1n, 2n)"),
 			want: tokens(
-				identToken("test"),
+				identToken("f"),
 				leftParenToken(),
-				stringToken(""),
-				commaToken(),
-				decimalToken("5"),
-				commaToken(),
-				stringToken("i"),
-				commaToken(),
-				decimalToken("4"),
-				commaToken(),
-				stringToken("k"),
-				commaToken(),
-				decimalToken("6.6"),
-				commaToken(),
-				hexToken("0x5"),
-				commaToken(),
-				identToken("arg"),
-				commaToken(),
-				stringToken("jssucks"),
-				commaToken(),
-				boolToken("false"),
-				commaToken(),
-				boolToken("true"),
-				commaToken(),
-				undefinedToken(),
+				bigint("1n"),
 				commaToken(),
-				nullToken(),
+				bigint("2n"),
 				rightParenToken(),
 			),
 		},
+		{
+			name: "Division",
+			code: Str("10n / 2n"),
+			want: tokens(bigint("10n"), tokval(token.Quo, "/"), bigint("2n")),
+		},
+		{
+			name: "Fraction",
+			code: Str("1.5n"),
+			want: []lexer.Tokval{illegalToken("1.5n")},
+		},
+		{
+			name: "Exponent",
+			code: Str("1e3n"),
+			want: []lexer.Tokval{illegalToken("1e3n")},
+		},
+		{
+			name: "LeadingZero",
+			code: Str("01n"),
+			want: []lexer.Tokval{illegalToken("01n")},
+		},
+		{
+			name: "LeadingZeroDecimal",
+			code: Str("09n"),
+			want: []lexer.Tokval{illegalToken("09n")},
+		},
+		{
+			name: "NoHexDigits",
+			code: Str("0xn"),
+			want: []lexer.Tokval{illegalToken("0xn")},
+		},
+		{
+			name: "IdentifierAfter",
+			code: Str("1na"),
+			want: []lexer.Tokval{illegalToken("1na")},
+		},
+		{
+			name: "TwoSuffixes",
+			code: Str("1nn"),
+			want: []lexer.Tokval{illegalToken("1nn")},
+		},
 	})
 }
 
-func TestFundecl(t *testing.T) {
+func TestTemplates(t *testing.T) {
+	es6 := []lexer.Option{lexer.ES6()}
+
 	runTests(t, []TestCase{
 		{
-			name: "EmptyFunDecl",
-			code: Str("function a() {}"),
+			name: "Empty",
+			code: Str("``"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "")),
+		},
+		{
+			name: "NoSubstitution",
+			code: Str("`abc`"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "abc")),
+		},
+		{
+			name: "Substitution",
+			code: Str("`a${b}c`"),
+			opts: es6,
 			want: tokens(
-				tokval(token.Function, "function"),
-				identToken("a"),
-				leftParenToken(), rightParenToken(),
-				tokval(token.LBrace, "{"),
-				tokval(token.RBrace, "}"),
+				tokval(token.TemplateHead, "a"),
+				identToken("b"),
+				tokval(token.TemplateTail, "c"),
 			),
 		},
 		{
-			name: "UnderscoreFunDecl",
-			code: Str("function _() {}"),
+			name: "EmptySpans",
+			code: Str("`${a}${b}`"),
+			opts: es6,
 			want: tokens(
-				tokval(token.Function, "function"),
-				identToken("_"),
-				leftParenToken(), rightParenToken(),
-				tokval(token.LBrace, "{"),
-				tokval(token.RBrace, "}"),
+				tokval(token.TemplateHead, ""),
+				identToken("a"),
+				tokval(token.TemplateMiddle, ""),
+				identToken("b"),
+				tokval(token.TemplateTail, ""),
 			),
 		},
 		{
-			name: "FunDeclArgs",
-			code: Str("function split(arr, delim) {}"),
+			name: "Funcall",
+			code: Str("`${f(1)}!`"),
+			opts: es6,
 			want: tokens(
-				tokval(token.Function, "function"),
-				identToken("split"),
+				tokval(token.TemplateHead, ""),
+				identToken("f"),
 				leftParenToken(),
-				identToken("arr"), commaToken(), identToken("delim"),
+				decimalToken("1"),
 				rightParenToken(),
-				tokval(token.LBrace, "{"),
-				tokval(token.RBrace, "}"),
+				tokval(token.TemplateTail, "!"),
 			),
 		},
 		{
-			name: "FunDeclArgsBody",
-			code: Str("function split(arr, delim) { something; }"),
+			name: "Braces",
+			code: Str("`${ {a: {}} }`"),
+			opts: es6,
 			want: tokens(
-				tokval(token.Function, "function"),
-				identToken("split"),
-				leftParenToken(),
-				identToken("arr"), commaToken(), identToken("delim"),
-				rightParenToken(),
+				tokval(token.TemplateHead, ""),
 				tokval(token.LBrace, "{"),
-				identToken("something"),
-				semiColonToken(),
+				identToken("a"),
+				tokval(token.Colon, ":"),
+				tokval(token.LBrace, "{"),
+				tokval(token.RBrace, "}"),
 				tokval(token.RBrace, "}"),
+				tokval(token.TemplateTail, ""),
 			),
 		},
-	})
-}
-
-func TestPosition(t *testing.T) {
-	cases := []TestCase{
 		{
-			name:          "MinusDecimal",
-			code:          Str("-1"),
-			checkPosition: true,
-			want:          tokens(minusTokenPos(1, 1), decimalTokenPos("1", 1, 2)),
+			name: "Nested",
+			code: Str("`a${`b${c}`}d`"),
+			opts: es6,
+			want: tokens(
+				tokval(token.TemplateHead, "a"),
+				tokval(token.TemplateHead, "b"),
+				identToken("c"),
+				tokval(token.TemplateTail, ""),
+				tokval(token.TemplateTail, "d"),
+			),
 		},
 		{
-			name:          "PlusDecimal",
-			code:          Str("+1"),
-			checkPosition: true,
-			want:          tokens(plusTokenPos(1, 1), decimalTokenPos("1", 1, 2)),
+			name: "AfterIdent",
+			code: Str("a`b`"),
+			opts: es6,
+			want: tokens(identToken("a"), tokval(token.Template, "b")),
 		},
 		{
-			name:          "PlusMinusDecimal",
-			code:          Str("+-666"),
-			checkPosition: true,
-			want:          tokens(plusTokenPos(1, 1), minusTokenPos(1, 2), decimalTokenPos("666", 1, 3)),
+			name: "Escapes",
+			code: Str("`\\\\\\`\\${a}\\u0041\\n`"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "\\`${a}A\n")),
 		},
-	}
-
-	for name, lt := range lineTerminators() {
-		code := sfmt(`func(a)%sfuncb(1)%sfuncc("hi")`, lt, lt)
-		cases = append(cases, TestCase{
-			name:          "FuncallsSeparatedBy" + name,
-			code:          code,
-			checkPosition: true,
-			want: tokens(
-				identTokenPos("func", 1, 1),
-				leftParenTokenPos(1, 5),
-				identTokenPos("a", 1, 6),
-				rightParenTokenPos(1, 7),
-				identTokenPos("funcb", 2, 1),
-				leftParenTokenPos(2, 6),
-				decimalTokenPos("1", 2, 7),
-				rightParenTokenPos(2, 8),
-				identTokenPos("funcc", 3, 1),
-				leftParenTokenPos(3, 6),
-				stringTokenPos("hi", 3, 7),
-				rightParenTokenPos(3, 11),
-			),
-		})
-	}
-
-	for name, sp := range whiteSpaces() {
-		code := sfmt(`func(a)%sfuncb(1)`, sp)
-		cases = append(cases, TestCase{
-			name:          "FuncallsSeparatedBy" + name,
-			code:          code,
-			checkPosition: true,
-			want: tokens(
-				identTokenPos("func", 1, 1),
-				leftParenTokenPos(1, 5),
-				identTokenPos("a", 1, 6),
-				rightParenTokenPos(1, 7),
-				identTokenPos("funcb", 1, 9),
-				leftParenTokenPos(1, 14),
-				decimalTokenPos("1", 1, 15),
-				rightParenTokenPos(1, 16),
-			),
-		})
-	}
-
-	runTests(t, cases)
-}
-
-func TestIllegalSingleDot(t *testing.T) {
-	cases := []TestCase{
 		{
-			name: "Nothing",
-			code: Str("."),
-			want: []lexer.Tokval{illegalToken(".")},
+			name: "LineTerminators",
+			code: Str("`a\nb\r\nc\rd`"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "a\nb\nc\nd")),
 		},
-	}
-
-	for _, ts := range tokenSeparators() {
-		code := sfmt(".%s.", ts.Value.String())
-		cases = append(cases, TestCase{
-			name: ts.Type.String(),
-			code: code,
-			want: []lexer.Tokval{illegalToken(code.String())},
-		})
-	}
-
-	runTests(t, cases)
-}
-
-func TestIllegalMemberAccess(t *testing.T) {
-	runTests(t, []TestCase{
 		{
-			name: "CantAccessMemberThatStartsWithNumber",
-			code: Str("test.123"),
+			name: "LineContinuation",
+			code: Str("`a\\\nb`"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "ab")),
+		},
+		{
+			name: "DollarWithoutBrace",
+			code: Str("`$a$`"),
+			opts: es6,
+			want: tokens(tokval(token.Template, "$a$")),
+		},
+		{
+			name: "Unterminated",
+			code: Str("`abc"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("`abc")},
+		},
+		{
+			name: "UnterminatedSubstitution",
+			code: Str("`a${b"),
+			opts: es6,
 			want: []lexer.Tokval{
-				identToken("test"),
-				dotToken(),
-				illegalToken("123"),
+				tokval(token.TemplateHead, "a"),
+				identToken("b"),
+				EOF,
 			},
 		},
 		{
-			name: "CantAccessMemberThatStartsWithDot",
-			code: Str("test.."),
+			name: "UnterminatedTail",
+			code: Str("`a${b}c"),
+			opts: es6,
 			want: []lexer.Tokval{
-				identToken("test"),
-				dotToken(),
-				illegalToken("."),
+				tokval(token.TemplateHead, "a"),
+				identToken("b"),
+				illegalToken("}c"),
 			},
 		},
+		{
+			name: "InvalidEscape",
+			code: Str("`\\x4`"),
+			opts: es6,
+			want: []lexer.Tokval{illegalToken("`\\x4`")},
+		},
 	})
 }
 
-func TestInvalidStrings(t *testing.T) {
-
-	cases := []TestCase{
-		{
-			name: "SingleDoubleQuote",
-			code: Str(`"`),
-			want: []lexer.Tokval{illegalToken(`"`)},
-		},
+func TestTemplatePosition(t *testing.T) {
+	runTests(t, []TestCase{
 		{
-			name: "NoEndingDoubleQuote",
-			code: Str(`"dsadasdsa123456`),
-			want: []lexer.Tokval{illegalToken(`"dsadasdsa123456`)},
+			name:          "MultiLine",
+			code:          Str("a `b\n${c}\nd` e"),
+			opts:          []lexer.Option{lexer.ES6()},
+			checkPosition: true,
+			want: tokens(
+				identTokenPos("a", 1, 1),
+				tokvalPos(token.TemplateHead, "b\n", 1, 3),
+				identTokenPos("c", 2, 3),
+				tokvalPos(token.TemplateTail, "\nd", 2, 4),
+				identTokenPos("e", 3, 4),
+			),
 		},
-	}
-
-	for name, lt := range lineTerminators() {
-		code := fmt.Sprintf(`"head%stail"`, lt)
-		cases = append(cases, TestCase{
-			code: Str(code),
-			name: "NewlineTerminator" + name,
-			want: []lexer.Tokval{illegalToken(code)},
-		})
-	}
-
-	runTests(t, cases)
+	})
 }
 
 func TestIllegalNumericLiterals(t *testing.T) {
@@ -1573,6 +2920,167 @@ func TestCorruptedInput(t *testing.T) {
 	})
 }
 
+func TestIllegalTokenErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		opts []lexer.Option
+		want lexer.Error
+	}{
+		{
+			name: "AfterNumber",
+			code: "x = 1\u2603",
+			want: lexer.Error{
+				Line: 1, Column: 6, Char: '\u2603',
+				Msg: "unexpected character U+2603 after numeric literal",
+			},
+		},
+		{
+			name: "InIdentifier",
+			code: "x;\n  y\u2603",
+			want: lexer.Error{
+				Line: 2, Column: 4, Char: '\u2603',
+				Msg: "unexpected character U+2603 in identifier",
+			},
+		},
+		{
+			name: "UnterminatedString",
+			code: `a = "abc`,
+			want: lexer.Error{
+				Line: 1, Column: 9, Char: -1,
+				Msg: "unterminated string literal",
+			},
+		},
+		{
+			name: "UnterminatedStringAfterLineContinuation",
+			code: "\"a\\\r\nbc\n",
+			want: lexer.Error{
+				Line: 2, Column: 3, Char: '\n',
+				Msg: "unterminated string literal",
+			},
+		},
+		{
+			name: "UnterminatedComment",
+			code: "a /* b",
+			want: lexer.Error{
+				Line: 1, Column: 3, Char: '/',
+				Msg: "unterminated comment",
+			},
+		},
+		{
+			name: "IdentifierEscape",
+			code: `a\u00`,
+			want: lexer.Error{
+				Line: 1, Column: 2, Char: '\\',
+				Msg: "invalid escape sequence in identifier",
+			},
+		},
+		{
+			name: "EscapedKeyword",
+			code: `\u0069f`,
+			want: lexer.Error{
+				Line: 1, Column: 1, Char: '\\',
+				Msg: "keyword if can't contain escape sequences",
+			},
+		},
+		{
+			name: "StrictOctal",
+			code: "0777",
+			opts: []lexer.Option{lexer.Strict()},
+			want: lexer.Error{
+				Line: 1, Column: 5, Char: -1,
+				Msg: "octal literals are not allowed in strict mode",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lx := lexer.New(Str(tc.code), tc.opts...)
+
+			tok := lx.Next()
+			for tok.Type != token.Illegal {
+				if tok.Type == token.EOF {
+					t.Fatal("got EOF, want an illegal token")
+				}
+				tok = lx.Next()
+			}
+
+			if tok.Err == nil {
+				t.Fatalf("illegal token %v has no error", tok)
+			}
+
+			if *tok.Err != tc.want {
+				t.Fatalf("got error %#v, want %#v", *tok.Err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewlineBefore(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		opts []lexer.Option
+		want []bool
+	}{
+		{
+			name: "SameLine",
+			code: "a = b; c",
+			want: []bool{false, false, false, false, false, false},
+		},
+		{
+			name: "Newlines",
+			code: "a\nb\r\n\n\tc\u2028d;",
+			want: []bool{false, true, true, true, false, false},
+		},
+		{
+			name: "LeadingNewline",
+			code: "\na.b\n",
+			want: []bool{true, false, false, true},
+		},
+		{
+			name: "LineComment",
+			code: "a // b\nc",
+			want: []bool{false, true, false},
+		},
+		{
+			name: "BlockComment",
+			code: "a /* b */ c /*\n*/ d",
+			want: []bool{false, false, true, false},
+		},
+		{
+			name: "CommentTokens",
+			code: "a /* b */ c /*\n*/ d // e\nf",
+			opts: []lexer.Option{lexer.Comments()},
+			want: []bool{false, false, false, false, true, false, true},
+		},
+		{
+			name: "MultilineString",
+			code: "a = \"b\\\nc\" d",
+			want: []bool{false, false, false, false, false},
+		},
+		{
+			name: "Template",
+			code: "`a\n${b}\nc` d",
+			want: []bool{false, false, false, false, false},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lx := lexer.New(Str(tc.code), append(tc.opts, lexer.ES6())...)
+			for i, want := range tc.want {
+				tok := lx.Next()
+				if tok.NewlineBefore != want {
+					t.Fatalf("token %d %v: got NewlineBefore %t, want %t",
+						i, tok, tok.NewlineBefore, want)
+				}
+			}
+
+			if tok := lx.Next(); tok.Type != token.EOF {
+				t.Fatalf("got %v, want EOF", tok)
+			}
+		})
+	}
+}
+
 func lineTerminators() map[string]string {
 	return map[string]string{
 		"LineFeed":           "\u000A",
@@ -1590,6 +3098,15 @@ func whiteSpaces() map[string]string {
 		"Space":         "\u0020",
 		"NoBreakSpace":  "\u00A0",
 		"ByteOrderMark": "\uFEFF",
+
+		// Unicode space separators
+		"OghamSpaceMark":     "\u1680",
+		"EnQuad":             "\u2000",
+		"EmSpace":            "\u2003",
+		"HairSpace":          "\u200A",
+		"NarrowNoBreakSpace": "\u202F",
+		"MathSpace":          "\u205F",
+		"IdeographicSpace":   "\u3000",
 	}
 }
 
@@ -1608,7 +3125,7 @@ func runTests(t *testing.T, testcases []TestCase) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			tokensStream := lexer.Lex(tc.code)
+			tokensStream := lexer.Lex(tc.code, tc.opts...)
 			tokens := []lexer.Tokval{}
 
 			for t := range tokensStream {
@@ -1616,10 +3133,37 @@ func runTests(t *testing.T, testcases []TestCase) {
 			}
 
 			assertWantedTokens(t, tc, tokens)
+			assertWantedTokens(t, tc, pullTokens(t, lexer.New(tc.code, tc.opts...)))
+
+			reader := iotest.OneByteReader(strings.NewReader(tc.code.String()))
+			assertWantedTokens(t, tc, pullTokens(t, lexer.LexReader(reader, tc.opts...)))
 		})
 	}
 }
 
+// pullTokens lexes with the pull API, up to the EOF or the first
+// illegal token, checking that no tokens come after them.
+func pullTokens(t *testing.T, lx *lexer.Lexer) []lexer.Tokval {
+	tokens := []lexer.Tokval{}
+
+	for {
+		tok := lx.Next()
+		tokens = append(tokens, tok)
+
+		if tok.Type == token.EOF || tok.Type == token.Illegal {
+			break
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if tok := lx.Next(); tok.Type != token.EOF {
+			t.Fatalf("got %v after the last token, want EOF", tok)
+		}
+	}
+
+	return tokens
+}
+
 // runWhiteSpaceTests will take an array of test cases and run the
 // tests with different white space at the start, end and intertwined
 // between the wanted tokens of each test case, validating if the tokens
@@ -1720,24 +3264,91 @@ func assertWantedTokens(t *testing.T, tc TestCase, got []lexer.Tokval) {
 	if len(tc.want) != len(got) {
 		t.Errorf("error parsing code[%s]", tc.code)
 		t.Errorf("wanted [%d] tokens, got [%d] tokens", len(tc.want), len(got))
-		t.Fatalf("\nwant=%v\ngot= %v\nare not equal.", tc.want, got)
+		t.Fatalf("\nwant:\n%s\ngot:\n%s\nare not equal.",
+			lexer.FormatTokens(tc.want), lexer.FormatTokens(got))
 	}
 
 	for i, w := range tc.want {
 		g := got[i]
 		if !w.Equal(g) {
-			t.Errorf("\nwanted:\ntoken[%d][%v]\n\ngot:\ntoken[%d][%v]", i, w, i, g)
-			t.Errorf("\nwanted:\n%v\ngot:\n%v\n", tc.want, got)
+			t.Errorf("\nwanted token[%d]:\n%s\ngot token[%d]:\n%s", i,
+				lexer.FormatTokens(tc.want[i:i+1]), i, lexer.FormatTokens(got[i:i+1]))
+			t.Errorf("\nwanted:\n%s\ngot:\n%s", lexer.FormatTokens(tc.want),
+				lexer.FormatTokens(got))
 		}
 
 		if tc.checkPosition {
 			if !w.EqualPos(g) {
-				t.Errorf("\nwant=%+v\ngot=%+v\nare equal but dont have the same position", w, g)
+				t.Errorf("\nwant:\n%s\ngot:\n%s\nare equal but dont have the same position",
+					lexer.FormatTokens(tc.want[i:i+1]), lexer.FormatTokens(got[i:i+1]))
 			}
 		}
 	}
 }
 
+func TestLoneSurrogates(t *testing.T) {
+	high, low := uint16(0xD800), uint16(0xDC00)
+	str := func(units ...uint16) utf16.Str { return utf16.Str(units) }
+	concat := func(strs ...utf16.Str) utf16.Str {
+		var res utf16.Str
+		for _, s := range strs {
+			res = append(res, s...)
+		}
+		return res
+	}
+
+	// the reader of runTests can't be used, lone surrogates are not
+	// valid UTF-8
+	for _, tc := range []TestCase{
+		{
+			name: "String",
+			code: concat(Str(`"a`), str(high), Str(`b"`)),
+			want: tokens(lexer.Tokval{
+				Type:  token.String,
+				Value: concat(Str("a"), str(high), Str("b")),
+			}),
+		},
+		{
+			name: "StringEnd",
+			code: concat(Str(`"`), str(low, high), Str(`"`)),
+			want: tokens(lexer.Tokval{Type: token.String, Value: str(low, high)}),
+		},
+		{
+			name: "Template",
+			code: concat(Str("`"), str(high), Str("`")),
+			opts: []lexer.Option{lexer.ES6()},
+			want: tokens(lexer.Tokval{Type: token.Template, Value: str(high)}),
+		},
+		{
+			name: "Comment",
+			code: concat(Str("/* "), str(low), Str(" */ a")),
+			want: tokens(identToken("a")),
+		},
+		{
+			name: "Pair",
+			code: Str("a\U00010400"),
+			want: tokens(identToken("a\U00010400")),
+		},
+		{
+			name: "Code",
+			code: concat(Str("a "), str(high)),
+			want: []lexer.Tokval{
+				identToken("a"),
+				{Type: token.Illegal, Value: str(high)},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assertWantedTokens(t, tc, pullTokens(t, lexer.New(tc.code, tc.opts...)))
+		})
+	}
+
+	tok := lexer.New(concat(Str("a"), str(low))).Next()
+	if tok.Type != token.Illegal || tok.Err.Char != 0xDC00 {
+		t.Fatalf("got %v, want an illegal U+DC00", tok)
+	}
+}
+
 func messStr(s utf16.Str, pos uint) utf16.Str {
 	// WHY: The go's utf16 package uses the replacement char everytime a some
 	// encoding/decoding error happens, so we inject one on the uint16 array to simulate
@@ -1874,10 +3485,6 @@ func tokval(t token.Type, val string) lexer.Tokval {
 	return tokvalPos(t, val, 0, 0)
 }
 
-func undefinedToken() lexer.Tokval {
-	return tokval(token.Undefined, "undefined")
-}
-
 func boolToken(s string) lexer.Tokval {
 	return tokval(token.Bool, s)
 }
@@ -1970,3 +3577,55 @@ func commaToken() lexer.Tokval {
 func tokens(t ...lexer.Tokval) []lexer.Tokval {
 	return append(t, EOF)
 }
+
+// benchCode is a sample of common code, repeated to make a large file.
+const benchCode = `// counts the words of the text
+function count(text, words) {
+	var n = 0x1F, total = 1.5e3;
+	/* the words are
+	   separated by spaces */
+	words.list[n] = "some \"quoted\" text";
+	console.log(text.length, words, total, 0755, $_ident123);
+}
+
+`
+
+func BenchmarkLexLargeFile(b *testing.B) {
+	code := Str(strings.Repeat(benchCode, 1000))
+	b.SetBytes(int64(len(code) * 2))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		drain(lexer.New(code))
+	}
+}
+
+func BenchmarkLexNumbers(b *testing.B) {
+	code := Str(strings.Repeat("12345 0x1F 1.5e10 0.25 ", 1000))
+	b.SetBytes(int64(len(code) * 2))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		drain(lexer.New(code))
+	}
+}
+
+func BenchmarkLexIdentifiers(b *testing.B) {
+	code := Str(strings.Repeat("abc.def $ghi _jkl123 mnopqrstuvwxyz; ", 1000))
+	b.SetBytes(int64(len(code) * 2))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		drain(lexer.New(code))
+	}
+}
+
+// drain lexes all the tokens, without keeping them.
+func drain(lx *lexer.Lexer) {
+	for {
+		tok := lx.Next()
+		if tok.Type == token.EOF || tok.Type == token.Illegal {
+			return
+		}
+	}
+}
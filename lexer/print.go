@@ -0,0 +1,63 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+// PrintTokens lexes code and prints its tokens to w, up to the EOF or
+// the first illegal token, in the format of FormatTokens. It returns
+// the error of the illegal token, if any, or of writing to w.
+func PrintTokens(w io.Writer, code utf16.Str, opts ...Option) error {
+	lx := New(code, opts...)
+
+	for {
+		tok := lx.Next()
+
+		_, err := io.WriteString(w, formatToken(tok)+"\n")
+		if err != nil {
+			return err
+		}
+
+		switch tok.Type {
+		case token.EOF:
+			return nil
+		case token.Illegal:
+			return tok.Err
+		}
+	}
+}
+
+// FormatTokens formats tokens one per line as line:column, type and
+// quoted value separated by tabs, followed by the error of illegal
+// tokens, eg.:
+//
+//	1:1	Ident	"a"
+//	1:3	=	"="
+//	1:5	Illegal	"@"	1:5: unexpected character U+0040
+//
+// The format is stable, it can be compared with golden files.
+func FormatTokens(tokens []Tokval) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteString(formatToken(tok))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func formatToken(tok Tokval) string {
+	line := fmt.Sprintf("%d:%d\t%s\t%s", tok.Line, tok.Column, tok.Type,
+		strconv.Quote(tok.Value.String()))
+	if tok.Err != nil {
+		line += "\t" + tok.Err.Error()
+	}
+
+	return line
+}
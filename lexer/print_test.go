@@ -0,0 +1,59 @@
+package lexer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NeowayLabs/abad/lexer"
+)
+
+func TestPrintTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want string
+		err  string
+	}{
+		{
+			name: "Tokens",
+			code: "a.b(\"c\\td\", 0x1F);\n  e",
+			want: "1:1\tIdent\t\"a\"\n" +
+				"1:2\t.\t\".\"\n" +
+				"1:3\tIdent\t\"b\"\n" +
+				"1:4\t(\t\"(\"\n" +
+				"1:5\tString\t\"c\\td\"\n" +
+				"1:11\t,\t\",\"\n" +
+				"1:13\tHexadecimal\t\"0x1F\"\n" +
+				"1:17\t)\t\")\"\n" +
+				"1:18\tSemiColon\t\";\"\n" +
+				"2:3\tIdent\t\"e\"\n" +
+				"0:0\tEOF\t\"EOF\"\n",
+		},
+		{
+			name: "Illegal",
+			code: "a = 1☃",
+			want: "1:1\tIdent\t\"a\"\n" +
+				"1:3\t=\t\"=\"\n" +
+				"1:5\tIllegal\t\"1☃\"\t1:6: unexpected character U+2603 after numeric literal\n",
+			err: "1:6: unexpected character U+2603 after numeric literal",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := lexer.PrintTokens(&out, Str(tc.code))
+			if (err == nil && tc.err != "") || (err != nil && err.Error() != tc.err) {
+				t.Fatalf("got error %v, want %q", err, tc.err)
+			}
+
+			if out.String() != tc.want {
+				t.Fatalf("printed:\n%s\nwant:\n%s", out.String(), tc.want)
+			}
+
+			got := lexer.FormatTokens(lexAll(lexer.New(Str(tc.code))))
+			if got != tc.want {
+				t.Fatalf("formatted:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
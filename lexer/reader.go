@@ -0,0 +1,171 @@
+package lexer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	abadutf16 "github.com/NeowayLabs/abad/utf16"
+)
+
+// readChunk is how many characters are read from a source at a time.
+const readChunk = 4096
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+)
+
+// LexReader creates a lexer of the code read from r, which is read
+// as the tokens are scanned, so the code is never entirely in memory.
+// The tokens are the same of New, but the value of an illegal token
+// is only the code read up to it.
+//
+// The code is decoded as UTF-8, unless it starts with a UTF-16 byte
+// order mark. Invalid encodings are lexed as U+FFFD, which is
+// illegal outside string literals, as lone UTF-16 surrogates are,
+// and a read error produces an illegal token.
+func LexReader(r io.Reader, opts ...Option) *Lexer {
+	l := newLexer(nil, opts...)
+	l.src = newSource(r)
+	return &Lexer{l: l, state: l.initialState}
+}
+
+// Decode decodes the code as LexReader does, as UTF-8 or as UTF-16
+// if it starts with a UTF-16 byte order mark, which is stripped, as
+// a UTF-8 one is.
+func Decode(code string) abadutf16.Str {
+	if strings.HasPrefix(code, string(utf16BEBOM)) ||
+		strings.HasPrefix(code, string(utf16LEBOM)) {
+		src := newSource(strings.NewReader(code))
+
+		var runes []rune
+		for ok := true; ok; {
+			runes, ok = src.read(runes)
+		}
+
+		return newStr(runes)
+	}
+
+	return abadutf16.Encode(strings.TrimPrefix(code, string(utf8BOM)))
+}
+
+// source decodes the characters of the code read on demand.
+type source struct {
+	r   io.RuneReader
+	err error
+}
+
+func newSource(r io.Reader) *source {
+	br := bufio.NewReader(r)
+	src := &source{r: br}
+
+	// the byte order mark isn't part of the code
+	if prefix, _ := br.Peek(len(utf8BOM)); bytes.HasPrefix(prefix, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	} else if bytes.HasPrefix(prefix, utf16BEBOM) {
+		_, _ = br.Discard(len(utf16BEBOM))
+		src.r = &utf16Reader{r: br, bigEndian: true}
+	} else if bytes.HasPrefix(prefix, utf16LEBOM) {
+		_, _ = br.Discard(len(utf16LEBOM))
+		src.r = &utf16Reader{r: br}
+	}
+
+	return src
+}
+
+// read appends up to readChunk characters to code. It returns false
+// if there are no more characters.
+func (src *source) read(code []rune) ([]rune, bool) {
+	if src.err != nil {
+		return code, false
+	}
+
+	n := len(code)
+	for i := 0; i < readChunk; i++ {
+		r, _, err := src.r.ReadRune()
+		if err != nil {
+			src.err = err
+			break
+		}
+
+		code = append(code, r)
+	}
+
+	return code, len(code) > n
+}
+
+// failed returns the error reading the source, if any.
+func (src *source) failed() error {
+	if src.err == io.EOF {
+		return nil
+	}
+
+	return src.err
+}
+
+// utf16Reader decodes UTF-16 code units as runes. Lone surrogates
+// are decoded as runes of their code unit, as decodeRunes does, and
+// a trailing odd byte as U+FFFD.
+type utf16Reader struct {
+	r         *bufio.Reader
+	bigEndian bool
+
+	// a code unit read after a lone high surrogate
+	pending   uint16
+	ispending bool
+}
+
+func (u *utf16Reader) ReadRune() (rune, int, error) {
+	r1, err := u.readUnit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !utf16.IsSurrogate(rune(r1)) {
+		return rune(r1), 2, nil
+	}
+
+	r2, err := u.readUnit()
+	if err == io.EOF {
+		return rune(r1), 2, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r := utf16.DecodeRune(rune(r1), rune(r2))
+	if r == unicode.ReplacementChar {
+		// the second unit starts the next character
+		u.pending, u.ispending = r2, true
+		return rune(r1), 2, nil
+	}
+
+	return r, 4, nil
+}
+
+func (u *utf16Reader) readUnit() (uint16, error) {
+	if u.ispending {
+		u.ispending = false
+		return u.pending, nil
+	}
+
+	var b [2]byte
+	n, err := io.ReadFull(u.r, b[:])
+	if err == io.ErrUnexpectedEOF && n == 1 {
+		return uint16(unicode.ReplacementChar), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if u.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1]), nil
+	}
+
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
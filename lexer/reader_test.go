@@ -0,0 +1,170 @@
+package lexer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/NeowayLabs/abad/lexer"
+	"github.com/NeowayLabs/abad/token"
+	abadutf16 "github.com/NeowayLabs/abad/utf16"
+)
+
+func TestLexReaderEncodings(t *testing.T) {
+	const code = "x\U0001D465 = \"☃\";\n\tf(x\U0001D465)"
+
+	for _, tc := range []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "UTF8",
+			input: []byte(code),
+		},
+		{
+			name:  "UTF8BOM",
+			input: append([]byte{0xEF, 0xBB, 0xBF}, code...),
+		},
+		{
+			name:  "UTF16BE",
+			input: append([]byte{0xFE, 0xFF}, encodeUTF16(code, true)...),
+		},
+		{
+			name:  "UTF16LE",
+			input: append([]byte{0xFF, 0xFE}, encodeUTF16(code, false)...),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := lexAll(lexer.New(Str(code)))
+			got := lexAll(lexer.LexReader(iotest.HalfReader(bytes.NewReader(tc.input))))
+			assertSameTokens(t, want, got)
+		})
+	}
+}
+
+func TestLexReaderLoneSurrogate(t *testing.T) {
+	input := []byte{0xFF, 0xFE, 'a', 0, ' ', 0, 0x00, 0xD8, 'b', 0}
+
+	got := lexAll(lexer.LexReader(bytes.NewReader(input)))
+	if len(got) != 2 || got[1].Type != token.Illegal {
+		t.Fatalf("got %v, want an identifier and an illegal token", got)
+	}
+
+	if got[1].Err.Char != 0xD800 {
+		t.Fatalf("got illegal char %U, want U+D800", got[1].Err.Char)
+	}
+
+	input = []byte{0xFF, 0xFE, '"', 0, 0x00, 0xD8, 'b', 0, '"', 0, 0x00, 0xDC}
+	got = lexAll(lexer.LexReader(bytes.NewReader(input)))
+	want := abadutf16.Str{0xD800, 'b'}
+	if len(got) != 2 || got[0].Type != token.String || !got[0].Value.Equal(want) {
+		t.Fatalf("got %v, want the string %v and an illegal token", got, want)
+	}
+
+	if got[1].Err.Char != 0xDC00 {
+		t.Fatalf("got illegal char %U, want U+DC00", got[1].Err.Char)
+	}
+}
+
+func TestLexReaderLongTokens(t *testing.T) {
+	long := strings.Repeat("ab", 5000)
+
+	for name, code := range map[string]string{
+		"String":  "s = \"" + long + "\";\nx",
+		"Comment": "/*\n" + long + "\n*/ x",
+		"Ident":   long + ".x",
+	} {
+		t.Run(name, func(t *testing.T) {
+			want := lexAll(lexer.New(Str(code)))
+			got := lexAll(lexer.LexReader(strings.NewReader(code)))
+			assertSameTokens(t, want, got)
+		})
+	}
+}
+
+func TestLexReaderError(t *testing.T) {
+	fail := errors.New("disk on fire")
+
+	for name, r := range map[string]io.Reader{
+		"AtStart": iotest.ErrReader(fail),
+		"AfterTokens": io.MultiReader(
+			strings.NewReader("a = 1"), iotest.ErrReader(fail)),
+		"InString": io.MultiReader(
+			strings.NewReader(`a = "abc`), iotest.ErrReader(fail)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := lexAll(lexer.LexReader(r))
+
+			last := got[len(got)-1]
+			if last.Type != token.Illegal {
+				t.Fatalf("got %v, want an illegal token", got)
+			}
+
+			if last.Err.Msg != fail.Error() {
+				t.Fatalf("got error %q, want %q", last.Err.Msg, fail)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	const code = "a = \"☃ \U0001D465\""
+
+	for name, input := range map[string]string{
+		"UTF8":    code,
+		"UTF8BOM": "\xEF\xBB\xBF" + code,
+		"UTF16BE": "\xFE\xFF" + string(encodeUTF16(code, true)),
+		"UTF16LE": "\xFF\xFE" + string(encodeUTF16(code, false)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := lexer.Decode(input)
+			if !got.Equal(Str(code)) {
+				t.Fatalf("got %q, want %q", got.String(), code)
+			}
+		})
+	}
+}
+
+// lexAll returns the tokens up to the EOF or the first illegal token.
+func lexAll(lx *lexer.Lexer) []lexer.Tokval {
+	var tokens []lexer.Tokval
+	for {
+		tok := lx.Next()
+		tokens = append(tokens, tok)
+
+		if tok.Type == token.EOF || tok.Type == token.Illegal {
+			return tokens
+		}
+	}
+}
+
+func assertSameTokens(t *testing.T, want, got []lexer.Tokval) {
+	t.Helper()
+
+	if len(want) != len(got) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if !want[i].Equal(got[i]) || !want[i].EqualPos(got[i]) {
+			t.Fatalf("got token %v, want %v", got[i], want[i])
+		}
+	}
+}
+
+func encodeUTF16(code string, bigEndian bool) []byte {
+	var res []byte
+	for _, unit := range abadutf16.Str(utf16.Encode([]rune(code))) {
+		if bigEndian {
+			res = append(res, byte(unit>>8), byte(unit))
+		} else {
+			res = append(res, byte(unit), byte(unit>>8))
+		}
+	}
+
+	return res
+}
@@ -1,37 +1,62 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/NeowayLabs/abad/ast"
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/internal/numparse"
 	"github.com/NeowayLabs/abad/lexer"
 	"github.com/NeowayLabs/abad/token"
+	"github.com/madlambda/spells/semaphore"
 )
 
 type (
 	Parser struct {
-		tokens    <-chan lexer.Tokval
+		tokens    *lexer.Lexer
 		lookahead []lexer.Tokval
 
 		filename string
 
 		openbraces int
+
+		// blocks is the number of open blocks of the body
+		// being parsed
+		blocks int
+
+		trailingCommas bool
+
+		// strict parses the code as strict mode code
+		strict bool
+
+		lexopts []lexer.Option
+
+		onWarning func(Warning)
 	}
 
 	parserfn func(*Parser) (ast.Node, error)
-)
 
-// used when the tokens is over
-var tokEOF = lexer.EOF
+	// Option configures the parser.
+	Option func(*Parser)
+
+	// File is a named source code.
+	File struct {
+		Name string
+		Code string
+	}
+)
 
 var (
-	keywordParsers   map[token.Type]parserfn
-	literalParsers   map[token.Type]parserfn
-	unaryParsers     map[token.Type]parserfn
-	varAssignParsers map[token.Type]parserfn
-	nodeParsers      map[token.Type]parserfn
+	keywordParsers map[token.Type]parserfn
+	literalParsers map[token.Type]parserfn
+	unaryParsers   map[token.Type]parserfn
+	exprParsers    map[token.Type]parserfn
+	nodeParsers    map[token.Type]parserfn
 )
 
 func init() {
@@ -42,45 +67,169 @@ func init() {
 	unaryParsers = map[token.Type]parserfn{
 		token.Minus: parseUnary,
 		token.Plus:  parseUnary,
+		token.LNot:  parseUnary,
 	}
 
 	literalParsers = map[token.Type]parserfn{
 		token.Decimal:     parseDecimal,
 		token.Hexadecimal: parseHex,
+		token.Octal:       parseOctal,
+		token.Binary:      parseBinary,
+		token.BigInt:      parseBigInt,
 		token.String:      parseString,
 		token.Bool:        parseBool,
-		token.Undefined:   parseUndefined,
 		token.Null:        parseNull,
 	}
 
-	varAssignParsers = mergeParsers(
+	exprParsers = mergeParsers(
 		literalParsers,
+		unaryParsers,
 		map[token.Type]parserfn{
-			token.Ident: parseIdentExpr,
+			token.Ident:    parseIdentExpr,
+			token.This:     parseThis,
+			token.LParen:   parseGroupExpr,
+			token.Function: parseFunExpr,
+			token.New:      parseNewExpr,
 		},
 	)
 
 	nodeParsers = mergeParsers(
 		keywordParsers,
-		literalParsers,
-		unaryParsers,
 		map[token.Type]parserfn{
-			token.Ident: parseIdentExpr,
-			token.Var:   parseVarDecls,
+			token.Var: parseVarDecls,
 		},
 	)
+
+	// WHY: a function keyword starting a statement is a
+	// declaration, not an expression.
+	for tokType := range exprParsers {
+		if _, ok := nodeParsers[tokType]; !ok {
+			nodeParsers[tokType] = parseExpr
+		}
+	}
 }
 
-// Parse input source into an AST representation.
-func Parse(fname string, code string) (*ast.Program, error) {
+// Parse input source into an AST representation. The code may
+// start with a byte order mark, see lexer.Decode.
+func Parse(fname string, code string, opts ...Option) (*ast.Program, error) {
 	p := Parser{
-		tokens:   lexer.Lex(utf16.Encode(code)),
 		filename: fname,
 	}
 
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	p.tokens = lexer.New(lexer.Decode(code), p.lexopts...)
+
 	return p.parse()
 }
 
+// ParseExpr parses code holding a single expression, eg.: a.b + 1,
+// optionally followed by a semicolon.
+func ParseExpr(fname string, code string, opts ...Option) (ast.Node, error) {
+	program, err := Parse(fname, code, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(program.Nodes) != 1 || !ast.IsExpr(program.Nodes[0]) {
+		return nil, fmt.Errorf("%s: %q is not a single expression", fname, code)
+	}
+
+	return program.Nodes[0], nil
+}
+
+// TrailingCommas accepts a comma after the last argument of calls
+// and the last parameter of functions, eg.: f(a, b,)
+// ES5 doesn't allow them but later editions do and transpiled code
+// is full of them.
+func TrailingCommas() Option {
+	return func(p *Parser) {
+		p.trailingCommas = true
+	}
+}
+
+// ES6 parses the binary and octal literals of ES6, eg.: 0b1010 and
+// 0o755.
+func ES6() Option {
+	return func(p *Parser) {
+		p.lexopts = append(p.lexopts, lexer.ES6())
+	}
+}
+
+// Strict parses the code as strict mode code, it rejects legacy
+// octal literals and the names reserved only in strict mode, eg.:
+// let, static and yield.
+// http://es5.github.io/#x10.1.1
+func Strict() Option {
+	return func(p *Parser) {
+		p.strict = true
+		p.lexopts = append(p.lexopts, lexer.Strict())
+	}
+}
+
+// ParseFiles parses all files concurrently, at most GOMAXPROCS files
+// at the same time. The programs are returned in the same order of the
+// given files. When parsing fails the error of the first failed file
+// (in the given order) is returned, so errors are deterministic.
+func ParseFiles(files []File, opts ...Option) ([]*ast.Program, error) {
+	programs := make([]*ast.Program, len(files))
+	errs := make([]error, len(files))
+
+	// the warnings of each file are reported after parsing all of
+	// them, so they are in the order of the files.
+	var conf Parser
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	warnings := make([][]Warning, len(files))
+
+	sem := semaphore.New(uint(runtime.GOMAXPROCS(0)))
+	wg := sync.WaitGroup{}
+
+	for i, file := range files {
+		release, err := sem.Acquire(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(i int, file File) {
+			defer wg.Done()
+			defer release()
+
+			fileopts := opts
+			if conf.onWarning != nil {
+				fileopts = append(opts[:len(opts):len(opts)], OnWarning(func(w Warning) {
+					warnings[i] = append(warnings[i], w)
+				}))
+			}
+
+			programs[i], errs[i] = Parse(file.Name, file.Code, fileopts...)
+		}(i, file)
+	}
+
+	wg.Wait()
+
+	if conf.onWarning != nil {
+		for _, fileWarnings := range warnings {
+			for _, w := range fileWarnings {
+				conf.onWarning(w)
+			}
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return programs, nil
+}
+
 func (p *Parser) parse() (*ast.Program, error) {
 	var nodes []ast.Node
 
@@ -103,12 +252,20 @@ func (p *Parser) parse() (*ast.Program, error) {
 }
 
 func (p *Parser) parseNode() (n ast.Node, eof bool, err error) {
-	p.scry(1)
-	tok := p.lookahead[0]
+	// the token ending the previous statement could be in the lookahead
+	tok := p.peek()
+
+	// FIXME: This will probably not be enough to handle semicolon on the future
+	for tok.Type == token.SemiColon {
+		p.forget(1)
+		p.scry(1)
+		tok = p.lookahead[0]
+	}
 
 	// http://es5.github.io/#A.4
 	if tok.Type == token.LBrace {
 		p.openbraces++
+		p.blocks++
 		p.forget(1)
 		return p.parseNode()
 	}
@@ -120,14 +277,15 @@ func (p *Parser) parseNode() (n ast.Node, eof bool, err error) {
 
 		p.openbraces--
 		p.forget(1)
-		return nil, true, nil
-	}
 
-	// FIXME: This will probably not be enough to handle semicolon on the future
-	for tok.Type == token.SemiColon {
-		p.forget(1)
-		p.scry(1)
-		tok = p.lookahead[0]
+		// WHY: the statements of blocks are flattened into the
+		// body, only the '}' of the body itself ends it.
+		if p.blocks > 0 {
+			p.blocks--
+			return p.parseNode()
+		}
+
+		return nil, true, nil
 	}
 
 	if tok.Type == token.EOF {
@@ -150,25 +308,71 @@ func (p *Parser) parseNode() (n ast.Node, eof bool, err error) {
 		return nil, false, err
 	}
 
-	// parsers should not leave tokens not processed
-	// in the lookahead buffer.
-	if len(p.lookahead) != 0 {
+	// expression parsers can leave the token following the
+	// expression in the lookahead buffer, but nothing else.
+	if len(p.lookahead) > 1 {
 		panic(fmt.Sprintf("parser for token[%v] not handled lookahead correctly, lookahead has[%v] but should be empty",
 			tok,
 			p.lookahead))
 	}
+
+	if err := p.checkTerminated(node); err != nil {
+		return nil, false, err
+	}
+
 	return node, false, nil
 }
 
-// next token
-func (p *Parser) next() lexer.Tokval {
-	tok, ok := <-p.tokens
-	if !ok {
-		return tokEOF
+// checkTerminated fails if a statement is followed by another in the
+// same line, where ES5 doesn't insert a semicolon, eg.: a = 1 b = 2
+// Function declarations don't need one, and var statements check
+// their own end.
+// http://es5.github.io/#x7.9.1
+func (p *Parser) checkTerminated(node ast.Node) error {
+	switch node.Type() {
+	case ast.NodeFunDecl, ast.NodeVarDecls:
+		return nil
+	}
+
+	next := p.peek()
+	switch next.Type {
+	case token.SemiColon, token.RBrace, token.EOF, token.Illegal:
+		return nil
+	}
+
+	if !next.NewlineBefore {
+		return p.errorf(next, "parser: missing ';' before %s, statements in the same line must be separated", next.Type)
+	}
+
+	return nil
+}
+
+// peek returns the next token, keeping it in the lookahead buffer.
+func (p *Parser) peek() lexer.Tokval {
+	if len(p.lookahead) == 0 {
+		p.scry(1)
 	}
+
+	return p.lookahead[0]
+}
+
+// pop the next token, taking it from the lookahead buffer if
+// it is not empty.
+func (p *Parser) pop() lexer.Tokval {
+	if len(p.lookahead) == 0 {
+		return p.next()
+	}
+
+	tok := p.lookahead[0]
+	p.forget(1)
 	return tok
 }
 
+// next token
+func (p *Parser) next() lexer.Tokval {
+	return p.tokens.Next()
+}
+
 // scry foretell the future using a crystal ball. Amount is how much
 // of the future you want to foresee.
 //
@@ -198,43 +402,56 @@ func (p *Parser) forget(amount int) {
 
 func parseIllegal(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
-	return nil, p.errorf(tok, "invalid token: %s",
-		tok.Value)
+	if tok.Err == nil {
+		return nil, p.errorf(tok, "invalid token: %s", tok.Value)
+	}
+
+	return nil, fmt.Errorf("%s:%d:%d: %s", p.filename,
+		tok.Err.Line, tok.Err.Column, tok.Err.Msg)
 }
 
+// parseString parses a string literal and its suffixes, eg.:
+// "abc".length
 func parseString(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
-	defer p.forget(1)
+	p.forget(1)
 
-	return ast.NewString(tok.Value), nil
+	return parseSuffixExpr(p, ast.NewString(tok.Value))
 }
 
 func parseBool(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
-	defer p.forget(1)
+	p.forget(1)
 
 	b, err := strconv.ParseBool(tok.Value.String())
-	return ast.NewBool(b), err
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSuffixExpr(p, ast.NewBool(b))
 }
 
-func parseUndefined(p *Parser) (ast.Node, error) {
+// parseNull parses null and its suffixes, eg.: null.a, which is a
+// TypeError when evaluated.
+func parseNull(p *Parser) (ast.Node, error) {
 	p.forget(1)
-	return ast.NewUndefined(), nil
+	return parseSuffixExpr(p, ast.NewNull())
 }
 
-func parseNull(p *Parser) (ast.Node, error) {
+// parseThis parses the this keyword and its suffixes, eg.: this.a
+// http://es5.github.io/#x11.1.1
+func parseThis(p *Parser) (ast.Node, error) {
 	p.forget(1)
-	return ast.NewNull(), nil
+	return parseSuffixExpr(p, ast.NewThis())
 }
 
 func parseDecimal(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
 	defer p.forget(1)
 
-	decstr := tok.Value
-	f, err := strconv.ParseFloat(decstr.String(), 64)
+	f, err := numparse.Literal(tok.Value.String())
 	if err != nil {
-		return nil, p.errorf(tok, err.Error())
+		return nil, p.errorf(tok, "%s: %s", err, tok.Value)
 	}
 	return ast.NewNumber(f), nil
 }
@@ -243,15 +460,56 @@ func parseHex(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
 	defer p.forget(1)
 
-	hexstr := tok.Value
-	hexPrefix := utf16.S("0x")
-	hexstr = hexstr.TrimPrefix(hexPrefix)
-	hex, err := strconv.ParseInt(hexstr.String(), 16, 64)
+	f, err := numparse.Literal(tok.Value.String())
+	if err != nil {
+		return nil, p.errorf(tok, "%s: %s", err, tok.Value)
+	}
+
+	return ast.NewNumber(f), nil
+}
+
+func parseOctal(p *Parser) (ast.Node, error) {
+	tok := p.lookahead[0]
+	defer p.forget(1)
+
+	if lit := tok.Value.String(); !strings.HasPrefix(strings.ToLower(lit), "0o") {
+		p.warnf(tok, "legacy octal literal %s is deprecated, use 0o%s", lit, lit[1:])
+	}
+
+	f, err := numparse.Literal(tok.Value.String())
+	if err != nil {
+		return nil, p.errorf(tok, "%s: %s", err, tok.Value)
+	}
+
+	return ast.NewNumber(f), nil
+}
+
+func parseBinary(p *Parser) (ast.Node, error) {
+	tok := p.lookahead[0]
+	defer p.forget(1)
+
+	f, err := numparse.Literal(tok.Value.String())
 	if err != nil {
-		return nil, p.errorf(tok, err.Error())
+		return nil, p.errorf(tok, "%s: %s", err, tok.Value)
 	}
 
-	return ast.NewIntNumber(hex), nil
+	return ast.NewNumber(f), nil
+}
+
+// parseBigInt parses BigInt literals, eg.: 10n and 0xFFn
+func parseBigInt(p *Parser) (ast.Node, error) {
+	tok := p.lookahead[0]
+	defer p.forget(1)
+
+	lit := strings.TrimSuffix(tok.Value.String(), "n")
+
+	// base 0 takes the 0x, 0o and 0b prefixes
+	v, ok := new(big.Int).SetString(lit, 0)
+	if !ok {
+		return nil, p.errorf(tok, "invalid BigInt literal: %s", tok.Value)
+	}
+
+	return ast.NewBigInt(v), nil
 }
 
 func parseUnary(p *Parser) (ast.Node, error) {
@@ -260,21 +518,139 @@ func parseUnary(p *Parser) (ast.Node, error) {
 		return nil, p.errorf(tok, "unexpected: %s", tok.Type)
 	}
 	p.forget(1)
-	expr, eof, err := p.parseNode()
+	expr, err := parseOperand(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewUnaryExpr(tok.Type, expr), nil
+}
+
+// parseExpr parses the expression starting at the next token.
+// Expression parsers consume only the tokens of the expression, the
+// token following it may be left in the lookahead buffer.
+func parseExpr(p *Parser) (ast.Node, error) {
+	return parseBinaryExpr(p, token.LowestPrec+1)
+}
+
+// parseBinaryExpr parses the operands joined by binary operators of
+// precedence minPrec or higher, by precedence climbing: the right
+// operand of an operator holds only the operators binding tighter,
+// so operators of the same precedence are left associative.
+// http://es5.github.io/#x11.5
+func parseBinaryExpr(p *Parser, minPrec int) (ast.Node, error) {
+	left, err := parseOperand(p)
 	if err != nil {
 		return nil, err
 	}
 
-	if eof {
+	for {
+		tok := p.peek()
+		prec := tok.Type.Precedence()
+		if !token.IsBinaryOperator(tok.Type) || prec < minPrec {
+			return left, nil
+		}
+
+		if tok.Type == token.Plus || tok.Type == token.Minus {
+			p.checkContinuation(tok)
+		}
+
+		p.forget(1)
+
+		right, err := parseBinaryExpr(p, prec+1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = ast.NewBinaryExpr(tok.Type, left, right)
+	}
+}
+
+// checkContinuation warns about tok continuing the expression of the
+// previous line, because no semicolon is inserted before a line
+// starting with ( [ + or -, eg.:
+//
+//	a = b
+//	(f || g)()
+//
+// calls b.
+// http://es5.github.io/#x7.9.2
+func (p *Parser) checkContinuation(tok lexer.Tokval) {
+	if tok.NewlineBefore {
+		p.warnf(tok, "the line starting with '%s' continues the expression of the previous line, no semicolon is inserted before it", tok.Type)
+	}
+}
+
+// parseOperand parses an expression without binary operators.
+func parseOperand(p *Parser) (ast.Node, error) {
+	tok := p.peek()
+	if tok.Type == token.EOF {
 		return nil, p.errorf(tok, "unexpected eof")
 	}
 
-	if !ast.IsExpr(expr) {
-		return nil, p.errorf(tok, "expected expression, but got %s",
-			expr.Type())
+	if tok.Type == token.Illegal {
+		return parseIllegal(p)
 	}
 
-	return ast.NewUnaryExpr(tok.Type, expr), nil
+	parser, ok := exprParsers[tok.Type]
+	if !ok {
+		return nil, p.errorf(tok, "expected expression, but got %s", tok)
+	}
+
+	return parser(p)
+}
+
+// parseGroupExpr parses a parenthesized expression. The grouped
+// expression is returned as is, because the tree already encodes its
+// precedence, and a comma separated list of expressions is returned
+// as a SequenceExpr.
+// http://es5.github.io/#x11.1.6
+//
+// state:
+// lookahead[0] = token.LParen
+func parseGroupExpr(p *Parser) (ast.Node, error) {
+	lparen := p.lookahead[0]
+	p.forget(1)
+
+	if p.peek().Type == token.RParen {
+		// only valid as the parameters of an arrow function
+		return nil, p.errorf(lparen, "parser: group: unexpected [)]")
+	}
+
+	var exprs []ast.Node
+	for {
+		expr, err := parseExpr(p)
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		tok := p.pop()
+		if tok.Type == token.RParen {
+			break
+		}
+
+		if tok.Type == token.EOF {
+			return nil, p.errorf(tok, "parser: group: unexpected eof")
+		}
+
+		if tok.Type != token.Comma {
+			return nil, p.errorf(tok, "parser: group: unexpected [%s]", tok.Value)
+		}
+	}
+
+	var group ast.Node = ast.NewSequenceExpr(exprs...)
+	if len(exprs) == 1 {
+		group = exprs[0]
+	}
+
+	if tok := p.peek(); tok.Type == token.Assign {
+		// eg.: (a, b) => a
+		return nil, p.errorf(tok, "parser: group: arrow functions and assignments not supported")
+	}
+
+	return parseSuffixExpr(p, group)
 }
 
 func parseVarDecls(p *Parser) (ast.Node, error) {
@@ -282,46 +658,58 @@ func parseVarDecls(p *Parser) (ast.Node, error) {
 	return parseVarDeclList(p)
 }
 
+// parseVarDeclList parses the comma separated declarations of a var
+// statement, the initializers are any expression, eg.:
+// var a, b = 1, f = function () {}
+// The statement ends with a semicolon, a line terminator, the end of
+// the block or the EOF.
+// http://es5.github.io/#x12.2
 func parseVarDeclList(p *Parser) (ast.VarDecls, error) {
 
-	identifier := p.next()
+	identifier := p.pop()
 	if identifier.Type != token.Ident {
 		return nil, fmt.Errorf("parser: var decl: expected identifier got[%s]", identifier)
 	}
 
-	varname := ast.NewIdent(identifier.Value)
-	possibleAssignment := p.next()
-
-	if possibleAssignment.Type == token.SemiColon {
-		return ast.NewVarDecls(ast.NewVarDecl(varname, ast.NewUndefined())), nil
-	}
-
-	if possibleAssignment.Type != token.Assign {
-		return nil, fmt.Errorf("parser: var decl: expected assignment token [=] got [%s]", possibleAssignment)
+	if err := checkIdentifier(p, identifier); err != nil {
+		return nil, err
 	}
 
-	p.scry(1)
-	assignExpr := p.lookahead[0]
-	parser, hasparser := varAssignParsers[assignExpr.Type]
+	varname := ast.NewIdent(identifier.Value)
+	var val ast.Node = ast.NewUndefined()
 
-	if !hasparser {
-		return nil, fmt.Errorf("parser: var decl: invalid token[%s] expected assigment expression", assignExpr)
-	}
+	switch tok := p.peek(); tok.Type {
+	case token.Assign:
+		p.forget(1)
 
-	val, err := parser(p)
-	if err != nil {
-		return nil, fmt.Errorf("parser: var decl: error[%s] parsing variable assign expression", err)
+		var err error
+		val, err = parseExpr(p)
+		if err != nil {
+			return nil, fmt.Errorf("parser: var decl: error[%s] parsing variable assign expression", err)
+		}
+	case token.SemiColon, token.Comma, token.RBrace:
+	default:
+		if !tok.NewlineBefore {
+			return nil, fmt.Errorf("parser: var decl: expected assignment token [=] got [%s]", tok)
+		}
 	}
 
 	res := ast.NewVarDecls(ast.NewVarDecl(varname, val))
-	possibleSemiColon := p.next()
 
-	if possibleSemiColon.Type == token.SemiColon || possibleSemiColon.Type == token.EOF {
+	switch tok := p.peek(); tok.Type {
+	case token.SemiColon:
+		p.forget(1)
 		return res, nil
-	}
+	case token.EOF, token.RBrace:
+		return res, nil
+	case token.Comma:
+		p.forget(1)
+	default:
+		if tok.NewlineBefore {
+			return res, nil
+		}
 
-	if possibleSemiColon.Type != token.Comma {
-		return nil, fmt.Errorf("parser: var decl: invalid token[%s] expected comma", possibleSemiColon)
+		return nil, fmt.Errorf("parser: var decl: invalid token[%s] expected comma", tok)
 	}
 
 	vars, err := parseVarDeclList(p)
@@ -333,27 +721,112 @@ func parseVarDeclList(p *Parser) (ast.VarDecls, error) {
 
 func parseIdentExpr(p *Parser) (ast.Node, error) {
 	tok := p.lookahead[0]
-	p.scry(1)
-	next := p.lookahead[1]
+	p.forget(1)
 
-	// eg.: console.
-	if next.Type == token.Dot {
-		p.forget(1)
-		return parseMemberExpr(p, ast.NewIdent(tok.Value))
+	if err := checkIdentifier(p, tok); err != nil {
+		return nil, err
 	}
 
-	// eg.: console(
-	if next.Type == token.LParen {
-		return parseCallExpr(p)
+	return parseSuffixExpr(p, ast.NewIdent(tok.Value))
+}
+
+// checkIdentifier fails if the identifier is a reserved word.
+// http://es5.github.io/#x7.6
+func checkIdentifier(p *Parser, tok lexer.Tokval) error {
+	if token.IsFutureReserved(tok.Value.String(), p.strict) {
+		return p.errorf(tok, "parser: unexpected reserved word [%s]", tok.Value)
 	}
 
-	if next.Type != token.EOF && next.Type != token.SemiColon {
-		return nil, p.errorf(next, "parser:identifier:unexpected token [%s]", next)
+	return nil
+}
+
+// parseSuffixExpr parses the member accesses, indexes and calls
+// following expr, eg.: a.b(1).c[2](3).d
+// Suffixes are left associative, each one applies to the expression
+// built so far. An assignment ends the chain.
+// http://es5.github.io/#x11.2
+func parseSuffixExpr(p *Parser, expr ast.Node) (ast.Node, error) {
+	for {
+		var err error
+
+		tok := p.peek()
+		switch tok.Type {
+		case token.Dot:
+			expr, err = parseMemberExpr(p, expr)
+		case token.LBrack:
+			p.checkContinuation(tok)
+			expr, err = parseIndexExpr(p, expr)
+		case token.LParen:
+			p.checkContinuation(tok)
+			expr, err = parseCallExpr(p, expr)
+		default:
+			if !token.IsAssignOperator(tok.Type) {
+				return expr, nil
+			}
+
+			// literals reach here too, eg.: null = 1
+			switch expr.(type) {
+			case ast.Ident, *ast.MemberExpr, *ast.IndexExpr:
+			default:
+				return nil, p.errorf(tok, "parser: invalid assignment target")
+			}
+
+			p.forget(1)
+			return parseAssignExpr(p, tok.Type, expr)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseNewExpr parses the constructor, with its member accesses
+// and indexes, and the optional arguments, eg.: new a.B(1)
+// http://es5.github.io/#x11.2
+//
+// state:
+// lookahead[0] = token.New
+func parseNewExpr(p *Parser) (ast.Node, error) {
+	p.forget(1)
+
+	tok := p.pop()
+	if tok.Type != token.Ident {
+		return nil, p.errorf(tok, "parser: new: unexpected [%s]", tok.Value)
+	}
+
+	if err := checkIdentifier(p, tok); err != nil {
+		return nil, err
 	}
 
-	p.forget(2)
+	var (
+		callee ast.Node = ast.NewIdent(tok.Value)
+		args   []ast.Node
+		err    error
+	)
 
-	return ast.NewIdent(tok.Value), nil
+	for {
+		switch p.peek().Type {
+		case token.Dot:
+			callee, err = parseMemberExpr(p, callee)
+		case token.LBrack:
+			callee, err = parseIndexExpr(p, callee)
+		case token.LParen:
+			p.forget(1)
+			args, err = parseFuncallArgs(p)
+			if err != nil {
+				return nil, err
+			}
+
+			return parseSuffixExpr(p, ast.NewNewExpr(callee, args))
+		default:
+			return parseSuffixExpr(p, ast.NewNewExpr(callee, args))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
 }
 
 // state:
@@ -361,51 +834,71 @@ func parseIdentExpr(p *Parser) (ast.Node, error) {
 func parseMemberExpr(p *Parser, object ast.Node) (ast.Node, error) {
 	p.forget(1)
 
-	tok := p.next()
-	if tok.Type != token.Ident {
+	tok := p.pop()
+	if !isIdentifierName(tok.Type) {
 		return nil, p.errorf(tok, "unexpected %s", tok.Value)
 	}
 
-	member := ast.NewMemberExpr(object, ast.NewIdent(tok.Value))
-
-	// TODO(i4k): Discuss this!
-	// We can just return the MemberExpr here but then if next token is '('
-	// then the main parser loop will need to retrieve the last parsed node
-	// to use it as the object in the CallExpr.
-	// Going into the CallExpr parser from here avoid to keep some states
-	// in the parser structure but can lead to some code duplicates also.
-	// I'm not sure of what's the best approach yet... Coding the naive one
-	// for now.
-	p.scry(1)
+	return ast.NewMemberExpr(object, ast.NewIdent(tok.Value)), nil
+}
 
-	tok = p.lookahead[0]
-	if tok.Type == token.LParen {
-		return parseMemberFuncall(p, member)
+// isIdentifierName tells if the token is a name, including the
+// reserved words, which are valid property names, eg.: a.default.
+// The future reserved words are lexed as identifiers.
+// http://es5.github.io/#x7.6
+func isIdentifierName(t token.Type) bool {
+	switch t {
+	case token.Ident, token.Bool, token.Null:
+		return true
 	}
 
-	if tok.Type == token.Dot {
-		return parseMemberExpr(p, member)
+	return t.IsKeyword()
+}
+
+// state:
+// lookahead[0] = token.LBrack
+func parseIndexExpr(p *Parser, object ast.Node) (ast.Node, error) {
+	p.forget(1)
+
+	index, err := parseExpr(p)
+	if err != nil {
+		return nil, err
 	}
 
-	if tok.Type != token.EOF {
-		return nil, p.errorf(tok, "unexpected %s", tok.Value)
+	tok := p.pop()
+	if tok.Type != token.RBrack {
+		return nil, p.errorf(tok, "parser: index: unexpected [%s]", tok.Value)
 	}
 
-	p.forget(1)
+	return ast.NewIndexExpr(object, index), nil
+}
 
-	return member, nil
+// parseAssignExpr parses the value assigned to target, the
+// operator, '=' or a compound assignment, was already consumed.
+// The value is an expression, so assignments are right associative:
+// a = b += c is a = (b += c)
+// http://es5.github.io/#x11.13
+func parseAssignExpr(p *Parser, op token.Type, target ast.Node) (ast.Node, error) {
+	value, err := parseExpr(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewCompoundAssignExpr(op, target, value), nil
 }
 
 // state:
 // lookahead[0] = token.LParen
-func parseMemberFuncall(p *Parser, member *ast.MemberExpr) (ast.Node, error) {
-	p.forget(1) // drops (
+func parseCallExpr(p *Parser, callee ast.Node) (ast.Node, error) {
+	lparen := p.pop()
 	args, err := parseFuncallArgs(p)
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.NewCallExpr(member, args), nil
+	call := ast.NewCallExpr(callee, args)
+	call.Line, call.Column = lparen.Line, lparen.Column
+	return call, nil
 }
 
 func parseFuncallArgs(p *Parser) ([]ast.Node, error) {
@@ -413,52 +906,46 @@ func parseFuncallArgs(p *Parser) ([]ast.Node, error) {
 		panic(fmt.Sprintf("parser: funcall args: unexpected non empty lookahead:%s", p.lookahead))
 	}
 
-	nextToken := func() lexer.Tokval {
-		p.scry(1)
-		return p.lookahead[0]
-	}
-
 	var args []ast.Node
 
+	if p.peek().Type == token.RParen {
+		p.forget(1)
+		return args, nil
+	}
+
 	for {
-		tok := nextToken()
+		tok := p.peek()
 
+		// eg.: a(b, )
 		if tok.Type == token.RParen {
+			if !p.trailingCommas {
+				return nil, p.errorf(tok, "parser: funcall args: trailing comma not allowed")
+			}
+
 			p.forget(1)
-			break
-		}
-		// TODO: not handling errors like successive commas
-		if tok.Type == token.Comma {
-			p.forget(1)
-			continue
+			return args, nil
 		}
 
-		parser, hasParser := literalParsers[tok.Type]
-		if hasParser {
-			parsed, err := parser(p)
-			if err != nil {
-				return nil, err
-			}
-			args = append(args, parsed)
-		} else {
+		_, hasParser := exprParsers[tok.Type]
+		if !hasParser {
 			return nil, p.errorf(tok, "parser: funcall args: unexpected token [%s]", tok.Value)
 		}
-	}
 
-	return args, nil
-}
+		parsed, err := parseExpr(p)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, parsed)
 
-// state:
-// lookahead[0] = token.Ident
-// lookahead[1] = token.LParen
-func parseCallExpr(p *Parser) (ast.Node, error) {
-	ident := p.lookahead[0]
-	p.forget(2) // drops <ident>(
-	args, err := parseFuncallArgs(p)
-	if err != nil {
-		return nil, err
+		tok = p.pop()
+		if tok.Type == token.RParen {
+			return args, nil
+		}
+
+		if tok.Type != token.Comma {
+			return nil, p.errorf(tok, "parser: funcall args: unexpected token [%s]", tok.Value)
+		}
 	}
-	return ast.NewCallExpr(ast.NewIdent(ident.Value), args), nil
 }
 
 func parseFundecl(p *Parser) (ast.Node, error) {
@@ -468,6 +955,10 @@ func parseFundecl(p *Parser) (ast.Node, error) {
 		return nil, p.errorf(tok, "parser: fundecl: Unexpected [%s]", tok.Value)
 	}
 
+	if err := checkIdentifier(p, tok); err != nil {
+		return nil, err
+	}
+
 	ident := ast.NewIdent(tok.Value)
 
 	args, err := parseFunargs(p)
@@ -483,8 +974,38 @@ func parseFundecl(p *Parser) (ast.Node, error) {
 	return ast.NewFunDecl(ident, args, body), nil
 }
 
+// state:
+// lookahead[0] = token.Function
+func parseFunExpr(p *Parser) (ast.Node, error) {
+	p.forget(1)
+
+	var name ast.Ident
+
+	p.scry(1)
+	if tok := p.lookahead[0]; tok.Type == token.Ident {
+		if err := checkIdentifier(p, tok); err != nil {
+			return nil, err
+		}
+
+		name = ast.NewIdent(tok.Value)
+		p.forget(1)
+	}
+
+	args, err := parseFunargs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := parseFunbody(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewFunExpr(name, args, body), nil
+}
+
 func parseFunargs(p *Parser) ([]ast.Ident, error) {
-	tok := p.next()
+	tok := p.pop()
 	if tok.Type != token.LParen {
 		return nil, p.errorf(tok, "parser: funargs: unexpected [%s]", tok.Value)
 	}
@@ -496,12 +1017,26 @@ func parseFunargs(p *Parser) ([]ast.Ident, error) {
 	}
 
 	for tok.Type == token.Ident {
+		if err := checkIdentifier(p, tok); err != nil {
+			return nil, err
+		}
+
 		args = append(args, ast.NewIdent(tok.Value))
 		tok = p.next()
 		if tok.Type != token.Comma {
 			break
 		}
+
 		tok = p.next()
+
+		// eg.: function f(a, )
+		if tok.Type == token.RParen {
+			if !p.trailingCommas {
+				return nil, p.errorf(tok, "parser: funargs: trailing comma not allowed")
+			}
+
+			return args, nil
+		}
 	}
 
 	if tok.Type != token.RParen {
@@ -518,11 +1053,14 @@ func parseFunbody(p *Parser) (*ast.Program, error) {
 	}
 
 	nbraces := p.openbraces
+	nblocks := p.blocks
 	p.openbraces++
+	p.blocks = 0
 	body, err := p.parse()
 	if err != nil {
 		return nil, err
 	}
+	p.blocks = nblocks
 
 	if p.openbraces != nbraces {
 		return nil, p.errorf(tok, "parser: funbody: expected '}' but found EOF")
@@ -2,12 +2,14 @@ package parser_test
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/NeowayLabs/abad/ast"
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/parser"
 	"github.com/NeowayLabs/abad/token"
+	"github.com/NeowayLabs/abad/utf16"
 	"github.com/madlambda/spells/assert"
 )
 
@@ -28,7 +30,7 @@ func TestParserNumbers(t *testing.T) {
 		{
 			name:    "InvalidDecimal",
 			code:    "1a",
-			wantErr: E("tests.js:1:0: invalid token: 1a"),
+			wantErr: E("tests.js:1:2: unexpected character U+0061 after numeric literal"),
 		},
 		{
 			name: "SmallHexadecimal",
@@ -45,6 +47,31 @@ func TestParserNumbers(t *testing.T) {
 			code: "0xff",
 			want: ast.NewIntNumber(0xff),
 		},
+		{
+			name: "UpperCaseHexadecimal",
+			code: "0XFF",
+			want: ast.NewIntNumber(0xff),
+		},
+		{
+			name: "HexadecimalBeyond64Bits",
+			code: "0x10000000000000000",
+			want: ast.NewNumber(18446744073709551616),
+		},
+		{
+			name: "LegacyOctal",
+			code: "0777",
+			want: ast.NewIntNumber(0777),
+		},
+		{
+			name: "ZeroFollowedByNonOctalDigit",
+			code: "09",
+			want: ast.NewIntNumber(9),
+		},
+		{
+			name: "DecimalOverflow",
+			code: "1e400",
+			want: ast.NewNumber(math.Inf(1)),
+		},
 		{
 			name: "SmallRealNumber",
 			code: ".1",
@@ -68,12 +95,12 @@ func TestParserNumbers(t *testing.T) {
 		{
 			name:    "InvalidRealNumberWithLetter",
 			code:    "0.a",
-			wantErr: E("tests.js:1:0: invalid token: 0.a"),
+			wantErr: E("tests.js:1:3: unexpected character U+0061 after numeric literal"),
 		},
 		{
 			name:    "InvalidRealNumberWithTwoDots",
 			code:    "12.13.",
-			wantErr: E("tests.js:1:0: invalid token: 12.13."),
+			wantErr: E("tests.js:1:6: unexpected character U+002E after numeric literal"),
 		},
 		{
 			name: "RealNumberWithExponent",
@@ -140,7 +167,7 @@ func TestParserNumbers(t *testing.T) {
 		{
 			name:    "InvalidNegativeRealNumber",
 			code:    "-12.13.",
-			wantErr: E("tests.js:1:0: invalid token: 12.13."),
+			wantErr: E("tests.js:1:7: unexpected character U+002E after numeric literal"),
 		},
 		{
 			name: "NegativeDecimalWithNegativeExponent",
@@ -167,6 +194,12 @@ func TestParserNumbers(t *testing.T) {
 						ast.NewUnaryExpr(token.Plus,
 							ast.NewNumber(0))))),
 		},
+		{
+			name: "StrictLegacyOctal",
+			code: "0777",
+			opts: []parser.Option{parser.Strict()},
+			fail: true,
+		},
 	})
 }
 
@@ -252,9 +285,9 @@ func TestKeywords(t *testing.T) {
 			want: null(),
 		},
 		{
-			name: "Undefined",
+			name: "UndefinedIsIdentifier",
 			code: "undefined",
-			want: undefined(),
+			want: identifier("undefined"),
 		},
 		{
 			name: "FalseBool",
@@ -269,6 +302,75 @@ func TestKeywords(t *testing.T) {
 	})
 }
 
+func TestReservedWords(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name:    "Expression",
+			code:    "class",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [class]"),
+		},
+		{
+			name:    "Assignment",
+			code:    "enum = 1",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [enum]"),
+		},
+		{
+			name:    "VarName",
+			code:    "var const = 1;",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [const]"),
+		},
+		{
+			name:    "FunctionName",
+			code:    "function super() {}",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [super]"),
+		},
+		{
+			name:    "FunctionExprName",
+			code:    "f = function import() {}",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [import]"),
+		},
+		{
+			name:    "Param",
+			code:    "function f(a, export) {}",
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [export]"),
+		},
+		{
+			name: "Property",
+			code: "a.class",
+			want: memberExpr(identifier("a"), "class"),
+		},
+		{
+			name: "StrictModeOnly",
+			code: "let",
+			want: identifier("let"),
+		},
+		{
+			name:    "Strict",
+			code:    "let",
+			opts:    []parser.Option{parser.Strict()},
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [let]"),
+		},
+		{
+			name:    "StrictParam",
+			code:    "function f(yield) {}",
+			opts:    []parser.Option{parser.Strict()},
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [yield]"),
+		},
+		{
+			name:    "StrictFutureReserved",
+			code:    "var class = 1;",
+			opts:    []parser.Option{parser.Strict()},
+			wantErr: E("tests.js:1:0: parser: unexpected reserved word [class]"),
+		},
+		{
+			name: "StrictProperty",
+			code: "a.static",
+			opts: []parser.Option{parser.Strict()},
+			want: memberExpr(identifier("a"), "static"),
+		},
+	})
+}
+
 func TestMemberExpr(t *testing.T) {
 	runTests(t, []TestCase{
 		{
@@ -311,6 +413,787 @@ func TestMemberExpr(t *testing.T) {
 				"f",
 			),
 		},
+		{
+			name: "MemberOfString",
+			code: `"abc".length`,
+			want: memberExpr(str("abc"), "length"),
+		},
+		{
+			name: "CallOnString",
+			code: `"a".b(1)+1`,
+			want: ast.NewBinaryExpr(
+				token.Plus,
+				callExpr(memberExpr(str("a"), "b"), []ast.Node{intNumber(1)}),
+				intNumber(1),
+			),
+		},
+		{
+			name: "IndexOfString",
+			code: `"abc"[0]`,
+			want: ast.NewIndexExpr(str("abc"), intNumber(0)),
+		},
+		{
+			name: "MemberOfThis",
+			code: "this.x",
+			want: memberExpr(ast.NewThis(), "x"),
+		},
+		{
+			name: "AssignToMemberOfThis",
+			code: "this.x = this",
+			want: ast.NewAssignExpr(memberExpr(ast.NewThis(), "x"), ast.NewThis()),
+		},
+		{
+			name: "MemberOfNull",
+			code: "null.x",
+			want: memberExpr(null(), "x"),
+		},
+		{
+			name: "MemberOfBool",
+			code: "true.x",
+			want: memberExpr(ast.NewBool(true), "x"),
+		},
+		{
+			name: "KeywordMember",
+			code: "a.default",
+			want: memberExpr(identifier("a"), "default"),
+		},
+		{
+			name: "KeywordMemberOfKeywordMember",
+			code: "a.new.b",
+			want: memberExpr(memberExpr(identifier("a"), "new"), "b"),
+		},
+		{
+			name: "FutureReservedMember",
+			code: "a.class",
+			want: memberExpr(identifier("a"), "class"),
+		},
+		{
+			name: "LiteralNameMember",
+			code: "a.null",
+			want: memberExpr(identifier("a"), "null"),
+		},
+		{
+			name: "AssignToKeywordMember",
+			code: "x.new = 1",
+			want: ast.NewAssignExpr(memberExpr(identifier("x"), "new"), intNumber(1)),
+		},
+		{
+			name: "CallKeywordMember",
+			code: "p.catch(f)",
+			want: callExpr(memberExpr(identifier("p"), "catch"), []ast.Node{identifier("f")}),
+		},
+		{
+			name: "DivideKeywordMember",
+			code: "a.in / 2",
+			want: ast.NewBinaryExpr(token.Quo, memberExpr(identifier("a"), "in"), intNumber(2)),
+		},
+	})
+}
+
+func TestGroupExpr(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Literal",
+			code: "(1)",
+			want: intNumber(1),
+		},
+		{
+			name: "Nested",
+			code: "((1));",
+			want: intNumber(1),
+		},
+		{
+			name: "Identifier",
+			code: "(a)",
+			want: identifier("a"),
+		},
+		{
+			name: "UnaryOfGroup",
+			code: "-(1)",
+			want: ast.NewUnaryExpr(token.Minus, intNumber(1)),
+		},
+		{
+			name: "GroupOfUnary",
+			code: "(-a)",
+			want: ast.NewUnaryExpr(token.Minus, identifier("a")),
+		},
+		{
+			name: "Sequence",
+			code: "(a, 1)",
+			want: ast.NewSequenceExpr(identifier("a"), intNumber(1)),
+		},
+		{
+			name: "NestedSequence",
+			code: "(1, (a.b, 3))",
+			want: ast.NewSequenceExpr(
+				intNumber(1),
+				ast.NewSequenceExpr(memberExpr(identifier("a"), "b"), intNumber(3)),
+			),
+		},
+		{
+			name: "MemberOfGroup",
+			code: "(console).log",
+			want: memberExpr(identifier("console"), "log"),
+		},
+		{
+			name: "CallOfGroup",
+			code: "(console.log)(1)",
+			want: callExpr(memberExpr(identifier("console"), "log"),
+				[]ast.Node{intNumber(1)}),
+		},
+		{
+			name:    "Empty",
+			code:    "()",
+			wantErr: E("tests.js:1:0: parser: group: unexpected [)]"),
+		},
+		{
+			name:    "Unclosed",
+			code:    "(1",
+			wantErr: E("tests.js:1:0: parser: group: unexpected eof"),
+		},
+		{
+			name:    "ArrowFunction",
+			code:    "(a) => a",
+			wantErr: E("tests.js:1:0: parser: group: arrow functions and assignments not supported"),
+		},
+		{
+			name: "UnbalancedParen",
+			code: "a)",
+			fail: true,
+		},
+	})
+}
+
+func TestAssignExpr(t *testing.T) {
+	runTests(t, []TestCase{
+		{
+			name: "Identifier",
+			code: "a = 1",
+			want: ast.NewAssignExpr(identifier("a"), intNumber(1)),
+		},
+		{
+			name: "Chained",
+			code: "a = b = c;",
+			want: ast.NewAssignExpr(
+				identifier("a"),
+				ast.NewAssignExpr(identifier("b"), identifier("c")),
+			),
+		},
+		{
+			name: "Member",
+			code: "console.mylog = console.log",
+			want: ast.NewAssignExpr(
+				memberExpr(identifier("console"), "mylog"),
+				memberExpr(identifier("console"), "log"),
+			),
+		},
+		{
+			name: "FunctionExpression",
+			code: "a.b.c = function (x) { x }",
+			want: ast.NewAssignExpr(
+				memberExpr(memberExpr(identifier("a"), "b"), "c"),
+				ast.NewFunExpr(
+					ast.Ident(nil),
+					[]ast.Ident{identifier("x")},
+					program(identifier("x")),
+				),
+			),
+		},
+		{
+			name: "NamedFunctionExpression",
+			code: "f(function g() {})",
+			want: callExpr(identifier("f"), []ast.Node{
+				ast.NewFunExpr(identifier("g"), nil, program()),
+			}),
+		},
+		{
+			name: "InsideGroup",
+			code: "(a = 1, b)",
+			want: ast.NewSequenceExpr(
+				ast.NewAssignExpr(identifier("a"), intNumber(1)),
+				identifier("b"),
+			),
+		},
+		{
+			name:    "MissingValue",
+			code:    "a =",
+			wantErr: E("tests.js:1:0: unexpected eof"),
+		},
+		{
+			name: "Compound",
+			code: "a += 1",
+			want: ast.NewCompoundAssignExpr(token.AddAssign, identifier("a"), intNumber(1)),
+		},
+		{
+			name: "CompoundChained",
+			code: "a >>>= b = c ^= 2",
+			want: ast.NewCompoundAssignExpr(
+				token.RShiftZeroAssign,
+				identifier("a"),
+				ast.NewAssignExpr(
+					identifier("b"),
+					ast.NewCompoundAssignExpr(token.XorAssign, identifier("c"), intNumber(2)),
+				),
+			),
+		},
+		{
+			name: "CompoundValueExpression",
+			code: "a *= b + 1",
+			want: ast.NewCompoundAssignExpr(
+				token.MulAssign,
+				identifier("a"),
+				ast.NewBinaryExpr(token.Plus, identifier("b"), intNumber(1)),
+			),
+		},
+		{
+			name: "CompoundMember",
+			code: "obj.a -= 1",
+			want: ast.NewCompoundAssignExpr(
+				token.SubAssign,
+				memberExpr(identifier("obj"), "a"),
+				intNumber(1),
+			),
+		},
+		{
+			name: "CompoundIndex",
+			code: "obj[0] |= 1",
+			want: ast.NewCompoundAssignExpr(
+				token.OrAssign,
+				ast.NewIndexExpr(identifier("obj"), intNumber(0)),
+				intNumber(1),
+			),
+		},
+		{
+			name:    "ToLiteral",
+			code:    `"a" = 1`,
+			wantErr: E("tests.js:1:0: parser: invalid assignment target"),
+		},
+		{
+			name:    "ToThis",
+			code:    `this = 1`,
+			wantErr: E("tests.js:1:0: parser: invalid assignment target"),
+		},
+		{
+			name:    "CompoundToCall",
+			code:    "f() %= 1",
+			wantErr: E("tests.js:1:0: parser: invalid assignment target"),
+		},
+	})
+}
+
+func TestInExpr(t *testing.T) {
+	in := func(left, right ast.Node) *ast.BinaryExpr {
+		return ast.NewBinaryExpr(token.In, left, right)
+	}
+
+	runTests(t, []TestCase{
+		{
+			name: "StringInIdentifier",
+			code: `"log" in console`,
+			want: in(str("log"), identifier("console")),
+		},
+		{
+			name: "LeftAssociative",
+			code: `a in b in c;`,
+			want: in(in(identifier("a"), identifier("b")), identifier("c")),
+		},
+		{
+			name: "UnaryOperand",
+			code: `-1 in a.b`,
+			want: in(
+				ast.NewUnaryExpr(token.Minus, intNumber(1)),
+				memberExpr(identifier("a"), "b"),
+			),
+		},
+		{
+			name: "InsideGroupAndArgs",
+			code: `f((a in b), c in d)`,
+			want: callExpr(identifier("f"), []ast.Node{
+				in(identifier("a"), identifier("b")),
+				in(identifier("c"), identifier("d")),
+			}),
+		},
+		{
+			name: "AssignedValue",
+			code: `a = b in c`,
+			want: ast.NewAssignExpr(
+				identifier("a"),
+				in(identifier("b"), identifier("c")),
+			),
+		},
+		{
+			name:    "MissingRightOperand",
+			code:    `a in`,
+			wantErr: E("tests.js:1:0: unexpected eof"),
+		},
+	})
+}
+
+func TestArithmeticExpr(t *testing.T) {
+	binary := func(op token.Type) func(left, right ast.Node) *ast.BinaryExpr {
+		return func(left, right ast.Node) *ast.BinaryExpr {
+			return ast.NewBinaryExpr(op, left, right)
+		}
+	}
+
+	add, sub := binary(token.Plus), binary(token.Minus)
+	mul, quo, rem := binary(token.Mul), binary(token.Quo), binary(token.Rem)
+	in := binary(token.In)
+	one, two, three := intNumber(1), intNumber(2), intNumber(3)
+
+	runTests(t, []TestCase{
+		{
+			name: "MulBindsTighter",
+			code: "1 + 2 * 3",
+			want: add(one, mul(two, three)),
+		},
+		{
+			name: "MulFirst",
+			code: "1 * 2 + 3",
+			want: add(mul(one, two), three),
+		},
+		{
+			name: "LeftAssociative",
+			code: "1 - 2 - 3",
+			want: sub(sub(one, two), three),
+		},
+		{
+			name: "MultiplicativeLeftAssociative",
+			code: "1 / 2 % 3 * 1",
+			want: mul(rem(quo(one, two), three), one),
+		},
+		{
+			name: "Group",
+			code: "(1 + 2) * 3",
+			want: mul(add(one, two), three),
+		},
+		{
+			name: "UnaryOperands",
+			code: "-1 - -2",
+			want: sub(
+				ast.NewUnaryExpr(token.Minus, one),
+				ast.NewUnaryExpr(token.Minus, two),
+			),
+		},
+		{
+			name: "InIsLooser",
+			code: "a + 1 in b * 2",
+			want: in(
+				add(identifier("a"), one),
+				mul(identifier("b"), two),
+			),
+		},
+		{
+			name: "Operands",
+			code: `a.b * f(1) + s[0]`,
+			want: add(
+				mul(memberExpr(identifier("a"), "b"),
+					callExpr(identifier("f"), []ast.Node{one})),
+				ast.NewIndexExpr(identifier("s"), intNumber(0)),
+			),
+		},
+		{
+			name: "AssignedValue",
+			code: "a = 1 + 2",
+			want: ast.NewAssignExpr(identifier("a"), add(one, two)),
+		},
+		{
+			name: "Unspaced",
+			code: "1+2*3",
+			want: add(one, mul(two, three)),
+		},
+		{
+			name: "UnspacedDivision",
+			code: "a=1/2%3",
+			want: ast.NewAssignExpr(identifier("a"), rem(quo(one, two), three)),
+		},
+		{
+			name: "UnspacedLiterals",
+			code: "0x1F+1.5-1e5",
+			want: sub(add(intNumber(31), number(1.5)), number(1e5)),
+		},
+		{
+			name:    "MissingRightOperand",
+			code:    "1 *",
+			wantErr: E("tests.js:1:0: unexpected eof"),
+		},
+	})
+}
+
+func TestComparisonExpr(t *testing.T) {
+	binary := func(op token.Type) func(left, right ast.Node) *ast.BinaryExpr {
+		return func(left, right ast.Node) *ast.BinaryExpr {
+			return ast.NewBinaryExpr(op, left, right)
+		}
+	}
+
+	a, b, c, d := identifier("a"), identifier("b"), identifier("c"), identifier("d")
+	add := binary(token.Plus)
+	less, greater := binary(token.Less), binary(token.Greater)
+	lessEq, greaterEq := binary(token.LessEq), binary(token.GreaterEq)
+	equal, notEqual := binary(token.Equal), binary(token.NotEqual)
+	tequal, notTEqual := binary(token.TEqual), binary(token.NotTEqual)
+
+	runTests(t, []TestCase{
+		{
+			name: "Relational",
+			code: "a < b; a > b; a <= b; a >= b",
+			wants: []ast.Node{
+				less(a, b), greater(a, b), lessEq(a, b), greaterEq(a, b),
+			},
+		},
+		{
+			name: "Equality",
+			code: "a == b; a != b; a === b; a !== b",
+			wants: []ast.Node{
+				equal(a, b), notEqual(a, b), tequal(a, b), notTEqual(a, b),
+			},
+		},
+		{
+			name: "ArithmeticBindsTighter",
+			code: "a + 1 < b + 2",
+			want: less(add(a, intNumber(1)), add(b, intNumber(2))),
+		},
+		{
+			name: "RelationalBindsTighter",
+			code: "a < b == c >= d",
+			want: equal(less(a, b), greaterEq(c, d)),
+		},
+		{
+			name: "LeftAssociative",
+			code: "a === b !== c",
+			want: notTEqual(tequal(a, b), c),
+		},
+		{
+			name: "SamePrecedenceAsIn",
+			code: "a in b < c",
+			want: less(ast.NewBinaryExpr(token.In, a, b), c),
+		},
+		{
+			name:    "MissingRightOperand",
+			code:    "a <=",
+			wantErr: E("tests.js:1:0: unexpected eof"),
+		},
+	})
+}
+
+func TestLogicalExpr(t *testing.T) {
+	binary := func(op token.Type) func(left, right ast.Node) *ast.BinaryExpr {
+		return func(left, right ast.Node) *ast.BinaryExpr {
+			return ast.NewBinaryExpr(op, left, right)
+		}
+	}
+
+	a, b, c := identifier("a"), identifier("b"), identifier("c")
+	and, or := binary(token.LAnd), binary(token.LOr)
+	not := func(operand ast.Node) *ast.UnaryExpr {
+		return ast.NewUnaryExpr(token.LNot, operand)
+	}
+
+	runTests(t, []TestCase{
+		{
+			name: "AndBindsTighter",
+			code: "a && b || !c",
+			want: or(and(a, b), not(c)),
+		},
+		{
+			name: "AndBindsTighterOnTheRight",
+			code: "a || b && c",
+			want: or(a, and(b, c)),
+		},
+		{
+			name: "LeftAssociative",
+			code: "a || b || c",
+			want: or(or(a, b), c),
+		},
+		{
+			name: "ComparisonsBindTighter",
+			code: "a < 1 && b == c",
+			want: and(
+				ast.NewBinaryExpr(token.Less, a, intNumber(1)),
+				ast.NewBinaryExpr(token.Equal, b, c),
+			),
+		},
+		{
+			name: "Not",
+			code: "!!a.b",
+			want: not(not(memberExpr(a, "b"))),
+		},
+		{
+			name: "NotBindsTighter",
+			code: "!a == b",
+			want: ast.NewBinaryExpr(token.Equal, not(a), b),
+		},
+		{
+			name:    "MissingRightOperand",
+			code:    "a ||",
+			wantErr: E("tests.js:1:0: unexpected eof"),
+		},
+	})
+}
+
+func TestIndexExpr(t *testing.T) {
+	index := ast.NewIndexExpr
+
+	runTests(t, []TestCase{
+		{
+			name: "Number",
+			code: "s[0]",
+			want: index(identifier("s"), intNumber(0)),
+		},
+		{
+			name: "Expression",
+			code: `a.b[c.d]["e"]`,
+			want: index(
+				index(memberExpr(identifier("a"), "b"), memberExpr(identifier("c"), "d")),
+				str("e"),
+			),
+		},
+		{
+			name: "MemberOfIndex",
+			code: "s[0].length",
+			want: memberExpr(index(identifier("s"), intNumber(0)), "length"),
+		},
+		{
+			name: "CallOfIndex",
+			code: "a[0](1)",
+			want: callExpr(index(identifier("a"), intNumber(0)), []ast.Node{intNumber(1)}),
+		},
+		{
+			name: "Assign",
+			code: "a[0] = 1",
+			want: ast.NewAssignExpr(index(identifier("a"), intNumber(0)), intNumber(1)),
+		},
+		{
+			name:    "Unclosed",
+			code:    "a[0",
+			wantErr: E("tests.js:1:0: parser: index: unexpected [EOF]"),
+		},
+	})
+}
+
+func TestSuffixChains(t *testing.T) {
+	index := ast.NewIndexExpr
+	args := func(nodes ...ast.Node) []ast.Node { return nodes }
+
+	runTests(t, []TestCase{
+		{
+			name: "CallOfCall",
+			code: "f()()",
+			want: callExpr(callExpr(identifier("f"), nil), nil),
+		},
+		{
+			name: "MemberOfCall",
+			code: "f(1).a",
+			want: memberExpr(callExpr(identifier("f"), args(intNumber(1))), "a"),
+		},
+		{
+			name: "IndexOfCall",
+			code: "f()[0]",
+			want: index(callExpr(identifier("f"), nil), intNumber(0)),
+		},
+		{
+			name: "CallOfMemberOfCall",
+			code: "a.b(1).c(2)",
+			want: callExpr(
+				memberExpr(
+					callExpr(memberExpr(identifier("a"), "b"), args(intNumber(1))),
+					"c",
+				),
+				args(intNumber(2)),
+			),
+		},
+		{
+			name: "MixedChain",
+			code: "a.b(1).c[2](3).d",
+			want: memberExpr(
+				callExpr(
+					index(
+						memberExpr(
+							callExpr(memberExpr(identifier("a"), "b"), args(intNumber(1))),
+							"c",
+						),
+						intNumber(2),
+					),
+					args(intNumber(3)),
+				),
+				"d",
+			),
+		},
+		{
+			name: "CallArgumentsAreChains",
+			code: "f(a.b(1), c[0]())",
+			want: callExpr(identifier("f"), args(
+				callExpr(memberExpr(identifier("a"), "b"), args(intNumber(1))),
+				callExpr(index(identifier("c"), intNumber(0)), nil),
+			)),
+		},
+		{
+			name: "AssignToMemberOfCall",
+			code: "f().a = 1",
+			want: ast.NewAssignExpr(
+				memberExpr(callExpr(identifier("f"), nil), "a"),
+				intNumber(1),
+			),
+		},
+		{
+			name:    "AssignToCall",
+			code:    "f() = 1",
+			wantErr: E("tests.js:1:0: parser: invalid assignment target"),
+		},
+	})
+}
+
+func TestNewExpr(t *testing.T) {
+	newExpr := ast.NewNewExpr
+	args := func(nodes ...ast.Node) []ast.Node { return nodes }
+
+	runTests(t, []TestCase{
+		{
+			name: "WithoutArgs",
+			code: "new Date",
+			want: newExpr(identifier("Date"), nil),
+		},
+		{
+			name: "EmptyArgs",
+			code: "new Date()",
+			want: newExpr(identifier("Date"), nil),
+		},
+		{
+			name: "Args",
+			code: "new Date(2000, a)",
+			want: newExpr(identifier("Date"), args(intNumber(2000), identifier("a"))),
+		},
+		{
+			name: "MemberCallee",
+			code: "new a.b[0].C(1)",
+			want: newExpr(
+				memberExpr(ast.NewIndexExpr(memberExpr(identifier("a"), "b"), intNumber(0)), "C"),
+				args(intNumber(1)),
+			),
+		},
+		{
+			name: "CallOfMember",
+			code: "new Date(0).getTime()",
+			want: callExpr(memberExpr(newExpr(identifier("Date"), args(intNumber(0))), "getTime"), nil),
+		},
+		{
+			name: "Assign",
+			code: "d = new Date",
+			want: ast.NewAssignExpr(identifier("d"), newExpr(identifier("Date"), nil)),
+		},
+		{
+			name:    "AssignToNew",
+			code:    "new Date() = 1",
+			wantErr: E("tests.js:1:0: parser: invalid assignment target"),
+		},
+		{
+			name:    "NoCallee",
+			code:    "new 1",
+			wantErr: E("tests.js:1:0: parser: new: unexpected [1]"),
+		},
+	})
+}
+
+func TestES6NumericLiterals(t *testing.T) {
+	es6 := []parser.Option{parser.ES6()}
+
+	runTests(t, []TestCase{
+		{
+			name: "Binary",
+			code: "0b1010",
+			want: intNumber(10),
+			opts: es6,
+		},
+		{
+			name: "Octal",
+			code: "0O755",
+			want: intNumber(0755),
+			opts: es6,
+		},
+		{
+			name: "LegacyOctal",
+			code: "0755",
+			want: intNumber(0755),
+			opts: es6,
+		},
+	})
+}
+
+func TestBigIntLiterals(t *testing.T) {
+	bigint := func(lit string) ast.BigInt {
+		v, _ := new(big.Int).SetString(lit, 10)
+		return ast.NewBigInt(v)
+	}
+
+	runTests(t, []TestCase{
+		{
+			name: "Decimal",
+			code: "123456789012345678901234567890n",
+			want: bigint("123456789012345678901234567890"),
+		},
+		{
+			name: "Hexadecimal",
+			code: "0xFFn",
+			want: bigint("255"),
+		},
+		{
+			name: "Binary",
+			code: "0b1010n",
+			want: bigint("10"),
+			opts: []parser.Option{parser.ES6()},
+		},
+		{
+			name: "Argument",
+			code: "f(0n)",
+			want: callExpr(identifier("f"), []ast.Node{bigint("0")}),
+		},
+	})
+}
+
+func TestTrailingCommas(t *testing.T) {
+	trailing := []parser.Option{parser.TrailingCommas()}
+
+	runTests(t, []TestCase{
+		{
+			name: "CallArgs",
+			code: "f(1, 2,)",
+			want: callExpr(identifier("f"), []ast.Node{intNumber(1), intNumber(2)}),
+			opts: trailing,
+		},
+		{
+			name: "FunctionParams",
+			code: "function f(a, b,) {}",
+			want: fundecl(identifier("f"), []ast.Ident{identifier("a"), identifier("b")}, &ast.Program{}),
+			opts: trailing,
+		},
+		{
+			name:    "CallArgsNotAllowed",
+			code:    "f(1, 2,)",
+			wantErr: E("tests.js:1:0: parser: funcall args: trailing comma not allowed"),
+		},
+		{
+			name:    "FunctionParamsNotAllowed",
+			code:    "function f(a,) {}",
+			wantErr: E("tests.js:1:0: parser: funargs: trailing comma not allowed"),
+		},
+		{
+			name:    "OnlyComma",
+			code:    "f(,)",
+			wantErr: E("tests.js:1:0: parser: funcall args: unexpected token [,]"),
+			opts:    trailing,
+		},
+		{
+			name:    "SuccessiveCommas",
+			code:    "f(1,,2)",
+			wantErr: E("tests.js:1:0: parser: funcall args: unexpected token [,]"),
+			opts:    trailing,
+		},
+		{
+			name:    "MissingComma",
+			code:    "f(1 2)",
+			wantErr: E("tests.js:1:0: parser: funcall args: unexpected token [2]"),
+		},
 	})
 }
 
@@ -371,6 +1254,11 @@ func TestVarDeclarationErrors(t *testing.T) {
 			code: "var d = obj.666",
 			fail: true,
 		},
+		{
+			name: "SameLineStatement",
+			code: "var a = 1 b = 2",
+			fail: true,
+		},
 	})
 }
 
@@ -383,12 +1271,6 @@ func TestVarStatement(t *testing.T) {
 		return varDecls(varDecl(name, val))
 	}
 
-	// TODO: add vars init to funcall and access member expressions
-	// eg: var a = func()
-	//     var b = a.x.i()
-
-	// TODO: add identifier to multiple vars statements and multiple vars on
-	// single statement.
 	runTests(t, []TestCase{
 		{
 			name: "NoInitializer",
@@ -418,7 +1300,7 @@ func TestVarStatement(t *testing.T) {
 		{
 			name: "Undefined",
 			code: "var u = undefined;",
-			want: vars(identifier("u"), undefined()),
+			want: vars(identifier("u"), identifier("undefined")),
 		},
 		{
 			name: "Null",
@@ -440,6 +1322,46 @@ func TestVarStatement(t *testing.T) {
 			code: "var b = a;",
 			want: vars(identifier("b"), identifier("a")),
 		},
+		{
+			name: "FunctionExpression",
+			code: "var f = function () {};",
+			want: vars(identifier("f"), ast.NewFunExpr(ast.Ident(nil), nil, program())),
+		},
+		{
+			name: "Call",
+			code: "var b = a.x.i();",
+			want: vars(identifier("b"), callExpr(memberExpr(memberExpr(identifier("a"), "x"), "i"), nil)),
+		},
+		{
+			name: "BinaryExpression",
+			code: "var b = a + 1",
+			want: vars(identifier("b"), ast.NewBinaryExpr(token.Plus, identifier("a"), intNumber(1))),
+		},
+		{
+			name: "MixedInitializers",
+			code: "var a, b = 1, c;",
+			want: varDecls(
+				varDecl(identifier("a"), undefined()),
+				varDecl(identifier("b"), intNumber(1)),
+				varDecl(identifier("c"), undefined()),
+			),
+		},
+		{
+			name: "EndOfLine",
+			code: "var a = 1\nvar b\na",
+			wants: []ast.Node{
+				vars(identifier("a"), intNumber(1)),
+				vars(identifier("b"), undefined()),
+				identifier("a"),
+			},
+		},
+		{
+			name: "EndOfBlock",
+			code: "function f() { var a = 1 }",
+			want: ast.NewFunDecl(identifier("f"), nil, program(
+				vars(identifier("a"), intNumber(1)),
+			)),
+		},
 		{
 			name: "MultipleVarsInSingleStatement",
 			code: `
@@ -453,7 +1375,7 @@ func TestVarStatement(t *testing.T) {
 				varDecl(identifier("d"), intNumber(666)),
 				varDecl(identifier("x"), intNumber(255)),
 				varDecl(identifier("s"), str("hi")),
-				varDecl(identifier("u"), undefined()),
+				varDecl(identifier("u"), identifier("undefined")),
 				varDecl(identifier("n"), null()),
 			),
 		},
@@ -470,7 +1392,7 @@ func TestVarStatement(t *testing.T) {
 				vars(identifier("d"), intNumber(666)),
 				vars(identifier("x"), intNumber(255)),
 				vars(identifier("s"), str("hi")),
-				vars(identifier("u"), undefined()),
+				vars(identifier("u"), identifier("undefined")),
 				vars(identifier("n"), null()),
 			},
 		},
@@ -494,6 +1416,11 @@ func TestParserFuncallError(t *testing.T) {
 			code: "a(666,777",
 			fail: true,
 		},
+		{
+			name: "IdentParamMissingRParen",
+			code: "a(b",
+			fail: true,
+		},
 	})
 }
 
@@ -507,7 +1434,7 @@ func TestParserFuncall(t *testing.T) {
 		{
 			name: "UndefinedParameter",
 			code: "b(undefined)",
-			want: callExpr(identifier("b"), []ast.Node{undefined()}),
+			want: callExpr(identifier("b"), []ast.Node{identifier("undefined")}),
 		},
 		{
 			name: "NullParameter",
@@ -660,7 +1587,7 @@ func TestParserFuncall(t *testing.T) {
 				boolean(true),
 				boolean(false),
 				null(),
-				undefined(),
+				identifier("undefined"),
 				intNumber(666),
 				intNumber(255),
 			}),
@@ -673,11 +1600,22 @@ func TestParserFuncall(t *testing.T) {
 				boolean(true),
 				boolean(false),
 				null(),
-				undefined(),
+				identifier("undefined"),
 				intNumber(666),
 				intNumber(255),
 			}),
 		},
+		{
+			name: "ExpressionsAsParams",
+			code: `f(a, b.c, -1, g(x), (y, 2))`,
+			want: callExpr(identifier("f"), []ast.Node{
+				identifier("a"),
+				memberExpr(identifier("b"), "c"),
+				ast.NewUnaryExpr(token.Minus, intNumber(1)),
+				callExpr(identifier("g"), []ast.Node{identifier("x")}),
+				ast.NewSequenceExpr(identifier("y"), intNumber(2)),
+			}),
+		},
 	})
 }
 
@@ -713,6 +1651,23 @@ func TestFunDecl(t *testing.T) {
 					})),
 			),
 		},
+		{
+			name: "nested functions",
+			code: `function a(b){ c(b); { function c(d){ d } } c }`,
+			want: fundecl(
+				identifier("a"),
+				[]ast.Ident{identifier("b")},
+				program(
+					callExpr(identifier("c"), []ast.Node{identifier("b")}),
+					fundecl(
+						identifier("c"),
+						[]ast.Ident{identifier("d")},
+						program(identifier("d")),
+					),
+					identifier("c"),
+				),
+			),
+		},
 		{
 			name: "function between stmts",
 			code: `console.log(1);
@@ -743,6 +1698,199 @@ func TestFunDecl(t *testing.T) {
 	})
 }
 
+func TestParseFiles(t *testing.T) {
+	var files []parser.File
+	var want []ast.Node
+
+	for i := 0; i < 50; i++ {
+		files = append(files, parser.File{
+			Name: fmt.Sprintf("file%d.js", i),
+			Code: fmt.Sprintf("%d", i),
+		})
+		want = append(want, intNumber(int64(i)))
+	}
+
+	programs, err := parser.ParseFiles(files)
+	assert.NoError(t, err, "parsing files")
+	assert.EqualInts(t, len(files), len(programs), "number of programs")
+
+	for i, program := range programs {
+		assertEqualNodes(t, want[i:i+1], program.Nodes)
+	}
+
+	files[10].Code = "0.1."
+	files[30].Code = "0.2."
+
+	_, err = parser.ParseFiles(files)
+	assert.EqualErrs(t, E("file10.js:1:4: unexpected character U+002E after numeric literal"), err,
+		"first error must be returned")
+}
+
+func TestParseExpr(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want ast.Node
+		err  error
+	}{
+		{
+			name: "Member",
+			code: "a.b",
+			want: memberExpr(identifier("a"), "b"),
+		},
+		{
+			name: "SemiColon",
+			code: `"x" in a;`,
+			want: ast.NewBinaryExpr(token.In, str("x"), identifier("a")),
+		},
+		{
+			name: "Statements",
+			code: "a; b",
+			err:  E(`expr.js: "a; b" is not a single expression`),
+		},
+		{
+			name: "FunDecl",
+			code: "function f() {}",
+			err:  E(`expr.js: "function f() {}" is not a single expression`),
+		},
+		{
+			name: "Empty",
+			code: "",
+			err:  E(`expr.js: "" is not a single expression`),
+		},
+		{
+			name: "Invalid",
+			code: "a in",
+			err:  E("expr.js:1:0: unexpected eof"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parser.ParseExpr("expr.js", tc.code)
+			assert.EqualErrs(t, tc.err, err, "parsing %s", tc.code)
+
+			if tc.err == nil {
+				assertEqualNodes(t, []ast.Node{tc.want}, []ast.Node{got})
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "None",
+			code: "a = 1;\nb = f(a)\nfunction g() {} g()",
+		},
+		{
+			name: "LegacyOctal",
+			code: "a = 017",
+			want: []string{"test.js:1:5: legacy octal literal 017 is deprecated, use 0o17"},
+		},
+		{
+			name: "CallContinuation",
+			code: "a = b\n(c)",
+			want: []string{"test.js:2:1: the line starting with '(' continues the expression of the previous line, no semicolon is inserted before it"},
+		},
+		{
+			name: "IndexContinuation",
+			code: "a\n[0]",
+			want: []string{"test.js:2:1: the line starting with '[' continues the expression of the previous line, no semicolon is inserted before it"},
+		},
+		{
+			name: "OperatorContinuation",
+			code: "a = b\n-1",
+			want: []string{"test.js:2:1: the line starting with '-' continues the expression of the previous line, no semicolon is inserted before it"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			_, err := parser.Parse("test.js", tc.code, parser.OnWarning(func(w parser.Warning) {
+				got = append(got, w.String())
+			}))
+			assert.NoError(t, err, "parsing %s", tc.code)
+
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("got warnings %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStatementTermination(t *testing.T) {
+	missing := func(next string) error {
+		return E("tests.js:1:0: parser: missing ';' before %s, statements in the same line must be separated", next)
+	}
+
+	runTests(t, []TestCase{
+		{
+			name:    "SameLine",
+			code:    "a = 1 b = 2",
+			wantErr: missing("Ident"),
+		},
+		{
+			name:    "CallsInSameLine",
+			code:    "f() g()",
+			wantErr: missing("Ident"),
+		},
+		{
+			name:    "AfterFunctionExpression",
+			code:    "f = function () {} f()",
+			wantErr: missing("Ident"),
+		},
+		{
+			name:  "SemiColon",
+			code:  "a; b",
+			wants: []ast.Node{identifier("a"), identifier("b")},
+		},
+		{
+			name:  "Newline",
+			code:  "a\nb",
+			wants: []ast.Node{identifier("a"), identifier("b")},
+		},
+		{
+			name: "AfterFunctionDeclaration",
+			code: "function f() {} f()",
+			wants: []ast.Node{
+				ast.NewFunDecl(identifier("f"), nil, program()),
+				callExpr(identifier("f"), nil),
+			},
+		},
+		{
+			name: "AfterVar",
+			code: "var a = 1; a",
+			wants: []ast.Node{
+				varDecls(varDecl(identifier("a"), intNumber(1))),
+				identifier("a"),
+			},
+		},
+	})
+}
+
+func TestParseFilesWarnings(t *testing.T) {
+	var files []parser.File
+	var want []string
+
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("file%d.js", i)
+		files = append(files, parser.File{Name: name, Code: "01; 02"})
+		want = append(want, name+":1:1", name+":1:5")
+	}
+
+	var got []string
+	_, err := parser.ParseFiles(files, parser.OnWarning(func(w parser.Warning) {
+		got = append(got, fmt.Sprintf("%s:%d:%d", w.File, w.Line, w.Column))
+	}))
+	assert.NoError(t, err, "parsing files")
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got warnings %q, want %q", got, want)
+	}
+}
+
 // TestCase is the description of an parser related test.
 // The fields want and wants are mutually exclusive, you should
 // never provide both. If "wants" is provided the "want" field will be ignored.
@@ -755,11 +1903,12 @@ type TestCase struct {
 	wants   []ast.Node
 	fail    bool
 	wantErr error
+	opts    []parser.Option
 }
 
 func (tc *TestCase) run(t *testing.T) {
 	t.Run(tc.name, func(t *testing.T) {
-		tree, err := parser.Parse("tests.js", tc.code)
+		tree, err := parser.Parse("tests.js", tc.code, tc.opts...)
 
 		if tc.fail && tc.wantErr == nil {
 			if err == nil {
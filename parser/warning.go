@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/NeowayLabs/abad/lexer"
+)
+
+// Warning reports valid code that is likely a mistake or that relies
+// on deprecated syntax, eg.: a legacy octal literal. Hosts can show
+// them to tighten their scripts progressively, before rejecting them
+// with a stricter option, eg.: Strict.
+type Warning struct {
+	File string
+
+	// Line and Column of the code, zero if the warning is not
+	// about a position of the code.
+	Line   uint
+	Column uint
+
+	Msg string
+}
+
+// OnWarning calls fn with the warnings found while parsing, in the
+// order of the code. ParseFiles calls it after all the files are
+// parsed, in the order of the files.
+func OnWarning(fn func(Warning)) Option {
+	return func(p *Parser) {
+		p.onWarning = fn
+	}
+}
+
+// String formats the warning as file:line:column: msg.
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return fmt.Sprintf("%s: %s", w.File, w.Msg)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", w.File, w.Line, w.Column, w.Msg)
+}
+
+func (p *Parser) warnf(tok lexer.Tokval, f string, a ...interface{}) {
+	if p.onWarning == nil {
+		return
+	}
+
+	p.onWarning(Warning{
+		File:   p.filename,
+		Line:   tok.Line,
+		Column: tok.Column,
+		Msg:    fmt.Sprintf(f, a...),
+	})
+}
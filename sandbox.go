@@ -0,0 +1,75 @@
+package abad
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// Capabilities is the set of builtins with side effects
+	// that are registered on the global object.
+	Capabilities uint
+)
+
+const (
+	// CapConsole allows writing on the standard output with console.
+	CapConsole Capabilities = 1 << iota
+
+	// CapClock allows reading the host clock with Date.
+	CapClock
+
+	// CapFiles allows reading files of the host named by the code,
+	// eg.: the source maps of sourceMappingURL comments.
+	CapFiles
+
+	// CapAll allows everything.
+	CapAll = CapConsole | CapClock | CapFiles
+)
+
+const (
+	// CapPure allows nothing, pure code can only compute values.
+	CapPure Capabilities = 0
+)
+
+// profiles are the named sandboxes. A profile denies
+// any capability that it does not explicitly list.
+var profiles = map[string]Capabilities{
+	"pure": CapPure,
+
+	// cli is for scripts run by the user on its terminal.
+	"cli": CapConsole | CapClock | CapFiles,
+
+	// server is for scripts run by services, which have
+	// no terminal to write on.
+	"server": CapClock,
+}
+
+// Sandbox grants caps to the interpreter. By default
+// interpreters are pure, without any capability.
+func Sandbox(caps Capabilities) Option {
+	return func(a *Abad) {
+		a.caps = caps
+	}
+}
+
+// Profile returns the capabilities of the named sandbox profile.
+func Profile(name string) (Capabilities, error) {
+	caps, ok := profiles[name]
+	if !ok {
+		var names []string
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return 0, fmt.Errorf("unknown sandbox profile [%s], valid profiles are: %s",
+			name, strings.Join(names, ", "))
+	}
+	return caps, nil
+}
+
+// Has tells if all capabilities of other are in c.
+func (c Capabilities) Has(other Capabilities) bool {
+	return c&other == other
+}
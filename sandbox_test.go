@@ -0,0 +1,67 @@
+package abad_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestSandbox(t *testing.T) {
+	for _, tc := range []struct {
+		profile string
+		code    string
+		err     error
+	}{
+		{profile: "cli", code: "console"},
+		{profile: "cli", code: "Date.now()"},
+		{profile: "server", code: "Date.now()"},
+		{profile: "server", code: "console", err: E("ReferenceError: [console] is not defined")},
+		{profile: "pure", code: "Math.random()"},
+		{profile: "pure", code: "console", err: E("ReferenceError: [console] is not defined")},
+		{profile: "pure", code: "Date", err: E("ReferenceError: [Date] is not defined")},
+	} {
+		t.Run(tc.profile+"/"+tc.code, func(t *testing.T) {
+			caps, err := abad.Profile(tc.profile)
+			assert.NoError(t, err, "getting profile")
+
+			js, err := abad.NewAbad(abad.Sandbox(caps))
+			assert.NoError(t, err, "failed to start interpreter")
+
+			_, err = js.Eval(tc.code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+		})
+	}
+}
+
+func TestDefaultSandboxIsPure(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval("console")
+	assert.EqualErrs(t, E("ReferenceError: [console] is not defined"), err, "errors differ")
+
+	_, err = js.Eval("Math.random()")
+	assert.NoError(t, err, "evaluating pure code")
+}
+
+func TestUnknownSandboxProfile(t *testing.T) {
+	_, err := abad.Profile("root")
+	assert.EqualErrs(t, E("unknown sandbox profile [root], valid profiles are: cli, pure, server"), err)
+}
+
+func TestConsoleTimersNeedClock(t *testing.T) {
+	js, err := abad.NewAbad(abad.Sandbox(abad.CapConsole))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.Eval(`console.count("calls")`)
+	assert.NoError(t, err, "counting")
+
+	_, err = js.Eval(`console.time("t")`)
+	jserr, ok := err.(*abad.JSError)
+	if !ok {
+		t.Fatalf("got %v, want a TypeError", err)
+	}
+
+	assert.EqualStrings(t, "TypeError", jserr.Name(), "error name")
+}
@@ -0,0 +1,273 @@
+// Package scope implements the identifier resolution of the
+// javascript AST. Every identifier is resolved to the scope that
+// declares it and to its slot inside this scope, before any
+// evaluation happens.
+//
+// Documentation: https://es5.github.io/#x10.5
+package scope
+
+import (
+	"fmt"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/token"
+)
+
+type (
+	// Scope is the set of names declared by the global code
+	// or by a function body. Each name has a slot index inside
+	// the scope, making possible to store the bindings in an array.
+	Scope struct {
+		Parent *Scope
+
+		// Node is the *ast.Program of the global code or the
+		// *ast.FunDecl (or *ast.FunExpr) of a function.
+		Node ast.Node
+
+		names []string
+		slots map[string]int
+
+		// functions declared or expressed directly in the scope
+		functions map[ast.Node]*Scope
+	}
+
+	// Binding is the declaration a name resolves to.
+	Binding struct {
+		Scope *Scope
+
+		// Depth is how many scopes up the declaring scope is
+		// from the scope where the name is referenced.
+		Depth int
+		Slot  int
+	}
+
+	// Ref is a reference to an identifier.
+	Ref struct {
+		Ident    ast.Ident
+		Scope    *Scope
+		Binding  Binding
+		Resolved bool
+
+		// Assigned tells the identifier is the target of a
+		// simple assignment, eg.: x = 1, which creates a global
+		// when x doesn't resolve.
+		Assigned bool
+	}
+
+	// Warning reports a reference that does not resolve to
+	// any declaration.
+	Warning struct {
+		Name string
+	}
+
+	// Result of the analysis of a program.
+	Result struct {
+		Global *Scope
+
+		// Refs are all the identifier references of the program
+		// in the order they appear in the source code.
+		Refs     []Ref
+		Warnings []Warning
+	}
+
+	analyzer struct {
+		result *Result
+	}
+)
+
+func newScope(parent *Scope, node ast.Node) *Scope {
+	return &Scope{
+		Parent:    parent,
+		Node:      node,
+		slots:     make(map[string]int),
+		functions: make(map[ast.Node]*Scope),
+	}
+}
+
+// Declare name in the scope returning its slot. Declaring the same
+// name twice returns the same slot, as var redeclarations do.
+func (s *Scope) Declare(name string) int {
+	slot, ok := s.slots[name]
+	if ok {
+		return slot
+	}
+
+	slot = len(s.names)
+	s.names = append(s.names, name)
+	s.slots[name] = slot
+	return slot
+}
+
+// Slot returns the slot of name if it is declared in this scope.
+func (s *Scope) Slot(name string) (int, bool) {
+	slot, ok := s.slots[name]
+	return slot, ok
+}
+
+// Names returns the declared names ordered by slot.
+func (s *Scope) Names() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Len is the number of slots of the scope.
+func (s *Scope) Len() int {
+	return len(s.names)
+}
+
+// Function returns the scope of the function fn, the *ast.FunDecl or
+// *ast.FunExpr found directly in this scope, not inside its functions.
+func (s *Scope) Function(fn ast.Node) (*Scope, bool) {
+	fnscope, ok := s.functions[fn]
+	return fnscope, ok
+}
+
+// Lookup resolves name walking the scope chain.
+func (s *Scope) Lookup(name string) (Binding, bool) {
+	depth := 0
+	for scope := s; scope != nil; scope = scope.Parent {
+		if slot, ok := scope.Slot(name); ok {
+			return Binding{
+				Scope: scope,
+				Depth: depth,
+				Slot:  slot,
+			}, true
+		}
+		depth++
+	}
+
+	return Binding{}, false
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s is not declared", w.Name)
+}
+
+// Analyze resolves all identifiers of program. The globals are the
+// names provided by the host environment (eg.: console).
+func Analyze(program *ast.Program, globals []string) *Result {
+	global := newScope(nil, program)
+	for _, name := range globals {
+		global.Declare(name)
+	}
+
+	a := &analyzer{
+		result: &Result{Global: global},
+	}
+
+	a.analyzeBody(global, program)
+	return a.result
+}
+
+func (a *analyzer) analyzeBody(s *Scope, body *ast.Program) {
+	// WHY: var and function declarations are hoisted, so they
+	// must be declared before resolving any reference.
+	for _, node := range body.Nodes {
+		a.declare(s, node)
+	}
+
+	for _, node := range body.Nodes {
+		a.resolve(s, node)
+	}
+}
+
+func (a *analyzer) declare(s *Scope, n ast.Node) {
+	switch n.Type() {
+	case ast.NodeVarDecls:
+		for _, decl := range n.(ast.VarDecls) {
+			s.Declare(decl.Name.String())
+		}
+	case ast.NodeVarDecl:
+		s.Declare(n.(ast.VarDecl).Name.String())
+	case ast.NodeFunDecl:
+		s.Declare(n.(*ast.FunDecl).Name.String())
+	}
+}
+
+func (a *analyzer) resolve(s *Scope, n ast.Node) {
+	switch n.Type() {
+	case ast.NodeIdent:
+		a.reference(s, n.(ast.Ident), false)
+	case ast.NodeVarDecls:
+		for _, decl := range n.(ast.VarDecls) {
+			a.resolve(s, decl)
+		}
+	case ast.NodeVarDecl:
+		a.resolve(s, n.(ast.VarDecl).Value)
+	case ast.NodeUnaryExpr:
+		a.resolve(s, n.(*ast.UnaryExpr).Operand)
+	case ast.NodeBinaryExpr:
+		expr := n.(*ast.BinaryExpr)
+		a.resolve(s, expr.Left)
+		a.resolve(s, expr.Right)
+	case ast.NodeMemberExpr:
+		// WHY: the property is not a reference
+		a.resolve(s, n.(*ast.MemberExpr).Object)
+	case ast.NodeIndexExpr:
+		index := n.(*ast.IndexExpr)
+		a.resolve(s, index.Object)
+		a.resolve(s, index.Index)
+	case ast.NodeCallExpr:
+		call := n.(*ast.CallExpr)
+		a.resolve(s, call.Callee)
+		for _, arg := range call.Args {
+			a.resolve(s, arg)
+		}
+	case ast.NodeNewExpr:
+		expr := n.(*ast.NewExpr)
+		a.resolve(s, expr.Callee)
+		for _, arg := range expr.Args {
+			a.resolve(s, arg)
+		}
+	case ast.NodeSequenceExpr:
+		for _, expr := range n.(*ast.SequenceExpr).Exprs {
+			a.resolve(s, expr)
+		}
+	case ast.NodeAssignExpr:
+		assign := n.(*ast.AssignExpr)
+		if ident, ok := assign.Target.(ast.Ident); ok && assign.Operator == token.Assign {
+			a.reference(s, ident, true)
+		} else {
+			a.resolve(s, assign.Target)
+		}
+		a.resolve(s, assign.Value)
+	case ast.NodeFunDecl:
+		fn := n.(*ast.FunDecl)
+		a.analyzeFunction(s, fn, fn.Args, fn.Body)
+	case ast.NodeFunExpr:
+		fn := n.(*ast.FunExpr)
+		a.analyzeFunction(s, fn, fn.Args, fn.Body)
+	}
+}
+
+func (a *analyzer) analyzeFunction(s *Scope, fn ast.Node, args []ast.Ident, body *ast.Program) {
+	fnscope := newScope(s, fn)
+	s.functions[fn] = fnscope
+
+	// WHY: a named function expression can call itself by its
+	// name, which is shadowed by its params and declarations.
+	if expr, ok := fn.(*ast.FunExpr); ok && len(expr.Name) > 0 {
+		fnscope.Declare(expr.Name.String())
+	}
+
+	for _, arg := range args {
+		fnscope.Declare(arg.String())
+	}
+	a.analyzeBody(fnscope, body)
+}
+
+func (a *analyzer) reference(s *Scope, ident ast.Ident, assigned bool) {
+	name := ident.String()
+	binding, ok := s.Lookup(name)
+
+	a.result.Refs = append(a.result.Refs, Ref{
+		Ident:    ident,
+		Scope:    s,
+		Binding:  binding,
+		Resolved: ok,
+		Assigned: assigned,
+	})
+
+	if !ok {
+		a.result.Warnings = append(a.result.Warnings, Warning{Name: name})
+	}
+}
@@ -0,0 +1,185 @@
+package scope_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/NeowayLabs/abad/ast"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/scope"
+	"github.com/madlambda/spells/assert"
+)
+
+type wantRef struct {
+	name     string
+	resolved bool
+	assigned bool
+	depth    int
+	slot     int
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		code     string
+		globals  []string
+		want     []wantRef
+		warnings []string
+	}{
+		{
+			name:    "Global",
+			code:    "console",
+			globals: []string{"console"},
+			want:    []wantRef{{name: "console", resolved: true}},
+		},
+		{
+			name:     "Undeclared",
+			code:     "angular",
+			want:     []wantRef{{name: "angular"}},
+			warnings: []string{"angular is not declared"},
+		},
+		{
+			name: "VarHoisting",
+			code: "a; var a = 1, b = a;",
+			want: []wantRef{
+				{name: "a", resolved: true},
+				{name: "a", resolved: true},
+			},
+		},
+		{
+			name:    "Slots",
+			code:    "var a = 1, b = 2; b",
+			globals: []string{"console"},
+			want:    []wantRef{{name: "b", resolved: true, slot: 2}},
+		},
+		{
+			name:    "MemberCall",
+			code:    "console.log(1)",
+			globals: []string{"console"},
+			want:    []wantRef{{name: "console", resolved: true}},
+		},
+		{
+			name: "Assignment",
+			code: "var a; a = 1; b = a; c += 1",
+			want: []wantRef{
+				{name: "a", resolved: true, assigned: true},
+				{name: "b", assigned: true},
+				{name: "a", resolved: true},
+				{name: "c"},
+			},
+			warnings: []string{"b is not declared", "c is not declared"},
+		},
+		{
+			name:    "NewExpr",
+			code:    "var a = 1; new Date(a, b)",
+			globals: []string{"Date"},
+			want: []wantRef{
+				{name: "Date", resolved: true},
+				{name: "a", resolved: true, slot: 1},
+				{name: "b"},
+			},
+			warnings: []string{"b is not declared"},
+		},
+		{
+			name: "FunctionHoisting",
+			code: "f(1); function f(x) { x; }",
+			want: []wantRef{
+				{name: "f", resolved: true},
+				{name: "x", resolved: true},
+			},
+		},
+		{
+			name: "FunctionScope",
+			code: "var a = 1; function f(x, y) { var z = 1; a; y; z; }",
+			want: []wantRef{
+				{name: "a", resolved: true, depth: 1},
+				{name: "y", resolved: true, slot: 1},
+				{name: "z", resolved: true, slot: 2},
+			},
+		},
+		{
+			name: "LocalsDoNotLeak",
+			code: "function f(x) { var y = 1; } x; y;",
+			want: []wantRef{
+				{name: "x"},
+				{name: "y"},
+			},
+			warnings: []string{"x is not declared", "y is not declared"},
+		},
+		{
+			name: "NamedFunctionExpression",
+			code: "var f = function g(x) { g; x }; g",
+			want: []wantRef{
+				{name: "g", resolved: true},
+				{name: "x", resolved: true, slot: 1},
+				{name: "g"},
+			},
+			warnings: []string{"g is not declared"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := parser.Parse("tests.js", tc.code)
+			assert.NoError(t, err, "parsing code")
+
+			result := scope.Analyze(program, tc.globals)
+			assert.EqualInts(t, len(tc.want), len(result.Refs), "number of refs")
+
+			for i, want := range tc.want {
+				got := result.Refs[i]
+				assert.EqualStrings(t, want.name, got.Ident.String(), "ref[%d] name", i)
+
+				if want.resolved != got.Resolved {
+					t.Fatalf("ref[%d]: resolved[%t] but want[%t]",
+						i, got.Resolved, want.resolved)
+				}
+
+				if want.assigned != got.Assigned {
+					t.Fatalf("ref[%d]: assigned[%t] but want[%t]",
+						i, got.Assigned, want.assigned)
+				}
+
+				if !want.resolved {
+					continue
+				}
+
+				assert.EqualInts(t, want.depth, got.Binding.Depth, "ref[%d] depth", i)
+				assert.EqualInts(t, want.slot, got.Binding.Slot, "ref[%d] slot", i)
+
+				slot, ok := got.Binding.Scope.Slot(want.name)
+				if !ok || slot != want.slot {
+					t.Fatalf("ref[%d]: binding scope does not declare %s", i, want.name)
+				}
+			}
+
+			assert.EqualInts(t, len(tc.warnings), len(result.Warnings), "number of warnings")
+			for i, want := range tc.warnings {
+				assert.EqualStrings(t, want, result.Warnings[i].String(), "warning[%d]", i)
+			}
+		})
+	}
+}
+
+func TestFunctionScopes(t *testing.T) {
+	program, err := parser.Parse("tests.js",
+		"function f(a) { var g = function (b) {} }")
+	assert.NoError(t, err, "parsing code")
+
+	global := scope.Analyze(program, nil).Global
+
+	f, ok := global.Function(program.Nodes[0])
+	if !ok {
+		t.Fatal("f has no scope")
+	}
+	assert.EqualStrings(t, "[a g]", fmt.Sprint(f.Names()), "names of f")
+
+	fn := program.Nodes[0].(*ast.FunDecl)
+	g, ok := f.Function(fn.Body.Nodes[0].(ast.VarDecls)[0].Value)
+	if !ok {
+		t.Fatal("g has no scope")
+	}
+	assert.EqualStrings(t, "[b]", fmt.Sprint(g.Names()), "names of g")
+
+	if _, ok := global.Function(fn.Body.Nodes[0].(ast.VarDecls)[0].Value); ok {
+		t.Fatal("g must be found only in the scope of f")
+	}
+}
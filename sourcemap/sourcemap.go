@@ -0,0 +1,323 @@
+// Package sourcemap implements the decoding of source maps (revision 3)
+// used to map positions of generated code back to the original sources.
+//
+// Documentation: https://sourcemaps.info/spec.html
+package sourcemap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// Map is a decoded source map.
+	Map struct {
+		File    string
+		Sources []string
+		Names   []string
+
+		lines [][]segment
+	}
+
+	// Position on the original source code.
+	// Line and Column starts at 1, like the lexer ones.
+	Position struct {
+		Source string
+		Line   uint
+		Column uint
+		Name   string
+	}
+
+	segment struct {
+		genColumn int
+		hasSource bool
+		source    int
+		line      int
+		column    int
+		hasName   bool
+		name      int
+	}
+
+	rawMap struct {
+		Version    int      `json:"version"`
+		File       string   `json:"file"`
+		SourceRoot string   `json:"sourceRoot"`
+		Sources    []string `json:"sources"`
+		Names      []string `json:"names"`
+		Mappings   string   `json:"mappings"`
+	}
+)
+
+const base64chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// MaxSize is the size in bytes of the largest source map file read
+// by Load.
+const MaxSize = 16 << 20
+
+var urlPrefixes = []string{"//# sourceMappingURL=", "//@ sourceMappingURL="}
+
+// Parse decodes the JSON source map in data.
+func Parse(data []byte) (*Map, error) {
+	var raw rawMap
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("sourcemap: %s", err)
+	}
+
+	if raw.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", raw.Version)
+	}
+
+	m := &Map{
+		File:    raw.File,
+		Sources: make([]string, len(raw.Sources)),
+		Names:   raw.Names,
+	}
+
+	for i, src := range raw.Sources {
+		if raw.SourceRoot != "" {
+			src = strings.TrimSuffix(raw.SourceRoot, "/") + "/" + src
+		}
+		m.Sources[i] = src
+	}
+
+	m.lines, err = decodeMappings(raw.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range m.lines {
+		for _, seg := range line {
+			if seg.hasSource && (seg.source < 0 || seg.source >= len(m.Sources)) {
+				return nil, fmt.Errorf("sourcemap: invalid source index %d", seg.source)
+			}
+
+			if seg.hasName && (seg.name < 0 || seg.name >= len(m.Names)) {
+				return nil, fmt.Errorf("sourcemap: invalid name index %d", seg.name)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Lookup the original position of the generated code at line and column.
+// It returns false if there is no mapping for the given position.
+func (m *Map) Lookup(line, column uint) (Position, bool) {
+	if line == 0 || int(line) > len(m.lines) {
+		return Position{}, false
+	}
+
+	var found *segment
+
+	for i, seg := range m.lines[line-1] {
+		if seg.genColumn > int(column)-1 {
+			break
+		}
+		found = &m.lines[line-1][i]
+	}
+
+	if found == nil || !found.hasSource {
+		return Position{}, false
+	}
+
+	pos := Position{
+		Source: m.Sources[found.source],
+		Line:   uint(found.line + 1),
+		Column: uint(found.column + 1),
+	}
+
+	if found.hasName {
+		pos.Name = m.Names[found.name]
+	}
+
+	return pos, true
+}
+
+// URL returns the source map URL referenced by the last
+// sourceMappingURL comment of code, if any.
+func URL(code string) (string, bool) {
+	lines := strings.Split(code, "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		for _, prefix := range urlPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				url := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+				return url, url != ""
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Load the source map referenced by url. The url can be an inline
+// base64 data URL or a file path, relative paths are resolved from dir.
+// Files bigger than MaxSize are rejected.
+func Load(url string, dir string) (*Map, error) {
+	if IsInline(url) {
+		return loadDataURL(url)
+	}
+
+	path := url
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("sourcemap: %s", err)
+	}
+	defer file.Close()
+
+	// WHY: the path may name an endless file, eg.: /dev/zero
+	data, err := ioutil.ReadAll(io.LimitReader(file, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("sourcemap: %s", err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("sourcemap: %s is bigger than %d bytes", path, MaxSize)
+	}
+
+	return Parse(data)
+}
+
+// LoadInline loads the source map of an inline base64 data URL,
+// never reading files.
+func LoadInline(url string) (*Map, error) {
+	if !IsInline(url) {
+		return nil, fmt.Errorf("sourcemap: %s is not an inline data URL", url)
+	}
+
+	return loadDataURL(url)
+}
+
+// IsInline tells if url is an inline data URL.
+func IsInline(url string) bool {
+	return strings.HasPrefix(url, "data:")
+}
+
+func loadDataURL(url string) (*Map, error) {
+	sep := strings.Index(url, ",")
+	if sep < 0 {
+		return nil, fmt.Errorf("sourcemap: invalid data URL")
+	}
+
+	mediatype := url[len("data:"):sep]
+	if !strings.HasSuffix(mediatype, ";base64") {
+		return nil, fmt.Errorf("sourcemap: unsupported data URL encoding [%s]", mediatype)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(url[sep+1:])
+	if err != nil {
+		return nil, fmt.Errorf("sourcemap: %s", err)
+	}
+
+	return Parse(data)
+}
+
+func decodeMappings(mappings string) ([][]segment, error) {
+	var (
+		lines  [][]segment
+		source int
+		line   int
+		column int
+		name   int
+	)
+
+	for _, rawline := range strings.Split(mappings, ";") {
+		var segments []segment
+		genColumn := 0
+
+		for _, rawseg := range strings.Split(rawline, ",") {
+			if rawseg == "" {
+				continue
+			}
+
+			fields, err := decodeVLQ(rawseg)
+			if err != nil {
+				return nil, err
+			}
+
+			switch len(fields) {
+			case 1, 4, 5:
+			default:
+				return nil, fmt.Errorf("sourcemap: invalid segment [%s]", rawseg)
+			}
+
+			genColumn += fields[0]
+			seg := segment{genColumn: genColumn}
+
+			if len(fields) >= 4 {
+				source += fields[1]
+				line += fields[2]
+				column += fields[3]
+
+				seg.hasSource = true
+				seg.source = source
+				seg.line = line
+				seg.column = column
+			}
+
+			if len(fields) == 5 {
+				name += fields[4]
+
+				seg.hasName = true
+				seg.name = name
+			}
+
+			segments = append(segments, seg)
+		}
+
+		lines = append(lines, segments)
+	}
+
+	return lines, nil
+}
+
+// decodeVLQ decodes the base64 VLQ values of a segment.
+func decodeVLQ(seg string) ([]int, error) {
+	var (
+		values []int
+		value  int
+		shift  uint
+	)
+
+	for i := 0; i < len(seg); i++ {
+		digit := strings.IndexByte(base64chars, seg[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("sourcemap: invalid base64 char [%c]", seg[i])
+		}
+
+		value += (digit & 0x1f) << shift
+
+		if digit&0x20 != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+
+		value = 0
+		shift = 0
+	}
+
+	if shift != 0 {
+		return nil, fmt.Errorf("sourcemap: truncated segment [%s]", seg)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,185 @@
+package sourcemap_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NeowayLabs/abad/sourcemap"
+	"github.com/madlambda/spells/assert"
+)
+
+var E = fmt.Errorf
+
+const testmap = `{
+	"version": 3,
+	"file": "out.js",
+	"sourceRoot": "src",
+	"sources": ["a.ts"],
+	"names": ["foo"],
+	"mappings": "AAAA,IAAIA;AACA;;GADDA"
+}`
+
+func TestLookup(t *testing.T) {
+	m, err := sourcemap.Parse([]byte(testmap))
+	assert.NoError(t, err, "parsing source map")
+	assert.EqualStrings(t, "out.js", m.File, "file")
+
+	for _, tc := range []struct {
+		line   uint
+		column uint
+		want   sourcemap.Position
+		found  bool
+	}{
+		{line: 1, column: 1, found: true, want: pos(1, 1, "")},
+		{line: 1, column: 4, found: true, want: pos(1, 1, "")},
+		{line: 1, column: 5, found: true, want: pos(1, 5, "foo")},
+		{line: 1, column: 80, found: true, want: pos(1, 5, "foo")},
+		{line: 2, column: 1, found: true, want: pos(2, 5, "")},
+		{line: 3, column: 1, found: false},
+		{line: 4, column: 1, found: false},
+		{line: 4, column: 4, found: true, want: pos(1, 4, "foo")},
+		{line: 5, column: 1, found: false},
+		{line: 0, column: 1, found: false},
+	} {
+		got, found := m.Lookup(tc.line, tc.column)
+		if found != tc.found {
+			t.Fatalf("%d:%d: found[%t] but want[%t]",
+				tc.line, tc.column, found, tc.found)
+		}
+
+		if got != tc.want {
+			t.Fatalf("%d:%d: got[%+v] but want[%+v]",
+				tc.line, tc.column, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		code string
+		err  error
+	}{
+		{
+			name: "Version",
+			code: `{"version": 2, "mappings": ""}`,
+			err:  E("sourcemap: unsupported version 2"),
+		},
+		{
+			name: "InvalidChar",
+			code: `{"version": 3, "sources": ["a"], "mappings": "AA!A"}`,
+			err:  E("sourcemap: invalid base64 char [!]"),
+		},
+		{
+			name: "Truncated",
+			code: `{"version": 3, "sources": ["a"], "mappings": "AAAg"}`,
+			err:  E("sourcemap: truncated segment [AAAg]"),
+		},
+		{
+			name: "InvalidSegment",
+			code: `{"version": 3, "sources": ["a"], "mappings": "AA"}`,
+			err:  E("sourcemap: invalid segment [AA]"),
+		},
+		{
+			name: "InvalidSource",
+			code: `{"version": 3, "sources": ["a"], "mappings": "ACAA"}`,
+			err:  E("sourcemap: invalid source index 1"),
+		},
+		{
+			name: "InvalidName",
+			code: `{"version": 3, "sources": ["a"], "mappings": "AAAAA"}`,
+			err:  E("sourcemap: invalid name index 0"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sourcemap.Parse([]byte(tc.code))
+			assert.EqualErrs(t, tc.err, err, "parse error")
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	for _, tc := range []struct {
+		code  string
+		url   string
+		found bool
+	}{
+		{code: "console.log(1);", found: false},
+		{code: "1;\n//# sourceMappingURL=out.js.map\n", url: "out.js.map", found: true},
+		{code: "1;\n//@ sourceMappingURL=old.js.map", url: "old.js.map", found: true},
+		{
+			code:  "//# sourceMappingURL=first.map\n1;\n//# sourceMappingURL=last.map",
+			url:   "last.map",
+			found: true,
+		},
+		{code: "//# sourceMappingURL=", found: false},
+	} {
+		url, found := sourcemap.URL(tc.code)
+		if found != tc.found {
+			t.Fatalf("code[%s]: found[%t] but want[%t]", tc.code, found, tc.found)
+		}
+		assert.EqualStrings(t, tc.url, url, "url")
+	}
+}
+
+func TestLoadInline(t *testing.T) {
+	url := "data:application/json;charset=utf-8;base64," +
+		base64.StdEncoding.EncodeToString([]byte(testmap))
+
+	m, err := sourcemap.Load(url, "")
+	assert.NoError(t, err, "loading inline source map")
+
+	got, found := m.Lookup(2, 1)
+	if !found || got != pos(2, 5, "") {
+		t.Fatalf("unexpected position: %+v", got)
+	}
+
+	_, err = sourcemap.Load("data:application/json,{}", "")
+	assert.EqualErrs(t, E("sourcemap: unsupported data URL encoding [application/json]"), err)
+
+	m, err = sourcemap.LoadInline(url)
+	assert.NoError(t, err, "loading only inline source map")
+
+	_, err = sourcemap.LoadInline("out.js.map")
+	assert.EqualErrs(t, E("sourcemap: out.js.map is not an inline data URL"), err)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sourcemap")
+	assert.NoError(t, err, "creating temp dir")
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "out.js.map"), []byte(testmap), 0644)
+	assert.NoError(t, err, "writing source map")
+
+	m, err := sourcemap.Load("out.js.map", dir)
+	assert.NoError(t, err, "loading source map file")
+
+	got, found := m.Lookup(2, 1)
+	if !found || got != pos(2, 5, "") {
+		t.Fatalf("unexpected position: %+v", got)
+	}
+
+	big := filepath.Join(dir, "big.js.map")
+	file, err := os.Create(big)
+	assert.NoError(t, err, "creating big file")
+	err = file.Truncate(sourcemap.MaxSize + 1)
+	assert.NoError(t, err, "growing big file")
+	assert.NoError(t, file.Close(), "closing big file")
+
+	_, err = sourcemap.Load("big.js.map", dir)
+	assert.EqualErrs(t, E("sourcemap: %s is bigger than %d bytes", big, sourcemap.MaxSize), err)
+}
+
+func pos(line, column uint, name string) sourcemap.Position {
+	return sourcemap.Position{
+		Source: "src/a.ts",
+		Line:   line,
+		Column: column,
+		Name:   name,
+	}
+}
@@ -0,0 +1,154 @@
+package abad_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NeowayLabs/abad"
+	"github.com/NeowayLabs/abad/parser"
+	"github.com/NeowayLabs/abad/sourcemap"
+	"github.com/madlambda/spells/assert"
+)
+
+// sourceMap maps the whole first line of the generated code
+// to app.ts:10:5
+const sourceMap = `{"version":3,"sources":["app.ts"],"names":[],"mappings":"AASI"}`
+
+func TestSourceMapInline(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	url := "data:application/json;base64," +
+		base64.StdEncoding.EncodeToString([]byte(sourceMap))
+	_, err = js.EvalFile("app.js", "Math.foo()\n//# sourceMappingURL="+url)
+	assert.EqualErrs(t, E("TypeError: Math.foo is not a function (app.ts:10:5)"), err, "errors differ")
+
+	jserr := err.(*abad.JSError)
+	frame := jserr.StackFrames()[0]
+	assert.EqualStrings(t, "app.ts", frame.File, "frame file")
+	assert.EqualInts(t, 10, int(frame.Line), "frame line")
+	assert.EqualInts(t, 5, int(frame.Column), "frame column")
+
+	_, err = js.EvalFile("other.js", "Math.foo()")
+	assert.EqualErrs(t, E("TypeError: Math.foo is not a function (other.js:1:9)"), err,
+		"the source map of a file must not apply to others")
+}
+
+func TestSourceMapFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abadsourcemap")
+	assert.NoError(t, err, "creating temp dir")
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "app.js.map"), []byte(sourceMap), 0644)
+	assert.NoError(t, err, "writing source map")
+
+	code := "Math.foo()\n//# sourceMappingURL=app.js.map"
+	file := filepath.Join(dir, "app.js")
+
+	for _, tc := range []struct {
+		name    string
+		opts    []abad.Option
+		err     error
+		warning string
+	}{
+		{
+			name: "Allowed",
+			opts: []abad.Option{
+				abad.SourceMaps(abad.LoadSourceMapFile),
+				abad.Sandbox(abad.CapFiles),
+			},
+			err: E("TypeError: Math.foo is not a function (app.ts:10:5)"),
+		},
+		{
+			name:    "NoLoader",
+			opts:    []abad.Option{abad.Sandbox(abad.CapFiles)},
+			err:     E("TypeError: Math.foo is not a function (%s:1:9)", file),
+			warning: "ignoring source map app.js.map: only inline source maps are loaded",
+		},
+		{
+			name:    "NoCapability",
+			opts:    []abad.Option{abad.SourceMaps(abad.LoadSourceMapFile)},
+			err:     E("TypeError: Math.foo is not a function (%s:1:9)", file),
+			warning: "ignoring source map app.js.map: the sandbox doesn't allow reading files",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			js, err := abad.NewAbad(tc.opts...)
+			assert.NoError(t, err, "failed to start interpreter")
+
+			var warnings []string
+			js.OnWarning(func(w parser.Warning) {
+				warnings = append(warnings, w.Msg)
+			})
+
+			_, err = js.EvalFile(file, code)
+			assert.EqualErrs(t, tc.err, err, "errors differ")
+
+			if tc.warning == "" {
+				assert.EqualInts(t, 0, len(warnings), "number of warnings")
+				return
+			}
+
+			assert.EqualInts(t, 1, len(warnings), "number of warnings")
+			assert.EqualStrings(t, tc.warning, warnings[0], "warning")
+		})
+	}
+}
+
+func TestSourceMapLoadedOnError(t *testing.T) {
+	loads := 0
+	js, err := abad.NewAbad(abad.Sandbox(abad.CapFiles),
+		abad.SourceMaps(func(file, url string) (*sourcemap.Map, error) {
+			loads++
+			return sourcemap.Parse([]byte(sourceMap))
+		}))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	code := "a = 1\n//# sourceMappingURL=app.js.map"
+	_, err = js.EvalFile("app.js", code)
+	assert.NoError(t, err, "evaluating")
+	assert.EqualInts(t, 0, loads, "source map loads without errors")
+
+	_, err = js.EvalFile("app.js", "Math.foo()\n"+code)
+	assert.Error(t, err, "evaluating")
+	assert.EqualInts(t, 1, loads, "source map loads with an error")
+}
+
+func TestSourceMapLoader(t *testing.T) {
+	var gotFile, gotURL string
+	js, err := abad.NewAbad(abad.Sandbox(abad.CapFiles),
+		abad.SourceMaps(func(file, url string) (*sourcemap.Map, error) {
+			gotFile, gotURL = file, url
+			return sourcemap.Parse([]byte(sourceMap))
+		}))
+	assert.NoError(t, err, "failed to start interpreter")
+
+	_, err = js.EvalFiles([]parser.File{
+		{Name: "lib.js", Code: "a = 1"},
+		{Name: "app.js", Code: "Math.foo()\n//# sourceMappingURL=maps/app.js.map"},
+	})
+	assert.EqualErrs(t, E("TypeError: Math.foo is not a function (app.ts:10:5)"), err, "errors differ")
+	assert.EqualStrings(t, "app.js", gotFile, "file of the source map")
+	assert.EqualStrings(t, "maps/app.js.map", gotURL, "url of the source map")
+}
+
+func TestSourceMapInvalid(t *testing.T) {
+	js, err := abad.NewAbad()
+	assert.NoError(t, err, "failed to start interpreter")
+
+	var warnings []string
+	js.OnWarning(func(w parser.Warning) {
+		warnings = append(warnings, w.File+": "+w.Msg)
+	})
+
+	_, err = js.EvalFile("app.js", "Math.foo()\n//# sourceMappingURL=data:application/json,{}")
+	assert.EqualErrs(t, E("TypeError: Math.foo is not a function (app.js:1:9)"), err, "errors differ")
+
+	assert.EqualInts(t, 1, len(warnings), "number of warnings")
+	assert.EqualStrings(t,
+		"app.js: ignoring source map data:application/json,{}: sourcemap: unsupported data URL encoding [application/json]",
+		warnings[0], "warning")
+}
@@ -5,6 +5,7 @@
 package e2e_test
 
 import (
+	"flag"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,11 @@ import (
 	"github.com/NeowayLabs/abad/tests/fixture"
 )
 
+var (
+	diffseed     = flag.Int64("diffseed", 1, "seed of the differential tests")
+	diffprograms = flag.Int("diffprograms", 50, "number of programs generated by the differential tests")
+)
+
 func TestE2E(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -36,3 +42,15 @@ func TestE2EConsoleLog(t *testing.T) {
 		},
 	})
 }
+
+func TestE2EDifferential(t *testing.T) {
+	t.Logf("differential tests seed: %d", *diffseed)
+
+	gen := fixture.NewGenerator(*diffseed)
+	programs := make([]string, *diffprograms)
+	for i := range programs {
+		programs[i] = gen.Program(10)
+	}
+
+	fixture.RunDifferential(t, fixture.NewV8(t), fixture.NewAbad(t), programs)
+}
@@ -6,6 +6,7 @@ package fixture
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -120,6 +121,47 @@ func RunCases(
 	}
 }
 
+// RunDifferential will run each of the given programs on the
+// reference and on the undertest interpreters, failing if their
+// output differs or if only one of them fails.
+//
+// Error messages are not compared since they are engine specific.
+func RunDifferential(
+	t *testing.T,
+	reference JsInterpreter,
+	undertest JsInterpreter,
+	programs []string,
+) {
+
+	for i, code := range programs {
+		t.Run(fmt.Sprintf("Program%d", i), func(t *testing.T) {
+			tmpfile, err := ioutil.TempFile("", "abaddiff")
+			assert.NoError(t, err)
+
+			defer func() {
+				tmpfile.Close()
+				os.Remove(tmpfile.Name())
+			}()
+
+			_, err = tmpfile.WriteString(code)
+			assert.NoError(t, err, "writing code on temp file")
+
+			wantErr, want := reference(tmpfile.Name())
+			gotErr, got := undertest(tmpfile.Name())
+
+			if (wantErr == nil) != (gotErr == nil) {
+				t.Fatalf("\ncode:\n%s\nreference error:[%v]\nundertest error:[%v]\nstdout:\n%s\nstderr:\n%s\n",
+					code, wantErr, gotErr, got.Stdout, got.Stderr)
+			}
+
+			if want.Stdout != got.Stdout {
+				t.Logf("code:\n%s", code)
+			}
+			assertEqualOutput(t, "stdout", want.Stdout, got.Stdout)
+		})
+	}
+}
+
 func assertSuccessRun(t *testing.T, r Result, err error) {
 	t.Helper()
 
@@ -0,0 +1,179 @@
+package token
+
+import "fmt"
+
+type (
+	// Class of tokens
+	Class int
+
+	// Info describes a token type.
+	Info struct {
+		// Text of punctuators and keywords, eg.: += and typeof,
+		// it's empty for the other tokens.
+		Text string
+
+		Class Class
+
+		// Precedence of binary operators, higher binds tighter.
+		// It's LowestPrec for the other tokens.
+		Precedence int
+	}
+)
+
+const (
+	// ClassSpecial are Illegal, Comment and EOF.
+	ClassSpecial Class = iota
+	ClassLiteral
+	ClassPunctuator
+	ClassKeyword
+	ClassIdent
+)
+
+// LowestPrec is the precedence of the tokens that are not binary
+// operators.
+const LowestPrec = 0
+
+var classNames = map[Class]string{
+	ClassSpecial:    "special",
+	ClassLiteral:    "literal",
+	ClassPunctuator: "punctuator",
+	ClassKeyword:    "keyword",
+	ClassIdent:      "identifier",
+}
+
+// infos of all the token types. The precedences of the binary
+// operators are from the lowest, LOr, to the highest, Mul.
+// http://es5.github.io/#x11
+var infos = map[Type]Info{
+	Illegal: {Class: ClassSpecial},
+	Comment: {Class: ClassSpecial},
+	EOF:     {Class: ClassSpecial},
+
+	Bool:           {Class: ClassLiteral},
+	Decimal:        {Class: ClassLiteral},
+	Hexadecimal:    {Class: ClassLiteral},
+	Octal:          {Class: ClassLiteral},
+	Binary:         {Class: ClassLiteral},
+	BigInt:         {Class: ClassLiteral},
+	String:         {Class: ClassLiteral},
+	Template:       {Class: ClassLiteral},
+	TemplateHead:   {Class: ClassLiteral},
+	TemplateMiddle: {Class: ClassLiteral},
+	TemplateTail:   {Class: ClassLiteral},
+	Regexp:         {Class: ClassLiteral},
+	Null:           {Text: "null", Class: ClassLiteral},
+
+	Ident: {Class: ClassIdent},
+
+	LOr:        {Text: "||", Class: ClassPunctuator, Precedence: 1},
+	LAnd:       {Text: "&&", Class: ClassPunctuator, Precedence: 2},
+	Or:         {Text: "|", Class: ClassPunctuator, Precedence: 3},
+	Xor:        {Text: "^", Class: ClassPunctuator, Precedence: 4},
+	And:        {Text: "&", Class: ClassPunctuator, Precedence: 5},
+	Equal:      {Text: "==", Class: ClassPunctuator, Precedence: 6},
+	NotEqual:   {Text: "!=", Class: ClassPunctuator, Precedence: 6},
+	TEqual:     {Text: "===", Class: ClassPunctuator, Precedence: 6},
+	NotTEqual:  {Text: "!==", Class: ClassPunctuator, Precedence: 6},
+	Less:       {Text: "<", Class: ClassPunctuator, Precedence: 7},
+	Greater:    {Text: ">", Class: ClassPunctuator, Precedence: 7},
+	LessEq:     {Text: "<=", Class: ClassPunctuator, Precedence: 7},
+	GreaterEq:  {Text: ">=", Class: ClassPunctuator, Precedence: 7},
+	InstanceOf: {Text: "instanceof", Class: ClassKeyword, Precedence: 7},
+	In:         {Text: "in", Class: ClassKeyword, Precedence: 7},
+	LShift:     {Text: "<<", Class: ClassPunctuator, Precedence: 8},
+	RShift:     {Text: ">>", Class: ClassPunctuator, Precedence: 8},
+	RShiftZero: {Text: ">>>", Class: ClassPunctuator, Precedence: 8},
+	Plus:       {Text: "+", Class: ClassPunctuator, Precedence: 9},
+	Minus:      {Text: "-", Class: ClassPunctuator, Precedence: 9},
+	Mul:        {Text: "*", Class: ClassPunctuator, Precedence: 10},
+	Quo:        {Text: "/", Class: ClassPunctuator, Precedence: 10},
+	Rem:        {Text: "%", Class: ClassPunctuator, Precedence: 10},
+
+	Inc:              {Text: "++", Class: ClassPunctuator},
+	Dec:              {Text: "--", Class: ClassPunctuator},
+	Dot:              {Text: ".", Class: ClassPunctuator},
+	LParen:           {Text: "(", Class: ClassPunctuator},
+	RParen:           {Text: ")", Class: ClassPunctuator},
+	Comma:            {Text: ",", Class: ClassPunctuator},
+	SemiColon:        {Text: ";", Class: ClassPunctuator},
+	LBrace:           {Text: "{", Class: ClassPunctuator},
+	RBrace:           {Text: "}", Class: ClassPunctuator},
+	LBrack:           {Text: "[", Class: ClassPunctuator},
+	RBrack:           {Text: "]", Class: ClassPunctuator},
+	Not:              {Text: "~", Class: ClassPunctuator},
+	LNot:             {Text: "!", Class: ClassPunctuator},
+	Colon:            {Text: ":", Class: ClassPunctuator},
+	Ternary:          {Text: "?", Class: ClassPunctuator},
+	Assign:           {Text: "=", Class: ClassPunctuator},
+	AddAssign:        {Text: "+=", Class: ClassPunctuator},
+	SubAssign:        {Text: "-=", Class: ClassPunctuator},
+	MulAssign:        {Text: "*=", Class: ClassPunctuator},
+	RemAssign:        {Text: "%=", Class: ClassPunctuator},
+	QuoAssign:        {Text: "/=", Class: ClassPunctuator},
+	LShiftAssign:     {Text: "<<=", Class: ClassPunctuator},
+	RShiftAssign:     {Text: ">>=", Class: ClassPunctuator},
+	RShiftZeroAssign: {Text: ">>>=", Class: ClassPunctuator},
+	AndAssign:        {Text: "&=", Class: ClassPunctuator},
+	OrAssign:         {Text: "|=", Class: ClassPunctuator},
+	XorAssign:        {Text: "^=", Class: ClassPunctuator},
+
+	Break:    {Text: "break", Class: ClassKeyword},
+	Case:     {Text: "case", Class: ClassKeyword},
+	Catch:    {Text: "catch", Class: ClassKeyword},
+	Continue: {Text: "continue", Class: ClassKeyword},
+	Debugger: {Text: "debugger", Class: ClassKeyword},
+	Default:  {Text: "default", Class: ClassKeyword},
+	Delete:   {Text: "delete", Class: ClassKeyword},
+	Do:       {Text: "do", Class: ClassKeyword},
+	Else:     {Text: "else", Class: ClassKeyword},
+	Finally:  {Text: "finally", Class: ClassKeyword},
+	For:      {Text: "for", Class: ClassKeyword},
+	Function: {Text: "function", Class: ClassKeyword},
+	If:       {Text: "if", Class: ClassKeyword},
+	New:      {Text: "new", Class: ClassKeyword},
+	Return:   {Text: "return", Class: ClassKeyword},
+	Switch:   {Text: "switch", Class: ClassKeyword},
+	This:     {Text: "this", Class: ClassKeyword},
+	Throw:    {Text: "throw", Class: ClassKeyword},
+	Try:      {Text: "try", Class: ClassKeyword},
+	TypeOf:   {Text: "typeof", Class: ClassKeyword},
+	Var:      {Text: "var", Class: ClassKeyword},
+	Void:     {Text: "void", Class: ClassKeyword},
+	While:    {Text: "while", Class: ClassKeyword},
+	With:     {Text: "with", Class: ClassKeyword},
+}
+
+func (c Class) String() string {
+	str, ok := classNames[c]
+	if !ok {
+		panic(fmt.Sprintf("unknown token class[%d]", c))
+	}
+	return str
+}
+
+// Info describes the token type.
+func (t Type) Info() Info {
+	info, ok := infos[t]
+	if !ok {
+		panic(fmt.Sprintf("unknown token type[%d]", t))
+	}
+	return info
+}
+
+// Precedence of the binary operator, or LowestPrec if t is not
+// a binary operator.
+func (t Type) Precedence() int {
+	return t.Info().Precedence
+}
+
+func (t Type) IsLiteral() bool {
+	return t.Info().Class == ClassLiteral
+}
+
+func (t Type) IsPunctuator() bool {
+	return t.Info().Class == ClassPunctuator
+}
+
+func (t Type) IsKeyword() bool {
+	return t.Info().Class == ClassKeyword
+}
@@ -0,0 +1,50 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/token"
+)
+
+func TestInfo(t *testing.T) {
+	for typ := token.Illegal; typ <= token.EOF; typ++ {
+		info := typ.Info()
+
+		if info.Class == token.ClassKeyword || typ == token.Null {
+			got, ok := token.Keyword(info.Text)
+			if !ok || got != typ {
+				t.Errorf("%s: keyword %q is %v, want %s", typ, info.Text, got, typ)
+			}
+		}
+
+		if info.Class == token.ClassPunctuator && info.Text == "" {
+			t.Errorf("%s: punctuator has no text", typ)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	ordered := []token.Type{
+		token.Mul, token.Plus, token.LShift, token.Less, token.Equal,
+		token.And, token.Xor, token.Or, token.LAnd, token.LOr,
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		higher, lower := ordered[i-1], ordered[i]
+		if higher.Precedence() <= lower.Precedence() {
+			t.Errorf("%s must bind tighter than %s", higher, lower)
+		}
+	}
+
+	for _, typ := range []token.Type{token.LOr, token.In, token.InstanceOf} {
+		if typ.Precedence() == token.LowestPrec {
+			t.Errorf("%s is a binary operator", typ)
+		}
+	}
+
+	for _, typ := range []token.Type{token.Assign, token.Dot, token.Ident, token.LNot} {
+		if typ.Precedence() != token.LowestPrec {
+			t.Errorf("%s is not a binary operator", typ)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package token
+
+var (
+	// http://es5.github.io/#x7.6.1
+	keywords = map[string]Type{
+		"null":       Null,
+		"false":      Bool,
+		"true":       Bool,
+		"break":      Break,
+		"case":       Case,
+		"catch":      Catch,
+		"continue":   Continue,
+		"debugger":   Debugger,
+		"default":    Default,
+		"delete":     Delete,
+		"do":         Do,
+		"else":       Else,
+		"finally":    Finally,
+		"for":        For,
+		"function":   Function,
+		"if":         If,
+		"in":         In,
+		"instanceof": InstanceOf,
+		"new":        New,
+		"return":     Return,
+		"switch":     Switch,
+		"this":       This,
+		"throw":      Throw,
+		"try":        Try,
+		"typeof":     TypeOf,
+		"var":        Var,
+		"void":       Void,
+		"while":      While,
+		"with":       With,
+	}
+
+	// http://es5.github.io/#x7.6.1.2
+	futureReserved = map[string]bool{
+		"class":   true,
+		"const":   true,
+		"enum":    true,
+		"export":  true,
+		"extends": true,
+		"import":  true,
+		"super":   true,
+	}
+
+	futureReservedStrict = map[string]bool{
+		"implements": true,
+		"interface":  true,
+		"let":        true,
+		"package":    true,
+		"private":    true,
+		"protected":  true,
+		"public":     true,
+		"static":     true,
+		"yield":      true,
+	}
+)
+
+// Keyword returns the token type of the keyword or literal name
+// (null, undefined, true and false), if name is one.
+func Keyword(name string) (Type, bool) {
+	t, ok := keywords[name]
+	return t, ok
+}
+
+// IsFutureReserved tells if name is reserved for future use and
+// can't be an identifier. Strict mode code reserves more names.
+func IsFutureReserved(name string, strict bool) bool {
+	return futureReserved[name] || (strict && futureReservedStrict[name])
+}
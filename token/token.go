@@ -14,8 +14,22 @@ const (
 	Decimal
 	Hexadecimal
 	Octal
+	Binary
+
+	// BigInt literals of ES2020, eg.: 10n and 0xFFn
+	BigInt
+
 	String
 
+	// template literals of ES6, eg.: `a${b}c${d}e` is lexed as
+	// TemplateHead, Ident, TemplateMiddle, Ident, TemplateTail.
+	Template
+	TemplateHead
+	TemplateMiddle
+	TemplateTail
+
+	Regexp
+
 	Minus
 	Plus
 	Mul
@@ -68,7 +82,6 @@ const (
 	Ident
 
 	Null
-	Undefined
 
 	Break
 	Case
@@ -97,6 +110,10 @@ const (
 	While
 	With
 
+	// Comment is only emitted by the lexer with the Comments
+	// option, eg.: // a and /* b */
+	Comment
+
 	EOF
 )
 
@@ -105,7 +122,14 @@ var names = map[Type]string{
 	Decimal:          "Decimal",
 	Hexadecimal:      "Hexadecimal",
 	Octal:            "Octal",
+	Binary:           "Binary",
+	BigInt:           "BigInt",
 	String:           "String",
+	Template:         "Template",
+	TemplateHead:     "TemplateHead",
+	TemplateMiddle:   "TemplateMiddle",
+	TemplateTail:     "TemplateTail",
+	Regexp:           "Regexp",
 	Bool:             "Bool",
 	Minus:            "-",
 	Plus:             "+",
@@ -117,7 +141,7 @@ var names = map[Type]string{
 	LBrace:           "{",
 	RBrace:           "}",
 	LBrack:           "[",
-	RBrack:           "[",
+	RBrack:           "]",
 	Less:             "<",
 	Greater:          ">",
 	LessEq:           "<=",
@@ -157,7 +181,6 @@ var names = map[Type]string{
 	Ident:            "Ident",
 	SemiColon:        "SemiColon",
 	Null:             "Null",
-	Undefined:        "Undefined",
 	Break:            "Break",
 	Case:             "Case",
 	Catch:            "Catch",
@@ -184,6 +207,7 @@ var names = map[Type]string{
 	Void:             "Void",
 	While:            "While",
 	With:             "With",
+	Comment:          "Comment",
 	EOF:              "EOF",
 }
 
@@ -198,10 +222,55 @@ func (t Type) String() string {
 func IsNumber(t Type) bool {
 	return t == Decimal ||
 		t == Hexadecimal ||
-		t == Octal
+		t == Octal ||
+		t == Binary
 }
 
 func IsUnaryOperator(t Type) bool {
 	return t == Minus ||
-		t == Plus
+		t == Plus ||
+		t == LNot
+}
+
+func IsBinaryOperator(t Type) bool {
+	switch t {
+	case LOr, LAnd, In, Plus, Minus, Mul, Quo, Rem,
+		Less, Greater, LessEq, GreaterEq,
+		Equal, NotEqual, TEqual, NotTEqual:
+		return true
+	}
+
+	return false
+}
+
+// compoundOps are the binary operators of the compound assignments.
+var compoundOps = map[Type]Type{
+	AddAssign:        Plus,
+	SubAssign:        Minus,
+	MulAssign:        Mul,
+	RemAssign:        Rem,
+	QuoAssign:        Quo,
+	LShiftAssign:     LShift,
+	RShiftAssign:     RShift,
+	RShiftZeroAssign: RShiftZero,
+	AndAssign:        And,
+	OrAssign:         Or,
+	XorAssign:        Xor,
+}
+
+// IsAssignOperator tells if t is = or a compound assignment, eg.: +=
+func IsAssignOperator(t Type) bool {
+	_, ok := compoundOps[t]
+	return t == Assign || ok
+}
+
+// CompoundOperator returns the binary operator applied by the
+// compound assignment t, eg.: Plus for AddAssign, or Illegal if t is
+// not a compound assignment.
+func CompoundOperator(t Type) Type {
+	op, ok := compoundOps[t]
+	if !ok {
+		return Illegal
+	}
+	return op
 }
@@ -34,9 +34,9 @@ func (b Bool) ToPrimitive(hint Kind) (Value, error) {
 	return b, nil
 }
 
+// ToObject fails, Boolean objects are not supported yet.
 func (b Bool) ToObject() (Object, error) {
-	panic("not implemented")
-	return nil, nil
+	return nil, NewTypeError("Boolean objects are not supported yet")
 }
 
 func (b Bool) Equal(a Bool) bool {
@@ -1,14 +1,35 @@
 package types
 
 type (
-	Execfn    func(this Object, args []Value) Value
+	Execfn    func(this Object, args []Value) (Value, error)
 	Builtinfn struct {
 		*UserFunction
 
 		fn Execfn
+
+		// params is only used when fixed is true
+		params []ArgKind
+		fixed  bool
 	}
+
+	// ArgKind declares how an argument of a builtin function is
+	// converted before the builtin is called.
+	ArgKind int
 )
 
+const (
+	// ArgAny passes the argument as is.
+	ArgAny ArgKind = iota
+
+	// ArgNumber converts the argument with ToNumber.
+	ArgNumber
+
+	// ArgString converts the argument with ToString.
+	ArgString
+)
+
+// NewBuiltinfn creates a builtin function that gets the arguments
+// exactly as they were passed, useful for variadic functions.
 func NewBuiltinfn(fn Execfn) *Builtinfn {
 	return &Builtinfn{
 		fn: fn,
@@ -19,10 +40,52 @@ func NewBuiltinfn(fn Execfn) *Builtinfn {
 	}
 }
 
-func (f *Builtinfn) Call(this Object, args []Value) Value {
+// NewBuiltinfnArgs creates a builtin function with one formal
+// parameter for each of params. Missing arguments are undefined and
+// extra arguments are ignored, then each argument is converted as
+// declared by its param, so fn always gets len(params) arguments.
+// The length property of the function is len(params).
+// https://es5.github.io/#x15
+func NewBuiltinfnArgs(fn Execfn, params ...ArgKind) *Builtinfn {
+	f := NewBuiltinfn(fn)
+	f.params = params
+	f.fixed = true
+
+	length := NewNumber(float64(len(params)))
+	_, _ = f.DefineOwnPropertyP(lengthAttr,
+		NewDataPropDesc(length, false, false, false), false)
+	return f
+}
+
+func (f *Builtinfn) Call(this Object, args []Value) (Value, error) {
+	if f.fixed {
+		args = convertArgs(args, f.params)
+	}
+
 	return f.fn(this, args)
 }
 
 func (f *Builtinfn) ToObject() (Object, error) {
 	return f, nil
 }
+
+func convertArgs(args []Value, params []ArgKind) []Value {
+	converted := make([]Value, len(params))
+	for i, kind := range params {
+		var arg Value = Undefined
+		if i < len(args) {
+			arg = args[i]
+		}
+
+		switch kind {
+		case ArgNumber:
+			arg = arg.ToNumber()
+		case ArgString:
+			arg = arg.ToString()
+		}
+
+		converted[i] = arg
+	}
+
+	return converted
+}
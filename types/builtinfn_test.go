@@ -16,31 +16,92 @@ func TestBuiltin(t *testing.T) {
 	}{
 		{
 			input: []types.Value{Str("hello"), Str("world")},
-			fn: func(obj types.Object, args []types.Value) types.Value {
-				return types.Undefined
+			fn: func(obj types.Object, args []types.Value) (types.Value, error) {
+				return types.Undefined, nil
 			},
 			output: types.Undefined,
 		},
 		{
 			input: []types.Value{Str("hello"), Str("world")},
-			fn: func(obj types.Object, args []types.Value) types.Value {
-				return args[0]
+			fn: func(obj types.Object, args []types.Value) (types.Value, error) {
+				return args[0], nil
 			},
 			output: Str("hello"),
 		},
 		{
 			input: []types.Value{Str("hello"), Str("world")},
-			fn: func(obj types.Object, args []types.Value) types.Value {
-				return types.NewNumber(float64(len(args)))
+			fn: func(obj types.Object, args []types.Value) (types.Value, error) {
+				return types.NewNumber(float64(len(args))), nil
 			},
 			output: types.NewNumber(2.0),
 		},
 	} {
 		global := types.NewBaseDataObject()
 		builtin := types.NewBuiltinfn(tc.fn)
-		got := builtin.Call(global, tc.input)
+		got, err := builtin.Call(global, tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
 		if !types.StrictEqual(tc.output, got) {
 			t.Fatalf("values differ: '%s' != '%s'", got, tc.output)
 		}
 	}
 }
+
+func TestBuiltinArgs(t *testing.T) {
+	params := []types.ArgKind{types.ArgAny, types.ArgNumber, types.ArgString}
+
+	var got []types.Value
+	builtin := types.NewBuiltinfnArgs(func(_ types.Object, args []types.Value) (types.Value, error) {
+		got = args
+		return types.Undefined, nil
+	}, params...)
+
+	for _, tc := range []struct {
+		name  string
+		input []types.Value
+		want  []types.Value
+	}{
+		{
+			name:  "Exact",
+			input: []types.Value{Str("a"), Str("1"), types.NewNumber(2)},
+			want:  []types.Value{Str("a"), types.NewNumber(1), Str("2")},
+		},
+		{
+			name:  "Missing",
+			input: []types.Value{types.True, Str("3")},
+			want:  []types.Value{types.True, types.NewNumber(3), Str("undefined")},
+		},
+		{
+			name:  "Extra",
+			input: []types.Value{types.Null, types.True, types.Null, Str("extra")},
+			want:  []types.Value{types.Null, types.NewNumber(1), Str("null")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := builtin.Call(types.NewBaseDataObject(), tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d args but want %d", len(got), len(tc.want))
+			}
+
+			for i, want := range tc.want {
+				if !types.StrictEqual(want, got[i]) {
+					t.Errorf("arg %d: got %v but want %v", i, got[i], want)
+				}
+			}
+		})
+	}
+
+	length, err := builtin.Get(types.S("length"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !types.StrictEqual(types.NewNumber(3), length) {
+		t.Fatalf("length is %v but want 3", length)
+	}
+}
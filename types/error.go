@@ -24,3 +24,9 @@ func (e TypeError) Error() string {
 }
 
 func (e TypeError) Exception() bool { return true }
+
+// Name of the error constructor.
+func (e TypeError) Name() string { return "TypeError" }
+
+// Message of the error.
+func (e TypeError) Message() string { return e.msg }
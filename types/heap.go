@@ -0,0 +1,267 @@
+package types
+
+import (
+	"sync"
+)
+
+type (
+	// Heap counts the memory held by the objects of an interpreter.
+	// An object is tracked when it's stored in an object already
+	// tracked, so the heap covers what the scripts can reach from
+	// the roots given to Track, eg.: the global object. The counts
+	// are updated on every change of the tracked objects and when
+	// the Go GC collects them, so they lag behind the objects
+	// unreachable but not yet collected.
+	Heap struct {
+		mu    sync.Mutex
+		stats HeapStats
+	}
+
+	// HeapStats are the counts of a Heap.
+	HeapStats struct {
+		// Objects are the live objects by class, eg.: Function.
+		Objects map[string]int
+
+		// Properties are the own properties of the objects and
+		// environments, the prototype excluded.
+		Properties int
+
+		// StringBytes are the bytes of the string values of the
+		// properties, two per UTF-16 code unit.
+		StringBytes int
+
+		// Environments are the live scopes of variables, the
+		// global and the ones of function calls.
+		Environments int
+	}
+
+	// heapAccount is what a tracked object adds to the counts of
+	// its heap, subtracted when it's collected. It's apart from
+	// the object so the cleanup doesn't keep it alive.
+	heapAccount struct {
+		heap   *Heap
+		class  string
+		object bool
+		env    bool
+		props  int
+		bytes  int
+	}
+)
+
+// NewHeap creates an empty heap.
+func NewHeap() *Heap {
+	return &Heap{
+		stats: HeapStats{Objects: make(map[string]int)},
+	}
+}
+
+// Stats returns a copy of the counts.
+func (h *Heap) Stats() HeapStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats
+	stats.Objects = make(map[string]int, len(h.stats.Objects))
+	for class, n := range h.stats.Objects {
+		stats.Objects[class] = n
+	}
+
+	return stats
+}
+
+// Track adds val, if it's an object, and the objects reachable from
+// its properties to the heap. Objects already tracked are ignored.
+// They are counted by class, Function for the callable ones.
+func (h *Heap) Track(val Value) {
+	obj, ok := val.(Object)
+	if !ok {
+		return
+	}
+
+	class := obj.Class()
+	if _, ok := obj.(Function); ok {
+		class = "Function"
+	}
+
+	h.track(obj.dataObject(), class, false)
+}
+
+// TrackEnvironment adds the bindings of an environment to the heap,
+// counted as an environment, and the objects reachable from them.
+// The bindings of the global environment, the global object, are
+// counted as both an object and an environment.
+func (h *Heap) TrackEnvironment(bindings *DataObject) {
+	h.track(bindings, bindings.class, true)
+}
+
+// TrackSlots adds the slots of an environment to the heap, counted
+// as an environment with a property by slot set, and the objects
+// reachable from them.
+func (h *Heap) TrackSlots(slots *Slots) {
+	if slots.account != nil {
+		return
+	}
+
+	acc := &heapAccount{heap: h, env: true}
+	for _, val := range slots.values {
+		if val != nil {
+			acc.props++
+			acc.bytes += valueBytes(val)
+		}
+	}
+
+	slots.account = acc
+	h.update(func(s *HeapStats) { acc.add(s, 1) })
+	onCollectSlots(slots, acc)
+
+	for _, val := range slots.values {
+		h.Track(val)
+	}
+}
+
+func (h *Heap) track(obj *DataObject, class string, env bool) {
+	if acc := obj.account; acc != nil {
+		// objects are tracked by a single interpreter
+		if acc.heap == h && env && !acc.env {
+			h.update(func(s *HeapStats) {
+				acc.env = true
+				s.Environments++
+			})
+		}
+
+		return
+	}
+
+	acc := &heapAccount{
+		heap:   h,
+		class:  class,
+		object: !env,
+		env:    env,
+	}
+
+	for key, desc := range obj.props {
+		desc.bytes = stringBytes(desc)
+		if key != protoKey {
+			acc.props++
+			acc.bytes += desc.bytes
+		}
+	}
+
+	obj.account = acc
+	h.update(func(s *HeapStats) { acc.add(s, 1) })
+	onCollect(obj, acc)
+
+	for _, desc := range obj.props {
+		h.trackValues(desc)
+	}
+}
+
+// trackValues tracks the objects of the value or accessors of desc.
+func (h *Heap) trackValues(desc *PropertyDescriptor) {
+	for _, attr := range []string{"value", "get", "set"} {
+		if val, ok := desc.attrs[attr]; ok {
+			h.Track(val)
+		}
+	}
+}
+
+func (h *Heap) update(fn func(*HeapStats)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fn(&h.stats)
+}
+
+// add the account to the counts sign times, 1 or -1.
+func (acc *heapAccount) add(s *HeapStats, sign int) {
+	if acc.object {
+		s.Objects[acc.class] += sign
+		if s.Objects[acc.class] == 0 {
+			delete(s.Objects, acc.class)
+		}
+	}
+
+	if acc.env {
+		s.Environments += sign
+	}
+
+	s.Properties += sign * acc.props
+	s.StringBytes += sign * acc.bytes
+}
+
+// changed accounts the property key set to desc, replacing old, nil
+// if the property is new. The descriptors may be the same, changed
+// in place, the bytes of old are the ones accounted before.
+func (acc *heapAccount) changed(key string, old, desc *PropertyDescriptor) {
+	props, bytes := 1, 0
+	if old != nil {
+		props--
+		bytes -= old.bytes
+	}
+
+	desc.bytes = stringBytes(desc)
+	bytes += desc.bytes
+
+	if key != protoKey {
+		acc.heap.update(func(s *HeapStats) {
+			acc.props += props
+			acc.bytes += bytes
+			s.Properties += props
+			s.StringBytes += bytes
+		})
+	}
+
+	acc.heap.trackValues(desc)
+}
+
+// assigned accounts the slot set to val, replacing old, nil if the
+// slot was unset.
+func (acc *heapAccount) assigned(old, val Value) {
+	props, bytes := 0, valueBytes(val)
+	if old == nil {
+		props++
+	} else {
+		bytes -= valueBytes(old)
+	}
+
+	acc.heap.update(func(s *HeapStats) {
+		acc.props += props
+		acc.bytes += bytes
+		s.Properties += props
+		s.StringBytes += bytes
+	})
+
+	acc.heap.Track(val)
+}
+
+// removed accounts the removed property key, described by desc.
+func (acc *heapAccount) removed(key string, desc *PropertyDescriptor) {
+	if key == protoKey {
+		return
+	}
+
+	acc.heap.update(func(s *HeapStats) {
+		acc.props--
+		acc.bytes -= desc.bytes
+		s.Properties--
+		s.StringBytes -= desc.bytes
+	})
+}
+
+// release subtracts the account of a collected object.
+func (acc *heapAccount) release() {
+	acc.heap.update(func(s *HeapStats) { acc.add(s, -1) })
+}
+
+func stringBytes(desc *PropertyDescriptor) int {
+	return valueBytes(desc.attrs["value"])
+}
+
+func valueBytes(val Value) int {
+	str, ok := val.(String)
+	if !ok {
+		return 0
+	}
+
+	return 2 * len(str)
+}
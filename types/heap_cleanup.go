@@ -0,0 +1,17 @@
+//go:build go1.24
+// +build go1.24
+
+package types
+
+import "runtime"
+
+// onCollect releases acc when obj is collected. Unlike finalizers,
+// cleanups run for objects in cycles, as closures and their scopes.
+func onCollect(obj *DataObject, acc *heapAccount) {
+	runtime.AddCleanup(obj, (*heapAccount).release, acc)
+}
+
+// onCollectSlots releases acc when slots are collected.
+func onCollectSlots(slots *Slots, acc *heapAccount) {
+	runtime.AddCleanup(slots, (*heapAccount).release, acc)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.24
+// +build !go1.24
+
+package types
+
+// onCollect does nothing without runtime.AddCleanup, finalizers would
+// leak the objects in cycles. The counts only grow then, they cover
+// all the objects tracked so far.
+func onCollect(obj *DataObject, acc *heapAccount) {}
+
+// onCollectSlots does nothing, as onCollect.
+func onCollectSlots(slots *Slots, acc *heapAccount) {}
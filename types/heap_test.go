@@ -0,0 +1,89 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestHeapCounts(t *testing.T) {
+	heap := types.NewHeap()
+	root := types.NewBaseDataObject()
+	heap.Track(root)
+
+	check := func(step string, objects, props, bytes int) {
+		t.Helper()
+
+		got := heap.Stats()
+		if got.Objects["object"] != objects || got.Properties != props ||
+			got.StringBytes != bytes {
+			t.Fatalf("%s: got %+v, want %d objects, %d properties and %d bytes",
+				step, got, objects, props, bytes)
+		}
+	}
+
+	check("empty", 1, 0, 0)
+
+	name := utf16.S("name")
+	err := root.Put(name, Str("abad"), true)
+	assert.NoError(t, err, "putting name")
+	check("put", 1, 1, 8)
+
+	err = root.Put(name, Str("ab"), true)
+	assert.NoError(t, err, "replacing name")
+	check("replaced", 1, 1, 4)
+
+	child := types.NewBaseDataObject()
+	err = child.Put(name, Str("a"), true)
+	assert.NoError(t, err, "putting child name")
+	check("untracked", 1, 1, 4)
+
+	err = root.Put(utf16.S("child"), child, true)
+	assert.NoError(t, err, "putting child")
+	check("child", 2, 3, 6)
+
+	_, err = root.Delete(name, true)
+	assert.NoError(t, err, "deleting name")
+	check("deleted", 2, 2, 2)
+
+	if got := heap.Stats(); got.Environments != 0 {
+		t.Fatalf("got %d environments, want none", got.Environments)
+	}
+
+	heap.TrackEnvironment(root)
+	if got := heap.Stats(); got.Environments != 1 || got.Objects["object"] != 2 {
+		t.Fatalf("got %+v, want the root as object and environment", got)
+	}
+}
+
+func TestHeapSlots(t *testing.T) {
+	heap := types.NewHeap()
+	slots := types.NewSlots(3)
+	slots.Set(0, Str("ab"))
+	heap.TrackSlots(slots)
+
+	check := func(step string, objects, props, bytes int) {
+		t.Helper()
+
+		got := heap.Stats()
+		if got.Environments != 1 || got.Objects["object"] != objects ||
+			got.Properties != props || got.StringBytes != bytes {
+			t.Fatalf("%s: got %+v, want an environment, %d objects, %d properties and %d bytes",
+				step, got, objects, props, bytes)
+		}
+	}
+
+	check("tracked", 0, 1, 4)
+
+	slots.Set(0, Str("abc"))
+	check("replaced", 0, 1, 6)
+
+	obj := types.NewBaseDataObject()
+	err := obj.Put(utf16.S("name"), Str("a"), true)
+	assert.NoError(t, err, "putting name")
+
+	slots.Set(2, obj)
+	check("object", 1, 3, 8)
+}
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// HostTraps are the Go functions that implement the properties
+	// of a host object, like the handler of an ES6 Proxy, eg.: to
+	// expose the tables of a database as properties listed lazily.
+	// A nil trap leaves the operation to the ordinary object.
+	HostTraps struct {
+		// Get returns the value of the property name, ok is false
+		// if the host has no such property, so it's looked up in
+		// the object and its prototype.
+		Get func(name utf16.Str) (val Value, ok bool, err error)
+
+		// Set assigns the property name. It returns false if the
+		// assignment is rejected, a TypeError in strict code.
+		Set func(name utf16.Str, val Value) (bool, error)
+
+		// Has tells if the object has the property name, as in:
+		// name in obj
+		Has func(name utf16.Str) bool
+
+		// Delete removes the property name. It returns false if
+		// the property can't be removed.
+		Delete func(name utf16.Str) (bool, error)
+
+		// OwnKeys lists the names of the own properties, all
+		// enumerable.
+		OwnKeys func() []utf16.Str
+
+		// Call makes the object a function.
+		Call func(this Object, args []Value) (Value, error)
+	}
+
+	// HostObject is an object whose properties are implemented by
+	// the traps of the host.
+	HostObject struct {
+		*DataObject
+
+		traps HostTraps
+	}
+
+	// HostFunction is a host object with a Call trap.
+	HostFunction struct {
+		*HostObject
+	}
+)
+
+// NewHostObject creates an object that runs the traps of the host.
+// It's a *HostFunction, callable from scripts, if traps has a Call
+// trap or a *HostObject otherwise.
+func NewHostObject(traps HostTraps) Object {
+	obj := &HostObject{
+		DataObject: NewBaseDataObject(),
+		traps:      traps,
+	}
+
+	if traps.Call == nil {
+		return obj
+	}
+
+	obj.class = "Function"
+	return &HostFunction{HostObject: obj}
+}
+
+// Get runs the Get trap, looking up the properties the host doesn't
+// have as in DataObject.
+func (h *HostObject) Get(name utf16.Str) (Value, error) {
+	if h.traps.Get != nil {
+		val, ok, err := h.traps.Get(name)
+		if err != nil || ok {
+			return val, err
+		}
+	}
+
+	return h.DataObject.Get(name)
+}
+
+// CanPut tells if name can be assigned, it's always true with a Set
+// trap.
+func (h *HostObject) CanPut(name utf16.Str) bool {
+	if h.traps.Set != nil {
+		return true
+	}
+
+	return h.DataObject.CanPut(name)
+}
+
+// Put runs the Set trap.
+func (h *HostObject) Put(name utf16.Str, val Value, throw bool) error {
+	if h.traps.Set == nil {
+		return h.DataObject.Put(name, val, throw)
+	}
+
+	ok, err := h.traps.Set(name, val)
+	if err != nil {
+		return err
+	}
+
+	if !ok && throw {
+		return NewTypeError("cannot assign to property %s of host object", name)
+	}
+
+	return nil
+}
+
+// Delete runs the Delete trap.
+func (h *HostObject) Delete(name utf16.Str, throw bool) (bool, error) {
+	if h.traps.Delete == nil {
+		return h.DataObject.Delete(name, throw)
+	}
+
+	ok, err := h.traps.Delete(name)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok && throw {
+		return false, NewTypeError("cannot delete property %s of host object", name)
+	}
+
+	return ok, nil
+}
+
+// HasProperty runs the Has trap.
+func (h *HostObject) HasProperty(name utf16.Str) bool {
+	if h.traps.Has != nil {
+		return h.traps.Has(name)
+	}
+
+	return h.DataObject.HasProperty(name)
+}
+
+// OwnPropertyKeys runs the OwnKeys trap, for any filter.
+func (h *HostObject) OwnPropertyKeys(filter KeyFilter) []utf16.Str {
+	if h.traps.OwnKeys != nil {
+		return h.traps.OwnKeys()
+	}
+
+	return h.DataObject.OwnPropertyKeys(filter)
+}
+
+// ToObject returns itself.
+func (h *HostObject) ToObject() (Object, error) {
+	return h, nil
+}
+
+// getProperty describes the properties of the Get trap as writable,
+// enumerable and configurable data properties, for objects
+// inheriting from the host object. The errors of the trap can't be
+// thrown from here, the property is missing then.
+func (h *HostObject) getProperty(name utf16.Str) (*PropertyDescriptor, bool) {
+	if h.traps.Get != nil {
+		val, ok, err := h.traps.Get(name)
+		if err == nil && ok {
+			return NewDataPropDesc(val, true, true, true), true
+		}
+	}
+
+	return h.DataObject.getProperty(name)
+}
+
+// Call runs the Call trap.
+func (f *HostFunction) Call(this Object, args []Value) (Value, error) {
+	return f.traps.Call(this, args)
+}
+
+// ToObject returns itself.
+func (f *HostFunction) ToObject() (Object, error) {
+	return f, nil
+}
@@ -0,0 +1,124 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestHostObjectTraps(t *testing.T) {
+	tables := map[string]types.Value{"users": Str("users table")}
+	var order []string
+
+	db := types.NewHostObject(types.HostTraps{
+		Get: func(name utf16.Str) (types.Value, bool, error) {
+			if name.String() == "broken" {
+				return nil, false, errors.New("connection lost")
+			}
+			val, ok := tables[name.String()]
+			return val, ok, nil
+		},
+		Set: func(name utf16.Str, val types.Value) (bool, error) {
+			if name.String() == "users" {
+				return false, nil
+			}
+			tables[name.String()] = val
+			order = append(order, name.String())
+			return true, nil
+		},
+		Has: func(name utf16.Str) bool {
+			_, ok := tables[name.String()]
+			return ok
+		},
+		Delete: func(name utf16.Str) (bool, error) {
+			delete(tables, name.String())
+			return true, nil
+		},
+		OwnKeys: func() []utf16.Str {
+			keys := []utf16.Str{S("users")}
+			for _, name := range order {
+				keys = append(keys, S(name))
+			}
+			return keys
+		},
+	})
+
+	if _, ok := db.(types.Function); ok {
+		t.Fatal("host object without Call trap must not be a function")
+	}
+
+	assertGet(t, db, "users", Str("users table"))
+	assertGet(t, db, "orders", types.Undefined)
+
+	_, err := db.Get(S("broken"))
+	assert.Error(t, err, "Get trap error")
+
+	_, err = db.(*types.HostObject).DefineOwnPropertyP(S("version"),
+		types.NewDataPropDesc(types.NewNumber(2), false, false, false), true)
+	assert.NoError(t, err, "defining version")
+	assertGet(t, db, "version", types.NewNumber(2))
+
+	err = db.Put(S("orders"), Str("orders table"), true)
+	assert.NoError(t, err, "setting orders")
+	assertGet(t, db, "orders", Str("orders table"))
+
+	err = db.Put(S("users"), Str("other"), true)
+	assert.Error(t, err, "rejected Set in strict mode")
+	err = db.Put(S("users"), Str("other"), false)
+	assert.NoError(t, err, "rejected Set in non strict mode")
+	assertGet(t, db, "users", Str("users table"))
+
+	if !db.HasProperty(S("orders")) || db.HasProperty(S("version")) {
+		t.Fatal("HasProperty must run the Has trap")
+	}
+
+	assertKeys(t, db.OwnPropertyKeys(types.EnumerableKeys), []string{
+		"users", "orders",
+	})
+
+	host := db.(*types.HostObject)
+	ok, err := host.Delete(S("orders"), true)
+	assert.NoError(t, err, "deleting orders")
+	if !ok || db.HasProperty(S("orders")) {
+		t.Fatal("orders must be deleted")
+	}
+}
+
+func TestHostObjectDefaults(t *testing.T) {
+	obj := types.NewHostObject(types.HostTraps{})
+
+	err := obj.Put(S("a"), Str("b"), true)
+	assert.NoError(t, err, "putting without trap")
+	assertGet(t, obj, "a", Str("b"))
+
+	if !obj.HasProperty(S("a")) {
+		t.Fatal("HasProperty without trap must find a")
+	}
+	assertKeys(t, obj.OwnPropertyKeys(types.AllKeys), []string{"a"})
+}
+
+func TestHostFunction(t *testing.T) {
+	fn := types.NewHostObject(types.HostTraps{
+		Call: func(this types.Object, args []types.Value) (types.Value, error) {
+			return types.NewNumber(float64(len(args))), nil
+		},
+	})
+
+	call, ok := fn.(types.Function)
+	if !ok {
+		t.Fatal("host object with Call trap must be a function")
+	}
+
+	got, err := call.Call(nil, []types.Value{Str("a"), Str("b")})
+	assert.NoError(t, err, "calling")
+	if !types.StrictEqual(got, types.NewNumber(2)) {
+		t.Fatalf("got %v, want 2", got)
+	}
+
+	if fn.Class() != "Function" {
+		t.Fatalf("got class %s, want Function", fn.Class())
+	}
+}
@@ -0,0 +1,141 @@
+package types
+
+var (
+	// IteratorAttr is the property holding the method that returns
+	// the iterator of an iterable object, the @@iterator of the
+	// spec. It's a string property until symbols are supported.
+	// http://www.ecma-international.org/ecma-262/6.0/#sec-iterable-interface
+	IteratorAttr = S("@@iterator")
+
+	nextAttr   = S("next")
+	doneAttr   = S("done")
+	returnAttr = S("return")
+)
+
+// GetIterator calls the @@iterator method of v and returns the
+// iterator object.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-getiterator
+func GetIterator(v Value) (Object, error) {
+	obj, err := v.ToObject()
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := obj.Get(IteratorAttr)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, ok := method.(callable)
+	if !ok {
+		return nil, NewTypeError("%s is not iterable", v.ToString())
+	}
+
+	it, err := fn.Call(obj, []Value{})
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, ok := it.(Object)
+	if !ok {
+		return nil, NewTypeError("result of the iterator method is not an object")
+	}
+
+	return iterator, nil
+}
+
+// IteratorStep calls the next method of the iterator and returns the
+// value of the result, or false if the iterator is done.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-iteratorstep
+func IteratorStep(iterator Object) (Value, bool, error) {
+	method, err := iterator.Get(nextAttr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	next, ok := method.(callable)
+	if !ok {
+		return nil, false, NewTypeError("iterator has no next method")
+	}
+
+	res, err := next.Call(iterator, []Value{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, ok := res.(Object)
+	if !ok {
+		return nil, false, NewTypeError("iterator result is not an object")
+	}
+
+	done, err := result.Get(doneAttr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if done.ToBool() {
+		return Undefined, false, nil
+	}
+
+	val, err := result.Get(valueAttr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return val, true, nil
+}
+
+// IteratorClose calls the return method of the iterator, if it has
+// one, when the iteration stops before the iterator is done.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-iteratorclose
+func IteratorClose(iterator Object) error {
+	method, err := iterator.Get(returnAttr)
+	if err != nil {
+		return err
+	}
+
+	ret, ok := method.(callable)
+	if !ok {
+		return nil
+	}
+
+	_, err = ret.Call(iterator, []Value{})
+	return err
+}
+
+// Iterate calls fn with each value of the iterable v. If fn fails the
+// iterator is closed and its error is returned.
+//
+// Slices are iterated by their elements, as arrays are.
+func Iterate(v Value, fn func(Value) error) error {
+	if s, ok := v.(*Slice); ok {
+		for _, val := range s.Values() {
+			if err := fn(val); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	iterator, err := GetIterator(v)
+	if err != nil {
+		return err
+	}
+
+	for {
+		val, ok, err := IteratorStep(iterator)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+
+		if err := fn(val); err != nil {
+			_ = IteratorClose(iterator)
+			return err
+		}
+	}
+}
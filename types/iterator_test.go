@@ -0,0 +1,145 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestIterate(t *testing.T) {
+	iterable, closed := newCounter(t, 3)
+
+	var got []types.Value
+	err := types.Iterate(iterable, func(v types.Value) error {
+		got = append(got, v)
+		return nil
+	})
+	assert.NoError(t, err, "iterating")
+	assertValues(t, got, []types.Value{
+		types.NewNumber(0), types.NewNumber(1), types.NewNumber(2),
+	})
+
+	if *closed {
+		t.Fatal("iterator closed after done")
+	}
+}
+
+func TestIterateStop(t *testing.T) {
+	iterable, closed := newCounter(t, 3)
+	stop := errors.New("stop")
+
+	var got []types.Value
+	err := types.Iterate(iterable, func(v types.Value) error {
+		got = append(got, v)
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("got error %v, want %v", err, stop)
+	}
+
+	assertValues(t, got, []types.Value{types.NewNumber(0)})
+	if !*closed {
+		t.Fatal("iterator not closed")
+	}
+}
+
+func TestIterateSlice(t *testing.T) {
+	values := []types.Value{Str("a"), types.NewNumber(1)}
+
+	var got []types.Value
+	err := types.Iterate(types.NewSlice(values, types.SliceShared),
+		func(v types.Value) error {
+			got = append(got, v)
+			return nil
+		})
+	assert.NoError(t, err, "iterating")
+	assertValues(t, got, values)
+}
+
+func TestIterateNotIterable(t *testing.T) {
+	for name, v := range map[string]types.Value{
+		"Undefined": types.Undefined,
+		"Null":      types.Null,
+		"Object":    types.NewDataObject(types.Null),
+		"NotObjectIterator": withMethod(t, types.IteratorAttr,
+			func(types.Object, []types.Value) (types.Value, error) {
+				return types.NewNumber(1), nil
+			}),
+		"NoNext": withMethod(t, types.IteratorAttr,
+			func(types.Object, []types.Value) (types.Value, error) {
+				return types.NewDataObject(types.Null), nil
+			}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := types.Iterate(v, func(types.Value) error {
+				t.Fatal("unexpected value")
+				return nil
+			})
+			if _, ok := err.(types.TypeError); !ok {
+				t.Fatalf("got error %v, want a TypeError", err)
+			}
+		})
+	}
+}
+
+// newCounter returns an iterable of the numbers from 0 to n-1 and
+// whether its iterator was closed.
+func newCounter(t *testing.T, n int) (*types.DataObject, *bool) {
+	closed := false
+	i := 0
+
+	iterator := withMethod(t, S("next"),
+		func(types.Object, []types.Value) (types.Value, error) {
+			res := types.NewDataObject(types.Null)
+			if i >= n {
+				put(t, res, "done", types.True)
+				return res, nil
+			}
+
+			put(t, res, "value", types.NewNumber(float64(i)))
+			put(t, res, "done", types.False)
+			i++
+			return res, nil
+		})
+	put(t, iterator, "return", types.NewBuiltinfn(
+		func(types.Object, []types.Value) (types.Value, error) {
+			closed = true
+			return types.Undefined, nil
+		}))
+
+	iterable := withMethod(t, types.IteratorAttr,
+		func(types.Object, []types.Value) (types.Value, error) {
+			return iterator, nil
+		})
+
+	return iterable, &closed
+}
+
+func withMethod(t *testing.T, name utf16.Str, fn types.Execfn) *types.DataObject {
+	obj := types.NewDataObject(types.Null)
+	err := obj.Put(name, types.NewBuiltinfn(fn), true)
+	assert.NoError(t, err, "putting method")
+	return obj
+}
+
+func put(t *testing.T, obj types.Object, name string, val types.Value) {
+	err := obj.Put(S(name), val, true)
+	assert.NoError(t, err, "putting %s", name)
+}
+
+func assertValues(t *testing.T, got, want []types.Value) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if !types.StrictEqual(got[i], want[i]) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
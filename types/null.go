@@ -1,7 +1,7 @@
 package types
 
 import (
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type null utf16.Str
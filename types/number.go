@@ -3,14 +3,28 @@ package types
 import (
 	"math"
 	"strconv"
+	"strings"
 )
 
 type (
 	Number float64
 )
 
+// smallIntCacheSize is the number of non-negative integers that have
+// their string representation cached. Small integers are very common
+// as indexes and loop counters.
+const smallIntCacheSize = 1024
+
 var ε = math.Nextafter(1, 2) - 1
 
+var smallInts [smallIntCacheSize]String
+
+func init() {
+	for i := range smallInts {
+		smallInts[i] = NewString(strconv.Itoa(i))
+	}
+}
+
 func NewNumber(a float64) Number {
 	return Number(a)
 }
@@ -48,12 +62,68 @@ func (a Number) ToNumber() Number {
 	return a
 }
 
-// ToString converts the number to string.
-// Check https://es5.github.io/#x9.8
-// TODO(i4k): revisit this.
+// ToString converts the number to its canonical string, which is
+// also the property key of the number, so a[1], a[1.0] and a["1"] are
+// the same property, as are a[-0] and a[0].
+// https://es5.github.io/#x9.8.1
 func (a Number) ToString() String {
-	val := strconv.FormatFloat(float64(a), 'f', -1, 64)
-	return NewString(val)
+	if i, ok := a.smallInt(); ok {
+		// WHY: full slice expression so appending on the
+		// returned string never writes on the cached one.
+		str := smallInts[i]
+		return str[:len(str):len(str)]
+	}
+
+	return NewString(formatNumber(float64(a)))
+}
+
+func formatNumber(m float64) string {
+	switch {
+	case math.IsNaN(m):
+		return "NaN"
+	case m == 0:
+		return "0"
+	case m < 0:
+		return "-" + formatNumber(-m)
+	case math.IsInf(m, 1):
+		return "Infinity"
+	}
+
+	// the shortest digits that identify m, as d.ddde±x
+	exp := strconv.FormatFloat(m, 'e', -1, 64)
+	parts := strings.SplitN(exp, "e", 2)
+	digits := strings.Replace(parts[0], ".", "", 1)
+
+	e, _ := strconv.Atoi(parts[1])
+	k, n := len(digits), e+1
+
+	switch {
+	case k <= n && n <= 21:
+		return digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return "0." + strings.Repeat("0", -n) + digits
+	}
+
+	sign := "+"
+	if n-1 < 0 {
+		sign = "-"
+	}
+
+	exponent := sign + strconv.Itoa(abs(n-1))
+	if k == 1 {
+		return digits + "e" + exponent
+	}
+
+	return digits[:1] + "." + digits[1:] + "e" + exponent
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
 }
 
 func (_ Number) Kind() Kind {
@@ -69,14 +139,51 @@ func (a Number) Equal(b Number) bool {
 	return equalValues(a.Value(), b.Value())
 }
 
+// ToInt32 converts the number to a 32 bit signed integer, modulo
+// 2^32, NaN and the infinities are zero.
+// https://es5.github.io/#x9.5
+func (a Number) ToInt32() int32 {
+	return int32(a.ToUint32())
+}
+
+// ToUint32 converts the number to a 32 bit unsigned integer, modulo
+// 2^32, NaN and the infinities are zero.
+// https://es5.github.io/#x9.6
+func (a Number) ToUint32() uint32 {
+	f := float64(a)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+
+	return uint32(int64(math.Trunc(math.Mod(f, 1<<32))))
+}
+
 func (a Number) ToPrimitive(hint Kind) (Value, error) {
 	return a, nil
 }
 
+// ToObject fails, Number objects are not supported yet.
 func (a Number) ToObject() (Object, error) {
-	panic("not implemented")
+	return nil, NewTypeError("Number objects are not supported yet")
+}
+
+// smallInt tells if the number is an integer that has its string
+// representation cached, returning it as an int.
+func (a Number) smallInt() (int, bool) {
+	f := float64(a)
+	if f < 0 || f >= smallIntCacheSize || math.Signbit(f) {
+		return 0, false
+	}
+
+	i := int(f)
+	if float64(i) != f {
+		return 0, false
+	}
+
+	return i, true
 }
 
 func equalValues(a, b float64) bool {
-	return math.Abs(a-b) < ε && math.Abs(b-a) < ε
+	// WHY: infinities are equal, but their difference is NaN
+	return a == b || math.Abs(a-b) < ε && math.Abs(b-a) < ε
 }
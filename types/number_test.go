@@ -0,0 +1,88 @@
+package types_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestNumberToString(t *testing.T) {
+	for _, tc := range []struct {
+		num  float64
+		want string
+	}{
+		{num: 0, want: "0"},
+		{num: math.Copysign(0, -1), want: "0"},
+		{num: 1, want: "1"},
+		{num: 1023, want: "1023"},
+		{num: 1024, want: "1024"},
+		{num: -1, want: "-1"},
+		{num: 1.5, want: "1.5"},
+		{num: 0.1, want: "0.1"},
+		{num: 1e10, want: "10000000000"},
+		{num: 123.456, want: "123.456"},
+		{num: -0.5, want: "-0.5"},
+		{num: 1e20, want: "100000000000000000000"},
+		{num: 1e21, want: "1e+21"},
+		{num: 1.5e300, want: "1.5e+300"},
+		{num: 0.000001, want: "0.000001"},
+		{num: 0.0000001, want: "1e-7"},
+		{num: 1.25e-7, want: "1.25e-7"},
+		{num: math.MaxFloat64, want: "1.7976931348623157e+308"},
+		{num: 5e-324, want: "5e-324"},
+		{num: math.NaN(), want: "NaN"},
+		{num: math.Inf(1), want: "Infinity"},
+		{num: math.Inf(-1), want: "-Infinity"},
+	} {
+		got := types.NewNumber(tc.num).ToString()
+		assert.EqualStrings(t, tc.want, got.String(), "number[%v]", tc.num)
+	}
+}
+
+func TestNumberToStringCacheIsNotShared(t *testing.T) {
+	str := types.NewNumber(1).ToString()
+	_ = append(str, str...)
+
+	got := types.NewNumber(1).ToString()
+	assert.EqualStrings(t, "1", got.String(), "cached string changed")
+}
+
+func TestNumberToInt32(t *testing.T) {
+	for _, tc := range []struct {
+		num    float64
+		int32  int32
+		uint32 uint32
+	}{
+		{num: 0, int32: 0, uint32: 0},
+		{num: 1.9, int32: 1, uint32: 1},
+		{num: -1.9, int32: -1, uint32: 1<<32 - 1},
+		{num: 1 << 31, int32: -1 << 31, uint32: 1 << 31},
+		{num: 1<<32 + 5, int32: 5, uint32: 5},
+		{num: -(1<<32 + 5), int32: -5, uint32: 1<<32 - 5},
+		{num: math.NaN(), int32: 0, uint32: 0},
+		{num: math.Inf(1), int32: 0, uint32: 0},
+		{num: math.Inf(-1), int32: 0, uint32: 0},
+	} {
+		n := types.NewNumber(tc.num)
+		if got := n.ToInt32(); got != tc.int32 {
+			t.Errorf("ToInt32(%v): got %d but want %d", tc.num, got, tc.int32)
+		}
+		if got := n.ToUint32(); got != tc.uint32 {
+			t.Errorf("ToUint32(%v): got %d but want %d", tc.num, got, tc.uint32)
+		}
+	}
+}
+
+func BenchmarkNumberToStringSmallInt(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		types.NewNumber(float64(i % 1024)).ToString()
+	}
+}
+
+func BenchmarkNumberToStringReal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		types.NewNumber(float64(i%1024) + 0.5).ToString()
+	}
+}
@@ -3,8 +3,10 @@ package types
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -14,10 +16,28 @@ type (
 		class         string
 		notExtensible bool
 		props         map[string]*PropertyDescriptor
+
+		// keys are the property names in creation order
+		keys []string
+
+		onChange ChangeHook
+
+		// account of the heap tracking the object, if any
+		account *heapAccount
 	}
 
+	// KeyFilter selects the properties listed by OwnPropertyKeys.
+	KeyFilter int
+
+	// Change is the kind of change of a property.
+	Change int
+
+	// ChangeHook is called after an own property of an object
+	// changes, with its name and the kind of change.
+	ChangeHook func(name utf16.Str, change Change)
+
 	callable interface {
-		Call(this *DataObject, args []Value) Value
+		Call(this Object, args []Value) (Value, error)
 	}
 )
 
@@ -32,10 +52,31 @@ var (
 	cfgAttr      = S("configurable")
 
 	protoAttr    = S("prototype")
+	protoKey     = "prototype"
 	toStringAttr = S("toString")
 	valueOfAttr  = S("valueOf")
 )
 
+const (
+	// AllKeys lists all properties.
+	AllKeys KeyFilter = iota
+
+	// EnumerableKeys lists only the enumerable properties.
+	EnumerableKeys
+)
+
+const (
+	// PropertySet is the assignment of a value, eg.: o.a = 1
+	PropertySet Change = iota
+
+	// PropertyDefined is the definition of a property, or the
+	// change of its attributes, eg.: by DefineOwnProperty.
+	PropertyDefined
+
+	// PropertyDeleted is the removal of a property by Delete.
+	PropertyDeleted
+)
+
 // DefaultPrototypeDesc is a base prototype object that extends Null.
 // The root of the prototype-based type hierarchy.
 func DefaultPrototypeDesc() *PropertyDescriptor {
@@ -48,7 +89,7 @@ func NewDataObject(proto Value) *DataObject {
 	obj := NewBaseDataObject()
 
 	// obj must extend proto
-	delete(obj.props, "prototype")
+	obj.remove(protoAttr)
 
 	// error ignored because it does not fail if
 	// there's no previous properties.
@@ -64,7 +105,9 @@ func NewDataObject(proto Value) *DataObject {
 }
 
 // NewBaseDataObject is the same as ecmascript code:
-//   Object.create(null);
+//
+//	Object.create(null);
+//
 // This is the root of the prototype chain.
 func NewBaseDataObject() *DataObject {
 	return NewDataObjectP(DefaultPrototypeDesc())
@@ -82,7 +125,7 @@ func NewDataObjectP(proto *PropertyDescriptor) *DataObject {
 		props: make(map[string]*PropertyDescriptor),
 	}
 
-	obj.props["prototype"] = proto
+	obj.put(protoAttr, proto)
 	return obj
 }
 
@@ -90,6 +133,22 @@ func NewDataObjectP(proto *PropertyDescriptor) *DataObject {
 func (o *DataObject) Class() string       { return o.class }
 func (o *DataObject) NotExtensible() bool { return o.notExtensible }
 
+// PreventExtensions forbids adding new properties to the object.
+// https://es5.github.io/#x15.2.3.10
+func (o *DataObject) PreventExtensions() { o.notExtensible = true }
+
+// OnChange sets the hook called after every change of the own
+// properties of the object, so hosts can track its state without
+// polling it. A nil hook removes it. Assignments calling setters
+// don't change the object and aren't notified.
+func (o *DataObject) OnChange(hook ChangeHook) { o.onChange = hook }
+
+func (o *DataObject) notify(name utf16.Str, change Change) {
+	if o.onChange != nil {
+		o.onChange(name, change)
+	}
+}
+
 // Value interface implementations
 
 // IsFalse SHALL return false for objects.
@@ -137,6 +196,8 @@ func (o *DataObject) ToObject() (Object, error) {
 	return o, nil
 }
 
+func (o *DataObject) dataObject() *DataObject { return o }
+
 // ToPropertyDescriptor creates a PropertyDescriptor from a DataObject.
 // This is required because property descriptors are defined in ECMAScript
 // using objects.
@@ -235,7 +296,7 @@ func (o *DataObject) Get(name utf16.Str) (Value, error) {
 		panic(fmt.Sprintf("object %s is not callable", getter))
 	}
 
-	return getter.Call(o, []Value{}), nil
+	return getter.Call(o, []Value{})
 }
 
 // Put is the default [[Put]] implementation for Object.
@@ -250,23 +311,15 @@ func (o *DataObject) Put(name utf16.Str, val Value, throw bool) error {
 
 	ownDesc, ok := o.getOwnProperty(name)
 	if ok && ownDesc.IsDataDescriptor() {
-		// TODO(i4k): I'm not sure of this parameters
-		desc := NewDataPropDesc(ownDesc.Value(), true, true, true)
-		_, err := o.DefineOwnPropertyP(name, desc, throw)
-		return err
+		// only the value changes, the attributes are kept
+		valueDesc := NewGenericPropDesc()
+		valueDesc.SetValue(val)
+		return o.set(name, valueDesc, throw)
 	}
 
 	desc, ok := o.getProperty(name)
-	if !ok {
-		desc := NewDataPropDesc(val, true, true, true)
-		_, err := o.DefineOwnPropertyP(name, desc, throw)
-		return err
-	}
-
-	if desc.IsDataDescriptor() {
-		valueDesc := NewDataPropDesc(val, true, true, true)
-		_, err := o.DefineOwnPropertyP(name, valueDesc, throw)
-		return err
+	if !ok || desc.IsDataDescriptor() {
+		return o.set(name, NewDataPropDesc(val, true, true, true), throw)
 	}
 
 	if desc.IsAcessorDescriptor() {
@@ -280,20 +333,141 @@ func (o *DataObject) Put(name utf16.Str, val Value, throw bool) error {
 			panic("setter is not a Function")
 		}
 
-		_ = setter.Call(o, []Value{val})
-		return nil
+		_, err := setter.Call(o, []Value{val})
+		return err
 	}
 
 	panic("TODO(i4k): property is not an acessor nor data. Is this a problem?")
 }
 
+// set defines the property assigned by Put.
+func (o *DataObject) set(name utf16.Str, desc *PropertyDescriptor, throw bool) error {
+	ok, err := o.defineOwnProperty(name, desc, throw)
+	if ok {
+		o.notify(name, PropertySet)
+	}
+
+	return err
+}
+
+// Delete removes the own property name, unless it's not configurable.
+// It returns false or, if throw is true, a TypeError if the property
+// can't be removed.
+// https://es5.github.io/#x8.12.7
+func (o *DataObject) Delete(name utf16.Str, throw bool) (bool, error) {
+	desc, ok := o.getOwnProperty(name)
+	if !ok {
+		return true, nil
+	}
+
+	if desc.Cfg().IsFalse() {
+		if throw {
+			return false, NewTypeError("property %s is not configurable", name)
+		}
+
+		return false, nil
+	}
+
+	o.remove(name)
+	o.notify(name, PropertyDeleted)
+	return true, nil
+}
+
 func (o *DataObject) get(name utf16.Str) (*PropertyDescriptor, bool) {
 	v, ok := o.props[name.String()]
 	return v, ok
 }
 
 func (o *DataObject) put(name utf16.Str, val *PropertyDescriptor) {
-	o.props[name.String()] = val
+	key := name.String()
+	old, ok := o.props[key]
+	if !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.props[key] = val
+
+	if o.account != nil {
+		o.account.changed(key, old, val)
+	}
+}
+
+func (o *DataObject) remove(name utf16.Str) {
+	key := name.String()
+	old, ok := o.props[key]
+	if !ok {
+		return
+	}
+
+	delete(o.props, key)
+	if o.account != nil {
+		o.account.removed(key, old)
+	}
+
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// OwnPropertyKeys returns the names of the own properties selected by
+// filter in the spec order: array indexes in ascending numeric order
+// followed by the other names in creation order. The prototype is an
+// internal property and it is never listed.
+// https://www.ecma-international.org/ecma-262/6.0/#sec-ordinaryownpropertykeys
+func (o *DataObject) OwnPropertyKeys(filter KeyFilter) []utf16.Str {
+	var (
+		indexes []uint32
+		names   []string
+	)
+
+	for _, key := range o.keys {
+		if key == protoKey {
+			continue
+		}
+
+		if filter == EnumerableKeys && o.props[key].Enum().IsFalse() {
+			continue
+		}
+
+		if index, ok := arrayIndex(key); ok {
+			indexes = append(indexes, index)
+			continue
+		}
+
+		names = append(names, key)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i] < indexes[j]
+	})
+
+	keys := make([]utf16.Str, 0, len(indexes)+len(names))
+	for _, index := range indexes {
+		keys = append(keys, S(strconv.FormatUint(uint64(index), 10)))
+	}
+
+	for _, name := range names {
+		keys = append(keys, S(name))
+	}
+
+	return keys
+}
+
+// arrayIndex tells if key is the canonical string of an array index.
+// https://es5.github.io/#x15.4
+func arrayIndex(key string) (uint32, bool) {
+	index, err := strconv.ParseUint(key, 10, 32)
+	if err != nil || index == math.MaxUint32 {
+		return 0, false
+	}
+
+	if strconv.FormatUint(index, 10) != key {
+		return 0, false
+	}
+
+	return uint32(index), true
 }
 
 func (o *DataObject) CanPut(name utf16.Str) bool {
@@ -306,7 +480,6 @@ func (o *DataObject) CanPut(name utf16.Str) bool {
 		}
 
 		panic("property is acessor nor data descriptor")
-		return false
 	}
 
 	protodesc, ok := o.getOwnProperty(protoAttr)
@@ -337,7 +510,6 @@ func (o *DataObject) CanPut(name utf16.Str) bool {
 	}
 
 	panic("inherited isn't acessor not data descriptor")
-	return false
 }
 
 func (o *DataObject) getOwnProperty(name utf16.Str) (*PropertyDescriptor, bool) {
@@ -348,6 +520,13 @@ func (o *DataObject) getOwnProperty(name utf16.Str) (*PropertyDescriptor, bool)
 	return prop, true
 }
 
+// HasOwnProperty tells if name is an own property of the object,
+// not inherited from its prototype.
+func (o *DataObject) HasOwnProperty(name utf16.Str) bool {
+	_, ok := o.getOwnProperty(name)
+	return ok
+}
+
 func (o *DataObject) GetOwnProperty(name utf16.Str) Value {
 	prop, ok := o.get(name)
 	if !ok {
@@ -408,9 +587,30 @@ func (o *DataObject) DefineOwnProperty(
 	return o.DefineOwnPropertyP(name, descobj.ToPropertyDescriptor(), throw)
 }
 
+// DefineBuiltin defines the property name of a builtin object,
+// eg.: Math.random. As the builtins of the spec, it's writable and
+// configurable but not enumerable, so it isn't copied by
+// Object.assign or listed by for-in.
+// https://es5.github.io/#x15
+func (o *DataObject) DefineBuiltin(name utf16.Str, val Value) error {
+	_, err := o.DefineOwnPropertyP(name, NewDataPropDesc(val, true, false, true), true)
+	return err
+}
+
 // https://es5.github.io/#x8.12.9
 func (o *DataObject) DefineOwnPropertyP(
 	name utf16.Str, desc *PropertyDescriptor, throw bool,
+) (bool, error) {
+	ok, err := o.defineOwnProperty(name, desc, throw)
+	if ok {
+		o.notify(name, PropertyDefined)
+	}
+
+	return ok, err
+}
+
+func (o *DataObject) defineOwnProperty(
+	name utf16.Str, desc *PropertyDescriptor, throw bool,
 ) (bool, error) {
 	// throw exception if requested, otherwise quietly returns
 	retOrThrow := func(err error) (bool, error) {
@@ -465,11 +665,11 @@ func (o *DataObject) DefineOwnPropertyP(
 	descWr := desc.Writable().ToBool()
 
 	if !curCfg {
-		if descCfg {
+		if desc.HasCfg() && descCfg.IsTrue() {
 			return retOrThrow(NewTypeError("configurable is false"))
 		}
 
-		if descEnum != curEnum {
+		if desc.HasEnum() && descEnum != curEnum {
 			return retOrThrow(
 				NewTypeError("enumerable dont match for configuration disabled"),
 			)
@@ -543,58 +743,52 @@ func (o *DataObject) HasProperty(name utf16.Str) bool {
 	return !StrictEqual(prop, Undefined)
 }
 
+// https://es5.github.io/#x8.12.8
+// DefaultValue converts the object to a primitive value, see
+// OrdinaryToPrimitive.
 // https://es5.github.io/#x8.12.8
 func (o *DataObject) DefaultValue(hint Kind) (Value, error) {
+	return OrdinaryToPrimitive(o, hint)
+}
+
+// OrdinaryToPrimitive converts obj calling its valueOf and toString
+// methods, with obj as this, toString first if hint is KindString or
+// valueOf first otherwise. The first primitive returned is the
+// result, if none is a TypeError is thrown. Errors thrown by the
+// methods are returned as is.
+// https://es5.github.io/#x8.12.8
+func OrdinaryToPrimitive(obj Object, hint Kind) (Value, error) {
+	methods := []utf16.Str{valueOfAttr, toStringAttr}
 	if hint == KindString {
-		//TODO(i4k): || hint == KindDate {
-		return o.defaultString()
+		methods[0], methods[1] = methods[1], methods[0]
 	}
 
-	return o.defaultNumber()
-}
-
-func (o *DataObject) defaultString() (Value, error) {
-	toString, _ := o.Get(toStringAttr)
-	if stringify, ok := toString.(Function); ok {
-		str := stringify.Call(o, []Value{})
-		if IsPrimitive(str) {
-			return str, nil
+	for _, name := range methods {
+		method, err := obj.Get(name)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	valueOf, _ := o.Get(valueOfAttr)
-	if valueFunc, ok := valueOf.(callable); ok {
-		val := valueFunc.Call(o, []Value{})
-		if IsPrimitive(val) {
-			return val, nil
+		fn, ok := method.(callable)
+		if !ok {
+			continue
 		}
-	}
 
-	return nil, NewTypeError("DataObject has no defaultValue")
-}
+		val, err := fn.Call(obj, []Value{})
+		if err != nil {
+			return nil, err
+		}
 
-func (o *DataObject) defaultNumber() (Value, error) {
-	valueOf, _ := o.Get(valueOfAttr)
-	if valuefunc, ok := valueOf.(callable); ok {
-		val := valuefunc.Call(o, []Value{})
 		if IsPrimitive(val) {
 			return val, nil
 		}
 	}
 
-	tostring, _ := o.Get(toStringAttr)
-	if stringify, ok := tostring.(callable); ok {
-		str := stringify.Call(o, []Value{})
-		if IsPrimitive(str) {
-			return str, nil
-		}
-	}
-
-	return nil, NewTypeError("DataObject has no defaultValue")
+	return nil, NewTypeError("cannot convert object to primitive value")
 }
 
 func (o *DataObject) String() string {
-	v, err := o.defaultString()
+	v, err := OrdinaryToPrimitive(o, KindString)
 	if err != nil {
 		panic(err)
 	}
@@ -1,10 +1,12 @@
 package types_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
 	"github.com/NeowayLabs/abad/types"
+	"github.com/NeowayLabs/abad/utf16"
 	"github.com/madlambda/spells/assert"
 )
 
@@ -106,3 +108,155 @@ func testDataDescriptorFail(t *testing.T, obj *types.DataObject, property string
 		t.Fatal("should fail")
 	}
 }
+
+func TestObjectPut(t *testing.T) {
+	obj := types.NewBaseDataObject()
+
+	err := obj.Put(S("a"), types.NewNumber(1), true)
+	assert.NoError(t, err, "creating property")
+
+	err = obj.Put(S("a"), types.NewNumber(2), true)
+	assert.NoError(t, err, "updating property")
+	assertGet(t, obj, "a", types.NewNumber(2))
+
+	// updates keep the attributes
+	desc := types.NewDataPropDesc(types.NewNumber(3), true, false, false)
+	ok, err := obj.DefineOwnPropertyP(S("b"), desc, true)
+	if !ok {
+		t.Fatal(err)
+	}
+
+	err = obj.Put(S("b"), types.NewNumber(4), true)
+	assert.NoError(t, err, "updating non configurable property")
+	assertGet(t, obj, "b", types.NewNumber(4))
+	assertKeys(t, obj.OwnPropertyKeys(types.EnumerableKeys), []string{"a"})
+
+	readonly := types.NewDataPropDesc(types.NewNumber(5), false, true, false)
+	ok, err = obj.DefineOwnPropertyP(S("c"), readonly, true)
+	if !ok {
+		t.Fatal(err)
+	}
+
+	err = obj.Put(S("c"), types.NewNumber(6), false)
+	assert.NoError(t, err, "silently failing on read only property")
+	assertGet(t, obj, "c", types.NewNumber(5))
+
+	err = obj.Put(S("c"), types.NewNumber(6), true)
+	assert.Error(t, err, "updating read only property")
+
+	obj.PreventExtensions()
+
+	err = obj.Put(S("d"), types.NewNumber(7), true)
+	assert.Error(t, err, "creating property on non extensible object")
+	assertGet(t, obj, "d", types.Undefined)
+
+	err = obj.Put(S("a"), types.NewNumber(8), true)
+	assert.NoError(t, err, "updating property of non extensible object")
+	assertGet(t, obj, "a", types.NewNumber(8))
+}
+
+func TestObjectOnChange(t *testing.T) {
+	obj := types.NewBaseDataObject()
+
+	var changes []string
+	obj.OnChange(func(name utf16.Str, change types.Change) {
+		changes = append(changes, fmt.Sprintf("%s:%d", name, change))
+	})
+
+	assert.NoError(t, obj.Put(S("a"), types.NewNumber(1), true), "creating a")
+	assert.NoError(t, obj.Put(S("a"), types.NewNumber(2), true), "updating a")
+
+	readonly := types.NewDataPropDesc(types.NewNumber(3), false, true, false)
+	_, err := obj.DefineOwnPropertyP(S("b"), readonly, true)
+	assert.NoError(t, err, "defining b")
+
+	// failed changes are not notified
+	assert.NoError(t, obj.Put(S("b"), types.NewNumber(4), false), "updating b")
+	ok, _ := obj.Delete(S("b"), false)
+	if ok {
+		t.Fatal("deleted non configurable property")
+	}
+
+	ok, err = obj.Delete(S("a"), true)
+	if !ok {
+		t.Fatal(err)
+	}
+	assertGet(t, obj, "a", types.Undefined)
+
+	want := []string{
+		fmt.Sprintf("a:%d", types.PropertySet),
+		fmt.Sprintf("a:%d", types.PropertySet),
+		fmt.Sprintf("b:%d", types.PropertyDefined),
+		fmt.Sprintf("a:%d", types.PropertyDeleted),
+	}
+	assert.EqualStrings(t, strings.Join(want, ","), strings.Join(changes, ","), "changes")
+
+	obj.OnChange(nil)
+	assert.NoError(t, obj.Put(S("c"), types.NewNumber(5), true), "creating c")
+	assert.EqualInts(t, len(want), len(changes), "changes after removing the hook")
+}
+
+func TestSliceOnChange(t *testing.T) {
+	slice := types.NewSlice([]types.Value{types.NewNumber(1)}, types.SliceShared)
+
+	var changed []string
+	slice.OnChange(func(name utf16.Str, change types.Change) {
+		if change == types.PropertySet {
+			changed = append(changed, name.String())
+		}
+	})
+
+	assert.NoError(t, slice.Put(S("0"), types.NewNumber(2), true), "writing index")
+	assert.NoError(t, slice.Put(S("x"), types.NewNumber(3), true), "writing property")
+	assert.EqualStrings(t, "0,x", strings.Join(changed, ","), "changes")
+}
+
+func TestOwnPropertyKeys(t *testing.T) {
+	obj := types.NewDataObject(types.NewBaseDataObject())
+
+	for _, prop := range []struct {
+		name string
+		enum bool
+	}{
+		{name: "b", enum: true},
+		{name: "10", enum: true},
+		{name: "a", enum: true},
+		{name: "hidden", enum: false},
+		{name: "2", enum: true},
+		{name: "01", enum: true},
+		{name: "4294967295", enum: true},
+		{name: "-1", enum: true},
+		{name: "1", enum: false},
+	} {
+		desc := types.NewDataPropDesc(types.True, true, prop.enum, true)
+		ok, err := obj.DefineOwnPropertyP(S(prop.name), desc, true)
+		if !ok {
+			t.Fatal(err)
+		}
+	}
+
+	// redefining keeps the creation order
+	err := obj.Put(S("b"), types.False, true)
+	assert.NoError(t, err, "redefining property")
+
+	assertKeys(t, obj.OwnPropertyKeys(types.AllKeys), []string{
+		"1", "2", "10", "b", "a", "hidden", "01", "4294967295", "-1",
+	})
+
+	assertKeys(t, obj.OwnPropertyKeys(types.EnumerableKeys), []string{
+		"2", "10", "b", "a", "01", "4294967295", "-1",
+	})
+
+	assertKeys(t, types.NewBaseDataObject().OwnPropertyKeys(types.AllKeys), nil)
+}
+
+func assertKeys(t *testing.T, got []utf16.Str, want []string) {
+	t.Helper()
+
+	var gotstrs []string
+	for _, key := range got {
+		gotstrs = append(gotstrs, key.String())
+	}
+
+	assert.EqualStrings(t, strings.Join(want, ","), strings.Join(gotstrs, ","), "keys differ")
+}
@@ -21,6 +21,9 @@ type (
 	//   });
 	PropertyDescriptor struct {
 		attrs map[string]Value
+
+		// bytes of the string value accounted by the heap
+		bytes int
 	}
 )
 
@@ -0,0 +1,219 @@
+package types
+
+import (
+	"strconv"
+
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// SliceMode tells how writes to a Slice reach the Go slice.
+	SliceMode int
+
+	// Slice is an array-like object backed by a Go slice.
+	// Index properties are read and written directly in the slice,
+	// without copying, so both sides observe the same elements.
+	// The slice length is fixed, writes out of range are rejected,
+	// unless the slice is growable.
+	Slice struct {
+		*DataObject
+
+		values []Value
+		mode   SliceMode
+		copied bool
+	}
+)
+
+const (
+	// SliceShared writes directly in the Go slice.
+	SliceShared SliceMode = iota
+
+	// SliceCopyOnWrite copies the Go slice on the first write,
+	// leaving the original untouched.
+	SliceCopyOnWrite
+
+	// SliceGrowable owns the Go slice and grows it like an array:
+	// writes past the end append to it, filling the gap with
+	// undefined, and writing length truncates or extends it.
+	SliceGrowable
+)
+
+// maxSliceLen is the length growable slices can't go beyond, so
+// scripts can't exhaust the memory with a single write,
+// eg.: a[4294967294] = 1
+const maxSliceLen = 1 << 24
+
+var lengthAttr = S("length")
+
+// NewSlice wraps values in an array-like object.
+func NewSlice(values []Value, mode SliceMode) *Slice {
+	return &Slice{
+		DataObject: NewDataObject(newSlicePrototype()),
+		values:     values,
+		mode:       mode,
+	}
+}
+
+// Len returns the number of elements.
+func (s *Slice) Len() int { return len(s.values) }
+
+// Index returns the element at position i.
+func (s *Slice) Index(i int) Value { return s.values[i] }
+
+// Values returns the elements of the slice. For copy-on-write
+// slices it's the original slice until the first write.
+func (s *Slice) Values() []Value { return s.values }
+
+// Get returns the element of index properties and the slice length for
+// the length property. Other properties are looked up as in DataObject.
+func (s *Slice) Get(name utf16.Str) (Value, error) {
+	desc, ok := s.ownProperty(name)
+	if ok {
+		return desc.Value(), nil
+	}
+
+	return s.DataObject.Get(name)
+}
+
+// CanPut tells if name can be written. Index properties out of range
+// and length can't, unless the slice is growable.
+func (s *Slice) CanPut(name utf16.Str) bool {
+	if index, ok := arrayIndex(name.String()); ok {
+		return s.mode == SliceGrowable || int64(index) < int64(len(s.values))
+	}
+
+	if name.String() == lengthAttr.String() {
+		return s.mode == SliceGrowable
+	}
+
+	return s.DataObject.CanPut(name)
+}
+
+// Put writes the element of index properties in the slice. Other
+// properties are stored as in DataObject.
+func (s *Slice) Put(name utf16.Str, val Value, throw bool) error {
+	index, ok := arrayIndex(name.String())
+	if !ok && name.String() != lengthAttr.String() {
+		return s.DataObject.Put(name, val, throw)
+	}
+
+	if !s.CanPut(name) {
+		if throw {
+			return NewTypeError("can not put %s on slice of length %d",
+				name, len(s.values))
+		}
+
+		return nil
+	}
+
+	if s.mode == SliceCopyOnWrite && !s.copied {
+		values := make([]Value, len(s.values))
+		copy(values, s.values)
+		s.values = values
+		s.copied = true
+	}
+
+	if !ok {
+		return s.setLength(val)
+	}
+
+	if int64(index) >= int64(len(s.values)) {
+		if err := s.resize(int64(index) + 1); err != nil {
+			return err
+		}
+	}
+
+	s.values[index] = val
+	s.notify(name, PropertySet)
+	return nil
+}
+
+// setLength truncates or extends a growable slice to the length val.
+// https://es5.github.io/#x15.4.5.1
+func (s *Slice) setLength(val Value) error {
+	num := val.ToNumber()
+	length := num.ToUint32()
+	if float64(length) != num.Value() {
+		return NewTypeError("invalid array length %s", val.ToString())
+	}
+
+	if err := s.resize(int64(length)); err != nil {
+		return err
+	}
+
+	s.notify(lengthAttr, PropertySet)
+	return nil
+}
+
+// resize truncates or extends the slice with undefined elements.
+func (s *Slice) resize(length int64) error {
+	if length > maxSliceLen {
+		return NewTypeError("array length %d exceeds the limit of %d elements",
+			length, maxSliceLen)
+	}
+
+	if length <= int64(len(s.values)) {
+		s.values = s.values[:length]
+		return nil
+	}
+
+	for int64(len(s.values)) < length {
+		s.values = append(s.values, Undefined)
+	}
+
+	return nil
+}
+
+// HasProperty tells if name is an index in range, length or a
+// property of the object.
+func (s *Slice) HasProperty(name utf16.Str) bool {
+	_, ok := s.getProperty(name)
+	return ok
+}
+
+// OwnPropertyKeys lists the slice indexes, length (when filter is
+// AllKeys) and then the other own properties.
+func (s *Slice) OwnPropertyKeys(filter KeyFilter) []utf16.Str {
+	keys := make([]utf16.Str, 0, len(s.values)+1)
+	for i := range s.values {
+		keys = append(keys, S(strconv.Itoa(i)))
+	}
+
+	if filter == AllKeys {
+		keys = append(keys, lengthAttr)
+	}
+
+	return append(keys, s.DataObject.OwnPropertyKeys(filter)...)
+}
+
+// ToObject returns itself.
+func (s *Slice) ToObject() (Object, error) {
+	return s, nil
+}
+
+func (s *Slice) getProperty(name utf16.Str) (*PropertyDescriptor, bool) {
+	desc, ok := s.ownProperty(name)
+	if ok {
+		return desc, true
+	}
+
+	return s.DataObject.getProperty(name)
+}
+
+func (s *Slice) ownProperty(name utf16.Str) (*PropertyDescriptor, bool) {
+	if index, ok := arrayIndex(name.String()); ok {
+		if int64(index) >= int64(len(s.values)) {
+			return nil, false
+		}
+
+		return NewDataPropDesc(s.values[index], true, true, false), true
+	}
+
+	if name.String() == lengthAttr.String() {
+		length := NewNumber(float64(len(s.values)))
+		writable := s.mode == SliceGrowable
+		return NewDataPropDesc(length, writable, false, false), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,107 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestSliceShared(t *testing.T) {
+	values := []types.Value{Str("a"), types.NewNumber(1)}
+	slice := types.NewSlice(values, types.SliceShared)
+
+	assertGet(t, slice, "0", Str("a"))
+	assertGet(t, slice, "1", types.NewNumber(1))
+	assertGet(t, slice, "2", types.Undefined)
+	assertGet(t, slice, "length", types.NewNumber(2))
+
+	err := slice.Put(S("1"), Str("b"), true)
+	assert.NoError(t, err, "writing index")
+	assertGet(t, slice, "1", Str("b"))
+
+	if !types.StrictEqual(values[1], Str("b")) {
+		t.Fatalf("write not visible in Go slice: %v", values[1])
+	}
+
+	values[0] = Str("c")
+	assertGet(t, slice, "0", Str("c"))
+
+	err = slice.Put(S("2"), Str("d"), true)
+	assert.Error(t, err, "writing out of range")
+
+	err = slice.Put(S("length"), types.NewNumber(10), true)
+	assert.Error(t, err, "writing length")
+
+	err = slice.Put(S("name"), Str("slice"), true)
+	assert.NoError(t, err, "writing property")
+	assertGet(t, slice, "name", Str("slice"))
+
+	for name, want := range map[string]bool{
+		"0": true, "1": true, "2": false, "length": true, "name": true,
+	} {
+		if slice.HasProperty(S(name)) != want {
+			t.Fatalf("HasProperty(%s) must be %t", name, want)
+		}
+	}
+
+	assertKeys(t, slice.OwnPropertyKeys(types.AllKeys), []string{
+		"0", "1", "length", "name",
+	})
+	assertKeys(t, slice.OwnPropertyKeys(types.EnumerableKeys), []string{
+		"0", "1", "name",
+	})
+}
+
+func TestSliceCopyOnWrite(t *testing.T) {
+	values := []types.Value{Str("a"), Str("b")}
+	slice := types.NewSlice(values, types.SliceCopyOnWrite)
+
+	values[0] = Str("c")
+	assertGet(t, slice, "0", Str("c"))
+
+	err := slice.Put(S("1"), Str("d"), true)
+	assert.NoError(t, err, "writing index")
+	assertGet(t, slice, "1", Str("d"))
+
+	if !types.StrictEqual(values[1], Str("b")) {
+		t.Fatalf("write changed the Go slice: %v", values[1])
+	}
+
+	if slice.Len() != 2 || !types.StrictEqual(slice.Index(1), Str("d")) {
+		t.Fatalf("unexpected slice values: %v", slice.Values())
+	}
+}
+
+func TestSliceGrowable(t *testing.T) {
+	slice := types.NewSlice([]types.Value{Str("a")}, types.SliceGrowable)
+
+	err := slice.Put(S("2"), Str("c"), true)
+	assert.NoError(t, err, "writing past the end")
+	assertGet(t, slice, "1", types.Undefined)
+	assertGet(t, slice, "2", Str("c"))
+	assertGet(t, slice, "length", types.NewNumber(3))
+
+	err = slice.Put(S("length"), types.NewNumber(1), true)
+	assert.NoError(t, err, "truncating")
+	assertGet(t, slice, "length", types.NewNumber(1))
+	assertGet(t, slice, "2", types.Undefined)
+
+	err = slice.Put(S("length"), types.NewNumber(-1), true)
+	assert.Error(t, err, "writing invalid length")
+
+	err = slice.Put(S("4294967294"), Str("z"), true)
+	assert.Error(t, err, "growing beyond the limit")
+	assertGet(t, slice, "length", types.NewNumber(1))
+}
+
+func assertGet(t *testing.T, obj types.Object, name string, want types.Value) {
+	t.Helper()
+
+	got, err := obj.Get(S(name))
+	assert.NoError(t, err, "getting %s", name)
+
+	if !types.StrictEqual(got, want) {
+		t.Fatalf("%s: got %v but want %v", name, got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"sort"
+	"strconv"
+)
+
+var sortAttr = S("sort")
+
+// newSlicePrototype creates the prototype of slices, with the
+// Array.prototype methods that work on fixed length arrays.
+// https://es5.github.io/#x15.4.4
+func newSlicePrototype() *DataObject {
+	proto := NewBaseDataObject()
+
+	sortfn := NewBuiltinfnArgs(sliceSort, ArgAny)
+	_, _ = proto.DefineOwnPropertyP(sortAttr,
+		NewDataPropDesc(sortfn, true, false, true), false)
+
+	return proto
+}
+
+// sliceSort sorts the elements of the slice in place and returns it.
+// Elements are ordered by comparefn, when it's a function, or by their
+// string values. The sort is stable and undefined elements are moved
+// to the end, without being compared. Errors of comparefn stop the
+// sort, leaving the slice untouched.
+// https://es5.github.io/#x15.4.4.11
+func sliceSort(this Object, args []Value) (Value, error) {
+	s, ok := this.(*Slice)
+	if !ok {
+		return nil, NewTypeError("Array.prototype.sort called on non slice")
+	}
+
+	var comparefn callable
+	if cmp := args[0]; cmp.Kind() != KindUndefined {
+		comparefn, ok = cmp.(callable)
+		if !ok {
+			return nil, NewTypeError("the comparison function must be a function or undefined")
+		}
+	}
+
+	values := make([]Value, 0, s.Len())
+	undefineds := 0
+	for _, val := range s.Values() {
+		if val.Kind() == KindUndefined {
+			undefineds++
+			continue
+		}
+		values = append(values, val)
+	}
+
+	var err error
+	sort.SliceStable(values, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+
+		var less bool
+		less, err = sortLess(comparefn, values[i], values[j])
+		return less
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < undefineds; i++ {
+		values = append(values, Undefined)
+	}
+
+	for i, val := range values {
+		err := s.Put(S(strconv.Itoa(i)), val, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// sortLess tells if x sorts before y.
+// https://es5.github.io/#x15.4.4.11
+func sortLess(comparefn callable, x, y Value) (bool, error) {
+	if comparefn == nil {
+		return compareStr(x.ToString(), y.ToString()) < 0, nil
+	}
+
+	res, err := comparefn.Call(nil, []Value{x, y})
+	if err != nil {
+		return false, err
+	}
+
+	return res.ToNumber().Value() < 0, nil
+}
+
+// compareStr compares the code units of a and b, as the
+// relational operators compare strings.
+// https://es5.github.io/#x11.8.5
+func compareStr(a, b String) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return len(a) - len(b)
+}
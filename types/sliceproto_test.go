@@ -0,0 +1,130 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestSliceSort(t *testing.T) {
+	num := types.NewNumber
+
+	// compares the numbers ignoring the fraction
+	byInt := types.NewBuiltinfn(func(_ types.Object, args []types.Value) (types.Value, error) {
+		x, y := int(args[0].ToNumber().Value()), int(args[1].ToNumber().Value())
+		return num(float64(x - y)), nil
+	})
+
+	for _, tc := range []struct {
+		name   string
+		values []types.Value
+		cmp    types.Value
+		want   []types.Value
+	}{
+		{
+			name:   "Empty",
+			values: []types.Value{},
+			cmp:    types.Undefined,
+			want:   []types.Value{},
+		},
+		{
+			name:   "Strings",
+			values: []types.Value{Str("b"), Str("c"), Str("a")},
+			cmp:    types.Undefined,
+			want:   []types.Value{Str("a"), Str("b"), Str("c")},
+		},
+		{
+			name:   "NumbersAsStrings",
+			values: []types.Value{num(10), num(9), num(1)},
+			cmp:    types.Undefined,
+			want:   []types.Value{num(1), num(10), num(9)},
+		},
+		{
+			name:   "CodeUnits",
+			values: []types.Value{Str("😀"), Str("￿"), Str("Z")},
+			cmp:    types.Undefined,
+			want:   []types.Value{Str("Z"), Str("😀"), Str("￿")},
+		},
+		{
+			name:   "UndefinedLast",
+			values: []types.Value{types.Undefined, Str("undefinee"), Str("a")},
+			cmp:    types.Undefined,
+			want:   []types.Value{Str("a"), Str("undefinee"), types.Undefined},
+		},
+		{
+			name:   "Comparator",
+			values: []types.Value{num(10), num(9), num(1)},
+			cmp:    byInt,
+			want:   []types.Value{num(1), num(9), num(10)},
+		},
+		{
+			name:   "Stable",
+			values: []types.Value{num(2.1), num(1.1), num(2.2), num(1.2), num(2.3)},
+			cmp:    byInt,
+			want:   []types.Value{num(1.1), num(1.2), num(2.1), num(2.2), num(2.3)},
+		},
+		{
+			name:   "ComparatorNotCalledForUndefined",
+			values: []types.Value{types.Undefined, num(2), types.Undefined, num(1)},
+			cmp:    byInt,
+			want:   []types.Value{num(1), num(2), types.Undefined, types.Undefined},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			slice := types.NewSlice(tc.values, types.SliceShared)
+
+			got, err := callSort(t, slice, tc.cmp)
+			assert.NoError(t, err, "sorting")
+
+			if got != slice {
+				t.Fatalf("sort returned %v, want the slice", got)
+			}
+
+			assertValues(t, tc.values, tc.want)
+		})
+	}
+}
+
+func TestSliceSortErrors(t *testing.T) {
+	values := []types.Value{Str("b"), Str("a")}
+	slice := types.NewSlice(values, types.SliceShared)
+
+	fails := types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		return nil, types.NewTypeError("fails")
+	})
+
+	_, err := callSort(t, slice, fails)
+	assert.EqualErrs(t, types.NewTypeError("fails"), err, "comparator error")
+	assertValues(t, values, []types.Value{Str("b"), Str("a")})
+
+	_, err = callSort(t, slice, Str("f"))
+	if _, ok := err.(types.TypeError); !ok {
+		t.Fatalf("got error %v, want a TypeError", err)
+	}
+}
+
+func TestSliceSortCopyOnWrite(t *testing.T) {
+	values := []types.Value{Str("b"), Str("a")}
+	slice := types.NewSlice(values, types.SliceCopyOnWrite)
+
+	_, err := callSort(t, slice, types.Undefined)
+	assert.NoError(t, err, "sorting")
+
+	assertValues(t, slice.Values(), []types.Value{Str("a"), Str("b")})
+	assertValues(t, values, []types.Value{Str("b"), Str("a")})
+}
+
+func callSort(t *testing.T, slice *types.Slice, cmp types.Value) (types.Value, error) {
+	t.Helper()
+
+	method, err := slice.Get(S("sort"))
+	assert.NoError(t, err, "getting sort")
+
+	sort, ok := method.(types.Function)
+	if !ok {
+		t.Fatalf("sort is not a function: %v", method)
+	}
+
+	return sort.Call(slice, []types.Value{cmp})
+}
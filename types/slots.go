@@ -0,0 +1,37 @@
+package types
+
+// Slots are the bindings of a declarative environment, eg.: of a
+// function call, stored by the slot of each name as resolved by the
+// scope analysis, so they're read and written without hashing names.
+// Unset slots are nil.
+type Slots struct {
+	values []Value
+
+	// account of the heap tracking the slots, if any
+	account *heapAccount
+}
+
+// NewSlots creates n unset slots.
+func NewSlots(n int) *Slots {
+	return &Slots{values: make([]Value, n)}
+}
+
+// Len is the number of slots.
+func (s *Slots) Len() int {
+	return len(s.values)
+}
+
+// Get returns the value of slot, nil if it's unset.
+func (s *Slots) Get(slot int) Value {
+	return s.values[slot]
+}
+
+// Set the value of slot.
+func (s *Slots) Set(slot int, val Value) {
+	old := s.values[slot]
+	s.values[slot] = val
+
+	if s.account != nil {
+		s.account.assigned(old, val)
+	}
+}
@@ -1,10 +1,8 @@
 package types
 
 import (
-	"math"
-	"strconv"
-
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/internal/numparse"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -20,7 +18,7 @@ func NewString(str string) String {
 func (a String) ToPrimitive(hint Kind) (Value, error) { return a, nil }
 
 func (a String) ToObject() (Object, error) {
-	panic("not implemented yet")
+	return NewStringObject(a), nil
 }
 
 func (a String) Length() int {
@@ -39,12 +37,11 @@ func (a String) ToBool() Bool {
 	return Bool(a.IsTrue())
 }
 
+// ToNumber converts the string to a number, NaN if it's not a
+// valid number.
+// http://es5.github.io/#x9.3.1
 func (a String) ToNumber() Number {
-	n, err := strconv.ParseFloat(utf16.Str(a).String(), 64)
-	if err != nil {
-		return NewNumber(math.NaN())
-	}
-	return NewNumber(n)
+	return NewNumber(numparse.String(a.String()))
 }
 
 func (a String) ToString() String {
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"strconv"
+
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+type (
+	// StringObject is the wrapper object of a string primitive.
+	// Its index properties are the UTF-16 code units of the string,
+	// they and the length property are read only.
+	// https://es5.github.io/#x15.5.5
+	StringObject struct {
+		*DataObject
+
+		value String
+	}
+)
+
+// NewStringObject wraps the string primitive value. Each wrapper
+// gets its own prototype, so changes to it don't leak to other
+// strings.
+func NewStringObject(value String) *StringObject {
+	obj := NewDataObject(newStringPrototype())
+	obj.class = "String"
+
+	return &StringObject{
+		DataObject: obj,
+		value:      value,
+	}
+}
+
+// PrimitiveValue returns the wrapped string.
+func (s *StringObject) PrimitiveValue() String { return s.value }
+
+// Get returns the code unit of index properties and the string
+// length for the length property.
+func (s *StringObject) Get(name utf16.Str) (Value, error) {
+	desc, ok := s.ownProperty(name)
+	if ok {
+		return desc.Value(), nil
+	}
+
+	return s.DataObject.Get(name)
+}
+
+// CanPut tells if name can be written. Index properties and
+// length can't.
+func (s *StringObject) CanPut(name utf16.Str) bool {
+	if _, ok := s.ownProperty(name); ok {
+		return false
+	}
+
+	return s.DataObject.CanPut(name)
+}
+
+// Put stores the property as in DataObject, failing for the
+// read only index and length properties.
+func (s *StringObject) Put(name utf16.Str, val Value, throw bool) error {
+	if _, ok := s.ownProperty(name); !ok {
+		return s.DataObject.Put(name, val, throw)
+	}
+
+	if throw {
+		return NewTypeError("cannot assign to read only property %s of string", name)
+	}
+
+	return nil
+}
+
+// HasProperty tells if name is an index in range, length or a
+// property of the object.
+func (s *StringObject) HasProperty(name utf16.Str) bool {
+	_, ok := s.getProperty(name)
+	return ok
+}
+
+// OwnPropertyKeys lists the string indexes, length (when filter is
+// AllKeys) and then the other own properties.
+func (s *StringObject) OwnPropertyKeys(filter KeyFilter) []utf16.Str {
+	keys := make([]utf16.Str, 0, len(s.value)+1)
+	for i := range s.value {
+		keys = append(keys, S(strconv.Itoa(i)))
+	}
+
+	if filter == AllKeys {
+		keys = append(keys, lengthAttr)
+	}
+
+	return append(keys, s.DataObject.OwnPropertyKeys(filter)...)
+}
+
+// ToPrimitive returns the wrapped string.
+func (s *StringObject) ToPrimitive(hint Kind) (Value, error) {
+	return s.value, nil
+}
+
+// ToString returns the wrapped string.
+func (s *StringObject) ToString() String {
+	return s.value
+}
+
+// ToNumber converts the wrapped string.
+func (s *StringObject) ToNumber() Number {
+	return s.value.ToNumber()
+}
+
+// ToObject returns itself.
+func (s *StringObject) ToObject() (Object, error) {
+	return s, nil
+}
+
+func (s *StringObject) getProperty(name utf16.Str) (*PropertyDescriptor, bool) {
+	desc, ok := s.ownProperty(name)
+	if ok {
+		return desc, true
+	}
+
+	return s.DataObject.getProperty(name)
+}
+
+// https://es5.github.io/#x15.5.5.2
+func (s *StringObject) ownProperty(name utf16.Str) (*PropertyDescriptor, bool) {
+	if index, ok := arrayIndex(name.String()); ok {
+		if int64(index) >= int64(len(s.value)) {
+			return nil, false
+		}
+
+		char := s.value[index : index+1]
+		return NewDataPropDesc(char, false, true, false), true
+	}
+
+	if name.String() == lengthAttr.String() {
+		length := NewNumber(float64(len(s.value)))
+		return NewDataPropDesc(length, false, false, false), true
+	}
+
+	return nil, false
+}
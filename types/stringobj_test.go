@@ -0,0 +1,41 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestStringObject(t *testing.T) {
+	obj, err := Str("hi").ToObject()
+	assert.NoError(t, err, "wrapping string")
+
+	assertGet(t, obj, "0", Str("h"))
+	assertGet(t, obj, "1", Str("i"))
+	assertGet(t, obj, "2", types.Undefined)
+	assertGet(t, obj, "length", types.NewNumber(2))
+
+	err = obj.Put(S("0"), Str("x"), true)
+	assert.Error(t, err, "writing index")
+
+	err = obj.Put(S("length"), types.NewNumber(0), false)
+	assert.NoError(t, err, "silently failing on length")
+	assertGet(t, obj, "length", types.NewNumber(2))
+
+	err = obj.Put(S("name"), Str("str"), true)
+	assert.NoError(t, err, "writing property")
+
+	if !obj.HasProperty(S("1")) || obj.HasProperty(S("2")) {
+		t.Fatal("wrong index properties")
+	}
+
+	assertKeys(t, obj.OwnPropertyKeys(types.AllKeys), []string{
+		"0", "1", "length", "name",
+	})
+
+	strobj := obj.(*types.StringObject)
+	if !types.StrictEqual(Str("hi"), strobj.PrimitiveValue()) {
+		t.Fatalf("got %v but want hi", strobj.PrimitiveValue())
+	}
+}
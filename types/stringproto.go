@@ -0,0 +1,142 @@
+package types
+
+import (
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+var replaceAttr = S("replace")
+
+// newStringPrototype creates the prototype of string objects,
+// with the String.prototype methods.
+// https://es5.github.io/#x15.5.4
+func newStringPrototype() *DataObject {
+	proto := NewBaseDataObject()
+
+	replacefn := NewBuiltinfnArgs(stringReplace, ArgAny, ArgAny)
+	_, _ = proto.DefineOwnPropertyP(replaceAttr,
+		NewDataPropDesc(replacefn, true, false, true), false)
+
+	return proto
+}
+
+// stringReplace replaces the first occurrence of the search string.
+// The replacement is the result of calling replaceValue, when it's a
+// function, or the replaceValue string with its $ patterns
+// substituted. Regular expression patterns are not supported yet.
+// https://es5.github.io/#x15.5.4.11
+func stringReplace(this Object, args []Value) (Value, error) {
+	thisval, ok := this.(Value)
+	if !ok {
+		return nil, NewTypeError("String.prototype.replace called on null or undefined")
+	}
+
+	str := utf16.Str(thisval.ToString())
+	search := utf16.Str(args[0].ToString())
+	replaceValue := args[1]
+
+	position := str.Index(search)
+	if position < 0 {
+		return String(str), nil
+	}
+
+	matched := str[position : position+len(search)]
+
+	var replacement utf16.Str
+	if fn, ok := replaceValue.(callable); ok {
+		val, err := fn.Call(nil, []Value{
+			String(matched), NewNumber(float64(position)), String(str),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		replacement = utf16.Str(val.ToString())
+	} else {
+		replacement = getSubstitution(matched, str, position, nil,
+			utf16.Str(replaceValue.ToString()))
+	}
+
+	result := make(utf16.Str, 0, len(str)-len(matched)+len(replacement))
+	result = append(result, str[:position]...)
+	result = append(result, replacement...)
+	result = append(result, str[position+len(matched):]...)
+	return String(result), nil
+}
+
+// getSubstitution expands the $ patterns of replacement, which
+// refer to the match at position of str and to its captures:
+//
+//	$$ is $
+//	$& is the match
+//	$` is the part of str before the match
+//	$' is the part of str after the match
+//	$n and $nn are the nth capture, from 1 to 99
+//
+// Patterns referring to captures that don't exist are kept as is.
+// http://www.ecma-international.org/ecma-262/6.0/#sec-getsubstitution
+func getSubstitution(
+	matched, str utf16.Str, position int, captures []Value, replacement utf16.Str,
+) utf16.Str {
+	tail := position + len(matched)
+	result := make(utf16.Str, 0, len(replacement))
+
+	for i := 0; i < len(replacement); i++ {
+		if replacement[i] != '$' || i+1 == len(replacement) {
+			result = append(result, replacement[i])
+			continue
+		}
+
+		switch next := replacement[i+1]; {
+		case next == '$':
+			result = append(result, '$')
+		case next == '&':
+			result = append(result, matched...)
+		case next == '`':
+			result = append(result, str[:position]...)
+		case next == '\'':
+			result = append(result, str[tail:]...)
+		case isDigit(next):
+			n, size := captureIndex(replacement[i+1:], len(captures))
+			if size == 0 {
+				result = append(result, '$')
+				continue
+			}
+
+			if capture := captures[n-1]; capture.Kind() != KindUndefined {
+				result = append(result, utf16.Str(capture.ToString())...)
+			}
+			i += size
+			continue
+		default:
+			result = append(result, '$')
+			continue
+		}
+
+		i++
+	}
+
+	return result
+}
+
+// captureIndex parses the one or two digits of a $n or $nn pattern,
+// preferring two digits if they refer to an existing capture. It
+// returns the size zero if no capture is referred.
+func captureIndex(digits utf16.Str, ncaptures int) (int, int) {
+	n := int(digits[0] - '0')
+	if len(digits) > 1 && isDigit(digits[1]) {
+		nn := n*10 + int(digits[1]-'0')
+		if nn >= 1 && nn <= ncaptures {
+			return nn, 2
+		}
+	}
+
+	if n >= 1 && n <= ncaptures {
+		return n, 1
+	}
+
+	return 0, 0
+}
+
+func isDigit(c uint16) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,148 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/types"
+	"github.com/madlambda/spells/assert"
+)
+
+func TestStringReplace(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		str         string
+		search      string
+		replacement types.Value
+		want        string
+	}{
+		{
+			name:        "First",
+			str:         "a-b-c",
+			search:      "-",
+			replacement: Str("+"),
+			want:        "a+b-c",
+		},
+		{
+			name:        "NotFound",
+			str:         "abc",
+			search:      "x",
+			replacement: Str("y"),
+			want:        "abc",
+		},
+		{
+			name:        "EmptySearch",
+			str:         "abc",
+			search:      "",
+			replacement: Str("x"),
+			want:        "xabc",
+		},
+		{
+			name:        "Dollar",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("$$"),
+			want:        "a$c",
+		},
+		{
+			name:        "Match",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("[$&]"),
+			want:        "a[b]c",
+		},
+		{
+			name:        "Before",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("$`"),
+			want:        "aac",
+		},
+		{
+			name:        "After",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("$'"),
+			want:        "acc",
+		},
+		{
+			name:        "CaptureWithoutGroups",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("$1$10"),
+			want:        "a$1$10c",
+		},
+		{
+			name:        "UnknownPattern",
+			str:         "abc",
+			search:      "b",
+			replacement: Str("$x$"),
+			want:        "a$x$c",
+		},
+		{
+			name:   "Function",
+			str:    "abcb",
+			search: "b",
+			replacement: types.NewBuiltinfn(
+				func(_ types.Object, args []types.Value) (types.Value, error) {
+					assertValues(t, args, []types.Value{
+						Str("b"), types.NewNumber(1), Str("abcb"),
+					})
+					return Str("$&!"), nil
+				}),
+			want: "a$&!cb",
+		},
+		{
+			name:        "ConvertsReplacement",
+			str:         "abc",
+			search:      "b",
+			replacement: types.NewNumber(1),
+			want:        "a1c",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := callReplace(t, Str(tc.str), Str(tc.search), tc.replacement)
+			if !types.StrictEqual(got, Str(tc.want)) {
+				t.Fatalf("got %v, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringReplaceError(t *testing.T) {
+	fails := types.NewBuiltinfn(func(types.Object, []types.Value) (types.Value, error) {
+		return nil, types.NewTypeError("fails")
+	})
+
+	obj, err := Str("abc").ToObject()
+	assert.NoError(t, err, "wrapping string")
+
+	replace, err := obj.Get(S("replace"))
+	assert.NoError(t, err, "getting replace")
+
+	_, err = replace.(types.Function).Call(obj, []types.Value{Str("b"), fails})
+	assert.Error(t, err, "replacer error must be returned")
+
+	_, err = replace.(types.Function).Call(nil, []types.Value{Str("b"), Str("c")})
+	if _, ok := err.(types.TypeError); !ok {
+		t.Fatalf("got error %v, want a TypeError", err)
+	}
+}
+
+func callReplace(t *testing.T, str types.String, args ...types.Value) types.Value {
+	t.Helper()
+
+	obj, err := str.ToObject()
+	assert.NoError(t, err, "wrapping string")
+
+	method, err := obj.Get(S("replace"))
+	assert.NoError(t, err, "getting replace")
+
+	replace, ok := method.(types.Function)
+	if !ok {
+		t.Fatalf("replace is not a function: %v", method)
+	}
+
+	val, err := replace.Call(obj, args)
+	assert.NoError(t, err, "calling replace")
+	return val
+}
@@ -3,7 +3,7 @@ package types
 import (
 	"math"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type undefined utf16.Str // yeah, science!
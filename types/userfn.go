@@ -2,7 +2,7 @@ package types
 
 import (
 	"github.com/NeowayLabs/abad/ast"
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -16,9 +16,16 @@ type (
 		params []utf16.Str
 		body   *ast.Program
 		scope  interface{}
+		call   Caller
 	}
+
+	// Caller evaluates the body of user functions, it's
+	// provided by the interpreter.
+	Caller func(fn *UserFunction, this Object, args []Value) (Value, error)
 )
 
+var nameAttr = S("name")
+
 func NewUserFunctionPrototype() *UserFunction {
 	return &UserFunction{
 		isFnPrototype: true,
@@ -28,19 +35,55 @@ func NewUserFunctionPrototype() *UserFunction {
 
 func NewUserFunction(
 	params []utf16.Str, body *ast.Program, scope interface{}, strict bool,
+	call Caller,
 ) *UserFunction {
 	return &UserFunction{
 		params:     params,
 		body:       body,
 		scope:      scope,
+		call:       call,
 		DataObject: NewDataObject(NewUserFunctionPrototype()),
 	}
 }
 
-func (f *UserFunction) Call(this *Object, params []Value) Value {
-	if f.isFnPrototype {
-		return Undefined
+// Call evaluates the function with the interpreter that created it.
+func (f *UserFunction) Call(this Object, args []Value) (Value, error) {
+	if f.isFnPrototype || f.call == nil {
+		return Undefined, nil
 	}
 
-	return True // todo
+	return f.call(f, this, args)
+}
+
+// ToObject returns itself.
+func (f *UserFunction) ToObject() (Object, error) {
+	return f, nil
+}
+
+// Params returns the names of the formal parameters.
+func (f *UserFunction) Params() []utf16.Str { return f.params }
+
+// Body returns the code of the function.
+func (f *UserFunction) Body() *ast.Program { return f.body }
+
+// Scope returns the scope where the function was declared.
+func (f *UserFunction) Scope() interface{} { return f.scope }
+
+// Name returns the name of the function, empty for
+// anonymous functions.
+func (f *UserFunction) Name() utf16.Str {
+	desc, ok := f.getOwnProperty(nameAttr)
+	if !ok {
+		return nil
+	}
+
+	return utf16.Str(desc.Value().ToString())
+}
+
+// SetName defines the name property of the function. It is read
+// only and not enumerable, as in later editions of the spec.
+// https://www.ecma-international.org/ecma-262/6.0/#sec-setfunctionname
+func (f *UserFunction) SetName(name utf16.Str) {
+	desc := NewDataPropDesc(String(name), false, false, true)
+	_, _ = f.DefineOwnPropertyP(nameAttr, desc, false)
 }
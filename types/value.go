@@ -1,7 +1,7 @@
 package types
 
 import (
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 type (
@@ -44,16 +44,28 @@ type (
 		ECMAObject
 
 		Class() string
+		HasProperty(name utf16.Str) bool
+		OwnPropertyKeys(filter KeyFilter) []utf16.Str
 		getProperty(name utf16.Str) (*PropertyDescriptor, bool)
+		dataObject() *DataObject
 
 		String() string
 	}
 
 	// Function is a kind of Object that's executable, ie. it has a Call method.
+	// The error is the exception thrown by the function, if any.
 	Function interface {
 		Object
 
-		Call(this Object, args []Value) Value
+		Call(this Object, args []Value) (Value, error)
+	}
+
+	// Constructor is a kind of Object that creates objects
+	// with the new operator.
+	Constructor interface {
+		Object
+
+		Construct(args []Value) (Value, error)
 	}
 )
 
@@ -66,6 +78,12 @@ const (
 	KindObject
 )
 
+// NoHint is the hint of ToPrimitive for the operators without a
+// preferred type, + and ==. Objects convert as with KindNumber,
+// except dates, which convert as with KindString.
+// https://es5.github.io/#x8.12.8
+const NoHint = KindUndefined
+
 func (k Kind) String() string {
 	switch k {
 	case KindUndefined:
@@ -83,7 +101,6 @@ func (k Kind) String() string {
 	}
 
 	panic("unrecognized type")
-	return ""
 }
 
 // StrictEqual compares values a and b using ECMAScript === (strict) rules.
@@ -120,14 +137,55 @@ func StrictEqual(a, b Value) bool {
 	}
 
 	if akind == KindObject {
-		aobj := a.(*DataObject)
-		bobj := b.(*DataObject)
-		return aobj == bobj // pointer comparison
+		// the same object, of any implementation
+		return a == b
 	}
 
 	panic("strict equal not implemented")
+}
 
-	return false
+// Equal compares values a and b using ECMAScript == rules, which
+// convert operands of different types, eg.: "1" == 1. The error is
+// the one thrown converting an object to a primitive.
+// https://es5.github.io/#x11.9.3
+func Equal(a, b Value) (bool, error) {
+	akind := a.Kind()
+	bkind := b.Kind()
+
+	if akind == bkind {
+		return StrictEqual(a, b), nil
+	}
+
+	switch {
+	case isNullish(akind) && isNullish(bkind):
+		return true, nil
+	case akind == KindNumber && bkind == KindString:
+		return StrictEqual(a, b.ToNumber()), nil
+	case akind == KindString && bkind == KindNumber:
+		return StrictEqual(a.ToNumber(), b), nil
+	case akind == KindBool:
+		return Equal(a.ToNumber(), b)
+	case bkind == KindBool:
+		return Equal(a, b.ToNumber())
+	case (akind == KindNumber || akind == KindString) && bkind == KindObject:
+		prim, err := b.ToPrimitive(NoHint)
+		if err != nil {
+			return false, err
+		}
+		return Equal(a, prim)
+	case akind == KindObject && (bkind == KindNumber || bkind == KindString):
+		prim, err := a.ToPrimitive(NoHint)
+		if err != nil {
+			return false, err
+		}
+		return Equal(prim, b)
+	}
+
+	return false, nil
+}
+
+func isNullish(kind Kind) bool {
+	return kind == KindUndefined || kind == KindNull
 }
 
 // IsPrimitive tells if val is a primitive value.
@@ -0,0 +1,113 @@
+package utf16
+
+import (
+	"unicode"
+	"unicode/utf16"
+)
+
+// ToUpper maps the characters of s to upper case with the simple,
+// locale independent, Unicode case mapping. Lone surrogates are kept.
+func (s Str) ToUpper() Str {
+	return s.mapRunes(unicode.ToUpper)
+}
+
+// ToLower maps the characters of s to lower case with the simple,
+// locale independent, Unicode case mapping. Lone surrogates are kept.
+func (s Str) ToLower() Str {
+	return s.mapRunes(unicode.ToLower)
+}
+
+// EqualFold tells if s and o are equal under simple Unicode case
+// folding, eg.: "Go" and "GO".
+func (s Str) EqualFold(o Str) bool {
+	for len(s) > 0 && len(o) > 0 {
+		r, n := s.rune(0)
+		q, m := o.rune(0)
+		s, o = s[n:], o[m:]
+
+		if r != q && !equalFold(r, q) {
+			return false
+		}
+	}
+
+	return len(s) == len(o)
+}
+
+// Trim removes the leading and trailing white spaces and line
+// terminators, as String.prototype.trim.
+// http://es5.github.io/#x15.5.4.20
+func (s Str) Trim() Str {
+	start := 0
+	for start < len(s) && isSpace(s[start]) {
+		start++
+	}
+
+	end := len(s)
+	for end > start && isSpace(s[end-1]) {
+		end--
+	}
+
+	return s[start:end]
+}
+
+func (s Str) mapRunes(mapping func(rune) rune) Str {
+	res := make(Str, 0, len(s))
+
+	for i := 0; i < len(s); {
+		r, n := s.rune(i)
+		i += n
+
+		if n == 1 && utf16.IsSurrogate(r) {
+			res = append(res, uint16(r))
+			continue
+		}
+
+		r = mapping(r)
+		if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+			res = append(res, uint16(r1), uint16(r2))
+			continue
+		}
+
+		res = append(res, uint16(r))
+	}
+
+	return res
+}
+
+// rune decodes the character at s[i] and tells how many code units
+// it takes. Lone surrogates are returned as is.
+func (s Str) rune(i int) (rune, int) {
+	r := rune(s[i])
+	if utf16.IsSurrogate(r) && i+1 < len(s) {
+		if pair := utf16.DecodeRune(r, rune(s[i+1])); pair != unicode.ReplacementChar {
+			return pair, 2
+		}
+	}
+
+	return r, 1
+}
+
+// equalFold tells if r and q are in the same simple case folding
+// orbit.
+func equalFold(r, q rune) bool {
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f == q {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSpace tells if c is a WhiteSpace or a LineTerminator.
+// http://es5.github.io/#x7.2
+// http://es5.github.io/#x7.3
+func isSpace(c uint16) bool {
+	switch c {
+	case '\t', '\v', '\f', ' ', '\u00A0', '\uFEFF',
+		'\n', '\r', '\u2028', '\u2029':
+		return true
+	}
+
+	return unicode.Is(unicode.Zs, rune(c))
+}
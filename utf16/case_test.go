@@ -0,0 +1,76 @@
+package utf16_test
+
+import (
+	"testing"
+
+	"github.com/NeowayLabs/abad/utf16"
+)
+
+// loneSurrogate is an unpaired high surrogate, which can't be
+// written in a Go string.
+const loneSurrogate = 0xD800
+
+func TestCaseMapping(t *testing.T) {
+	for _, tc := range []struct {
+		str   utf16.Str
+		upper utf16.Str
+		lower utf16.Str
+	}{
+		{str: S(""), upper: S(""), lower: S("")},
+		{str: S("Hello, World"), upper: S("HELLO, WORLD"), lower: S("hello, world")},
+		{str: S("ÀéÎõü"), upper: S("ÀÉÎÕÜ"), lower: S("àéîõü")},
+		{str: S("Σίσυφος"), upper: S("ΣΊΣΥΦΟΣ"), lower: S("σίσυφος")},
+		{str: S("𐐨𐐀"), upper: S("𐐀𐐀"), lower: S("𐐨𐐨")},
+		{
+			str:   append(S("a"), loneSurrogate, 'b'),
+			upper: append(S("A"), loneSurrogate, 'B'),
+			lower: append(S("a"), loneSurrogate, 'b'),
+		},
+	} {
+		if got := tc.str.ToUpper(); !got.Equal(tc.upper) {
+			t.Errorf("ToUpper(%v) = %v, want %v", tc.str, got, tc.upper)
+		}
+		if got := tc.str.ToLower(); !got.Equal(tc.lower) {
+			t.Errorf("ToLower(%v) = %v, want %v", tc.str, got, tc.lower)
+		}
+	}
+}
+
+func TestEqualFold(t *testing.T) {
+	for _, tc := range []struct {
+		a, b utf16.Str
+		want bool
+	}{
+		{a: S(""), b: S(""), want: true},
+		{a: S("Go"), b: S("GO"), want: true},
+		{a: S("straße"), b: S("STRASSE"), want: false},
+		{a: S("\u212Aelvin"), b: S("kelvin"), want: true},
+		{a: S("σ"), b: S("Σ"), want: true},
+		{a: S("𐐨"), b: S("𐐀"), want: true},
+		{a: S("ab"), b: S("abc"), want: false},
+		{a: utf16.Str{loneSurrogate}, b: utf16.Str{loneSurrogate}, want: true},
+		{a: utf16.Str{loneSurrogate}, b: S("a"), want: false},
+	} {
+		if got := tc.a.EqualFold(tc.b); got != tc.want {
+			t.Errorf("EqualFold(%v, %v) = %t, want %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	for _, tc := range []struct {
+		str  string
+		want string
+	}{
+		{str: "", want: ""},
+		{str: "   ", want: ""},
+		{str: "a", want: "a"},
+		{str: " \t\n a b \r\v\f", want: "a b"},
+		{str: " \u00A0\uFEFF a \u2028\u2029\u3000", want: "a"},
+		{str: "\u200Ba", want: "\u200Ba"},
+	} {
+		if got := S(tc.str).Trim(); got.String() != tc.want {
+			t.Errorf("Trim(%q) = %q, want %q", tc.str, got, tc.want)
+		}
+	}
+}
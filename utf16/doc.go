@@ -0,0 +1,14 @@
+// Package utf16 implements the strings of JavaScript, sequences of
+// UTF-16 code units, and their conversion from and to Go strings.
+//
+// Embedders use it to name properties and to build the strings of
+// their builtins, eg.:
+//
+//	obj.Put(utf16.S("name"), types.NewString("abad"), true)
+//
+// Unlike Go strings, a Str may have lone surrogates, which are
+// decoded as U+FFFD by String and Runes.
+//
+// The API of the package is stable: its exported names are not
+// removed or changed in incompatible ways, only new ones are added.
+package utf16
@@ -5,14 +5,18 @@ type (
 	Str []uint16
 )
 
+// S converts the Go string a to a Str, it's a short form of NewStr
+// for literals.
 func S(a string) Str {
 	return NewStr(a)
 }
 
+// NewStr converts the Go string a to a Str.
 func NewStr(a string) Str {
 	return Encode(a)
 }
 
+// NewFromRunes converts runes to a Str.
 func NewFromRunes(r []rune) Str {
 	return EncodeRunes(r)
 }
@@ -49,25 +53,13 @@ func (s Str) Index(substr Str) int {
 	}
 
 	// brute force
-	var i, j int
-	for j < len(substr) && i < len(s) {
-		si := s[i]
-		bi := substr[j]
-
-		i++
-
-		if si == bi {
-			j++
-		} else {
-			j = 0
+	n := len(substr)
+	for i := 0; i+n <= len(s); i++ {
+		if s[i : i+n].Equal(substr) {
+			return i
 		}
 	}
-
-	if j == len(substr) {
-		return i - len(substr)
-	}
 	return -1
-
 }
 
 // Equal checks if s is equal o
@@ -84,6 +76,32 @@ func (s Str) Equal(o Str) bool {
 	return true
 }
 
+// Compare returns -1, 0 or 1 if s is less than, equal to or greater
+// than o, comparing the code units lexicographically, as the
+// relational operators of JavaScript.
+// https://es5.github.io/#x11.8.5
+func (s Str) Compare(o Str) int {
+	for i := 0; i < len(s) && i < len(o); i++ {
+		if s[i] != o[i] {
+			if s[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(s) < len(o):
+		return -1
+	case len(s) > len(o):
+		return 1
+	}
+
+	return 0
+}
+
+// TrimPrefix returns s without the leading substr, or s if it
+// doesn't start with substr.
 func (s Str) TrimPrefix(substr Str) Str {
 	if s.Index(substr) == 0 {
 		return Str(s[len(substr):])
@@ -91,22 +109,28 @@ func (s Str) TrimPrefix(substr Str) Str {
 	return s
 }
 
+// HasPrefix tells if s starts with substr.
 func (s Str) HasPrefix(substr Str) bool {
 	return s.Index(substr) == 0
 }
 
+// Len returns the number of code units of s, the length of the
+// string in JavaScript.
 func (s Str) Len() int {
 	return len(s)
 }
 
+// Runes decodes s into runes.
 func (s Str) Runes() []rune {
 	return DecodeRunes(s)
 }
 
+// Append returns the concatenation of s and o.
 func (s Str) Append(o Str) Str {
 	return Str(append(s, o...))
 }
 
+// Prepend returns the concatenation of o and s.
 func (s Str) Prepend(o Str) Str {
 	return Str(append(o, s...))
 }
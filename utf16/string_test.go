@@ -4,7 +4,7 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/NeowayLabs/abad/internal/utf16"
+	"github.com/NeowayLabs/abad/utf16"
 )
 
 var S = utf16.S
@@ -46,6 +46,18 @@ func TestStrIndex(t *testing.T) {
 			index:    6,
 			contains: true,
 		},
+		{
+			str:      S("aab"),
+			sub:      S("ab"),
+			index:    1,
+			contains: true,
+		},
+		{
+			str:      S("abab"),
+			sub:      S("abb"),
+			index:    -1,
+			contains: false,
+		},
 		{
 			str:      S(""),
 			sub:      S("world"),
@@ -138,3 +150,26 @@ func TestPrependStrings(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareStrings(t *testing.T) {
+	for _, tc := range []struct {
+		s, o utf16.Str
+		want int
+	}{
+		{s: S("a"), o: S("b"), want: -1},
+		{s: S("b"), o: S("a"), want: 1},
+		{s: S("abad"), o: S("abad"), want: 0},
+		{s: S(""), o: S(""), want: 0},
+		{s: S("ab"), o: S("abad"), want: -1},
+		{s: S("abad"), o: S("ab"), want: 1},
+		{s: S("B"), o: S("a"), want: -1},
+		{s: S("10"), o: S("9"), want: -1},
+		// code units, not code points: U+10000 is D800 DC00
+		{s: S("\U00010000"), o: S("\uFFFF"), want: -1},
+	} {
+		got := tc.s.Compare(tc.o)
+		if got != tc.want {
+			t.Fatalf("comparing %q and %q: got %d, want %d", tc.s, tc.o, got, tc.want)
+		}
+	}
+}